@@ -0,0 +1,146 @@
+package dtos
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+)
+
+// Tamanho de página padrão e máximo da consulta à trilha de auditoria
+const (
+	AuditDefaultPageSize = 50
+	AuditMaxPageSize     = 500
+)
+
+// AuditRecordDTO representa uma ação capturada pela camada HTTP para registro na trilha de auditoria
+type AuditRecordDTO struct {
+	ActorID    *uint
+	Operator   string // Autor sem cadastro, como o operador da linha de comando; usuários são gravados só pelo ID
+	Action     string
+	TargetType string
+	TargetID   string
+	StatusCode int
+	Before     interface{} // Estado do alvo antes da ação; nil se não havia
+	After      interface{} // Estado do alvo depois da ação; nil se deixou de existir
+	IP         string
+	UserAgent  string
+	RequestID  string
+}
+
+// AuditListFilterDTO representa os filtros e a paginação aceitos na consulta à trilha de auditoria
+type AuditListFilterDTO struct {
+	ActorID    uint      `form:"actor_id"`
+	Action     string    `form:"action"`
+	TargetType string    `form:"target_type"`
+	TargetID   string    `form:"target_id"`
+	From       time.Time `form:"from" time_format:"2006-01-02T15:04:05Z07:00"`
+	To         time.Time `form:"to" time_format:"2006-01-02T15:04:05Z07:00"`
+	Page       int       `form:"page" binding:"omitempty,min=1"`
+	PageSize   int       `form:"page_size" binding:"omitempty,min=1,max=500"`
+}
+
+// AuditEventResponseDTO representa um evento da trilha de auditoria
+type AuditEventResponseDTO struct {
+	ID         uint            `json:"id"`
+	CreatedAt  time.Time       `json:"created_at"`
+	ActorID    *uint           `json:"actor_id"`
+	ActorEmail string          `json:"actor_email"`
+	Action     string          `json:"action"`
+	TargetType string          `json:"target_type"`
+	TargetID   string          `json:"target_id"`
+	StatusCode int             `json:"status_code"`
+	Before     json.RawMessage `json:"before,omitempty"`
+	After      json.RawMessage `json:"after,omitempty"`
+	Changes    json.RawMessage `json:"changes,omitempty"`
+	IP         string          `json:"ip"`
+	UserAgent  string          `json:"user_agent"`
+	RequestID  string          `json:"request_id"`
+	PrevHash   string          `json:"prev_hash"`
+	Hash       string          `json:"hash"`
+}
+
+// AuditEventPageDTO representa uma página da trilha de auditoria
+type AuditEventPageDTO struct {
+	Items    []AuditEventResponseDTO `json:"items"`
+	Total    int64                   `json:"total"`
+	Page     int                     `json:"page"`
+	PageSize int                     `json:"page_size"`
+}
+
+// AuditVerificationDTO representa o resultado da verificação da cadeia de auditoria
+type AuditVerificationDTO struct {
+	Valid         bool   `json:"valid"`
+	CheckedEvents int    `json:"checked_events"`
+	BrokenAtID    uint   `json:"broken_at_id,omitempty"` // Primeiro evento cuja ligação não confere
+	Reason        string `json:"reason,omitempty"`
+}
+
+// AuditEventToResponseDTO converte uma entidade AuditEvent para um AuditEventResponseDTO
+func AuditEventToResponseDTO(event entities.AuditEvent) AuditEventResponseDTO {
+	return AuditEventResponseDTO{
+		ID:         event.ID,
+		CreatedAt:  event.CreatedAt,
+		ActorID:    event.ActorID,
+		ActorEmail: event.ActorEmail,
+		Action:     event.Action,
+		TargetType: event.TargetType,
+		TargetID:   event.TargetID,
+		StatusCode: event.StatusCode,
+		Before:     rawJSON(event.Before),
+		After:      rawJSON(event.After),
+		Changes:    rawJSON(event.Changes),
+		IP:         event.IP,
+		UserAgent:  event.UserAgent,
+		RequestID:  event.RequestID,
+		PrevHash:   event.PrevHash,
+		Hash:       event.Hash,
+	}
+}
+
+// AuditUserSnapshotDTO representa o estado de um usuário guardado na trilha de auditoria.
+// A trilha não pode ser alterada depois de gravada, por isso traz apenas campos que não identificam
+// a pessoa: o nome e o e-mail precisam sumir quando os dados pessoais são apagados.
+type AuditUserSnapshotDTO struct {
+	ID                 uint       `json:"id"`
+	IsAdmin            bool       `json:"is_admin"`
+	IsLibrarian        bool       `json:"is_librarian"`
+	BranchID           *uint      `json:"branch_id"`
+	EmailNotifications bool       `json:"email_notifications"`
+	InAppNotifications bool       `json:"in_app_notifications"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+	DeletedAt          *time.Time `json:"deleted_at,omitempty"`
+	ErasureScheduledAt *time.Time `json:"erasure_scheduled_at,omitempty"`
+	ErasedAt           *time.Time `json:"erased_at,omitempty"`
+}
+
+// UserToAuditSnapshotDTO converte uma entidade User para o estado guardado na trilha de auditoria
+func UserToAuditSnapshotDTO(user entities.User) AuditUserSnapshotDTO {
+	var deletedAt *time.Time
+	if user.DeletedAt.Valid {
+		deletedAt = &user.DeletedAt.Time
+	}
+
+	return AuditUserSnapshotDTO{
+		ID:                 user.ID,
+		IsAdmin:            user.IsAdmin,
+		IsLibrarian:        user.IsLibrarian,
+		BranchID:           user.BranchID,
+		EmailNotifications: !user.EmailNotificationsOff,
+		InAppNotifications: !user.InAppNotificationsOff,
+		CreatedAt:          user.CreatedAt,
+		UpdatedAt:          user.UpdatedAt,
+		DeletedAt:          deletedAt,
+		ErasureScheduledAt: user.ErasureScheduledAt,
+		ErasedAt:           user.ErasedAt,
+	}
+}
+
+// rawJSON devolve o texto como JSON já serializado, ou nil se estiver vazio
+func rawJSON(value string) json.RawMessage {
+	if value == "" {
+		return nil
+	}
+	return json.RawMessage(value)
+}
@@ -8,42 +8,174 @@ import (
 
 // BookResponseDTO representa os dados de livro que serão retornados nas respostas da API
 type BookResponseDTO struct {
-	ID          uint      `json:"id"`
-	Title       string    `json:"title"`
-	Author      string    `json:"author"`
-	Description string    `json:"description"`
-	Quantity    int       `json:"quantity"`
-	Available   int       `json:"available"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID              uint                        `json:"id"`
+	Title           string                      `json:"title"`
+	Author          string                      `json:"author"`
+	Authors         []string                    `json:"authors"`
+	Description     string                      `json:"description"`
+	ISBN            string                      `json:"isbn,omitempty"`
+	Publisher       string                      `json:"publisher,omitempty"`
+	PublicationYear int                         `json:"publication_year,omitempty"`
+	Edition         string                      `json:"edition,omitempty"`
+	Language        string                      `json:"language,omitempty"`
+	PageCount       int                         `json:"page_count,omitempty"`
+	CoverURL        string                      `json:"cover_url,omitempty"`
+	Subjects        []string                    `json:"subjects"`
+	Quantity        int                         `json:"quantity"`
+	Available       int                         `json:"available"`
+	Status          string                      `json:"status"`
+	WithdrawnAt     *time.Time                  `json:"withdrawn_at,omitempty"`
+	DeletedAt       *time.Time                  `json:"deleted_at,omitempty"`
+	Branches        []BookBranchAvailabilityDTO `json:"branches"`
+	CreatedAt       time.Time                   `json:"created_at"`
+	UpdatedAt       time.Time                   `json:"updated_at"`
 }
 
-// BookCreateDTO representa os dados para criação de um livro
+// BookBranchAvailabilityDTO representa os exemplares de um livro em uma unidade
+type BookBranchAvailabilityDTO struct {
+	BranchID   uint   `json:"branch_id"`
+	BranchCode string `json:"branch_code"`
+	BranchName string `json:"branch_name"`
+	Quantity   int    `json:"quantity"`
+	Available  int    `json:"available"`
+}
+
+// InventoryReportDTO representa o resultado da conferência dos exemplares disponíveis com os empréstimos em aberto
+type InventoryReportDTO struct {
+	CheckedAt     time.Time                 `json:"checked_at"`
+	Repaired      bool                      `json:"repaired"` // Os contadores divergentes foram corrigidos
+	Discrepancies []InventoryDiscrepancyDTO `json:"discrepancies"`
+}
+
+// InventoryDiscrepancyDTO representa um contador de exemplares disponíveis que não confere com os empréstimos em aberto.
+// Sem branch_id, o contador é o do livro; com ele, o dos exemplares do livro na unidade.
+type InventoryDiscrepancyDTO struct {
+	BookID    uint   `json:"book_id"`
+	Title     string `json:"title"`
+	BranchID  *uint  `json:"branch_id"`
+	Quantity  int    `json:"quantity"`
+	Available int    `json:"available"` // Valor gravado
+	OpenLoans int    `json:"open_loans"`
+	Expected  int    `json:"expected"` // Quantidade menos empréstimos em aberto, de 0 à quantidade
+}
+
+// BookCreateDTO representa os dados para criação de um livro.
+// Os autores podem ser informados em lista (authors) ou como texto livre (author).
 type BookCreateDTO struct {
-	Title       string `json:"title" binding:"required,min=1,max=200"`
-	Author      string `json:"author" binding:"required,min=1,max=100"`
-	Description string `json:"description"`
-	Quantity    int    `json:"quantity" binding:"min=1"`
+	Title           string   `json:"title" binding:"required,min=1,max=200"`
+	Author          string   `json:"author" binding:"required_without=Authors,max=255"`
+	Authors         []string `json:"authors" binding:"required_without=Author,dive,min=1,max=100"`
+	Description     string   `json:"description"`
+	ISBN            string   `json:"isbn" binding:"omitempty,isbn"`
+	Publisher       string   `json:"publisher" binding:"max=150"`
+	PublicationYear int      `json:"publication_year" binding:"omitempty,min=1450,max=2100"`
+	Edition         string   `json:"edition" binding:"max=50"`
+	Language        string   `json:"language" binding:"omitempty,bcp47_language_tag"`
+	PageCount       int      `json:"page_count" binding:"omitempty,min=1"`
+	CoverURL        string   `json:"cover_url" binding:"omitempty,url,max=500"`
+	Subjects        []string `json:"subjects" binding:"dive,min=1,max=100"`
+	Quantity        int      `json:"quantity" binding:"min=1"`
 }
 
-// BookUpdateDTO representa os dados para atualização de um livro
+// BookUpdateDTO representa os dados para atualização de um livro.
+// Listas omitidas mantêm os valores atuais; listas vazias removem todos os itens.
 type BookUpdateDTO struct {
-	Title       string `json:"title" binding:"omitempty,min=1,max=200"`
-	Author      string `json:"author" binding:"omitempty,min=1,max=100"`
-	Description string `json:"description"`
-	Quantity    int    `json:"quantity" binding:"omitempty,min=1"`
+	Title           string   `json:"title" binding:"omitempty,min=1,max=200"`
+	Author          string   `json:"author" binding:"omitempty,min=1,max=255"`
+	Authors         []string `json:"authors" binding:"omitempty,dive,min=1,max=100"`
+	Description     string   `json:"description"`
+	ISBN            string   `json:"isbn" binding:"omitempty,isbn"`
+	Publisher       string   `json:"publisher" binding:"max=150"`
+	PublicationYear int      `json:"publication_year" binding:"omitempty,min=1450,max=2100"`
+	Edition         string   `json:"edition" binding:"max=50"`
+	Language        string   `json:"language" binding:"omitempty,bcp47_language_tag"`
+	PageCount       int      `json:"page_count" binding:"omitempty,min=1"`
+	CoverURL        string   `json:"cover_url" binding:"omitempty,url,max=500"`
+	Subjects        []string `json:"subjects" binding:"dive,min=1,max=100"`
+	Quantity        int      `json:"quantity" binding:"omitempty,min=1"`
+}
+
+// MergeIncrementQuantity soma os exemplares ao livro existente quando a criação encontra um repetido
+const MergeIncrementQuantity = "increment_quantity"
+
+// BookCreateOptionsDTO representa as opções de criação de livro recebidas na query string
+type BookCreateOptionsDTO struct {
+	Merge string `form:"merge" binding:"omitempty,oneof=increment_quantity"`
+}
+
+// BookListFilterDTO representa os filtros aceitos na listagem de livros
+type BookListFilterDTO struct {
+	Title     string `form:"title"`
+	Author    string `form:"author"`
+	Subject   string `form:"subject"`
+	ISBN      string `form:"isbn" binding:"omitempty,isbn"`
+	Publisher string `form:"publisher"`
+	Language  string `form:"language"`
+	YearFrom  int    `form:"year_from" binding:"omitempty,min=0"`
+	YearTo    int    `form:"year_to" binding:"omitempty,min=0"`
+	Available bool   `form:"available"`
+}
+
+// AdminBookListFilterDTO representa os filtros aceitos na listagem administrativa de livros
+type AdminBookListFilterDTO struct {
+	BookListFilterDTO
+	Status string `form:"status" binding:"omitempty,oneof=active withdrawn"`
 }
 
 // BookToResponseDTO converte uma entidade Book para um BookResponseDTO
 func BookToResponseDTO(book entities.Book) BookResponseDTO {
+	authors := make([]string, 0, len(book.Authors))
+	for _, author := range book.Authors {
+		authors = append(authors, author.Name)
+	}
+
+	subjects := make([]string, 0, len(book.Subjects))
+	for _, subject := range book.Subjects {
+		subjects = append(subjects, subject.Name)
+	}
+
+	branches := make([]BookBranchAvailabilityDTO, 0, len(book.Holdings))
+	for _, holding := range book.Holdings {
+		branches = append(branches, BookBranchAvailabilityDTO{
+			BranchID:   holding.BranchID,
+			BranchCode: holding.Branch.Code,
+			BranchName: holding.Branch.Name,
+			Quantity:   holding.Quantity,
+			Available:  holding.Available,
+		})
+	}
+
+	var isbn string
+	if book.ISBN != nil {
+		isbn = *book.ISBN
+	}
+
+	var deletedAt *time.Time
+	if book.DeletedAt.Valid {
+		deletedAt = &book.DeletedAt.Time
+	}
+
 	return BookResponseDTO{
-		ID:          book.ID,
-		Title:       book.Title,
-		Author:      book.Author,
-		Description: book.Description,
-		Quantity:    book.Quantity,
-		Available:   book.Available,
-		CreatedAt:   book.CreatedAt,
-		UpdatedAt:   book.UpdatedAt,
+		ID:              book.ID,
+		Title:           book.Title,
+		Author:          book.Author,
+		Authors:         authors,
+		Description:     book.Description,
+		ISBN:            isbn,
+		Publisher:       book.Publisher,
+		PublicationYear: book.PublicationYear,
+		Edition:         book.Edition,
+		Language:        book.Language,
+		PageCount:       book.PageCount,
+		CoverURL:        book.CoverURL,
+		Subjects:        subjects,
+		Quantity:        book.Quantity,
+		Available:       book.Available,
+		Status:          book.Status,
+		WithdrawnAt:     book.WithdrawnAt,
+		DeletedAt:       deletedAt,
+		Branches:        branches,
+		CreatedAt:       book.CreatedAt,
+		UpdatedAt:       book.UpdatedAt,
 	}
 }
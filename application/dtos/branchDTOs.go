@@ -0,0 +1,92 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+)
+
+// BranchCreateDTO representa os dados para criação de uma unidade
+type BranchCreateDTO struct {
+	Name    string `json:"name" binding:"required,min=1,max=100"`
+	Code    string `json:"code" binding:"required,min=1,max=20"`
+	Address string `json:"address" binding:"max=255"`
+}
+
+// BranchUpdateDTO representa os dados para atualização de uma unidade
+type BranchUpdateDTO struct {
+	Name    string `json:"name" binding:"omitempty,min=1,max=100"`
+	Code    string `json:"code" binding:"omitempty,min=1,max=20"`
+	Address string `json:"address" binding:"max=255"`
+}
+
+// BranchResponseDTO representa os dados de unidade que serão retornados nas respostas da API
+type BranchResponseDTO struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Code      string    `json:"code"`
+	Address   string    `json:"address"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// HoldingUpdateDTO representa a quantidade de exemplares de um livro alocados em uma unidade
+type HoldingUpdateDTO struct {
+	Quantity int `json:"quantity" binding:"min=0"`
+}
+
+// TransferCreateDTO representa os dados para solicitar uma transferência entre unidades
+type TransferCreateDTO struct {
+	BookID       uint `json:"book_id" binding:"required"`
+	FromBranchID uint `json:"from_branch_id" binding:"required"`
+	ToBranchID   uint `json:"to_branch_id" binding:"required,nefield=FromBranchID"`
+	Quantity     int  `json:"quantity" binding:"required,min=1"`
+}
+
+// TransferResponseDTO representa os dados de transferência que serão retornados nas respostas da API
+type TransferResponseDTO struct {
+	ID             uint       `json:"id"`
+	BookID         uint       `json:"book_id"`
+	BookTitle      string     `json:"book_title"`
+	FromBranchID   uint       `json:"from_branch_id"`
+	FromBranchName string     `json:"from_branch_name"`
+	ToBranchID     uint       `json:"to_branch_id"`
+	ToBranchName   string     `json:"to_branch_name"`
+	Quantity       int        `json:"quantity"`
+	Status         string     `json:"status"`
+	RequestedByID  uint       `json:"requested_by_id"`
+	ResolvedByID   *uint      `json:"resolved_by_id"`
+	ResolvedAt     *time.Time `json:"resolved_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// BranchToResponseDTO converte uma entidade Branch para um BranchResponseDTO
+func BranchToResponseDTO(branch entities.Branch) BranchResponseDTO {
+	return BranchResponseDTO{
+		ID:        branch.ID,
+		Name:      branch.Name,
+		Code:      branch.Code,
+		Address:   branch.Address,
+		CreatedAt: branch.CreatedAt,
+		UpdatedAt: branch.UpdatedAt,
+	}
+}
+
+// TransferToResponseDTO converte uma entidade TransferRequest para um TransferResponseDTO
+func TransferToResponseDTO(transfer entities.TransferRequest) TransferResponseDTO {
+	return TransferResponseDTO{
+		ID:             transfer.ID,
+		BookID:         transfer.BookID,
+		BookTitle:      transfer.Book.Title,
+		FromBranchID:   transfer.FromBranchID,
+		FromBranchName: transfer.FromBranch.Name,
+		ToBranchID:     transfer.ToBranchID,
+		ToBranchName:   transfer.ToBranch.Name,
+		Quantity:       transfer.Quantity,
+		Status:         transfer.Status,
+		RequestedByID:  transfer.RequestedByID,
+		ResolvedByID:   transfer.ResolvedByID,
+		ResolvedAt:     transfer.ResolvedAt,
+		CreatedAt:      transfer.CreatedAt,
+	}
+}
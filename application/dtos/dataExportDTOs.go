@@ -0,0 +1,55 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+)
+
+// Formatos aceitos na exportação de dados pessoais
+const (
+	DataExportFormatZIP  = "zip"
+	DataExportFormatJSON = "json"
+)
+
+// DataExportOptionsDTO representa as opções de exportação de dados pessoais recebidas na query string
+type DataExportOptionsDTO struct {
+	Format string `form:"format" binding:"omitempty,oneof=zip json"`
+	Async  bool   `form:"async"`
+}
+
+// DataExportBundleDTO representa o conjunto de dados pessoais entregue ao usuário
+type DataExportBundleDTO struct {
+	GeneratedAt time.Time               `json:"generated_at"`
+	Profile     UserResponseDTO         `json:"profile"`
+	Loans       []LoanResponseDTO       `json:"loans"`
+	AuditEvents []AuditEventResponseDTO `json:"audit_events"`
+}
+
+// DataExportResponseDTO representa os dados de uma exportação de dados pessoais
+type DataExportResponseDTO struct {
+	ID          uint       `json:"id"`
+	Status      string     `json:"status"`
+	Format      string     `json:"format"`
+	Size        int64      `json:"size,omitempty"`
+	Message     string     `json:"message,omitempty"`
+	DownloadURL string     `json:"download_url,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+}
+
+// DataExportToResponseDTO converte uma entidade DataExport para um DataExportResponseDTO
+func DataExportToResponseDTO(export entities.DataExport, downloadURL string) DataExportResponseDTO {
+	return DataExportResponseDTO{
+		ID:          export.ID,
+		Status:      export.Status,
+		Format:      export.Format,
+		Size:        export.Size,
+		Message:     export.Message,
+		DownloadURL: downloadURL,
+		CreatedAt:   export.CreatedAt,
+		CompletedAt: export.CompletedAt,
+		ExpiresAt:   export.ExpiresAt,
+	}
+}
@@ -0,0 +1,14 @@
+package dtos
+
+// Formatos aceitos na exportação do acervo
+const (
+	ExportFormatCSV     = "csv"
+	ExportFormatJSON    = "json"
+	ExportFormatMARC    = "marc"
+	ExportFormatMARCXML = "marcxml"
+)
+
+// BookExportOptionsDTO representa as opções de exportação recebidas na query string
+type BookExportOptionsDTO struct {
+	Format string `form:"format" binding:"omitempty,oneof=csv json marc marcxml"`
+}
@@ -0,0 +1,21 @@
+package dtos
+
+// Situações informadas pelas verificações de saúde
+const (
+	HealthStatusOK       = "ok"
+	HealthStatusFail     = "fail"
+	HealthStatusDraining = "draining"
+)
+
+// HealthCheckDTO representa o resultado da verificação de uma dependência
+type HealthCheckDTO struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// HealthReportDTO representa a situação da aplicação e de cada dependência verificada
+type HealthReportDTO struct {
+	Status string                    `json:"status"`
+	Checks map[string]HealthCheckDTO `json:"checks,omitempty"`
+}
@@ -0,0 +1,98 @@
+package dtos
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+)
+
+// Formatos aceitos na importação de livros
+const (
+	ImportFormatCSV   = "csv"
+	ImportFormatJSONL = "jsonl"
+)
+
+// Situação de cada linha no relatório de importação
+const (
+	ImportRowCreate = "create"
+	ImportRowUpdate = "update"
+	ImportRowSkip   = "skip"
+	ImportRowError  = "error"
+)
+
+// BookImportOptionsDTO representa as opções de importação recebidas na query string
+type BookImportOptionsDTO struct {
+	Format string `form:"format" binding:"omitempty,oneof=csv jsonl"`
+	DryRun bool   `form:"dry_run"`
+	Async  bool   `form:"async"`
+}
+
+// ImportRowResultDTO representa o resultado do processamento de uma linha importada
+type ImportRowResultDTO struct {
+	Row    int      `json:"row"`
+	Status string   `json:"status"`
+	BookID uint     `json:"book_id,omitempty"`
+	ISBN   string   `json:"isbn,omitempty"`
+	Title  string   `json:"title,omitempty"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// ImportReportDTO representa o relatório de uma importação síncrona
+type ImportReportDTO struct {
+	Format    string               `json:"format"`
+	DryRun    bool                 `json:"dry_run"`
+	TotalRows int                  `json:"total_rows"`
+	Created   int                  `json:"created"`
+	Updated   int                  `json:"updated"`
+	Skipped   int                  `json:"skipped"`
+	Failed    int                  `json:"failed"`
+	Rows      []ImportRowResultDTO `json:"rows"`
+	// Error traz o motivo quando a importação parou no meio; as linhas do relatório são as dos lotes já gravados
+	Error string `json:"error,omitempty"`
+}
+
+// ImportJobResponseDTO representa os dados de uma importação assíncrona
+type ImportJobResponseDTO struct {
+	ID            uint                 `json:"id"`
+	Status        string               `json:"status"`
+	Format        string               `json:"format"`
+	DryRun        bool                 `json:"dry_run"`
+	TotalRows     int                  `json:"total_rows"`
+	ProcessedRows int                  `json:"processed_rows"`
+	Created       int                  `json:"created"`
+	Updated       int                  `json:"updated"`
+	Skipped       int                  `json:"skipped"`
+	Failed        int                  `json:"failed"`
+	Issues        []ImportRowResultDTO `json:"issues"`
+	Message       string               `json:"message,omitempty"`
+	CreatedAt     time.Time            `json:"created_at"`
+	StartedAt     *time.Time           `json:"started_at"`
+	FinishedAt    *time.Time           `json:"finished_at"`
+}
+
+// ImportJobToResponseDTO converte uma entidade ImportJob para um ImportJobResponseDTO
+func ImportJobToResponseDTO(job entities.ImportJob) ImportJobResponseDTO {
+	issues := []ImportRowResultDTO{}
+	if job.Issues != "" {
+		_ = json.Unmarshal([]byte(job.Issues), &issues)
+	}
+
+	return ImportJobResponseDTO{
+		ID:            job.ID,
+		Status:        job.Status,
+		Format:        job.Format,
+		DryRun:        job.DryRun,
+		TotalRows:     job.TotalRows,
+		ProcessedRows: job.ProcessedRows,
+		Created:       job.CreatedRows,
+		Updated:       job.UpdatedRows,
+		Skipped:       job.SkippedRows,
+		Failed:        job.FailedRows,
+		Issues:        issues,
+		Message:       job.Message,
+		CreatedAt:     job.CreatedAt,
+		StartedAt:     job.StartedAt,
+		FinishedAt:    job.FinishedAt,
+	}
+}
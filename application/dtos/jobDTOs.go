@@ -0,0 +1,59 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+)
+
+// Quantidade padrão e máxima de execuções retornadas no histórico de tarefas
+const (
+	JobRunDefaultLimit = 50
+	JobRunMaxLimit     = 500
+)
+
+// JobDTO representa uma tarefa recorrente registrada no agendador
+type JobDTO struct {
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Schedule    string     `json:"schedule,omitempty"` // Vazio quando a tarefa só é executada a pedido
+	NextRunAt   *time.Time `json:"next_run_at,omitempty"`
+	LastRun     *JobRunDTO `json:"last_run,omitempty"`
+}
+
+// JobRunDTO representa uma execução de tarefa no histórico
+type JobRunDTO struct {
+	ID           uint       `json:"id"`
+	Job          string     `json:"job"`
+	Trigger      string     `json:"trigger"`
+	Status       string     `json:"status"`
+	Attempts     int        `json:"attempts"`
+	Error        string     `json:"error,omitempty"`
+	Instance     string     `json:"instance"`
+	ScheduledFor time.Time  `json:"scheduled_for"`
+	StartedAt    time.Time  `json:"started_at"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+}
+
+// JobRunFilterDTO representa os filtros aceitos na consulta ao histórico de execuções
+type JobRunFilterDTO struct {
+	Job    string `form:"job"`
+	Status string `form:"status" binding:"omitempty,oneof=running succeeded failed"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=500"`
+}
+
+// JobRunToDTO converte uma entidade JobRun para um JobRunDTO
+func JobRunToDTO(run entities.JobRun) JobRunDTO {
+	return JobRunDTO{
+		ID:           run.ID,
+		Job:          run.JobName,
+		Trigger:      run.Trigger,
+		Status:       run.Status,
+		Attempts:     run.Attempts,
+		Error:        run.Error,
+		Instance:     run.Instance,
+		ScheduledFor: run.ScheduledFor,
+		StartedAt:    run.StartedAt,
+		FinishedAt:   run.FinishedAt,
+	}
+}
@@ -9,6 +9,15 @@ import (
 // LoanCreateDTO representa os dados para criação de um empréstimo
 type LoanCreateDTO struct {
 	BookID     uint      `json:"book_id" binding:"required"`
+	BranchID   *uint     `json:"branch_id"` // Unidade de retirada
+	ReturnDate time.Time `json:"return_date" binding:"required,gt"`
+}
+
+// StaffLoanCreateDTO representa os dados para um empréstimo registrado no balcão de uma unidade
+type StaffLoanCreateDTO struct {
+	UserID     uint      `json:"user_id" binding:"required"`
+	BookID     uint      `json:"book_id" binding:"required"`
+	BranchID   *uint     `json:"branch_id"` // Padrão: unidade do bibliotecário
 	ReturnDate time.Time `json:"return_date" binding:"required,gt"`
 }
 
@@ -19,6 +28,8 @@ type LoanResponseDTO struct {
 	BookTitle  string     `json:"book_title"`
 	UserID     uint       `json:"user_id"`
 	UserName   string     `json:"user_name"`
+	BranchID   *uint      `json:"branch_id"`
+	BranchName string     `json:"branch_name,omitempty"`
 	LoanDate   time.Time  `json:"loan_date"`
 	ReturnDate time.Time  `json:"return_date"`
 	ReturnedAt *time.Time `json:"returned_at"`
@@ -29,12 +40,19 @@ type LoanResponseDTO struct {
 
 // LoanToResponseDTO converte uma entidade Loan para um LoanResponseDTO
 func LoanToResponseDTO(loan entities.Loan) LoanResponseDTO {
+	var branchName string
+	if loan.Branch != nil {
+		branchName = loan.Branch.Name
+	}
+
 	return LoanResponseDTO{
 		ID:         loan.ID,
 		BookID:     loan.BookID,
 		BookTitle:  loan.Book.Title,
 		UserID:     loan.UserID,
 		UserName:   loan.User.Name,
+		BranchID:   loan.BranchID,
+		BranchName: branchName,
 		LoanDate:   loan.LoanDate,
 		ReturnDate: loan.ReturnDate,
 		ReturnedAt: loan.ReturnedAt,
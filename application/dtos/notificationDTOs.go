@@ -0,0 +1,81 @@
+package dtos
+
+import (
+	"time"
+
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+)
+
+// Quantidade padrão e máxima de notificações retornadas na caixa de entrada
+const (
+	NotificationDefaultLimit = 50
+	NotificationMaxLimit     = 200
+)
+
+// NotificationDTO representa uma notificação da caixa de entrada
+type NotificationDTO struct {
+	ID        uint       `json:"id"`
+	Kind      string     `json:"kind"`
+	Level     int        `json:"level,omitempty"`
+	LoanID    *uint      `json:"loan_id,omitempty"`
+	Subject   string     `json:"subject"`
+	Body      string     `json:"body"`
+	Read      bool       `json:"read"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// NotificationInboxDTO representa a caixa de entrada com a contagem de não lidas
+type NotificationInboxDTO struct {
+	Unread        int64             `json:"unread"`
+	Notifications []NotificationDTO `json:"notifications"`
+}
+
+// NotificationFilterDTO representa os filtros aceitos na consulta à caixa de entrada
+type NotificationFilterDTO struct {
+	Unread bool `form:"unread"`
+	Limit  int  `form:"limit" binding:"omitempty,min=1,max=200"`
+}
+
+// NotificationPreferencesDTO representa os canais pelos quais o usuário recebe notificações
+type NotificationPreferencesDTO struct {
+	Email bool `json:"email"`
+	InApp bool `json:"in_app"`
+}
+
+// NotificationPreferencesUpdateDTO representa a alteração dos canais; campos omitidos ficam como estão
+type NotificationPreferencesUpdateDTO struct {
+	Email *bool `json:"email"`
+	InApp *bool `json:"in_app"`
+}
+
+// NotificationScanResultDTO resume uma varredura de lembretes e avisos de atraso
+type NotificationScanResultDTO struct {
+	Loans   int `json:"loans"`   // Empréstimos em aberto dentro do prazo de lembrete ou atrasados
+	Created int `json:"created"` // Notificações novas, somando todos os canais
+	Sent    int `json:"sent"`    // Entregas concluídas pelos canais externos
+	Failed  int `json:"failed"`  // Entregas que falharam e serão tentadas de novo na próxima varredura
+}
+
+// NotificationToDTO converte uma entidade Notification para um NotificationDTO
+func NotificationToDTO(notification entities.Notification) NotificationDTO {
+	return NotificationDTO{
+		ID:        notification.ID,
+		Kind:      notification.Kind,
+		Level:     notification.Level,
+		LoanID:    notification.LoanID,
+		Subject:   notification.Subject,
+		Body:      notification.Body,
+		Read:      notification.ReadAt != nil,
+		ReadAt:    notification.ReadAt,
+		CreatedAt: notification.CreatedAt,
+	}
+}
+
+// UserNotificationPreferencesDTO extrai os canais de notificação do usuário
+func UserNotificationPreferencesDTO(user entities.User) NotificationPreferencesDTO {
+	return NotificationPreferencesDTO{
+		Email: !user.EmailNotificationsOff,
+		InApp: !user.InAppNotificationsOff,
+	}
+}
@@ -0,0 +1,8 @@
+package dtos
+
+// LibraryStatsDTO reúne os indicadores gerais do acervo e da circulação
+type LibraryStatsDTO struct {
+	ActiveLoans    int64 `json:"active_loans"`
+	OverdueLoans   int64 `json:"overdue_loans"`
+	BooksAvailable int64 `json:"books_available"`
+}
@@ -13,30 +13,59 @@ type UserCreateDTO struct {
 	Password string `json:"password" binding:"required,min=6"`
 }
 
+// SetupDTO representa os dados para criação do primeiro administrador com o token de instalação
+type SetupDTO struct {
+	Token string `json:"token" binding:"required"`
+	UserCreateDTO
+}
+
 // UserUpdateDTO representa os dados para atualização de um usuário
 type UserUpdateDTO struct {
 	Name     string `json:"name" binding:"omitempty,min=3,max=100"`
 	Password string `json:"password" binding:"omitempty,min=6"`
 }
 
+// UserBranchDTO representa a lotação de um usuário como bibliotecário de uma unidade
+type UserBranchDTO struct {
+	BranchID    *uint `json:"branch_id"`
+	IsLibrarian bool  `json:"is_librarian"`
+}
+
 // UserResponseDTO representa os dados de usuário que serão retornados nas respostas da API
 type UserResponseDTO struct {
-	ID        uint      `json:"id"`
-	Name      string    `json:"name"`
-	Email     string    `json:"email"`
-	IsAdmin   bool      `json:"is_admin"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID          uint      `json:"id"`
+	Name        string    `json:"name"`
+	Email       string    `json:"email"`
+	IsAdmin     bool      `json:"is_admin"`
+	IsLibrarian bool      `json:"is_librarian"`
+	BranchID    *uint     `json:"branch_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+
+	DeletedAt          *time.Time `json:"deleted_at,omitempty"`
+	ErasureScheduledAt *time.Time `json:"erasure_scheduled_at,omitempty"`
+	ErasedAt           *time.Time `json:"erased_at,omitempty"`
 }
 
 // ToResponseDTO converte uma entidade User para um UserResponseDTO
 func ToResponseDTO(user entities.User) UserResponseDTO {
+	var deletedAt *time.Time
+	if user.DeletedAt.Valid {
+		deletedAt = &user.DeletedAt.Time
+	}
+
 	return UserResponseDTO{
-		ID:        user.ID,
-		Name:      user.Name,
-		Email:     user.Email,
-		IsAdmin:   user.IsAdmin,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+		ID:          user.ID,
+		Name:        user.Name,
+		Email:       user.Email,
+		IsAdmin:     user.IsAdmin,
+		IsLibrarian: user.IsLibrarian,
+		BranchID:    user.BranchID,
+		CreatedAt:   user.CreatedAt,
+		UpdatedAt:   user.UpdatedAt,
+
+		DeletedAt:          deletedAt,
+		ErasureScheduledAt: user.ErasureScheduledAt,
+		ErasedAt:           user.ErasedAt,
 	}
 }
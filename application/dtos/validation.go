@@ -0,0 +1,39 @@
+package dtos
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var (
+	validate     *validator.Validate
+	validateOnce sync.Once
+)
+
+// Validate aplica as regras das tags "binding" de um DTO fora de uma requisição HTTP,
+// com o mesmo validador usado pelo Gin. Retorna uma mensagem por campo inválido.
+func Validate(dto interface{}) []string {
+	validateOnce.Do(func() {
+		validate = validator.New()
+		validate.SetTagName("binding")
+	})
+
+	err := validate.Struct(dto)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return []string{err.Error()}
+	}
+
+	messages := make([]string, 0, len(validationErrors))
+	for _, fieldError := range validationErrors {
+		messages = append(messages, fmt.Sprintf("campo %s inválido (regra %s)", fieldError.Field(), fieldError.Tag()))
+	}
+	return messages
+}
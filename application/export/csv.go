@@ -0,0 +1,104 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"strconv"
+	"strings"
+
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+)
+
+// csvHeader lista as colunas do CSV exportado
+var csvHeader = []string{
+	"id", "title", "authors", "isbn", "publisher", "publication_year", "edition", "language",
+	"page_count", "description", "cover_url", "subjects", "quantity", "available", "status",
+}
+
+// csvEncoder grava livros em CSV, uma linha por livro. Listas são separadas por "|".
+type csvEncoder struct {
+	buffer        *bufio.Writer
+	writer        *csv.Writer
+	headerWritten bool
+}
+
+// newCSVEncoder cria o codificador CSV
+func newCSVEncoder(buffer *bufio.Writer) *csvEncoder {
+	return &csvEncoder{
+		buffer: buffer,
+		writer: csv.NewWriter(buffer),
+	}
+}
+
+// Encode grava um livro como linha do CSV
+func (csvEncoder *csvEncoder) Encode(book *entities.Book) error {
+	if err := csvEncoder.writeHeader(); err != nil {
+		return err
+	}
+
+	authors := make([]string, 0, len(book.Authors))
+	for _, author := range book.Authors {
+		authors = append(authors, author.Name)
+	}
+	subjects := make([]string, 0, len(book.Subjects))
+	for _, subject := range book.Subjects {
+		subjects = append(subjects, subject.Name)
+	}
+
+	var isbn string
+	if book.ISBN != nil {
+		isbn = *book.ISBN
+	}
+
+	return csvEncoder.writer.Write([]string{
+		strconv.FormatUint(uint64(book.ID), 10),
+		book.Title,
+		strings.Join(authors, "|"),
+		isbn,
+		book.Publisher,
+		optionalInt(book.PublicationYear),
+		book.Edition,
+		book.Language,
+		optionalInt(book.PageCount),
+		book.Description,
+		book.CoverURL,
+		strings.Join(subjects, "|"),
+		strconv.Itoa(book.Quantity),
+		strconv.Itoa(book.Available),
+		book.Status,
+	})
+}
+
+// Flush envia as linhas em buffer ao destino
+func (csvEncoder *csvEncoder) Flush() error {
+	csvEncoder.writer.Flush()
+	if err := csvEncoder.writer.Error(); err != nil {
+		return err
+	}
+	return csvEncoder.buffer.Flush()
+}
+
+// Close grava o cabeçalho, caso nenhum livro tenha sido exportado, e envia o restante ao destino
+func (csvEncoder *csvEncoder) Close() error {
+	if err := csvEncoder.writeHeader(); err != nil {
+		return err
+	}
+	return csvEncoder.Flush()
+}
+
+// writeHeader grava o cabeçalho uma única vez
+func (csvEncoder *csvEncoder) writeHeader() error {
+	if csvEncoder.headerWritten {
+		return nil
+	}
+	csvEncoder.headerWritten = true
+	return csvEncoder.writer.Write(csvHeader)
+}
+
+// optionalInt formata o número, deixando vazio quando não informado
+func optionalInt(value int) string {
+	if value == 0 {
+		return ""
+	}
+	return strconv.Itoa(value)
+}
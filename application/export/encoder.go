@@ -0,0 +1,65 @@
+// Package export converte livros nos formatos de exportação do acervo
+package export
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+	domainerrors "github.com/henrygoeszanin/api_golang_estudos/domain/errors"
+)
+
+// BookEncoder grava livros, um a um, em um formato de exportação
+type BookEncoder interface {
+	// Encode grava um livro
+	Encode(book *entities.Book) error
+	// Flush envia ao destino o que estiver em buffer
+	Flush() error
+	// Close finaliza o documento e envia ao destino o que estiver em buffer
+	Close() error
+}
+
+// NewBookEncoder cria o codificador do formato informado, gravando no destino
+func NewBookEncoder(format string, output io.Writer) (BookEncoder, error) {
+	writer := bufio.NewWriter(output)
+
+	switch format {
+	case dtos.ExportFormatCSV:
+		return newCSVEncoder(writer), nil
+	case dtos.ExportFormatJSON:
+		return newJSONEncoder(writer), nil
+	case dtos.ExportFormatMARC:
+		return newMARCEncoder(writer), nil
+	case dtos.ExportFormatMARCXML:
+		return newMARCXMLEncoder(writer), nil
+	}
+	return nil, fmt.Errorf("%w: formato de exportação não suportado: %q", domainerrors.ErrInvalidData, format)
+}
+
+// ContentType retorna o tipo de conteúdo HTTP do formato informado
+func ContentType(format string) string {
+	switch format {
+	case dtos.ExportFormatCSV:
+		return "text/csv; charset=utf-8"
+	case dtos.ExportFormatJSON:
+		return "application/json; charset=utf-8"
+	case dtos.ExportFormatMARC:
+		return "application/marc"
+	case dtos.ExportFormatMARCXML:
+		return "application/marcxml+xml; charset=utf-8"
+	}
+	return "application/octet-stream"
+}
+
+// FileExtension retorna a extensão de arquivo do formato informado
+func FileExtension(format string) string {
+	switch format {
+	case dtos.ExportFormatMARC:
+		return "mrc"
+	case dtos.ExportFormatMARCXML:
+		return "xml"
+	}
+	return format
+}
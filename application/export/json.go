@@ -0,0 +1,56 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+)
+
+// jsonEncoder grava livros como um array JSON, no mesmo formato da listagem da API
+type jsonEncoder struct {
+	buffer  *bufio.Writer
+	started bool
+}
+
+// newJSONEncoder cria o codificador JSON
+func newJSONEncoder(buffer *bufio.Writer) *jsonEncoder {
+	return &jsonEncoder{buffer: buffer}
+}
+
+// Encode grava um livro como elemento do array
+func (jsonEncoder *jsonEncoder) Encode(book *entities.Book) error {
+	separator := ",\n"
+	if !jsonEncoder.started {
+		separator = "[\n"
+		jsonEncoder.started = true
+	}
+
+	data, err := json.Marshal(dtos.BookToResponseDTO(*book))
+	if err != nil {
+		return err
+	}
+	if _, err := jsonEncoder.buffer.WriteString(separator); err != nil {
+		return err
+	}
+	_, err = jsonEncoder.buffer.Write(data)
+	return err
+}
+
+// Flush envia os livros em buffer ao destino
+func (jsonEncoder *jsonEncoder) Flush() error {
+	return jsonEncoder.buffer.Flush()
+}
+
+// Close fecha o array e envia o restante ao destino
+func (jsonEncoder *jsonEncoder) Close() error {
+	closing := "\n]\n"
+	if !jsonEncoder.started {
+		closing = "[]\n"
+	}
+	if _, err := jsonEncoder.buffer.WriteString(closing); err != nil {
+		return err
+	}
+	return jsonEncoder.buffer.Flush()
+}
@@ -0,0 +1,300 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/language"
+
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+)
+
+// Delimitadores do formato ISO 2709 usado pelo MARC21
+const (
+	marcSubfieldDelimiter = 0x1F
+	marcFieldTerminator   = 0x1E
+	marcRecordTerminator  = 0x1D
+)
+
+// Limites do formato: tamanho do registro com 5 dígitos e de cada campo com 4
+const (
+	marcMaxRecordLength = 99999
+	marcMaxFieldLength  = 9999
+	marcMaxValueLength  = 1000
+	marcMaxNoteLength   = 9000
+)
+
+// marcBibliographicCodes traduz os códigos ISO 639-2/T para os códigos bibliográficos
+// da lista de idiomas MARC, quando diferentes
+var marcBibliographicCodes = map[string]string{
+	"bod": "tib", "ces": "cze", "cym": "wel", "deu": "ger", "ell": "gre", "eus": "baq",
+	"fas": "per", "fra": "fre", "hye": "arm", "isl": "ice", "kat": "geo", "mkd": "mac",
+	"mri": "mao", "msa": "may", "mya": "bur", "nld": "dut", "ron": "rum", "slk": "slo",
+	"sqi": "alb", "zho": "chi",
+}
+
+// marcRecord representa um registro bibliográfico MARC21
+type marcRecord struct {
+	Status        byte // Situação do registro no líder: n (novo) ou d (excluído)
+	ControlFields []marcControlField
+	DataFields    []marcDataField
+}
+
+// marcControlField representa um campo de controle (00X)
+type marcControlField struct {
+	Tag   string
+	Value string
+}
+
+// marcDataField representa um campo de dados, com indicadores e subcampos
+type marcDataField struct {
+	Tag       string
+	Ind1      byte
+	Ind2      byte
+	Subfields []marcSubfield
+}
+
+// marcSubfield representa um subcampo de um campo de dados
+type marcSubfield struct {
+	Code  byte
+	Value string
+}
+
+// newMARCRecord monta o registro MARC21 de um livro
+func newMARCRecord(book *entities.Book) marcRecord {
+	record := marcRecord{Status: 'n'}
+	if book.Status == entities.BookStatusWithdrawn {
+		// Livros retirados do acervo são informados à rede como registros excluídos
+		record.Status = 'd'
+	}
+
+	record.ControlFields = []marcControlField{
+		{Tag: "001", Value: strconv.FormatUint(uint64(book.ID), 10)},
+		{Tag: "005", Value: book.UpdatedAt.UTC().Format("20060102150405") + ".0"},
+		{Tag: "008", Value: marcFixedData(book)},
+	}
+
+	if book.ISBN != nil {
+		record.addField("020", ' ', ' ', marcSubfield{'a', *book.ISBN})
+	}
+
+	for i, author := range book.Authors {
+		// O primeiro autor é a entrada principal; os demais são entradas secundárias
+		tag := "700"
+		if i == 0 {
+			tag = "100"
+		}
+		record.addField(tag, marcNameIndicator(author.Name), ' ', marcSubfield{'a', author.Name})
+	}
+
+	titleIndicator := byte('0')
+	if len(book.Authors) > 0 {
+		titleIndicator = '1'
+	}
+	title := []marcSubfield{{'a', book.Title}}
+	if book.Author != "" {
+		title = append(title, marcSubfield{'c', book.Author})
+	}
+	record.addField("245", titleIndicator, '0', title...)
+
+	if book.Edition != "" {
+		record.addField("250", ' ', ' ', marcSubfield{'a', book.Edition})
+	}
+
+	var publication []marcSubfield
+	if book.Publisher != "" {
+		publication = append(publication, marcSubfield{'b', book.Publisher})
+	}
+	if book.PublicationYear > 0 {
+		publication = append(publication, marcSubfield{'c', strconv.Itoa(book.PublicationYear)})
+	}
+	if len(publication) > 0 {
+		record.addField("264", ' ', '1', publication...)
+	}
+
+	if book.PageCount > 0 {
+		record.addField("300", ' ', ' ', marcSubfield{'a', fmt.Sprintf("%d p.", book.PageCount)})
+	}
+
+	if book.Description != "" {
+		record.addField("520", ' ', ' ', marcSubfield{'a', truncateUTF8(marcClean(book.Description), marcMaxNoteLength)})
+	}
+
+	for _, subject := range book.Subjects {
+		record.addField("650", ' ', '4', marcSubfield{'a', subject.Name})
+	}
+
+	if book.CoverURL != "" {
+		record.addField("856", '4', '2', marcSubfield{'3', "Capa"}, marcSubfield{'u', book.CoverURL})
+	}
+
+	// Os campos ficam em ordem de tag, mantendo a ordem de inclusão entre campos repetidos
+	sort.SliceStable(record.DataFields, func(i, j int) bool {
+		return record.DataFields[i].Tag < record.DataFields[j].Tag
+	})
+
+	return record
+}
+
+// addField acrescenta um campo de dados, limpando e limitando o conteúdo dos subcampos
+func (record *marcRecord) addField(tag string, ind1, ind2 byte, subfields ...marcSubfield) {
+	for i := range subfields {
+		if len(subfields[i].Value) > marcMaxValueLength && tag != "520" {
+			subfields[i].Value = truncateUTF8(subfields[i].Value, marcMaxValueLength)
+		}
+		subfields[i].Value = marcClean(subfields[i].Value)
+	}
+	record.DataFields = append(record.DataFields, marcDataField{Tag: tag, Ind1: ind1, Ind2: ind2, Subfields: subfields})
+}
+
+// marshalISO2709 codifica o registro no formato binário MARC21 (ISO 2709)
+func (record marcRecord) marshalISO2709() ([]byte, error) {
+	var directory, data bytes.Buffer
+
+	addEntry := func(tag string, field []byte) error {
+		if len(field) > marcMaxFieldLength {
+			return fmt.Errorf("campo %s excede o tamanho máximo do MARC21", tag)
+		}
+		fmt.Fprintf(&directory, "%s%04d%05d", tag, len(field), data.Len())
+		data.Write(field)
+		return nil
+	}
+
+	for _, field := range record.ControlFields {
+		if err := addEntry(field.Tag, append([]byte(field.Value), marcFieldTerminator)); err != nil {
+			return nil, err
+		}
+	}
+	for _, field := range record.DataFields {
+		var encoded bytes.Buffer
+		encoded.WriteByte(field.Ind1)
+		encoded.WriteByte(field.Ind2)
+		for _, subfield := range field.Subfields {
+			encoded.WriteByte(marcSubfieldDelimiter)
+			encoded.WriteByte(subfield.Code)
+			encoded.WriteString(subfield.Value)
+		}
+		encoded.WriteByte(marcFieldTerminator)
+		if err := addEntry(field.Tag, encoded.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+	directory.WriteByte(marcFieldTerminator)
+	data.WriteByte(marcRecordTerminator)
+
+	baseAddress := 24 + directory.Len()
+	recordLength := baseAddress + data.Len()
+	if recordLength > marcMaxRecordLength {
+		return nil, fmt.Errorf("registro MARC21 do livro %s excede o tamanho máximo", record.ControlFields[0].Value)
+	}
+
+	encoded := make([]byte, 0, recordLength)
+	encoded = append(encoded, marcLeader(record.Status, recordLength, baseAddress)...)
+	encoded = append(encoded, directory.Bytes()...)
+	encoded = append(encoded, data.Bytes()...)
+	return encoded, nil
+}
+
+// marcLeader monta o líder do registro: material textual (a) monográfico (m),
+// codificado em Unicode (a), com nível de catalogação mínimo (7)
+func marcLeader(status byte, recordLength, baseAddress int) string {
+	return fmt.Sprintf("%05d%cam a22%05d7u 4500", recordLength, status, baseAddress)
+}
+
+// marcFixedData monta o campo 008 (dados de tamanho fixo) de um livro
+func marcFixedData(book *entities.Book) string {
+	dateType, year := "n", "uuuu"
+	if book.PublicationYear > 0 && book.PublicationYear <= 9999 {
+		dateType, year = "s", fmt.Sprintf("%04d", book.PublicationYear)
+	}
+
+	return book.CreatedAt.UTC().Format("060102") + // 00-05 data de entrada
+		dateType + year + "    " + // 06-14 tipo de data e datas
+		"xx " + // 15-17 local de publicação não informado
+		"    " + " " + " " + "    " + " " + // 18-28 ilustrações, público, forma, natureza, publicação oficial
+		"000" + " " + "|" + " " + // 29-34 conferência, homenagem, índice, indefinido, forma literária, biografia
+		marcLanguageCode(book.Language) + // 35-37 idioma
+		" " + "d" // 38-39 registro modificado, fonte da catalogação
+}
+
+// marcLanguageCode converte a tag de idioma BCP 47 no código de idioma MARC de três letras
+func marcLanguageCode(tag string) string {
+	if tag == "" {
+		return "und"
+	}
+
+	parsed, err := language.Parse(tag)
+	if err != nil {
+		return "und"
+	}
+	base, _ := parsed.Base()
+	code := base.ISO3()
+	if bibliographic, found := marcBibliographicCodes[code]; found {
+		return bibliographic
+	}
+	if len(code) != 3 {
+		return "und"
+	}
+	return code
+}
+
+// marcNameIndicator indica se o nome está na forma invertida ("Sobrenome, Nome")
+func marcNameIndicator(name string) byte {
+	if strings.Contains(name, ",") {
+		return '1'
+	}
+	return '0'
+}
+
+// marcClean remove caracteres de controle, que conflitam com os delimitadores do formato
+func marcClean(value string) string {
+	return strings.Join(strings.FieldsFunc(value, func(r rune) bool {
+		return r < 0x20 || r == 0x7F
+	}), " ")
+}
+
+// truncateUTF8 limita o texto ao número de bytes informado sem cortar um caractere ao meio
+func truncateUTF8(value string, limit int) string {
+	if len(value) <= limit {
+		return value
+	}
+	for limit > 0 && !utf8.RuneStart(value[limit]) {
+		limit--
+	}
+	return value[:limit]
+}
+
+// marcEncoder grava livros como registros MARC21 binários, um após o outro
+type marcEncoder struct {
+	buffer *bufio.Writer
+}
+
+// newMARCEncoder cria o codificador MARC21 binário
+func newMARCEncoder(buffer *bufio.Writer) *marcEncoder {
+	return &marcEncoder{buffer: buffer}
+}
+
+// Encode grava o registro MARC21 de um livro
+func (marcEncoder *marcEncoder) Encode(book *entities.Book) error {
+	encoded, err := newMARCRecord(book).marshalISO2709()
+	if err != nil {
+		return err
+	}
+	_, err = marcEncoder.buffer.Write(encoded)
+	return err
+}
+
+// Flush envia os registros em buffer ao destino
+func (marcEncoder *marcEncoder) Flush() error {
+	return marcEncoder.buffer.Flush()
+}
+
+// Close envia o restante ao destino
+func (marcEncoder *marcEncoder) Close() error {
+	return marcEncoder.buffer.Flush()
+}
@@ -0,0 +1,210 @@
+package export
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+)
+
+// marcSample é o livro do registro de referência
+func marcSample() *entities.Book {
+	isbn := "9788535910667"
+	book := &entities.Book{
+		Title:           "Dom Casmurro",
+		Author:          "Machado de Assis",
+		ISBN:            &isbn,
+		Publisher:       "Garnier",
+		PublicationYear: 1899,
+		Language:        "pt-BR",
+		PageCount:       256,
+		Status:          entities.BookStatusActive,
+		Authors:         []entities.Author{{Name: "Assis, Machado de"}},
+		Subjects:        []entities.Subject{{Name: "Romance"}},
+	}
+	book.ID = 42
+	book.CreatedAt = time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	book.UpdatedAt = time.Date(2024, 3, 5, 14, 30, 15, 0, time.UTC)
+	return book
+}
+
+// marcSampleRecord é o registro ISO 2709 esperado para marcSample, montado campo a campo
+const marcSampleRecord = "" +
+	// Líder: tamanho 311, registro novo, endereço base 133 (24 do líder e 9 entradas de 12 no diretório)
+	"00311nam a22001337u 4500" +
+	// Diretório: tag, tamanho e posição de cada campo
+	"001000300000" +
+	"005001700003" +
+	"008004100020" +
+	"020001800061" +
+	"100002200079" +
+	"245003500101" +
+	"264001800136" +
+	"300001100154" +
+	"650001200165" +
+	"\x1e" +
+	// Campos de controle
+	"42\x1e" +
+	"20240305143015.0\x1e" +
+	"240301s1899    xx            000 | por d\x1e" +
+	// Campos de dados
+	"  \x1fa9788535910667\x1e" +
+	"1 \x1faAssis, Machado de\x1e" +
+	"10\x1faDom Casmurro\x1fcMachado de Assis\x1e" +
+	" 1\x1fbGarnier\x1fc1899\x1e" +
+	"  \x1fa256 p.\x1e" +
+	" 4\x1faRomance\x1e" +
+	"\x1d"
+
+func TestMarshalISO2709(t *testing.T) {
+	encoded, err := newMARCRecord(marcSample()).marshalISO2709()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(encoded) != marcSampleRecord {
+		t.Errorf("registro MARC21 diferente do esperado:\n%q\nesperado:\n%q", encoded, marcSampleRecord)
+	}
+}
+
+func TestMarshalISO2709Directory(t *testing.T) {
+	book := marcSample()
+	book.Status = entities.BookStatusWithdrawn
+	book.Description = strings.Repeat("á", 5000)
+	book.Authors = append(book.Authors, entities.Author{Name: "Outro Autor"})
+
+	encoded, err := newMARCRecord(book).marshalISO2709()
+	if err != nil {
+		t.Fatal(err)
+	}
+	record := string(encoded)
+
+	leader := record[:24]
+	if leader[5] != 'd' {
+		t.Errorf("livro retirado deveria gerar registro excluído, líder %q", leader)
+	}
+	if got := leader[:5]; got != fmt.Sprintf("%05d", len(record)) {
+		t.Errorf("tamanho no líder %s, registro com %d bytes", got, len(record))
+	}
+	baseAddress := strings.IndexByte(record, marcFieldTerminator) + 1
+	if got := leader[12:17]; got != fmt.Sprintf("%05d", baseAddress) {
+		t.Errorf("endereço base no líder %s, esperado %d", got, baseAddress)
+	}
+	if record[len(record)-1] != marcRecordTerminator {
+		t.Errorf("registro deveria terminar com o terminador de registro")
+	}
+
+	// Cada entrada do diretório aponta para um campo terminado exatamente no fim informado
+	directory := record[24 : baseAddress-1]
+	if len(directory)%12 != 0 {
+		t.Fatalf("diretório com %d bytes, esperado múltiplo de 12", len(directory))
+	}
+	var tags []string
+	for entry := 0; entry < len(directory); entry += 12 {
+		tag, length, start := directory[entry:entry+3], directoryNumber(t, directory[entry+3:entry+7]), directoryNumber(t, directory[entry+7:entry+12])
+		tags = append(tags, tag)
+		field := record[baseAddress+start : baseAddress+start+length]
+		if strings.IndexByte(field, marcFieldTerminator) != len(field)-1 {
+			t.Errorf("campo %s nas posições %d-%d não termina no terminador de campo: %q", tag, start, start+length, field)
+		}
+	}
+	if got := strings.Join(tags, " "); got != "001 005 008 020 100 245 264 300 520 650 700" {
+		t.Errorf("campos %s fora da ordem de tag", got)
+	}
+}
+
+func TestMarshalISO2709FieldTooLong(t *testing.T) {
+	record := marcRecord{
+		Status:        'n',
+		ControlFields: []marcControlField{{Tag: "001", Value: "1"}},
+		DataFields:    []marcDataField{{Tag: "500", Ind1: ' ', Ind2: ' ', Subfields: []marcSubfield{{'a', strings.Repeat("a", marcMaxFieldLength)}}}},
+	}
+	if _, err := record.marshalISO2709(); err == nil {
+		t.Error("campo maior que o limite do diretório deveria ser recusado")
+	}
+}
+
+func TestMARCLeader(t *testing.T) {
+	tests := []struct {
+		status       byte
+		recordLength int
+		baseAddress  int
+		want         string
+	}{
+		{'n', 311, 133, "00311nam a22001337u 4500"},
+		{'d', 99999, 61, "99999dam a22000617u 4500"},
+		{'n', 26, 25, "00026nam a22000257u 4500"},
+	}
+
+	for _, tt := range tests {
+		got := marcLeader(tt.status, tt.recordLength, tt.baseAddress)
+		if got != tt.want {
+			t.Errorf("marcLeader(%c, %d, %d) = %q, esperado %q", tt.status, tt.recordLength, tt.baseAddress, got, tt.want)
+		}
+		if len(got) != 24 {
+			t.Errorf("líder com %d caracteres, esperados 24", len(got))
+		}
+	}
+}
+
+func TestMARCFixedData(t *testing.T) {
+	tests := []struct {
+		name     string
+		year     int
+		language string
+		want     string
+	}{
+		{"ano e idioma", 1899, "pt-BR", "240301s1899    xx            000 | por d"},
+		{"sem ano", 0, "en", "240301nuuuu    xx            000 | eng d"},
+		{"ano com mais de quatro dígitos", 12000, "", "240301nuuuu    xx            000 | und d"},
+		{"código bibliográfico", 2001, "de", "240301s2001    xx            000 | ger d"},
+		{"idioma inválido", 2001, "???", "240301s2001    xx            000 | und d"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			book := marcSample()
+			book.PublicationYear = tt.year
+			book.Language = tt.language
+			got := marcFixedData(book)
+			if got != tt.want {
+				t.Errorf("008 = %q, esperado %q", got, tt.want)
+			}
+			if len(got) != 40 {
+				t.Errorf("008 com %d caracteres, esperados 40", len(got))
+			}
+		})
+	}
+}
+
+func TestTruncateUTF8(t *testing.T) {
+	tests := []struct {
+		value string
+		limit int
+		want  string
+	}{
+		{"abc", 5, "abc"},
+		{"abcdef", 3, "abc"},
+		{"aáb", 2, "a"},
+		{"aáb", 3, "aá"},
+		{"ááá", 1, ""},
+	}
+
+	for _, tt := range tests {
+		if got := truncateUTF8(tt.value, tt.limit); got != tt.want {
+			t.Errorf("truncateUTF8(%q, %d) = %q, esperado %q", tt.value, tt.limit, got, tt.want)
+		}
+	}
+}
+
+// directoryNumber converte um campo numérico do diretório
+func directoryNumber(t *testing.T, value string) int {
+	t.Helper()
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		t.Fatalf("campo numérico inválido no diretório: %q", value)
+	}
+	return number
+}
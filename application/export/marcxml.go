@@ -0,0 +1,113 @@
+package export
+
+import (
+	"bufio"
+	"encoding/xml"
+
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+)
+
+// marcXMLNamespace é o namespace do esquema MARCXML (MARC21 slim)
+const marcXMLNamespace = "http://www.loc.gov/MARC21/slim"
+
+// marcXMLRecord representa um registro no formato MARCXML
+type marcXMLRecord struct {
+	XMLName       xml.Name              `xml:"record"`
+	Leader        string                `xml:"leader"`
+	ControlFields []marcXMLControlField `xml:"controlfield"`
+	DataFields    []marcXMLDataField    `xml:"datafield"`
+}
+
+// marcXMLControlField representa um campo de controle no MARCXML
+type marcXMLControlField struct {
+	Tag   string `xml:"tag,attr"`
+	Value string `xml:",chardata"`
+}
+
+// marcXMLDataField representa um campo de dados no MARCXML
+type marcXMLDataField struct {
+	Tag       string            `xml:"tag,attr"`
+	Ind1      string            `xml:"ind1,attr"`
+	Ind2      string            `xml:"ind2,attr"`
+	Subfields []marcXMLSubfield `xml:"subfield"`
+}
+
+// marcXMLSubfield representa um subcampo no MARCXML
+type marcXMLSubfield struct {
+	Code  string `xml:"code,attr"`
+	Value string `xml:",chardata"`
+}
+
+// marcXMLEncoder grava livros como uma coleção MARCXML
+type marcXMLEncoder struct {
+	buffer  *bufio.Writer
+	encoder *xml.Encoder
+	started bool
+}
+
+// newMARCXMLEncoder cria o codificador MARCXML
+func newMARCXMLEncoder(buffer *bufio.Writer) *marcXMLEncoder {
+	return &marcXMLEncoder{
+		buffer:  buffer,
+		encoder: xml.NewEncoder(buffer),
+	}
+}
+
+// Encode grava o registro MARCXML de um livro
+func (marcXMLEncoder *marcXMLEncoder) Encode(book *entities.Book) error {
+	if err := marcXMLEncoder.start(); err != nil {
+		return err
+	}
+
+	record := newMARCRecord(book)
+
+	// O líder usa os tamanhos do registro binário equivalente
+	encoded, err := record.marshalISO2709()
+	if err != nil {
+		return err
+	}
+
+	xmlRecord := marcXMLRecord{Leader: string(encoded[:24])}
+	for _, field := range record.ControlFields {
+		xmlRecord.ControlFields = append(xmlRecord.ControlFields, marcXMLControlField{Tag: field.Tag, Value: field.Value})
+	}
+	for _, field := range record.DataFields {
+		dataField := marcXMLDataField{Tag: field.Tag, Ind1: string(field.Ind1), Ind2: string(field.Ind2)}
+		for _, subfield := range field.Subfields {
+			dataField.Subfields = append(dataField.Subfields, marcXMLSubfield{Code: string(subfield.Code), Value: subfield.Value})
+		}
+		xmlRecord.DataFields = append(xmlRecord.DataFields, dataField)
+	}
+
+	if err := marcXMLEncoder.encoder.Encode(xmlRecord); err != nil {
+		return err
+	}
+	_, err = marcXMLEncoder.buffer.WriteString("\n")
+	return err
+}
+
+// Flush envia os registros em buffer ao destino
+func (marcXMLEncoder *marcXMLEncoder) Flush() error {
+	return marcXMLEncoder.buffer.Flush()
+}
+
+// Close fecha a coleção e envia o restante ao destino
+func (marcXMLEncoder *marcXMLEncoder) Close() error {
+	if err := marcXMLEncoder.start(); err != nil {
+		return err
+	}
+	if _, err := marcXMLEncoder.buffer.WriteString("</collection>\n"); err != nil {
+		return err
+	}
+	return marcXMLEncoder.buffer.Flush()
+}
+
+// start grava o cabeçalho XML e abre a coleção uma única vez
+func (marcXMLEncoder *marcXMLEncoder) start() error {
+	if marcXMLEncoder.started {
+		return nil
+	}
+	marcXMLEncoder.started = true
+	_, err := marcXMLEncoder.buffer.WriteString(xml.Header + `<collection xmlns="` + marcXMLNamespace + `">` + "\n")
+	return err
+}
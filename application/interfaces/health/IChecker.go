@@ -0,0 +1,10 @@
+package health
+
+import "context"
+
+// Checker verifica uma dependência da aplicação (banco, migrações...).
+// Check retorna nil quando a dependência está pronta para atender requisições.
+type Checker interface {
+	Name() string
+	Check(ctx context.Context) error
+}
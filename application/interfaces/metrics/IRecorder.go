@@ -0,0 +1,13 @@
+package metrics
+
+import "time"
+
+// Recorder define a coleta das métricas da aplicação.
+// A camada HTTP, o banco e a autenticação registram os eventos por meio dele,
+// sem depender da ferramenta que os expõe.
+type Recorder interface {
+	ObserveHTTPRequest(method, route string, status int, duration time.Duration)
+	ObserveDBQuery(operation, table string, duration time.Duration, err error)
+	LoginSucceeded()
+	LoginFailed()
+}
@@ -0,0 +1,18 @@
+package notifications
+
+import "context"
+
+// Message é uma notificação pronta para entrega por um canal externo
+type Message struct {
+	Channel string
+	UserID  uint
+	To      string // Endereço do destinatário no canal, como o e-mail
+	Subject string
+	Body    string
+}
+
+// Sender entrega as mensagens de um canal externo. O serviço de notificações depende apenas desta
+// interface, de modo que o envio por log, por arquivo ou por um provedor de e-mail é intercambiável.
+type Sender interface {
+	Send(ctx context.Context, message Message) error
+}
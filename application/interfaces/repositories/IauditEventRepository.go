@@ -0,0 +1,28 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+)
+
+// AuditEventFilter reúne os filtros aceitos na consulta da trilha de auditoria
+type AuditEventFilter struct {
+	ActorID    uint
+	Action     string
+	TargetType string
+	TargetID   string
+	From       *time.Time
+	To         *time.Time
+}
+
+// AuditEventRepository define as operações possíveis no repositório da trilha de auditoria.
+// Os eventos só podem ser acrescentados: não há atualização nem remoção.
+type AuditEventRepository interface {
+	Append(ctx context.Context, event *entities.AuditEvent) error
+	List(ctx context.Context, filter AuditEventFilter, offset, limit int) ([]*entities.AuditEvent, int64, error)
+	ListByUser(ctx context.Context, userID uint) ([]*entities.AuditEvent, error)
+	Walk(ctx context.Context, batchSize int, process func(events []*entities.AuditEvent) error) error
+	Head(ctx context.Context) (*entities.AuditChainHead, error)
+}
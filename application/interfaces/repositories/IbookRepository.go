@@ -1,14 +1,53 @@
 package repositories
 
 import (
+	"context"
+
 	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
 )
 
+// BookFilter define os critérios opcionais de busca de livros
+type BookFilter struct {
+	Title         string
+	Author        string
+	Subject       string
+	ISBN          string
+	Publisher     string
+	Language      string
+	YearFrom      int
+	YearTo        int
+	AvailableOnly bool
+	Status        string // Vazio considera livros em qualquer situação
+}
+
+// InventoryDiscrepancy descreve um contador de exemplares disponíveis que não confere com os empréstimos
+// em aberto. Sem BranchID, o contador é o do livro; com ele, o dos exemplares do livro na unidade.
+type InventoryDiscrepancy struct {
+	BookID    uint
+	Title     string
+	BranchID  *uint
+	Quantity  int
+	Available int // Valor gravado no contador
+	OpenLoans int
+	Expected  int // Calculado por entities.ExpectedAvailable
+}
+
 // BookRepository define as operações possíveis no repositório de livros
 type BookRepository interface {
-	Create(book *entities.Book) error
-	FindByID(id uint) (*entities.Book, error)
-	List() ([]*entities.Book, error)
-	Update(book *entities.Book) error
-	Delete(id uint) error
+	Create(ctx context.Context, book *entities.Book) error
+	FindByID(ctx context.Context, id uint) (*entities.Book, error)
+	FindByISBN(ctx context.Context, isbn string) (*entities.Book, error)
+	FindByDedupKey(ctx context.Context, key string) ([]*entities.Book, error)
+	FindByISBNs(ctx context.Context, isbns []string) ([]*entities.Book, error)
+	FindByDedupKeys(ctx context.Context, keys []string) ([]*entities.Book, error)
+	List(ctx context.Context, filter BookFilter) ([]*entities.Book, error)
+	ListInBatches(ctx context.Context, filter BookFilter, batchSize int, process func(books []*entities.Book) error) error
+	Update(ctx context.Context, book *entities.Book) error
+	AddCopies(ctx context.Context, id uint, quantity int) error
+	SaveBatch(ctx context.Context, creates []*entities.Book, updates []*entities.Book) error
+	Delete(ctx context.Context, id uint) error
+	ListDeleted(ctx context.Context) ([]*entities.Book, error)
+	SumAvailable(ctx context.Context) (int64, error)
+	Restore(ctx context.Context, id uint) error
+	ReconcileInventory(ctx context.Context, repair bool) ([]InventoryDiscrepancy, error)
 }
@@ -0,0 +1,18 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+)
+
+// BranchRepository define as operações possíveis no repositório de unidades
+type BranchRepository interface {
+	Create(ctx context.Context, branch *entities.Branch) error
+	FindByID(ctx context.Context, id uint) (*entities.Branch, error)
+	List(ctx context.Context) ([]*entities.Branch, error)
+	Update(ctx context.Context, branch *entities.Branch) error
+	Delete(ctx context.Context, id uint) error
+	FindHolding(ctx context.Context, bookID, branchID uint) (*entities.BookHolding, error)
+	SetHoldingQuantity(ctx context.Context, bookID, branchID uint, quantity int) error
+}
@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+)
+
+// DataExportRepository define as operações possíveis no repositório de exportações de dados pessoais
+type DataExportRepository interface {
+	Create(ctx context.Context, export *entities.DataExport) error
+	FindByID(ctx context.Context, id uint) (*entities.DataExport, error)
+	ListByUser(ctx context.Context, userID uint) ([]*entities.DataExport, error)
+	ListExpired(ctx context.Context, now time.Time) ([]*entities.DataExport, error)
+	Update(ctx context.Context, export *entities.DataExport) error
+}
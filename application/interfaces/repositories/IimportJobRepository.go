@@ -0,0 +1,15 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+)
+
+// ImportJobRepository define as operações possíveis no repositório de importações
+type ImportJobRepository interface {
+	Create(ctx context.Context, job *entities.ImportJob) error
+	FindByID(ctx context.Context, id uint) (*entities.ImportJob, error)
+	List(ctx context.Context, limit int) ([]*entities.ImportJob, error)
+	Update(ctx context.Context, job *entities.ImportJob) error
+}
@@ -0,0 +1,23 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+)
+
+// JobRunFilter define os critérios opcionais da listagem de execuções de tarefas
+type JobRunFilter struct {
+	JobName string
+	Status  string
+	Limit   int
+}
+
+// JobRunRepository define as operações possíveis no repositório de execuções de tarefas agendadas
+type JobRunRepository interface {
+	Claim(ctx context.Context, run *entities.JobRun) (bool, error)
+	Update(ctx context.Context, run *entities.JobRun) error
+	List(ctx context.Context, filter JobRunFilter) ([]*entities.JobRun, error)
+	DeleteFinishedBefore(ctx context.Context, before time.Time) (int64, error)
+}
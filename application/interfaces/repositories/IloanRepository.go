@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"time"
 
 	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
@@ -8,9 +9,13 @@ import (
 
 // LoanRepository define as operações possíveis no repositório de empréstimos
 type LoanRepository interface {
-	Create(loan *entities.Loan) error
-	FindByID(id uint) (*entities.Loan, error)
-	FindByUserID(userID uint) ([]*entities.Loan, error)
-	Update(loan *entities.Loan) error
-	ReturnLoan(id uint, returnDate time.Time) error
+	Create(ctx context.Context, loan *entities.Loan) error
+	FindByID(ctx context.Context, id uint) (*entities.Loan, error)
+	FindByUserID(ctx context.Context, userID uint) ([]*entities.Loan, error)
+	CountByUserID(ctx context.Context, userID uint) (int64, error)
+	CountActive(ctx context.Context) (int64, error)
+	CountOverdue(ctx context.Context, now time.Time) (int64, error)
+	ListOverdue(ctx context.Context, now time.Time) ([]*entities.Loan, error)
+	Update(ctx context.Context, loan *entities.Loan) error
+	ReturnLoan(ctx context.Context, id uint, returnDate time.Time) error
 }
@@ -0,0 +1,26 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+)
+
+// NotificationFilter define os critérios opcionais da listagem da caixa de entrada
+type NotificationFilter struct {
+	UnreadOnly bool
+	Limit      int
+}
+
+// NotificationRepository define as operações possíveis no repositório de notificações.
+// As operações da caixa de entrada consideram apenas as notificações do canal interno.
+type NotificationRepository interface {
+	Create(ctx context.Context, notification *entities.Notification) (bool, error)
+	Update(ctx context.Context, notification *entities.Notification) error
+	ListPending(ctx context.Context, channel string, maxAttempts int, limit int) ([]*entities.Notification, error)
+	ListInbox(ctx context.Context, userID uint, filter NotificationFilter) ([]*entities.Notification, error)
+	CountUnread(ctx context.Context, userID uint) (int64, error)
+	MarkRead(ctx context.Context, userID uint, id uint, readAt time.Time) (bool, error)
+	MarkAllRead(ctx context.Context, userID uint, readAt time.Time) (int64, error)
+}
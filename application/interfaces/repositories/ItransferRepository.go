@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+)
+
+// TransferRepository define as operações possíveis no repositório de transferências entre unidades
+type TransferRepository interface {
+	Create(ctx context.Context, transfer *entities.TransferRequest) error
+	FindByID(ctx context.Context, id uint) (*entities.TransferRequest, error)
+	List(ctx context.Context) ([]*entities.TransferRequest, error)
+	ListByBranch(ctx context.Context, branchID uint) ([]*entities.TransferRequest, error)
+	Complete(ctx context.Context, id uint, resolvedByID uint) error
+	Reject(ctx context.Context, id uint, resolvedByID uint) error
+}
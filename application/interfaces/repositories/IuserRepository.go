@@ -1,17 +1,27 @@
 package repositories
 
 import (
+	"context"
+	"time"
+
 	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
 )
 
 // UserRepository define as operações possíveis no repositório de usuários
 type UserRepository interface {
-	Create(user *entities.User) error
-	FindByID(id uint) (*entities.User, error)
-	FindByEmail(email string) (*entities.User, error)
-	Update(user *entities.User) error
-	Delete(id uint) error
-	List() ([]*entities.User, error)
-	PromoteToAdmin(id uint) error
-	IsFirstUser() (bool, error)
+	Create(ctx context.Context, user *entities.User) error
+	FindByID(ctx context.Context, id uint) (*entities.User, error)
+	FindByIDWithDeleted(ctx context.Context, id uint) (*entities.User, error)
+	FindByEmail(ctx context.Context, email string) (*entities.User, error)
+	Update(ctx context.Context, user *entities.User) error
+	Delete(ctx context.Context, id uint) error
+	List(ctx context.Context) ([]*entities.User, error)
+	PromoteToAdmin(ctx context.Context, id uint) error
+	IsFirstUser(ctx context.Context) (bool, error)
+	CountAdmins(ctx context.Context) (int64, error)
+	ListDeleted(ctx context.Context) ([]*entities.User, error)
+	Restore(ctx context.Context, id uint) error
+	ScheduleErasure(ctx context.Context, id uint, eraseAt time.Time) error
+	Erase(ctx context.Context, id uint, erasedAt time.Time) error
+	ListErasureDue(ctx context.Context, now time.Time) ([]uint, error)
 }
@@ -0,0 +1,19 @@
+package repositories
+
+import "context"
+
+// primaryKey marca, no contexto, as leituras que precisam do banco principal
+type primaryKey struct{}
+
+// WithPrimary marca o contexto para que as leituras dos repositórios vão ao banco principal, e não a uma
+// réplica. Serve às verificações que antecedem uma escrita, como a de cadastro repetido, que não podem
+// deixar de ver um registro gravado há pouco e ainda não replicado.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+// ReadsPrimary informa se o contexto pede as leituras no banco principal
+func ReadsPrimary(ctx context.Context) bool {
+	primary, _ := ctx.Value(primaryKey{}).(bool)
+	return primary
+}
@@ -0,0 +1,16 @@
+package services
+
+import (
+	"context"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+)
+
+// AuditService define os serviços da trilha de auditoria
+type AuditService interface {
+	Record(ctx context.Context, record dtos.AuditRecordDTO) error
+	Snapshot(ctx context.Context, targetType string, targetID string) (interface{}, error)
+	List(ctx context.Context, filterDTO dtos.AuditListFilterDTO) (*dtos.AuditEventPageDTO, error)
+	ListByUser(ctx context.Context, userID uint) ([]dtos.AuditEventResponseDTO, error)
+	Verify(ctx context.Context) (*dtos.AuditVerificationDTO, error)
+}
@@ -0,0 +1,16 @@
+package services
+
+import (
+	"context"
+	"io"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+)
+
+// BookImportService define os serviços de importação de livros em lote
+type BookImportService interface {
+	Import(ctx context.Context, format string, dryRun bool, input io.Reader) (*dtos.ImportReportDTO, error)
+	StartImport(ctx context.Context, requestedByID uint, format string, dryRun bool, path string) (*dtos.ImportJobResponseDTO, error)
+	GetJob(ctx context.Context, id uint) (*dtos.ImportJobResponseDTO, error)
+	ListJobs(ctx context.Context) ([]dtos.ImportJobResponseDTO, error)
+}
@@ -1,14 +1,26 @@
 package services
 
 import (
+	"context"
+	"io"
+
 	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
 )
 
 // BookService define os serviços disponíveis para livros
 type BookService interface {
-	Create(bookDTO dtos.BookCreateDTO) (*dtos.BookResponseDTO, error)
-	GetByID(id uint) (*dtos.BookResponseDTO, error)
-	List() ([]dtos.BookResponseDTO, error)
-	Update(id uint, bookDTO dtos.BookUpdateDTO) (*dtos.BookResponseDTO, error)
-	Delete(id uint) error
+	Create(ctx context.Context, bookDTO dtos.BookCreateDTO) (*dtos.BookResponseDTO, error)
+	GetByID(ctx context.Context, id uint) (*dtos.BookResponseDTO, error)
+	GetByISBN(ctx context.Context, isbn string) (*dtos.BookResponseDTO, error)
+	List(ctx context.Context, filterDTO dtos.BookListFilterDTO) ([]dtos.BookResponseDTO, error)
+	AdminList(ctx context.Context, filterDTO dtos.AdminBookListFilterDTO) ([]dtos.BookResponseDTO, error)
+	Export(ctx context.Context, filterDTO dtos.BookListFilterDTO, format string, output io.Writer) error
+	Update(ctx context.Context, id uint, bookDTO dtos.BookUpdateDTO) (*dtos.BookResponseDTO, error)
+	AddCopies(ctx context.Context, id uint, quantity int) (*dtos.BookResponseDTO, error)
+	Withdraw(ctx context.Context, id uint) (*dtos.BookResponseDTO, error)
+	Reinstate(ctx context.Context, id uint) (*dtos.BookResponseDTO, error)
+	Delete(ctx context.Context, id uint) error
+	ListDeleted(ctx context.Context) ([]dtos.BookResponseDTO, error)
+	Restore(ctx context.Context, id uint) (*dtos.BookResponseDTO, error)
+	ReconcileInventory(ctx context.Context, repair bool) (*dtos.InventoryReportDTO, error)
 }
@@ -0,0 +1,15 @@
+package services
+
+import (
+	"context"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+)
+
+// BootstrapService define a criação explícita de administradores: pelo token de instalação, enquanto
+// o sistema não tem nenhum administrador, ou pela linha de comando
+type BootstrapService interface {
+	IssueSetupToken(ctx context.Context) (string, error)
+	CompleteSetup(ctx context.Context, setupDTO dtos.SetupDTO) (*dtos.UserResponseDTO, error)
+	CreateAdmin(ctx context.Context, userDTO dtos.UserCreateDTO) (*dtos.UserResponseDTO, bool, error)
+}
@@ -0,0 +1,21 @@
+package services
+
+import (
+	"context"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+)
+
+// BranchService define os serviços disponíveis para unidades, acervo por unidade e transferências
+type BranchService interface {
+	Create(ctx context.Context, branchDTO dtos.BranchCreateDTO) (*dtos.BranchResponseDTO, error)
+	GetByID(ctx context.Context, id uint) (*dtos.BranchResponseDTO, error)
+	List(ctx context.Context) ([]dtos.BranchResponseDTO, error)
+	Update(ctx context.Context, id uint, branchDTO dtos.BranchUpdateDTO) (*dtos.BranchResponseDTO, error)
+	Delete(ctx context.Context, id uint) error
+	SetHolding(ctx context.Context, bookID, branchID uint, holdingDTO dtos.HoldingUpdateDTO) (*dtos.BookResponseDTO, error)
+	RequestTransfer(ctx context.Context, staffID uint, transferDTO dtos.TransferCreateDTO) (*dtos.TransferResponseDTO, error)
+	ListTransfers(ctx context.Context, staffID uint) ([]dtos.TransferResponseDTO, error)
+	ApproveTransfer(ctx context.Context, id uint, staffID uint) (*dtos.TransferResponseDTO, error)
+	RejectTransfer(ctx context.Context, id uint, staffID uint) (*dtos.TransferResponseDTO, error)
+}
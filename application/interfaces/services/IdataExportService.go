@@ -0,0 +1,20 @@
+package services
+
+import (
+	"context"
+	"io"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+)
+
+// DataExportService define os serviços de exportação de dados pessoais
+type DataExportService interface {
+	RequiresAsync(ctx context.Context, userID uint) (bool, error)
+	WriteBundle(ctx context.Context, userID uint, format string, output io.Writer) error
+	StartExport(ctx context.Context, userID uint, format string) (*dtos.DataExportResponseDTO, error)
+	GetExport(ctx context.Context, userID uint, id uint) (*dtos.DataExportResponseDTO, error)
+	ListExports(ctx context.Context, userID uint) ([]dtos.DataExportResponseDTO, error)
+	OpenDownload(ctx context.Context, id uint, expires int64, signature string) (*entities.DataExport, error)
+	DeleteExpired(ctx context.Context) (int, error)
+}
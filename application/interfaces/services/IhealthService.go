@@ -0,0 +1,14 @@
+package services
+
+import (
+	"context"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+)
+
+// HealthService define as verificações de saúde usadas pelo orquestrador
+type HealthService interface {
+	Live(ctx context.Context) dtos.HealthReportDTO
+	Ready(ctx context.Context) (dtos.HealthReportDTO, bool)
+	StartDraining()
+}
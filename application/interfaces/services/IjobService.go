@@ -0,0 +1,16 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+)
+
+// JobService define os serviços de consulta e execução das tarefas agendadas
+type JobService interface {
+	List(ctx context.Context) ([]dtos.JobDTO, error)
+	ListRuns(ctx context.Context, filter dtos.JobRunFilterDTO) ([]dtos.JobRunDTO, error)
+	Run(ctx context.Context, name string) (*dtos.JobRunDTO, error)
+	PruneHistory(ctx context.Context, retention time.Duration) (int64, error)
+}
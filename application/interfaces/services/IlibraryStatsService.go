@@ -0,0 +1,12 @@
+package services
+
+import (
+	"context"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+)
+
+// LibraryStatsService define os serviços de indicadores gerais da biblioteca
+type LibraryStatsService interface {
+	Collect(ctx context.Context) (*dtos.LibraryStatsDTO, error)
+}
@@ -1,13 +1,19 @@
 package services
 
 import (
+	"context"
+
 	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
 )
 
 // LoanService define os serviços disponíveis para empréstimos
 type LoanService interface {
-	Create(userID uint, loanDTO dtos.LoanCreateDTO) (*dtos.LoanResponseDTO, error)
-	GetByID(id uint, userID uint) (*dtos.LoanResponseDTO, error)
-	ListByUser(userID uint) ([]dtos.LoanResponseDTO, error)
-	ReturnLoan(id uint, userID uint) (*dtos.LoanResponseDTO, error)
+	Create(ctx context.Context, userID uint, loanDTO dtos.LoanCreateDTO) (*dtos.LoanResponseDTO, error)
+	GetByID(ctx context.Context, id uint, userID uint) (*dtos.LoanResponseDTO, error)
+	ListByUser(ctx context.Context, userID uint) ([]dtos.LoanResponseDTO, error)
+	ReturnLoan(ctx context.Context, id uint, userID uint) (*dtos.LoanResponseDTO, error)
+	CheckOut(ctx context.Context, staffID uint, loanDTO dtos.StaffLoanCreateDTO) (*dtos.LoanResponseDTO, error)
+	CheckIn(ctx context.Context, id uint, staffID uint) (*dtos.LoanResponseDTO, error)
+	ForceReturn(ctx context.Context, id uint) (*dtos.LoanResponseDTO, error)
+	ListOverdue(ctx context.Context) ([]dtos.LoanResponseDTO, error)
 }
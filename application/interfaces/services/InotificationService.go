@@ -0,0 +1,17 @@
+package services
+
+import (
+	"context"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+)
+
+// NotificationService define os serviços de lembretes de devolução, avisos de atraso e caixa de entrada
+type NotificationService interface {
+	SendLoanReminders(ctx context.Context) (*dtos.NotificationScanResultDTO, error)
+	Inbox(ctx context.Context, userID uint, filter dtos.NotificationFilterDTO) (*dtos.NotificationInboxDTO, error)
+	MarkRead(ctx context.Context, userID uint, id uint) error
+	MarkAllRead(ctx context.Context, userID uint) (int64, error)
+	GetPreferences(ctx context.Context, userID uint) (*dtos.NotificationPreferencesDTO, error)
+	UpdatePreferences(ctx context.Context, userID uint, preferences dtos.NotificationPreferencesUpdateDTO) (*dtos.NotificationPreferencesDTO, error)
+}
@@ -1,18 +1,27 @@
 package services
 
 import (
+	"context"
+
 	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
 	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
 )
 
 // UserService define os serviços disponíveis para usuários
 type UserService interface {
-	Create(userDTO dtos.UserCreateDTO) (*dtos.UserResponseDTO, error)
-	GetByID(id uint) (*dtos.UserResponseDTO, error)
-	GetByEmail(email string) (*entities.User, error)
-	Update(id uint, userDTO dtos.UserUpdateDTO) (*dtos.UserResponseDTO, error)
-	Delete(id uint) error
-	List() ([]dtos.UserResponseDTO, error)
-	PromoteToAdmin(id uint) (*dtos.UserResponseDTO, error)
-	AuthenticateUser(email, password string) (*entities.User, error)
+	Create(ctx context.Context, userDTO dtos.UserCreateDTO) (*dtos.UserResponseDTO, error)
+	GetByID(ctx context.Context, id uint) (*dtos.UserResponseDTO, error)
+	GetByEmail(ctx context.Context, email string) (*entities.User, error)
+	Update(ctx context.Context, id uint, userDTO dtos.UserUpdateDTO) (*dtos.UserResponseDTO, error)
+	Delete(ctx context.Context, id uint) error
+	RequestDeletion(ctx context.Context, id uint) (*dtos.UserResponseDTO, error)
+	ListDeleted(ctx context.Context) ([]dtos.UserResponseDTO, error)
+	Restore(ctx context.Context, id uint) (*dtos.UserResponseDTO, error)
+	Erase(ctx context.Context, id uint) (*dtos.UserResponseDTO, error)
+	EraseDue(ctx context.Context) (int, error)
+	List(ctx context.Context) ([]dtos.UserResponseDTO, error)
+	PromoteToAdmin(ctx context.Context, id uint) (*dtos.UserResponseDTO, error)
+	DemoteFromAdmin(ctx context.Context, id uint) (*dtos.UserResponseDTO, error)
+	AssignBranch(ctx context.Context, id uint, branchDTO dtos.UserBranchDTO) (*dtos.UserResponseDTO, error)
+	AuthenticateUser(ctx context.Context, email, password string) (*entities.User, error)
 }
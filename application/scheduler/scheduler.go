@@ -0,0 +1,274 @@
+// Package scheduler executa tarefas recorrentes no próprio processo da API, nos horários de expressões cron.
+// Cada horário previsto é registrado no banco antes da execução, e o índice único do registro garante que,
+// com várias réplicas, apenas uma execute a tarefa naquele horário. Falhas são repetidas com espera
+// crescente, e os registros formam o histórico consultado pelos administradores.
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/repositories"
+	"github.com/henrygoeszanin/api_golang_estudos/application/workers"
+	"github.com/henrygoeszanin/api_golang_estudos/domain/cron"
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+	domainerrors "github.com/henrygoeszanin/api_golang_estudos/domain/errors"
+	"github.com/henrygoeszanin/api_golang_estudos/logging"
+)
+
+// Job descreve uma tarefa recorrente
+type Job struct {
+	Name        string
+	Description string
+	Schedule    *cron.Schedule // Sem expressão, a tarefa só é executada a pedido de um administrador
+	Run         func(ctx context.Context) error
+}
+
+// Options define o fuso das expressões cron e as novas tentativas após uma falha
+type Options struct {
+	Location     *time.Location
+	MaxAttempts  int           // Tentativas por execução, contando a primeira
+	RetryBackoff time.Duration // Espera antes da segunda tentativa; dobra a cada nova falha
+}
+
+// Scheduler guarda as tarefas registradas e as executa nos horários previstos
+type Scheduler struct {
+	repository repositories.JobRunRepository
+	pool       *workers.Pool
+	options    Options
+	instance   string
+
+	mutex   sync.Mutex
+	jobs    map[string]*Job
+	running map[string]bool
+}
+
+// New cria o agendador. As tarefas são executadas no pool informado e param no encerramento dele.
+func New(repository repositories.JobRunRepository, pool *workers.Pool, options Options) *Scheduler {
+	if options.Location == nil {
+		options.Location = time.UTC
+	}
+	if options.MaxAttempts < 1 {
+		options.MaxAttempts = 1
+	}
+
+	hostname, _ := os.Hostname()
+	return &Scheduler{
+		repository: repository,
+		pool:       pool,
+		options:    options,
+		instance:   fmt.Sprintf("%s/%d", hostname, os.Getpid()),
+		jobs:       make(map[string]*Job),
+		running:    make(map[string]bool),
+	}
+}
+
+// Register acrescenta uma tarefa. Deve ser chamado antes de Start.
+func (scheduler *Scheduler) Register(job Job) {
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+	if _, ok := scheduler.jobs[job.Name]; ok {
+		panic("tarefa agendada registrada duas vezes: " + job.Name)
+	}
+	scheduler.jobs[job.Name] = &job
+}
+
+// Start passa a executar as tarefas com expressão cron nos horários previstos. Horários que passam com
+// a aplicação parada ou com a execução anterior ainda em andamento não são recuperados.
+func (scheduler *Scheduler) Start() {
+	for _, job := range scheduler.Jobs() {
+		if job.Schedule == nil {
+			continue
+		}
+		job := job
+		scheduler.pool.Go(func(ctx context.Context) {
+			scheduler.loop(ctx, &job)
+		})
+	}
+}
+
+// Jobs retorna as tarefas registradas, em ordem de nome
+func (scheduler *Scheduler) Jobs() []Job {
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+
+	jobs := make([]Job, 0, len(scheduler.jobs))
+	for _, job := range scheduler.jobs {
+		jobs = append(jobs, *job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].Name < jobs[j].Name })
+	return jobs
+}
+
+// Location retorna o fuso em que as expressões cron são interpretadas
+func (scheduler *Scheduler) Location() *time.Location {
+	return scheduler.options.Location
+}
+
+// Next retorna o próximo horário previsto da tarefa, ou nil se ela não tiver expressão cron
+func (scheduler *Scheduler) Next(job Job, after time.Time) *time.Time {
+	if job.Schedule == nil {
+		return nil
+	}
+	next := job.Schedule.Next(after.In(scheduler.options.Location))
+	if next.IsZero() {
+		return nil
+	}
+	return &next
+}
+
+// Trigger executa a tarefa agora, a pedido de um administrador, e retorna o registro da execução já iniciada.
+// A execução continua em segundo plano; o andamento aparece no histórico.
+func (scheduler *Scheduler) Trigger(ctx context.Context, name string) (*entities.JobRun, error) {
+	scheduler.mutex.Lock()
+	job, ok := scheduler.jobs[name]
+	scheduler.mutex.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: tarefa %q não encontrada", domainerrors.ErrNotFound, name)
+	}
+
+	run, err := scheduler.claim(ctx, job, time.Now().Truncate(time.Second), entities.JobTriggerManual)
+	if err != nil {
+		return nil, err
+	}
+	if run == nil {
+		return nil, fmt.Errorf("%w: a tarefa %q já foi iniciada neste instante por outra instância", domainerrors.ErrConflict, name)
+	}
+
+	started := *run
+	scheduler.pool.Go(func(poolCtx context.Context) {
+		scheduler.perform(logging.Detach(ctx, poolCtx), job, run)
+	})
+	return &started, nil
+}
+
+// loop aguarda cada horário previsto da tarefa e a executa, até o encerramento do pool
+func (scheduler *Scheduler) loop(ctx context.Context, job *Job) {
+	for {
+		next := scheduler.Next(*job, time.Now())
+		if next == nil {
+			return
+		}
+
+		timer := time.NewTimer(time.Until(*next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		run, err := scheduler.claim(ctx, job, *next, entities.JobTriggerSchedule)
+		if err != nil {
+			logging.FromContext(ctx).Error("falha ao registrar a tarefa agendada", "job", job.Name, "scheduled_for", *next, "error", err)
+			continue
+		}
+		if run == nil {
+			logging.FromContext(ctx).Debug("tarefa agendada executada por outra instância", "job", job.Name, "scheduled_for", *next)
+			continue
+		}
+		scheduler.perform(ctx, job, run)
+	}
+}
+
+// claim registra a execução da tarefa no horário previsto. Retorna nil, sem erro, quando outra réplica
+// já registrou o mesmo horário. Nesta instância, uma tarefa não começa enquanto a anterior não terminar.
+func (scheduler *Scheduler) claim(ctx context.Context, job *Job, scheduledFor time.Time, trigger string) (*entities.JobRun, error) {
+	scheduler.mutex.Lock()
+	if scheduler.running[job.Name] {
+		scheduler.mutex.Unlock()
+		return nil, fmt.Errorf("%w: a tarefa %q ainda está em execução", domainerrors.ErrConflict, job.Name)
+	}
+	scheduler.running[job.Name] = true
+	scheduler.mutex.Unlock()
+
+	run := &entities.JobRun{
+		JobName:      job.Name,
+		ScheduledFor: scheduledFor.UTC(),
+		Trigger:      trigger,
+		Status:       entities.JobRunStatusRunning,
+		Instance:     scheduler.instance,
+		StartedAt:    time.Now(),
+	}
+	claimed, err := scheduler.repository.Claim(ctx, run)
+	if err != nil || !claimed {
+		scheduler.release(job.Name)
+		return nil, err
+	}
+	return run, nil
+}
+
+// perform executa a tarefa registrada, repetindo-a com espera crescente enquanto falhar, e grava o resultado
+func (scheduler *Scheduler) perform(ctx context.Context, job *Job, run *entities.JobRun) {
+	defer scheduler.release(job.Name)
+	logger := logging.FromContext(ctx).With("job", job.Name, "run_id", run.ID)
+	logger.Info("tarefa agendada iniciada", "trigger", run.Trigger)
+
+	var err error
+	backoff := scheduler.options.RetryBackoff
+	for {
+		run.Attempts++
+		if err = runSafely(ctx, job); err == nil || run.Attempts >= scheduler.options.MaxAttempts || ctx.Err() != nil {
+			break
+		}
+
+		// O erro da tentativa fica visível no histórico enquanto a próxima aguarda
+		run.Error = err.Error()
+		if updateErr := scheduler.repository.Update(context.WithoutCancel(ctx), run); updateErr != nil {
+			logger.Error("falha ao gravar o andamento da tarefa", "error", updateErr)
+		}
+		logger.Warn("tarefa agendada falhou, nova tentativa", "attempt", run.Attempts, "retry_in", backoff.String(), "error", err)
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+		case <-timer.C:
+		}
+		if ctx.Err() != nil {
+			err = fmt.Errorf("interrompida no encerramento: %w", err)
+			break
+		}
+		backoff *= 2
+	}
+
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	run.Status = entities.JobRunStatusSucceeded
+	run.Error = ""
+	if err != nil {
+		run.Status = entities.JobRunStatusFailed
+		run.Error = err.Error()
+	}
+
+	// A execução já aconteceu: o resultado deve ser gravado mesmo durante o encerramento
+	if updateErr := scheduler.repository.Update(context.WithoutCancel(ctx), run); updateErr != nil {
+		logger.Error("falha ao gravar o resultado da tarefa", "error", updateErr)
+	}
+	if err != nil {
+		logger.Error("tarefa agendada falhou", "attempts", run.Attempts, "error", err)
+		return
+	}
+	logger.Info("tarefa agendada concluída", "attempts", run.Attempts, "duration_ms", finishedAt.Sub(run.StartedAt).Milliseconds())
+}
+
+// release libera a tarefa para a próxima execução nesta instância
+func (scheduler *Scheduler) release(name string) {
+	scheduler.mutex.Lock()
+	defer scheduler.mutex.Unlock()
+	delete(scheduler.running, name)
+}
+
+// runSafely executa a tarefa convertendo um pânico em erro, para que ele não derrube a aplicação
+func runSafely(ctx context.Context, job *Job) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("pânico: %v", recovered)
+		}
+	}()
+	return job.Run(ctx)
+}
@@ -0,0 +1,129 @@
+// Package seed carrega dados de demonstração: usuários, com um administrador predefinido, livros e
+// empréstimos lidos de um arquivo de fixture (YAML ou JSON) ou gerados em quantidade para testes de carga.
+// Os registros são criados pelos serviços da aplicação, com as mesmas validações da API, e a carga é
+// idempotente: registros que já existem são mantidos e contados como ignorados.
+package seed
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+)
+
+// defaultLoanDays é o prazo dos empréstimos da fixture que não informam days
+const defaultLoanDays = 14
+
+// Fixture descreve os dados a carregar
+type Fixture struct {
+	Admin *User                `json:"admin"` // Administrador predefinido, criado antes dos demais usuários
+	Users []User               `json:"users"`
+	Books []dtos.BookCreateDTO `json:"books"`
+	Loans []Loan               `json:"loans"`
+}
+
+// User descreve um usuário da fixture
+type User struct {
+	dtos.UserCreateDTO
+	Admin bool `json:"admin"`
+}
+
+// Loan descreve um empréstimo da fixture. O livro é indicado pelo ISBN ou, sem ele, pelo título exato.
+type Loan struct {
+	User     string `json:"user"` // Email do usuário
+	ISBN     string `json:"isbn"`
+	Title    string `json:"title"`
+	Days     int    `json:"days"`     // Prazo para devolução a partir de hoje; negativo gera um empréstimo atrasado
+	Returned bool   `json:"returned"` // Registra o empréstimo já devolvido
+}
+
+// Empty informa se a fixture não tem nenhum registro
+func (fixture *Fixture) Empty() bool {
+	return fixture.Admin == nil && len(fixture.Users) == 0 && len(fixture.Books) == 0 && len(fixture.Loans) == 0
+}
+
+// Append acrescenta os registros de outra fixture. O administrador só é trocado se esta não tiver um.
+func (fixture *Fixture) Append(other Fixture) {
+	if fixture.Admin == nil {
+		fixture.Admin = other.Admin
+	}
+	fixture.Users = append(fixture.Users, other.Users...)
+	fixture.Books = append(fixture.Books, other.Books...)
+	fixture.Loans = append(fixture.Loans, other.Loans...)
+}
+
+// LoadFixture lê a fixture do arquivo, no formato indicado pela extensão (.yaml, .yml ou .json).
+// Campos desconhecidos são rejeitados e todos os registros são validados antes de qualquer gravação.
+func LoadFixture(path string) (Fixture, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Fixture{}, fmt.Errorf("falha ao ler a fixture: %w", err)
+	}
+
+	// O YAML é convertido em JSON, para que os dois formatos usem as mesmas chaves dos DTOs da API
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		var document interface{}
+		if err := yaml.Unmarshal(content, &document); err != nil {
+			return Fixture{}, fmt.Errorf("falha ao interpretar a fixture %s: %w", path, err)
+		}
+		if content, err = json.Marshal(document); err != nil {
+			return Fixture{}, fmt.Errorf("falha ao interpretar a fixture %s: %w", path, err)
+		}
+	case ".json":
+	default:
+		return Fixture{}, fmt.Errorf("formato da fixture não suportado: %s (use .yaml ou .json)", path)
+	}
+
+	var fixture Fixture
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&fixture); err != nil {
+		return Fixture{}, fmt.Errorf("falha ao interpretar a fixture %s: %w", path, err)
+	}
+
+	if problems := fixture.validate(); len(problems) > 0 {
+		return Fixture{}, fmt.Errorf("fixture %s inválida:\n  - %s", path, strings.Join(problems, "\n  - "))
+	}
+	return fixture, nil
+}
+
+// validate aplica aos registros as regras dos DTOs da API, confere as referências dos empréstimos e
+// retorna todos os problemas encontrados. Livros sem quantidade recebem um exemplar.
+func (fixture *Fixture) validate() []string {
+	var problems []string
+	if fixture.Admin != nil {
+		for _, message := range dtos.Validate(fixture.Admin.UserCreateDTO) {
+			problems = append(problems, "admin: "+message)
+		}
+	}
+	for i, user := range fixture.Users {
+		for _, message := range dtos.Validate(user.UserCreateDTO) {
+			problems = append(problems, fmt.Sprintf("users[%d]: %s", i, message))
+		}
+	}
+	for i := range fixture.Books {
+		if fixture.Books[i].Quantity == 0 {
+			fixture.Books[i].Quantity = 1
+		}
+		for _, message := range dtos.Validate(fixture.Books[i]) {
+			problems = append(problems, fmt.Sprintf("books[%d]: %s", i, message))
+		}
+	}
+	for i, loan := range fixture.Loans {
+		if loan.User == "" {
+			problems = append(problems, fmt.Sprintf("loans[%d]: informe o email do usuário em user", i))
+		}
+		if loan.ISBN == "" && loan.Title == "" {
+			problems = append(problems, fmt.Sprintf("loans[%d]: informe o livro por isbn ou title", i))
+		}
+	}
+
+	return problems
+}
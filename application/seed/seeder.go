@@ -0,0 +1,198 @@
+package seed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/repositories"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/services"
+	domainerrors "github.com/henrygoeszanin/api_golang_estudos/domain/errors"
+)
+
+// Report conta os registros criados e os ignorados por já existirem
+type Report struct {
+	UsersCreated int `json:"users_created"`
+	UsersSkipped int `json:"users_skipped"`
+	BooksCreated int `json:"books_created"`
+	BooksSkipped int `json:"books_skipped"`
+	LoansCreated int `json:"loans_created"`
+	LoansSkipped int `json:"loans_skipped"`
+}
+
+// Seeder grava os registros de uma fixture pelos serviços da aplicação
+type Seeder struct {
+	userService    services.UserService
+	bookService    services.BookService
+	loanService    services.LoanService
+	bookRepository repositories.BookRepository
+	loanRepository repositories.LoanRepository
+}
+
+// NewSeeder cria o carregador de fixtures. Os repositórios são usados apenas nas consultas
+// que identificam os registros já existentes.
+func NewSeeder(
+	userService services.UserService,
+	bookService services.BookService,
+	loanService services.LoanService,
+	bookRepository repositories.BookRepository,
+	loanRepository repositories.LoanRepository,
+) *Seeder {
+	return &Seeder{
+		userService:    userService,
+		bookService:    bookService,
+		loanService:    loanService,
+		bookRepository: bookRepository,
+		loanRepository: loanRepository,
+	}
+}
+
+// Run grava a fixture: primeiro o administrador, criado como usuário comum e promovido em seguida,
+// depois usuários, livros e empréstimos. Usuários são identificados pelo email, livros pelo ISBN ou
+// por título e autores, e empréstimos pelo par usuário e livro; os que já existem são ignorados.
+// Um usuário existente marcado como administrador na fixture é promovido.
+func (seeder *Seeder) Run(ctx context.Context, fixture Fixture) (Report, error) {
+	var report Report
+
+	users := fixture.Users
+	if fixture.Admin != nil {
+		admin := *fixture.Admin
+		admin.Admin = true
+		users = append([]User{admin}, users...)
+	}
+	for _, user := range users {
+		created, err := seeder.seedUser(ctx, user)
+		if err != nil {
+			return report, fmt.Errorf("usuário %s: %w", user.Email, err)
+		}
+		if created {
+			report.UsersCreated++
+		} else {
+			report.UsersSkipped++
+		}
+	}
+
+	for _, book := range fixture.Books {
+		_, err := seeder.bookService.Create(ctx, book)
+		var duplicate *domainerrors.DuplicateError
+		switch {
+		case errors.As(err, &duplicate):
+			report.BooksSkipped++
+		case err != nil:
+			return report, fmt.Errorf("livro %s: %w", book.Title, err)
+		default:
+			report.BooksCreated++
+		}
+	}
+
+	for i, loan := range fixture.Loans {
+		created, err := seeder.seedLoan(ctx, loan)
+		if err != nil {
+			return report, fmt.Errorf("empréstimo %d (%s): %w", i+1, loan.User, err)
+		}
+		if created {
+			report.LoansCreated++
+		} else {
+			report.LoansSkipped++
+		}
+	}
+
+	return report, nil
+}
+
+// seedUser cria o usuário, se o email ainda não estiver em uso, e o promove quando marcado como administrador
+func (seeder *Seeder) seedUser(ctx context.Context, user User) (bool, error) {
+	existing, err := seeder.userService.GetByEmail(ctx, user.Email)
+	if err != nil {
+		return false, err
+	}
+
+	created := existing == nil
+	id, isAdmin := uint(0), false
+	if created {
+		response, err := seeder.userService.Create(ctx, user.UserCreateDTO)
+		if err != nil {
+			return false, err
+		}
+		id, isAdmin = response.ID, response.IsAdmin
+	} else {
+		id, isAdmin = existing.ID, existing.IsAdmin
+	}
+
+	if user.Admin && !isAdmin {
+		if _, err := seeder.userService.PromoteToAdmin(ctx, id); err != nil {
+			return false, err
+		}
+	}
+	return created, nil
+}
+
+// seedLoan registra o empréstimo, se o usuário ainda não tiver um empréstimo do livro, e a devolução quando indicada
+func (seeder *Seeder) seedLoan(ctx context.Context, loan Loan) (bool, error) {
+	user, err := seeder.userService.GetByEmail(ctx, loan.User)
+	if err != nil {
+		return false, err
+	}
+	if user == nil {
+		return false, errors.New("usuário não encontrado")
+	}
+
+	bookID, err := seeder.findBook(ctx, loan)
+	if err != nil {
+		return false, err
+	}
+
+	existing, err := seeder.loanRepository.FindByUserID(ctx, user.ID)
+	if err != nil {
+		return false, err
+	}
+	for _, existingLoan := range existing {
+		if existingLoan.BookID == bookID {
+			return false, nil
+		}
+	}
+
+	days := loan.Days
+	if days == 0 {
+		days = defaultLoanDays
+	}
+	created, err := seeder.loanService.Create(ctx, user.ID, dtos.LoanCreateDTO{
+		BookID:     bookID,
+		ReturnDate: time.Now().AddDate(0, 0, days),
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if loan.Returned {
+		if _, err := seeder.loanService.ReturnLoan(ctx, created.ID, user.ID); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// findBook busca o livro do empréstimo pelo ISBN ou, sem ele, pelo título exato
+func (seeder *Seeder) findBook(ctx context.Context, loan Loan) (uint, error) {
+	if loan.ISBN != "" {
+		book, err := seeder.bookService.GetByISBN(ctx, loan.ISBN)
+		if err != nil {
+			return 0, fmt.Errorf("livro %s: %w", loan.ISBN, err)
+		}
+		return book.ID, nil
+	}
+
+	books, err := seeder.bookRepository.List(ctx, repositories.BookFilter{Title: loan.Title})
+	if err != nil {
+		return 0, err
+	}
+	for _, book := range books {
+		if strings.EqualFold(book.Title, strings.TrimSpace(loan.Title)) {
+			return book.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: livro %q não encontrado", domainerrors.ErrNotFound, loan.Title)
+}
@@ -0,0 +1,36 @@
+package seed
+
+import (
+	"fmt"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+)
+
+// SyntheticPassword é a senha de todos os usuários sintéticos
+const SyntheticPassword = "senha-sintetica"
+
+// Synthetic gera uma fixture com a quantidade informada de livros e usuários para testes de carga.
+// Os registros são sempre os mesmos para a mesma posição, de modo que gerar de novo não os duplica.
+func Synthetic(books, users int) Fixture {
+	var fixture Fixture
+	for i := 1; i <= users; i++ {
+		fixture.Users = append(fixture.Users, User{UserCreateDTO: dtos.UserCreateDTO{
+			Name:     fmt.Sprintf("Leitor Sintético %05d", i),
+			Email:    fmt.Sprintf("leitor%05d@seed.invalid", i),
+			Password: SyntheticPassword,
+		}})
+	}
+	for i := 1; i <= books; i++ {
+		fixture.Books = append(fixture.Books, dtos.BookCreateDTO{
+			Title:           fmt.Sprintf("Livro Sintético %05d", i),
+			Authors:         []string{fmt.Sprintf("Autor Sintético %02d", i%50+1)},
+			Publisher:       fmt.Sprintf("Editora Sintética %d", i%7+1),
+			PublicationYear: 1950 + i%75,
+			Language:        "pt-BR",
+			PageCount:       100 + i%400,
+			Subjects:        []string{fmt.Sprintf("Assunto %d", i%10+1)},
+			Quantity:        i%5 + 1,
+		})
+	}
+	return fixture
+}
@@ -0,0 +1,328 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/repositories"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/services"
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+)
+
+// Tipos de alvo reconhecidos pela trilha de auditoria
+const (
+	AuditTargetBook     = "book"
+	AuditTargetUser     = "user"
+	AuditTargetLoan     = "loan"
+	AuditTargetBranch   = "branch"
+	AuditTargetTransfer = "transfer"
+	AuditTargetJob      = "job"
+)
+
+// auditVerifyBatchSize define quantos eventos são lidos por vez na verificação da cadeia
+const auditVerifyBatchSize = 1000
+
+// auditService implementa a interface AuditService
+type auditService struct {
+	auditRepo    repositories.AuditEventRepository
+	bookRepo     repositories.BookRepository
+	userRepo     repositories.UserRepository
+	loanRepo     repositories.LoanRepository
+	branchRepo   repositories.BranchRepository
+	transferRepo repositories.TransferRepository
+}
+
+// NewAuditService cria uma nova instância do serviço da trilha de auditoria
+func NewAuditService(
+	auditRepo repositories.AuditEventRepository,
+	bookRepo repositories.BookRepository,
+	userRepo repositories.UserRepository,
+	loanRepo repositories.LoanRepository,
+	branchRepo repositories.BranchRepository,
+	transferRepo repositories.TransferRepository,
+) services.AuditService {
+	return &auditService{
+		auditRepo:    auditRepo,
+		bookRepo:     bookRepo,
+		userRepo:     userRepo,
+		loanRepo:     loanRepo,
+		branchRepo:   branchRepo,
+		transferRepo: transferRepo,
+	}
+}
+
+// Record registra uma ação na trilha de auditoria, calculando os campos alterados
+func (auditService *auditService) Record(ctx context.Context, record dtos.AuditRecordDTO) error {
+	before, err := marshalSnapshot(record.Before)
+	if err != nil {
+		return err
+	}
+	after, err := marshalSnapshot(record.After)
+	if err != nil {
+		return err
+	}
+	changes, err := diffSnapshots(before, after)
+	if err != nil {
+		return err
+	}
+
+	event := &entities.AuditEvent{
+		ActorID:    record.ActorID,
+		ActorEmail: record.Operator,
+		Action:     record.Action,
+		TargetType: record.TargetType,
+		TargetID:   record.TargetID,
+		StatusCode: record.StatusCode,
+		Before:     before,
+		After:      after,
+		Changes:    changes,
+		IP:         record.IP,
+		UserAgent:  truncate(record.UserAgent, 255),
+		RequestID:  truncate(record.RequestID, 64),
+	}
+	return auditService.auditRepo.Append(ctx, event)
+}
+
+// Snapshot retorna o estado atual do alvo, na mesma forma em que a API o expõe.
+// Retorna nil quando o alvo não existe ou o tipo não é reconhecido.
+func (auditService *auditService) Snapshot(ctx context.Context, targetType string, targetID string) (interface{}, error) {
+	id, err := strconv.ParseUint(targetID, 10, 32)
+	if err != nil {
+		return nil, nil
+	}
+
+	switch targetType {
+	case AuditTargetBook:
+		book, err := auditService.bookRepo.FindByID(ctx, uint(id))
+		if err != nil || book == nil {
+			return nil, err
+		}
+		return dtos.BookToResponseDTO(*book), nil
+	case AuditTargetUser:
+		user, err := auditService.userRepo.FindByIDWithDeleted(ctx, uint(id))
+		if err != nil || user == nil {
+			return nil, err
+		}
+		return dtos.UserToAuditSnapshotDTO(*user), nil
+	case AuditTargetLoan:
+		loan, err := auditService.loanRepo.FindByID(ctx, uint(id))
+		if err != nil || loan == nil {
+			return nil, err
+		}
+		// O nome do usuário fica de fora pelo mesmo motivo do estado dos usuários; basta o ID
+		snapshot := dtos.LoanToResponseDTO(*loan)
+		snapshot.UserName = ""
+		return snapshot, nil
+	case AuditTargetBranch:
+		branch, err := auditService.branchRepo.FindByID(ctx, uint(id))
+		if err != nil || branch == nil {
+			return nil, err
+		}
+		return dtos.BranchToResponseDTO(*branch), nil
+	case AuditTargetTransfer:
+		transfer, err := auditService.transferRepo.FindByID(ctx, uint(id))
+		if err != nil || transfer == nil {
+			return nil, err
+		}
+		return dtos.TransferToResponseDTO(*transfer), nil
+	}
+	return nil, nil
+}
+
+// List retorna uma página da trilha de auditoria, do evento mais recente ao mais antigo
+func (auditService *auditService) List(ctx context.Context, filterDTO dtos.AuditListFilterDTO) (*dtos.AuditEventPageDTO, error) {
+	page := filterDTO.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := filterDTO.PageSize
+	if pageSize < 1 {
+		pageSize = dtos.AuditDefaultPageSize
+	}
+	if pageSize > dtos.AuditMaxPageSize {
+		pageSize = dtos.AuditMaxPageSize
+	}
+
+	filter := repositories.AuditEventFilter{
+		ActorID:    filterDTO.ActorID,
+		Action:     filterDTO.Action,
+		TargetType: filterDTO.TargetType,
+		TargetID:   filterDTO.TargetID,
+	}
+	if !filterDTO.From.IsZero() {
+		filter.From = &filterDTO.From
+	}
+	if !filterDTO.To.IsZero() {
+		filter.To = &filterDTO.To
+	}
+
+	events, total, err := auditService.auditRepo.List(ctx, filter, (page-1)*pageSize, pageSize)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := auditService.toResponseDTOs(ctx, events)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dtos.AuditEventPageDTO{
+		Items:    items,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}, nil
+}
+
+// ListByUser retorna os eventos realizados pelo usuário ou que o tiveram como alvo
+func (auditService *auditService) ListByUser(ctx context.Context, userID uint) ([]dtos.AuditEventResponseDTO, error) {
+	events, err := auditService.auditRepo.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return auditService.toResponseDTOs(ctx, events)
+}
+
+// Verify percorre a cadeia de auditoria e confere o hash de cada evento e a ligação com o anterior.
+// Um evento alterado, removido ou inserido fora da ordem interrompe a cadeia a partir dele.
+func (auditService *auditService) Verify(ctx context.Context) (*dtos.AuditVerificationDTO, error) {
+	result := &dtos.AuditVerificationDTO{Valid: true}
+	prevHash := ""
+	var lastID uint
+
+	err := auditService.auditRepo.Walk(ctx, auditVerifyBatchSize, func(events []*entities.AuditEvent) error {
+		for _, event := range events {
+			if !result.Valid {
+				return nil
+			}
+			result.CheckedEvents++
+
+			switch {
+			case event.PrevHash != prevHash:
+				result.Valid = false
+				result.BrokenAtID = event.ID
+				result.Reason = "o evento não aponta para o evento anterior da cadeia"
+			case event.ComputeHash() != event.Hash:
+				result.Valid = false
+				result.BrokenAtID = event.ID
+				result.Reason = "o conteúdo do evento não confere com o hash registrado"
+			}
+
+			prevHash = event.Hash
+			lastID = event.ID
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !result.Valid {
+		return result, nil
+	}
+
+	// Confere o último elo, o que revela a remoção de eventos do fim da cadeia
+	head, err := auditService.auditRepo.Head(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if head.LastHash != prevHash || head.LastEventID != lastID {
+		result.Valid = false
+		result.BrokenAtID = lastID
+		result.Reason = "o último evento da cadeia não confere com o último elo registrado"
+	}
+
+	return result, nil
+}
+
+// toResponseDTOs converte os eventos, preenchendo o e-mail atual de cada autor cadastrado.
+// Depois que os dados de um usuário são apagados, aparece o endereço anonimizado.
+func (auditService *auditService) toResponseDTOs(ctx context.Context, events []*entities.AuditEvent) ([]dtos.AuditEventResponseDTO, error) {
+	emails := map[uint]string{}
+	items := make([]dtos.AuditEventResponseDTO, 0, len(events))
+	for _, event := range events {
+		item := dtos.AuditEventToResponseDTO(*event)
+		if event.ActorID != nil {
+			email, ok := emails[*event.ActorID]
+			if !ok {
+				actor, err := auditService.userRepo.FindByIDWithDeleted(ctx, *event.ActorID)
+				if err != nil {
+					return nil, err
+				}
+				if actor != nil {
+					email = actor.Email
+				}
+				emails[*event.ActorID] = email
+			}
+			item.ActorEmail = email
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// marshalSnapshot serializa o estado do alvo em JSON; nil resulta em texto vazio
+func marshalSnapshot(snapshot interface{}) (string, error) {
+	if snapshot == nil {
+		return "", nil
+	}
+	content, err := json.Marshal(snapshot)
+	if err != nil {
+		return "", fmt.Errorf("falha ao serializar o estado para a auditoria: %w", err)
+	}
+	return string(content), nil
+}
+
+// diffSnapshots compara os estados antes e depois da ação, campo a campo
+func diffSnapshots(before, after string) (string, error) {
+	if before == "" && after == "" {
+		return "", nil
+	}
+
+	beforeFields := map[string]interface{}{}
+	afterFields := map[string]interface{}{}
+	if before != "" {
+		if err := json.Unmarshal([]byte(before), &beforeFields); err != nil {
+			return "", nil // Estado que não é um objeto: não há campos a comparar
+		}
+	}
+	if after != "" {
+		if err := json.Unmarshal([]byte(after), &afterFields); err != nil {
+			return "", nil
+		}
+	}
+
+	changes := map[string]map[string]interface{}{}
+	for field, from := range beforeFields {
+		to := afterFields[field]
+		if !reflect.DeepEqual(from, to) {
+			changes[field] = map[string]interface{}{"from": from, "to": to}
+		}
+	}
+	for field, to := range afterFields {
+		if _, exists := beforeFields[field]; !exists {
+			changes[field] = map[string]interface{}{"from": nil, "to": to}
+		}
+	}
+	if len(changes) == 0 {
+		return "", nil
+	}
+
+	content, err := json.Marshal(changes)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+// truncate limita o texto ao tamanho da coluna
+func truncate(value string, size int) string {
+	if len(value) > size {
+		return value[:size]
+	}
+	return value
+}
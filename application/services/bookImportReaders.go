@@ -0,0 +1,195 @@
+package services
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	domainerrors "github.com/henrygoeszanin/api_golang_estudos/domain/errors"
+)
+
+// importRow representa uma linha lida do arquivo de importação
+type importRow struct {
+	Number int // Linha do arquivo
+	Book   dtos.BookCreateDTO
+	Err    error // Erro de leitura ou conversão da linha
+}
+
+// bookRowReader lê as linhas de um arquivo de importação, uma por vez, sem carregá-lo inteiro na memória
+type bookRowReader interface {
+	// Next retorna a próxima linha ou io.EOF ao final do arquivo
+	Next() (importRow, error)
+}
+
+// newBookRowReader cria o leitor adequado ao formato do arquivo
+func newBookRowReader(format string, input io.Reader) (bookRowReader, error) {
+	switch format {
+	case dtos.ImportFormatCSV:
+		return newCSVRowReader(input)
+	case dtos.ImportFormatJSONL:
+		return &jsonLinesRowReader{reader: bufio.NewReader(input)}, nil
+	default:
+		return nil, fmt.Errorf("%w: formato de importação não suportado: %q", domainerrors.ErrInvalidData, format)
+	}
+}
+
+// csvRowReader lê livros de um CSV com cabeçalho. Listas (authors, subjects) usam "|" ou ";" como separador.
+type csvRowReader struct {
+	reader  *csv.Reader
+	columns map[string]int
+}
+
+// csvColumns lista as colunas reconhecidas no cabeçalho do CSV
+var csvColumns = []string{
+	"title", "author", "authors", "description", "isbn", "publisher", "publication_year",
+	"edition", "language", "page_count", "cover_url", "subjects", "quantity",
+}
+
+// newCSVRowReader lê o cabeçalho do CSV e mapeia as colunas reconhecidas
+func newCSVRowReader(input io.Reader) (*csvRowReader, error) {
+	reader := csv.NewReader(input)
+	reader.TrimLeadingSpace = true
+	reader.FieldsPerRecord = -1 // A quantidade de campos é validada por linha
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%w: arquivo CSV vazio", domainerrors.ErrInvalidData)
+		}
+		return nil, fmt.Errorf("%w: cabeçalho do CSV inválido: %v", domainerrors.ErrInvalidData, err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		columns[name] = i
+	}
+	if _, ok := columns["title"]; !ok {
+		return nil, fmt.Errorf("%w: cabeçalho do CSV deve conter a coluna title", domainerrors.ErrInvalidData)
+	}
+
+	known := make(map[string]bool, len(csvColumns))
+	for _, name := range csvColumns {
+		known[name] = true
+	}
+	for name := range columns {
+		if !known[name] {
+			return nil, fmt.Errorf("%w: coluna desconhecida no CSV: %q", domainerrors.ErrInvalidData, name)
+		}
+	}
+
+	return &csvRowReader{reader: reader, columns: columns}, nil
+}
+
+// Next retorna a próxima linha do CSV
+func (csvReader *csvRowReader) Next() (importRow, error) {
+	record, err := csvReader.reader.Read()
+	if errors.Is(err, io.EOF) {
+		return importRow{}, io.EOF
+	}
+
+	line, _ := csvReader.reader.FieldPos(0)
+	row := importRow{Number: line}
+
+	var parseErr *csv.ParseError
+	if err != nil {
+		if !errors.As(err, &parseErr) {
+			return importRow{}, err
+		}
+		row.Number = parseErr.Line
+		row.Err = fmt.Errorf("linha CSV malformada: %v", parseErr.Err)
+		return row, nil
+	}
+	if len(record) != len(csvReader.columns) {
+		row.Err = fmt.Errorf("esperadas %d colunas, encontradas %d", len(csvReader.columns), len(record))
+		return row, nil
+	}
+
+	value := func(name string) string {
+		if index, ok := csvReader.columns[name]; ok {
+			return strings.TrimSpace(record[index])
+		}
+		return ""
+	}
+	number := func(name string) int {
+		raw := value(name)
+		if raw == "" || row.Err != nil {
+			return 0
+		}
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			row.Err = fmt.Errorf("coluna %s deve ser um número inteiro", name)
+		}
+		return parsed
+	}
+
+	row.Book = dtos.BookCreateDTO{
+		Title:           value("title"),
+		Author:          value("author"),
+		Authors:         splitList(value("authors")),
+		Description:     value("description"),
+		ISBN:            value("isbn"),
+		Publisher:       value("publisher"),
+		PublicationYear: number("publication_year"),
+		Edition:         value("edition"),
+		Language:        value("language"),
+		PageCount:       number("page_count"),
+		CoverURL:        value("cover_url"),
+		Subjects:        splitList(value("subjects")),
+		Quantity:        number("quantity"),
+	}
+	return row, nil
+}
+
+// jsonLinesRowReader lê livros de um arquivo JSON Lines, com um objeto por linha
+type jsonLinesRowReader struct {
+	reader *bufio.Reader
+	line   int
+}
+
+// Next retorna a próxima linha não vazia do arquivo JSON Lines
+func (jsonReader *jsonLinesRowReader) Next() (importRow, error) {
+	for {
+		content, err := jsonReader.reader.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return importRow{}, err
+		}
+		if len(content) == 0 && errors.Is(err, io.EOF) {
+			return importRow{}, io.EOF
+		}
+		jsonReader.line++
+
+		content = bytes.TrimSpace(content)
+		if len(content) == 0 {
+			continue
+		}
+
+		row := importRow{Number: jsonReader.line}
+		if decodeErr := json.Unmarshal(content, &row.Book); decodeErr != nil {
+			row.Err = fmt.Errorf("JSON inválido: %v", decodeErr)
+		}
+		return row, nil
+	}
+}
+
+// splitList separa uma célula com vários valores por "|" ou ";"
+func splitList(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+
+	var values []string
+	for _, value := range strings.FieldsFunc(raw, func(r rune) bool { return r == '|' || r == ';' }) {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -0,0 +1,458 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/repositories"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/services"
+	"github.com/henrygoeszanin/api_golang_estudos/application/workers"
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+	domainerrors "github.com/henrygoeszanin/api_golang_estudos/domain/errors"
+	"github.com/henrygoeszanin/api_golang_estudos/domain/isbn"
+	"github.com/henrygoeszanin/api_golang_estudos/logging"
+)
+
+const (
+	// importBatchSize é a quantidade de linhas gravadas por transação
+	importBatchSize = 500
+	// maxStoredIssues limita as linhas com problema guardadas em uma importação assíncrona
+	maxStoredIssues = 1000
+	// recentImportJobs é a quantidade de importações retornadas na listagem
+	recentImportJobs = 50
+)
+
+// bookImportService implementa a interface BookImportService
+type bookImportService struct {
+	bookRepository      repositories.BookRepository
+	importJobRepository repositories.ImportJobRepository
+	pool                *workers.Pool
+}
+
+// NewBookImportService cria uma nova instância do serviço de importação de livros
+func NewBookImportService(
+	bookRepository repositories.BookRepository,
+	importJobRepository repositories.ImportJobRepository,
+	pool *workers.Pool,
+) services.BookImportService {
+	return &bookImportService{
+		bookRepository:      bookRepository,
+		importJobRepository: importJobRepository,
+		pool:                pool,
+	}
+}
+
+// Import processa o arquivo imediatamente e retorna o relatório linha a linha.
+// Cada lote é gravado em sua própria transação: se a importação parar no meio, o erro vem acompanhado
+// do relatório dos lotes anteriores, que continuam gravados.
+func (importService *bookImportService) Import(ctx context.Context, format string, dryRun bool, input io.Reader) (*dtos.ImportReportDTO, error) {
+	reader, err := newBookRowReader(format, input)
+	if err != nil {
+		return nil, err
+	}
+
+	report := dtos.ImportReportDTO{Format: format, DryRun: dryRun, Rows: []dtos.ImportRowResultDTO{}}
+	err = importService.process(ctx, reader, dryRun, func(results []dtos.ImportRowResultDTO) error {
+		report.Rows = append(report.Rows, results...)
+		return nil
+	})
+	if err != nil {
+		report.Error = err.Error()
+	}
+
+	report.TotalRows = len(report.Rows)
+	for _, row := range report.Rows {
+		switch row.Status {
+		case dtos.ImportRowCreate:
+			report.Created++
+		case dtos.ImportRowUpdate:
+			report.Updated++
+		case dtos.ImportRowSkip:
+			report.Skipped++
+		case dtos.ImportRowError:
+			report.Failed++
+		}
+	}
+
+	return &report, err
+}
+
+// StartImport registra a importação e a processa em segundo plano a partir do arquivo informado.
+// O arquivo é removido ao final do processamento.
+func (importService *bookImportService) StartImport(ctx context.Context, requestedByID uint, format string, dryRun bool, path string) (*dtos.ImportJobResponseDTO, error) {
+	// Validar o formato e o cabeçalho antes de aceitar a importação
+	totalRows, err := countImportRows(format, path)
+	if err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+
+	job := entities.ImportJob{
+		Format:        format,
+		DryRun:        dryRun,
+		Status:        entities.ImportStatusPending,
+		TotalRows:     totalRows,
+		RequestedByID: requestedByID,
+	}
+	if err := importService.importJobRepository.Create(ctx, &job); err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+
+	importService.pool.Go(func(poolCtx context.Context) {
+		defer os.Remove(path)
+		importService.runJob(logging.Detach(ctx, poolCtx), &job, path)
+	})
+
+	responseDTO := dtos.ImportJobToResponseDTO(job)
+	return &responseDTO, nil
+}
+
+// GetJob busca o andamento de uma importação
+func (importService *bookImportService) GetJob(ctx context.Context, id uint) (*dtos.ImportJobResponseDTO, error) {
+	job, err := importService.importJobRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, fmt.Errorf("%w: importação não encontrada", domainerrors.ErrNotFound)
+	}
+
+	responseDTO := dtos.ImportJobToResponseDTO(*job)
+	return &responseDTO, nil
+}
+
+// ListJobs retorna as importações mais recentes
+func (importService *bookImportService) ListJobs(ctx context.Context) ([]dtos.ImportJobResponseDTO, error) {
+	jobs, err := importService.importJobRepository.List(ctx, recentImportJobs)
+	if err != nil {
+		return nil, err
+	}
+
+	var jobDTOs []dtos.ImportJobResponseDTO
+	for _, job := range jobs {
+		jobDTOs = append(jobDTOs, dtos.ImportJobToResponseDTO(*job))
+	}
+
+	return jobDTOs, nil
+}
+
+// runJob processa uma importação assíncrona, registrando o andamento a cada lote
+func (importService *bookImportService) runJob(ctx context.Context, job *entities.ImportJob, path string) {
+	startedAt := time.Now()
+	job.Status = entities.ImportStatusRunning
+	job.StartedAt = &startedAt
+	_ = importService.importJobRepository.Update(ctx, job)
+
+	var issues []dtos.ImportRowResultDTO
+	err := func() error {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		reader, err := newBookRowReader(job.Format, file)
+		if err != nil {
+			return err
+		}
+
+		return importService.process(ctx, reader, job.DryRun, func(results []dtos.ImportRowResultDTO) error {
+			for _, row := range results {
+				job.ProcessedRows++
+				switch row.Status {
+				case dtos.ImportRowCreate:
+					job.CreatedRows++
+				case dtos.ImportRowUpdate:
+					job.UpdatedRows++
+				case dtos.ImportRowSkip:
+					job.SkippedRows++
+				case dtos.ImportRowError:
+					job.FailedRows++
+				}
+				if (row.Status == dtos.ImportRowSkip || row.Status == dtos.ImportRowError) && len(issues) < maxStoredIssues {
+					issues = append(issues, row)
+				}
+			}
+
+			encoded, _ := json.Marshal(issues)
+			job.Issues = string(encoded)
+			return importService.importJobRepository.Update(ctx, job)
+		})
+	}()
+
+	finishedAt := time.Now()
+	job.FinishedAt = &finishedAt
+	job.Status = entities.ImportStatusCompleted
+	if err != nil {
+		job.Status = entities.ImportStatusFailed
+		job.Message = err.Error()
+		if errors.Is(err, context.Canceled) {
+			job.Message = "importação interrompida pelo encerramento do servidor"
+		}
+		logging.FromContext(ctx).Error("importação de livros falhou", "job_id", job.ID, "error", err)
+	} else {
+		logging.FromContext(ctx).Info("importação de livros concluída", "job_id", job.ID,
+			"processed", job.ProcessedRows, "created", job.CreatedRows, "updated", job.UpdatedRows, "failed", job.FailedRows)
+	}
+
+	// O resultado é gravado mesmo que o servidor esteja sendo encerrado
+	if err := importService.importJobRepository.Update(context.WithoutCancel(ctx), job); err != nil {
+		logging.FromContext(ctx).Error("falha ao gravar o resultado da importação", "job_id", job.ID, "error", err)
+	}
+}
+
+// process lê o arquivo em lotes e entrega o resultado de cada lote à função informada
+func (importService *bookImportService) process(
+	ctx context.Context,
+	reader bookRowReader,
+	dryRun bool,
+	onBatch func(results []dtos.ImportRowResultDTO) error,
+) error {
+	seen := newImportSeen()
+	var batch []importRow
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		results, err := importService.processBatch(ctx, batch, dryRun, seen)
+		if err != nil {
+			return err
+		}
+		batch = batch[:0]
+		return onBatch(results)
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		row, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("falha ao ler o arquivo: %w", err)
+		}
+
+		batch = append(batch, row)
+		if len(batch) >= importBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	return flush()
+}
+
+// importSeen guarda os livros já vistos no arquivo para detectar linhas repetidas
+type importSeen struct {
+	isbns     map[string]int  // ISBN → linha em que apareceu
+	dedupKeys map[string]bool // Chave → se a linha tinha ISBN
+}
+
+// newImportSeen cria o registro vazio de livros vistos
+func newImportSeen() *importSeen {
+	return &importSeen{
+		isbns:     make(map[string]int),
+		dedupKeys: make(map[string]bool),
+	}
+}
+
+// preparedRow representa uma linha válida com os dados normalizados
+type preparedRow struct {
+	result   *dtos.ImportRowResultDTO
+	book     dtos.BookCreateDTO
+	authors  []string
+	isbn     *string
+	dedupKey string
+}
+
+// processBatch valida as linhas do lote, decide entre criar, atualizar ou ignorar cada uma
+// e, fora do modo de simulação, grava o lote em uma única transação
+func (importService *bookImportService) processBatch(ctx context.Context, rows []importRow, dryRun bool, seen *importSeen) ([]dtos.ImportRowResultDTO, error) {
+	results := make([]dtos.ImportRowResultDTO, len(rows))
+	var prepared []preparedRow
+	var isbns, dedupKeys []string
+
+	// Validar cada linha com as mesmas regras da criação pela API
+	for i, row := range rows {
+		results[i] = dtos.ImportRowResultDTO{Row: row.Number, Title: row.Book.Title, ISBN: row.Book.ISBN}
+		if row.Err != nil {
+			results[i].Status = dtos.ImportRowError
+			results[i].Errors = []string{row.Err.Error()}
+			continue
+		}
+		if messages := dtos.Validate(row.Book); len(messages) > 0 {
+			results[i].Status = dtos.ImportRowError
+			results[i].Errors = messages
+			continue
+		}
+
+		authors := authorNames(row.Book.Authors, row.Book.Author)
+		if len(authors) == 0 {
+			results[i].Status = dtos.ImportRowError
+			results[i].Errors = []string{"informe ao menos um autor"}
+			continue
+		}
+
+		item := preparedRow{
+			result:   &results[i],
+			book:     row.Book,
+			authors:  authors,
+			dedupKey: entities.BookDedupKey(row.Book.Title, authors),
+		}
+		if row.Book.ISBN != "" {
+			normalized, err := isbn.Normalize(row.Book.ISBN)
+			if err != nil {
+				results[i].Status = dtos.ImportRowError
+				results[i].Errors = []string{err.Error()}
+				continue
+			}
+			item.isbn = &normalized
+			results[i].ISBN = normalized
+			isbns = append(isbns, normalized)
+		}
+		dedupKeys = append(dedupKeys, item.dedupKey)
+		prepared = append(prepared, item)
+	}
+
+	// Buscar de uma vez os livros já cadastrados que correspondem ao lote, no banco principal:
+	// a réplica pode ainda não ter os livros gravados pelos lotes anteriores
+	primaryCtx := repositories.WithPrimary(ctx)
+	byISBN := make(map[string]*entities.Book)
+	existing, err := importService.bookRepository.FindByISBNs(primaryCtx, isbns)
+	if err != nil {
+		return nil, err
+	}
+	for _, book := range existing {
+		byISBN[*book.ISBN] = book
+	}
+
+	byDedupKey := make(map[string][]*entities.Book)
+	candidates, err := importService.bookRepository.FindByDedupKeys(primaryCtx, dedupKeys)
+	if err != nil {
+		return nil, err
+	}
+	for _, book := range candidates {
+		byDedupKey[book.DedupKey] = append(byDedupKey[book.DedupKey], book)
+	}
+
+	var creates, updates []*entities.Book
+	var created, updated []*preparedRow
+	for i := range prepared {
+		item := &prepared[i]
+
+		if item.isbn != nil {
+			if line, repeated := seen.isbns[*item.isbn]; repeated {
+				item.result.Status = dtos.ImportRowError
+				item.result.Errors = []string{fmt.Sprintf("ISBN repetido no arquivo (linha %d)", line)}
+				continue
+			}
+			seen.isbns[*item.isbn] = item.result.Row
+
+			// Upsert por ISBN: o livro existente recebe os dados da linha
+			if book, found := byISBN[*item.isbn]; found {
+				if err := applyBookUpdate(book, importUpdateDTO(item.book, item.authors), nil); err != nil {
+					item.result.Status = dtos.ImportRowError
+					item.result.Errors = []string{err.Error()}
+					continue
+				}
+				item.result.Status = dtos.ImportRowUpdate
+				item.result.BookID = book.ID
+				updates = append(updates, book)
+				updated = append(updated, item)
+				continue
+			}
+		}
+
+		if duplicate := findDuplicate(byDedupKey[item.dedupKey], item.isbn); duplicate != nil {
+			item.result.Status = dtos.ImportRowSkip
+			item.result.BookID = duplicate.ID
+			item.result.Errors = []string{"livro com o mesmo título e autores já cadastrado"}
+			continue
+		}
+		if hadISBN, repeated := seen.dedupKeys[item.dedupKey]; repeated && (!hadISBN || item.isbn == nil) {
+			item.result.Status = dtos.ImportRowSkip
+			item.result.Errors = []string{"livro com o mesmo título e autores repetido no arquivo"}
+			continue
+		}
+		seen.dedupKeys[item.dedupKey] = item.isbn != nil
+
+		book := newBook(item.book, item.authors, item.isbn)
+		item.result.Status = dtos.ImportRowCreate
+		creates = append(creates, &book)
+		created = append(created, item)
+	}
+
+	if dryRun || (len(creates) == 0 && len(updates) == 0) {
+		return results, nil
+	}
+
+	// Gravar o lote em uma única transação; se falhar, nenhuma linha do lote é gravada
+	if err := importService.bookRepository.SaveBatch(ctx, creates, updates); err != nil {
+		for _, item := range append(created, updated...) {
+			item.result.Status = dtos.ImportRowError
+			item.result.Errors = []string{"falha ao gravar o lote: " + err.Error()}
+		}
+		return results, nil
+	}
+	for i, book := range creates {
+		created[i].result.BookID = book.ID
+	}
+
+	return results, nil
+}
+
+// importUpdateDTO converte a linha importada nos dados de atualização de um livro existente.
+// Campos vazios na linha mantêm o valor atual.
+func importUpdateDTO(book dtos.BookCreateDTO, authors []string) dtos.BookUpdateDTO {
+	return dtos.BookUpdateDTO{
+		Title:           book.Title,
+		Authors:         authors,
+		Description:     book.Description,
+		Publisher:       book.Publisher,
+		PublicationYear: book.PublicationYear,
+		Edition:         book.Edition,
+		Language:        book.Language,
+		PageCount:       book.PageCount,
+		CoverURL:        book.CoverURL,
+		Subjects:        book.Subjects,
+		Quantity:        book.Quantity,
+	}
+}
+
+// countImportRows valida o cabeçalho e conta as linhas do arquivo para acompanhar o andamento
+func countImportRows(format string, path string) (int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	reader, err := newBookRowReader(format, file)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for {
+		_, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return total, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+		total++
+	}
+}
@@ -0,0 +1,67 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/repositories"
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+	"github.com/henrygoeszanin/api_golang_estudos/infrastructure/memory"
+)
+
+// failingBatches falha na consulta dos livros já cadastrados a partir do lote de número failFrom, contado a partir de 1
+type failingBatches struct {
+	repositories.BookRepository
+	failFrom int
+	batches  int
+}
+
+func (books *failingBatches) FindByDedupKeys(ctx context.Context, keys []string) ([]*entities.Book, error) {
+	books.batches++
+	if books.batches >= books.failFrom {
+		return nil, errors.New("banco indisponível")
+	}
+	return books.BookRepository.FindByDedupKeys(ctx, keys)
+}
+
+func TestImportKeepsPartialReport(t *testing.T) {
+	var csv strings.Builder
+	csv.WriteString("title,author,quantity\n")
+	for i := 1; i <= importBatchSize+10; i++ {
+		fmt.Fprintf(&csv, "Livro %d,Autora %d,1\n", i, i)
+	}
+
+	books := &failingBatches{BookRepository: memory.NewBookRepository(memory.NewStore()), failFrom: 2}
+	importService := NewBookImportService(books, nil, nil)
+
+	report, err := importService.Import(context.Background(), "csv", false, strings.NewReader(csv.String()))
+	if err == nil {
+		t.Fatal("esperava a falha do segundo lote")
+	}
+	if report == nil {
+		t.Fatal("o relatório dos lotes gravados deveria acompanhar o erro")
+	}
+	if report.Created != importBatchSize || report.TotalRows != importBatchSize {
+		t.Errorf("relatório com %d linhas e %d criadas, esperadas %d gravadas no primeiro lote", report.TotalRows, report.Created, importBatchSize)
+	}
+	if report.Error == "" {
+		t.Error("o relatório deveria trazer o motivo da falha")
+	}
+
+	stored, err := books.FindByDedupKey(context.Background(), entities.BookDedupKey("Livro 1", []string{"Autora 1"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 1 {
+		t.Errorf("o primeiro lote deveria continuar gravado, encontrados %d livros", len(stored))
+	}
+	for _, row := range report.Rows {
+		if row.Status != dtos.ImportRowCreate {
+			t.Errorf("linha %d com situação %q, esperada %q", row.Row, row.Status, dtos.ImportRowCreate)
+		}
+	}
+}
@@ -1,14 +1,25 @@
 package services
 
 import (
-	"errors"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
 
 	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	"github.com/henrygoeszanin/api_golang_estudos/application/export"
 	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/repositories"
 	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/services"
 	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+	domainerrors "github.com/henrygoeszanin/api_golang_estudos/domain/errors"
+	"github.com/henrygoeszanin/api_golang_estudos/domain/isbn"
+	"github.com/henrygoeszanin/api_golang_estudos/logging"
 )
 
+// exportBatchSize é a quantidade de livros lidos do banco por vez na exportação
+const exportBatchSize = 500
+
 // bookService implementa a interface BookService
 type bookService struct {
 	bookRepository repositories.BookRepository
@@ -22,16 +33,31 @@ func NewBookService(bookRepository repositories.BookRepository) services.BookSer
 }
 
 // Create cria um novo livro
-func (bookservice *bookService) Create(bookDTO dtos.BookCreateDTO) (*dtos.BookResponseDTO, error) {
-	book := entities.Book{
-		Title:       bookDTO.Title,
-		Author:      bookDTO.Author,
-		Description: bookDTO.Description,
-		Quantity:    bookDTO.Quantity,
-		Available:   bookDTO.Quantity, // Inicialmente todos disponíveis
+func (bookservice *bookService) Create(ctx context.Context, bookDTO dtos.BookCreateDTO) (*dtos.BookResponseDTO, error) {
+	authors := authorNames(bookDTO.Authors, bookDTO.Author)
+	if len(authors) == 0 {
+		return nil, domainerrors.New("informe ao menos um autor")
+	}
+
+	bookISBN, err := bookservice.uniqueISBN(ctx, bookDTO.ISBN, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// A verificação de livro repetido não pode ler de uma réplica atrasada
+	candidates, err := bookservice.bookRepository.FindByDedupKey(repositories.WithPrimary(ctx), entities.BookDedupKey(bookDTO.Title, authors))
+	if err != nil {
+		return nil, err
+	}
+	if duplicate := findDuplicate(candidates, bookISBN); duplicate != nil {
+		return nil, &domainerrors.DuplicateError{
+			ExistingID: duplicate.ID,
+			Reason:     "livro com o mesmo título e autores já cadastrado",
+		}
 	}
 
-	if err := bookservice.bookRepository.Create(&book); err != nil {
+	book := newBook(bookDTO, authors, bookISBN)
+	if err := bookservice.bookRepository.Create(ctx, &book); err != nil {
 		return nil, err
 	}
 
@@ -39,23 +65,64 @@ func (bookservice *bookService) Create(bookDTO dtos.BookCreateDTO) (*dtos.BookRe
 	return &responseDTO, nil
 }
 
-// GetByID busca um livro pelo ID
-func (bookservice *bookService) GetByID(id uint) (*dtos.BookResponseDTO, error) {
-	book, err := bookservice.bookRepository.FindByID(id)
+// GetByID busca um livro do catálogo pelo ID. Livros retirados do acervo não são retornados.
+func (bookservice *bookService) GetByID(ctx context.Context, id uint) (*dtos.BookResponseDTO, error) {
+	book, err := bookservice.findBook(ctx, id)
 	if err != nil {
 		return nil, err
 	}
-	if book == nil {
-		return nil, errors.New("livro não encontrado")
+	if book.Status == entities.BookStatusWithdrawn {
+		return nil, fmt.Errorf("%w: livro não encontrado", domainerrors.ErrNotFound)
+	}
+
+	responseDTO := dtos.BookToResponseDTO(*book)
+	return &responseDTO, nil
+}
+
+// GetByISBN busca um livro pelo ISBN-10 ou ISBN-13
+func (bookservice *bookService) GetByISBN(ctx context.Context, rawISBN string) (*dtos.BookResponseDTO, error) {
+	normalized, err := isbn.Normalize(rawISBN)
+	if err != nil {
+		return nil, err
+	}
+
+	book, err := bookservice.bookRepository.FindByISBN(ctx, normalized)
+	if err != nil {
+		return nil, err
+	}
+	if book == nil || book.Status == entities.BookStatusWithdrawn {
+		return nil, fmt.Errorf("%w: livro não encontrado", domainerrors.ErrNotFound)
 	}
 
 	responseDTO := dtos.BookToResponseDTO(*book)
 	return &responseDTO, nil
 }
 
-// List retorna todos os livros
-func (bookservice *bookService) List() ([]dtos.BookResponseDTO, error) {
-	books, err := bookservice.bookRepository.List()
+// List retorna os livros do catálogo que atendem aos filtros
+func (bookservice *bookService) List(ctx context.Context, filterDTO dtos.BookListFilterDTO) ([]dtos.BookResponseDTO, error) {
+	filter, err := bookFilter(filterDTO)
+	if err != nil {
+		return nil, err
+	}
+	filter.Status = entities.BookStatusActive
+
+	return bookservice.list(ctx, filter)
+}
+
+// AdminList retorna os livros que atendem aos filtros, incluindo os retirados do acervo
+func (bookservice *bookService) AdminList(ctx context.Context, filterDTO dtos.AdminBookListFilterDTO) ([]dtos.BookResponseDTO, error) {
+	filter, err := bookFilter(filterDTO.BookListFilterDTO)
+	if err != nil {
+		return nil, err
+	}
+	filter.Status = filterDTO.Status
+
+	return bookservice.list(ctx, filter)
+}
+
+// list busca os livros do filtro e os converte para a resposta da API
+func (bookservice *bookService) list(ctx context.Context, filter repositories.BookFilter) ([]dtos.BookResponseDTO, error) {
+	books, err := bookservice.bookRepository.List(ctx, filter)
 	if err != nil {
 		return nil, err
 	}
@@ -68,27 +135,314 @@ func (bookservice *bookService) List() ([]dtos.BookResponseDTO, error) {
 	return bookDTOs, nil
 }
 
+// Export grava no destino os livros que atendem ao filtro, no formato informado.
+// Os livros são lidos em lotes, sem carregar o acervo inteiro em memória.
+func (bookservice *bookService) Export(ctx context.Context, filterDTO dtos.BookListFilterDTO, format string, output io.Writer) error {
+	filter, err := bookFilter(filterDTO)
+	if err != nil {
+		return err
+	}
+
+	encoder, err := export.NewBookEncoder(format, output)
+	if err != nil {
+		return err
+	}
+
+	err = bookservice.bookRepository.ListInBatches(ctx, filter, exportBatchSize, func(books []*entities.Book) error {
+		for _, book := range books {
+			if err := encoder.Encode(book); err != nil {
+				return err
+			}
+		}
+		return encoder.Flush()
+	})
+	if err != nil {
+		return err
+	}
+
+	return encoder.Close()
+}
+
 // Update atualiza os dados de um livro
-func (bookservice *bookService) Update(id uint, bookDTO dtos.BookUpdateDTO) (*dtos.BookResponseDTO, error) {
-	book, err := bookservice.bookRepository.FindByID(id)
+func (bookservice *bookService) Update(ctx context.Context, id uint, bookDTO dtos.BookUpdateDTO) (*dtos.BookResponseDTO, error) {
+	book, err := bookservice.bookRepository.FindByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 	if book == nil {
-		return nil, errors.New("livro não encontrado")
+		return nil, fmt.Errorf("%w: livro não encontrado", domainerrors.ErrNotFound)
+	}
+
+	var bookISBN *string
+	if bookDTO.ISBN != "" {
+		bookISBN, err = bookservice.uniqueISBN(ctx, bookDTO.ISBN, book.ID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := applyBookUpdate(book, bookDTO, bookISBN); err != nil {
+		return nil, err
+	}
+
+	if err := bookservice.bookRepository.Update(ctx, book); err != nil {
+		return nil, err
+	}
+
+	responseDTO := dtos.BookToResponseDTO(*book)
+	return &responseDTO, nil
+}
+
+// AddCopies adiciona exemplares a um livro já cadastrado
+func (bookservice *bookService) AddCopies(ctx context.Context, id uint, quantity int) (*dtos.BookResponseDTO, error) {
+	if quantity < 1 {
+		return nil, domainerrors.New("quantidade deve ser maior que zero")
+	}
+
+	if err := bookservice.bookRepository.AddCopies(ctx, id, quantity); err != nil {
+		return nil, err
+	}
+
+	book, err := bookservice.findBook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	responseDTO := dtos.BookToResponseDTO(*book)
+	return &responseDTO, nil
+}
+
+// Withdraw retira um livro do acervo: ele sai do catálogo e não pode mais ser emprestado,
+// mas o registro e o histórico de empréstimos são mantidos
+func (bookservice *bookService) Withdraw(ctx context.Context, id uint) (*dtos.BookResponseDTO, error) {
+	book, err := bookservice.findBook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if book.Status == entities.BookStatusWithdrawn {
+		return nil, fmt.Errorf("%w: livro já foi retirado do acervo", domainerrors.ErrConflict)
+	}
+
+	withdrawnAt := time.Now()
+	book.Status = entities.BookStatusWithdrawn
+	book.WithdrawnAt = &withdrawnAt
+	if err := bookservice.bookRepository.Update(ctx, book); err != nil {
+		return nil, err
+	}
+
+	responseDTO := dtos.BookToResponseDTO(*book)
+	return &responseDTO, nil
+}
+
+// Reinstate devolve ao catálogo um livro retirado do acervo
+func (bookservice *bookService) Reinstate(ctx context.Context, id uint) (*dtos.BookResponseDTO, error) {
+	book, err := bookservice.findBook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if book.Status != entities.BookStatusWithdrawn {
+		return nil, fmt.Errorf("%w: livro não está retirado do acervo", domainerrors.ErrConflict)
+	}
+
+	book.Status = entities.BookStatusActive
+	book.WithdrawnAt = nil
+	if err := bookservice.bookRepository.Update(ctx, book); err != nil {
+		return nil, err
 	}
 
+	responseDTO := dtos.BookToResponseDTO(*book)
+	return &responseDTO, nil
+}
+
+// Delete remove um livro. Livros com empréstimos em aberto não podem ser removidos.
+func (bookservice *bookService) Delete(ctx context.Context, id uint) error {
+	return bookservice.bookRepository.Delete(ctx, id)
+}
+
+// ListDeleted retorna os livros removidos
+func (bookservice *bookService) ListDeleted(ctx context.Context) ([]dtos.BookResponseDTO, error) {
+	books, err := bookservice.bookRepository.ListDeleted(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	bookDTOs := []dtos.BookResponseDTO{}
+	for _, book := range books {
+		bookDTOs = append(bookDTOs, dtos.BookToResponseDTO(*book))
+	}
+
+	return bookDTOs, nil
+}
+
+// Restore desfaz a remoção de um livro
+func (bookservice *bookService) Restore(ctx context.Context, id uint) (*dtos.BookResponseDTO, error) {
+	if err := bookservice.bookRepository.Restore(ctx, id); err != nil {
+		return nil, err
+	}
+
+	book, err := bookservice.findBook(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	responseDTO := dtos.BookToResponseDTO(*book)
+	return &responseDTO, nil
+}
+
+// ReconcileInventory confere os exemplares disponíveis de cada livro e unidade com os empréstimos em aberto.
+// Os contadores são mantidos por soma e subtração a cada empréstimo, devolução e alteração de quantidade,
+// e podem divergir depois de uma falha; com repair, os valores calculados são gravados.
+func (bookservice *bookService) ReconcileInventory(ctx context.Context, repair bool) (*dtos.InventoryReportDTO, error) {
+	checkedAt := time.Now()
+	discrepancies, err := bookservice.bookRepository.ReconcileInventory(ctx, repair)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &dtos.InventoryReportDTO{
+		CheckedAt:     checkedAt,
+		Repaired:      repair && len(discrepancies) > 0,
+		Discrepancies: make([]dtos.InventoryDiscrepancyDTO, 0, len(discrepancies)),
+	}
+	for _, discrepancy := range discrepancies {
+		report.Discrepancies = append(report.Discrepancies, dtos.InventoryDiscrepancyDTO{
+			BookID:    discrepancy.BookID,
+			Title:     discrepancy.Title,
+			BranchID:  discrepancy.BranchID,
+			Quantity:  discrepancy.Quantity,
+			Available: discrepancy.Available,
+			OpenLoans: discrepancy.OpenLoans,
+			Expected:  discrepancy.Expected,
+		})
+	}
+
+	if len(discrepancies) > 0 {
+		logging.FromContext(ctx).Warn("exemplares disponíveis divergentes dos empréstimos em aberto",
+			"discrepancies", len(discrepancies), "repaired", report.Repaired)
+	}
+	return report, nil
+}
+
+// findBook busca um livro pelo ID, em qualquer situação
+func (bookservice *bookService) findBook(ctx context.Context, id uint) (*entities.Book, error) {
+	book, err := bookservice.bookRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if book == nil {
+		return nil, fmt.Errorf("%w: livro não encontrado", domainerrors.ErrNotFound)
+	}
+	return book, nil
+}
+
+// uniqueISBN normaliza o ISBN informado e garante, consultando o banco principal, que nenhum outro livro
+// o utiliza. Retorna nil quando o ISBN não é informado.
+func (bookservice *bookService) uniqueISBN(ctx context.Context, raw string, bookID uint) (*string, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+
+	normalized, err := isbn.Normalize(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := bookservice.bookRepository.FindByISBN(repositories.WithPrimary(ctx), normalized)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.ID != bookID {
+		return nil, &domainerrors.DuplicateError{ExistingID: existing.ID, Reason: "ISBN já cadastrado"}
+	}
+
+	return &normalized, nil
+}
+
+// findDuplicate retorna, entre os livros de mesmo título e autores, o que deve ser tratado como repetido.
+// Quando ambos têm ISBNs diferentes trata-se de outra edição, que não é considerada repetida.
+func findDuplicate(candidates []*entities.Book, bookISBN *string) *entities.Book {
+	for _, candidate := range candidates {
+		if bookISBN == nil || candidate.ISBN == nil {
+			return candidate
+		}
+	}
+	return nil
+}
+
+// newBook monta a entidade de um novo livro a partir dos dados de criação
+func newBook(bookDTO dtos.BookCreateDTO, authors []string, bookISBN *string) entities.Book {
+	return entities.Book{
+		Title:           bookDTO.Title,
+		Author:          strings.Join(authors, ", "),
+		DedupKey:        entities.BookDedupKey(bookDTO.Title, authors),
+		Description:     bookDTO.Description,
+		ISBN:            bookISBN,
+		Publisher:       bookDTO.Publisher,
+		PublicationYear: bookDTO.PublicationYear,
+		Edition:         bookDTO.Edition,
+		Language:        bookDTO.Language,
+		PageCount:       bookDTO.PageCount,
+		CoverURL:        bookDTO.CoverURL,
+		Authors:         toAuthors(authors),
+		Subjects:        toSubjects(bookDTO.Subjects),
+		Quantity:        bookDTO.Quantity,
+		Available:       bookDTO.Quantity, // Inicialmente todos disponíveis
+		Status:          entities.BookStatusActive,
+	}
+}
+
+// applyBookUpdate aplica ao livro os campos informados na atualização.
+// O ISBN, quando informado, já deve estar normalizado e validado quanto à unicidade.
+func applyBookUpdate(book *entities.Book, bookDTO dtos.BookUpdateDTO, bookISBN *string) error {
 	// Atualizar campos se fornecidos
 	if bookDTO.Title != "" {
 		book.Title = bookDTO.Title
 	}
-	if bookDTO.Author != "" {
-		book.Author = bookDTO.Author
+	if bookDTO.Authors != nil || bookDTO.Author != "" {
+		authors := authorNames(bookDTO.Authors, bookDTO.Author)
+		if len(authors) == 0 {
+			return domainerrors.New("informe ao menos um autor")
+		}
+		book.Author = strings.Join(authors, ", ")
+		book.Authors = toAuthors(authors)
 	}
 	if bookDTO.Description != "" {
 		book.Description = bookDTO.Description
 	}
+	if bookISBN != nil {
+		book.ISBN = bookISBN
+	}
+	if bookDTO.Publisher != "" {
+		book.Publisher = bookDTO.Publisher
+	}
+	if bookDTO.PublicationYear > 0 {
+		book.PublicationYear = bookDTO.PublicationYear
+	}
+	if bookDTO.Edition != "" {
+		book.Edition = bookDTO.Edition
+	}
+	if bookDTO.Language != "" {
+		book.Language = bookDTO.Language
+	}
+	if bookDTO.PageCount > 0 {
+		book.PageCount = bookDTO.PageCount
+	}
+	if bookDTO.CoverURL != "" {
+		book.CoverURL = bookDTO.CoverURL
+	}
+	if bookDTO.Subjects != nil {
+		book.Subjects = toSubjects(bookDTO.Subjects)
+	}
 	if bookDTO.Quantity > 0 {
+		// Exemplares alocados em unidades não podem ficar sem correspondência no total
+		allocated := 0
+		for _, holding := range book.Holdings {
+			allocated += holding.Quantity
+		}
+		if bookDTO.Quantity < allocated {
+			return domainerrors.New("quantidade menor que o total de exemplares alocados em unidades")
+		}
+
 		// Atualizar também o disponível proporcionalmente
 		diff := bookDTO.Quantity - book.Quantity
 		book.Available = book.Available + diff
@@ -98,15 +452,76 @@ func (bookservice *bookService) Update(id uint, bookDTO dtos.BookUpdateDTO) (*dt
 		book.Quantity = bookDTO.Quantity
 	}
 
-	if err := bookservice.bookRepository.Update(book); err != nil {
-		return nil, err
+	authors := make([]string, 0, len(book.Authors))
+	for _, author := range book.Authors {
+		authors = append(authors, author.Name)
 	}
+	book.DedupKey = entities.BookDedupKey(book.Title, authors)
 
-	responseDTO := dtos.BookToResponseDTO(*book)
-	return &responseDTO, nil
+	return nil
+}
+
+// authorNames retorna os autores da lista ou, se ela estiver vazia, os extraídos do texto livre
+func authorNames(authors []string, author string) []string {
+	if len(authors) == 0 {
+		authors = entities.SplitAuthorNames(author)
+	}
+	return uniqueNames(authors)
 }
 
-// Delete remove um livro
-func (bookservice *bookService) Delete(id uint) error {
-	return bookservice.bookRepository.Delete(id)
+// uniqueNames remove espaços extras e nomes repetidos, preservando a ordem
+func uniqueNames(names []string) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, name := range names {
+		name = strings.Join(strings.Fields(name), " ")
+		key := strings.ToLower(name)
+		if name == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, name)
+	}
+	return result
+}
+
+// toAuthors converte nomes em entidades Author
+func toAuthors(names []string) []entities.Author {
+	authors := make([]entities.Author, 0, len(names))
+	for _, name := range names {
+		authors = append(authors, entities.Author{Name: name})
+	}
+	return authors
+}
+
+// toSubjects converte nomes em entidades Subject
+func toSubjects(names []string) []entities.Subject {
+	names = uniqueNames(names)
+	subjects := make([]entities.Subject, 0, len(names))
+	for _, name := range names {
+		subjects = append(subjects, entities.Subject{Name: name})
+	}
+	return subjects
+}
+
+// bookFilter converte os filtros recebidos na query string no filtro do repositório
+func bookFilter(filterDTO dtos.BookListFilterDTO) (repositories.BookFilter, error) {
+	filter := repositories.BookFilter{
+		Title:         filterDTO.Title,
+		Author:        filterDTO.Author,
+		Subject:       filterDTO.Subject,
+		Publisher:     filterDTO.Publisher,
+		Language:      filterDTO.Language,
+		YearFrom:      filterDTO.YearFrom,
+		YearTo:        filterDTO.YearTo,
+		AvailableOnly: filterDTO.Available,
+	}
+	if filterDTO.ISBN != "" {
+		normalized, err := isbn.Normalize(filterDTO.ISBN)
+		if err != nil {
+			return filter, err
+		}
+		filter.ISBN = normalized
+	}
+	return filter, nil
 }
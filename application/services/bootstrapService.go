@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"sync"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/repositories"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/services"
+	domainerrors "github.com/henrygoeszanin/api_golang_estudos/domain/errors"
+)
+
+// bootstrapService implementa a interface BootstrapService
+type bootstrapService struct {
+	userService    services.UserService
+	userRepository repositories.UserRepository
+
+	// mutex serializa o uso do token, para que duas requisições simultâneas não criem dois administradores
+	mutex      sync.Mutex
+	setupToken string
+}
+
+// NewBootstrapService cria uma nova instância do serviço de criação de administradores
+func NewBootstrapService(userService services.UserService, userRepository repositories.UserRepository) services.BootstrapService {
+	return &bootstrapService{
+		userService:    userService,
+		userRepository: userRepository,
+	}
+}
+
+// IssueSetupToken gera o token de instalação de uso único quando o sistema ainda não tem nenhum
+// administrador. Retorna uma string vazia se já houver um administrador.
+func (bootstrapService *bootstrapService) IssueSetupToken(ctx context.Context) (string, error) {
+	bootstrapService.mutex.Lock()
+	defer bootstrapService.mutex.Unlock()
+
+	admins, err := bootstrapService.userRepository.CountAdmins(ctx)
+	if err != nil {
+		return "", err
+	}
+	if admins > 0 {
+		bootstrapService.setupToken = ""
+		return "", nil
+	}
+
+	var random [32]byte
+	if _, err := rand.Read(random[:]); err != nil {
+		return "", fmt.Errorf("falha ao gerar o token de instalação: %w", err)
+	}
+	bootstrapService.setupToken = base64.RawURLEncoding.EncodeToString(random[:])
+	return bootstrapService.setupToken, nil
+}
+
+// CompleteSetup cria o primeiro administrador com o token de instalação, que deixa de valer em seguida.
+// Falha se o token não confere, se já houver um administrador ou se o email já estiver em uso.
+func (bootstrapService *bootstrapService) CompleteSetup(ctx context.Context, setupDTO dtos.SetupDTO) (*dtos.UserResponseDTO, error) {
+	bootstrapService.mutex.Lock()
+	defer bootstrapService.mutex.Unlock()
+
+	if bootstrapService.setupToken == "" || subtle.ConstantTimeCompare([]byte(setupDTO.Token), []byte(bootstrapService.setupToken)) != 1 {
+		return nil, fmt.Errorf("%w: token de instalação inválido ou já utilizado", domainerrors.ErrForbidden)
+	}
+
+	// Outra instância ou a linha de comando pode ter criado um administrador depois da emissão do token
+	admins, err := bootstrapService.userRepository.CountAdmins(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if admins > 0 {
+		bootstrapService.setupToken = ""
+		return nil, fmt.Errorf("%w: o sistema já tem um administrador", domainerrors.ErrForbidden)
+	}
+
+	existing, err := bootstrapService.userService.GetByEmail(ctx, setupDTO.Email)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("%w: email já está em uso", domainerrors.ErrAlreadyExists)
+	}
+
+	admin, _, err := bootstrapService.CreateAdmin(ctx, setupDTO.UserCreateDTO)
+	if err != nil {
+		return nil, err
+	}
+	bootstrapService.setupToken = ""
+	return admin, nil
+}
+
+// CreateAdmin cria um administrador ou, se o email já estiver em uso, promove o usuário existente,
+// mantendo a senha dele. Informa se o usuário foi criado.
+func (bootstrapService *bootstrapService) CreateAdmin(ctx context.Context, userDTO dtos.UserCreateDTO) (*dtos.UserResponseDTO, bool, error) {
+	existing, err := bootstrapService.userService.GetByEmail(ctx, userDTO.Email)
+	if err != nil {
+		return nil, false, err
+	}
+
+	created := existing == nil
+	var user *dtos.UserResponseDTO
+	if created {
+		if user, err = bootstrapService.userService.Create(ctx, userDTO); err != nil {
+			return nil, false, err
+		}
+	} else {
+		response := dtos.ToResponseDTO(*existing)
+		user = &response
+	}
+
+	if !user.IsAdmin {
+		if user, err = bootstrapService.userService.PromoteToAdmin(ctx, user.ID); err != nil {
+			return nil, false, err
+		}
+	}
+	return user, created, nil
+}
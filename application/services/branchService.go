@@ -0,0 +1,266 @@
+package services
+
+import (
+	"context"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/repositories"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/services"
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+	domainerrors "github.com/henrygoeszanin/api_golang_estudos/domain/errors"
+)
+
+// branchService implementa a interface BranchService
+type branchService struct {
+	branchRepository   repositories.BranchRepository
+	transferRepository repositories.TransferRepository
+	bookRepository     repositories.BookRepository
+	userRepository     repositories.UserRepository
+}
+
+// NewBranchService cria uma nova instância do serviço de unidades
+func NewBranchService(
+	branchRepository repositories.BranchRepository,
+	transferRepository repositories.TransferRepository,
+	bookRepository repositories.BookRepository,
+	userRepository repositories.UserRepository,
+) services.BranchService {
+	return &branchService{
+		branchRepository:   branchRepository,
+		transferRepository: transferRepository,
+		bookRepository:     bookRepository,
+		userRepository:     userRepository,
+	}
+}
+
+// Create cria uma nova unidade
+func (branchService *branchService) Create(ctx context.Context, branchDTO dtos.BranchCreateDTO) (*dtos.BranchResponseDTO, error) {
+	branch := entities.Branch{
+		Name:    branchDTO.Name,
+		Code:    branchDTO.Code,
+		Address: branchDTO.Address,
+	}
+
+	if err := branchService.branchRepository.Create(ctx, &branch); err != nil {
+		return nil, err
+	}
+
+	responseDTO := dtos.BranchToResponseDTO(branch)
+	return &responseDTO, nil
+}
+
+// GetByID busca uma unidade pelo ID
+func (branchService *branchService) GetByID(ctx context.Context, id uint) (*dtos.BranchResponseDTO, error) {
+	branch, err := branchService.branchRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if branch == nil {
+		return nil, domainerrors.New("unidade não encontrada")
+	}
+
+	responseDTO := dtos.BranchToResponseDTO(*branch)
+	return &responseDTO, nil
+}
+
+// List retorna todas as unidades
+func (branchService *branchService) List(ctx context.Context) ([]dtos.BranchResponseDTO, error) {
+	branches, err := branchService.branchRepository.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var branchDTOs []dtos.BranchResponseDTO
+	for _, branch := range branches {
+		branchDTOs = append(branchDTOs, dtos.BranchToResponseDTO(*branch))
+	}
+
+	return branchDTOs, nil
+}
+
+// Update atualiza os dados de uma unidade
+func (branchService *branchService) Update(ctx context.Context, id uint, branchDTO dtos.BranchUpdateDTO) (*dtos.BranchResponseDTO, error) {
+	branch, err := branchService.branchRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if branch == nil {
+		return nil, domainerrors.New("unidade não encontrada")
+	}
+
+	// Atualizar campos se fornecidos
+	if branchDTO.Name != "" {
+		branch.Name = branchDTO.Name
+	}
+	if branchDTO.Code != "" {
+		branch.Code = branchDTO.Code
+	}
+	if branchDTO.Address != "" {
+		branch.Address = branchDTO.Address
+	}
+
+	if err := branchService.branchRepository.Update(ctx, branch); err != nil {
+		return nil, err
+	}
+
+	responseDTO := dtos.BranchToResponseDTO(*branch)
+	return &responseDTO, nil
+}
+
+// Delete remove uma unidade
+func (branchService *branchService) Delete(ctx context.Context, id uint) error {
+	return branchService.branchRepository.Delete(ctx, id)
+}
+
+// SetHolding define quantos exemplares de um livro ficam na unidade
+func (branchService *branchService) SetHolding(ctx context.Context, bookID, branchID uint, holdingDTO dtos.HoldingUpdateDTO) (*dtos.BookResponseDTO, error) {
+	if err := branchService.branchRepository.SetHoldingQuantity(ctx, bookID, branchID, holdingDTO.Quantity); err != nil {
+		return nil, err
+	}
+
+	book, err := branchService.bookRepository.FindByID(ctx, bookID)
+	if err != nil {
+		return nil, err
+	}
+	if book == nil {
+		return nil, domainerrors.New("livro não encontrado")
+	}
+
+	responseDTO := dtos.BookToResponseDTO(*book)
+	return &responseDTO, nil
+}
+
+// RequestTransfer solicita a transferência de exemplares entre unidades.
+// O pedido pode partir de qualquer uma das unidades envolvidas.
+func (branchService *branchService) RequestTransfer(ctx context.Context, staffID uint, transferDTO dtos.TransferCreateDTO) (*dtos.TransferResponseDTO, error) {
+	staff, err := loadStaff(ctx, branchService.userRepository, staffID)
+	if err != nil {
+		return nil, err
+	}
+	if !canOperateAt(staff, transferDTO.FromBranchID) && !canOperateAt(staff, transferDTO.ToBranchID) {
+		return nil, errOtherBranch
+	}
+
+	holding, err := branchService.branchRepository.FindHolding(ctx, transferDTO.BookID, transferDTO.FromBranchID)
+	if err != nil {
+		return nil, err
+	}
+	if holding == nil || holding.Quantity < transferDTO.Quantity {
+		return nil, domainerrors.New("unidade de origem não possui exemplares suficientes")
+	}
+
+	destination, err := branchService.branchRepository.FindByID(ctx, transferDTO.ToBranchID)
+	if err != nil {
+		return nil, err
+	}
+	if destination == nil {
+		return nil, domainerrors.New("unidade de destino não encontrada")
+	}
+
+	transfer := entities.TransferRequest{
+		BookID:        transferDTO.BookID,
+		FromBranchID:  transferDTO.FromBranchID,
+		ToBranchID:    transferDTO.ToBranchID,
+		Quantity:      transferDTO.Quantity,
+		RequestedByID: staff.ID,
+	}
+
+	if err := branchService.transferRepository.Create(ctx, &transfer); err != nil {
+		return nil, err
+	}
+
+	return branchService.getTransfer(ctx, transfer.ID)
+}
+
+// ListTransfers retorna as transferências visíveis para o usuário da equipe
+func (branchService *branchService) ListTransfers(ctx context.Context, staffID uint) ([]dtos.TransferResponseDTO, error) {
+	staff, err := loadStaff(ctx, branchService.userRepository, staffID)
+	if err != nil {
+		return nil, err
+	}
+
+	var transfers []*entities.TransferRequest
+	if staff.IsAdmin {
+		transfers, err = branchService.transferRepository.List(ctx)
+	} else if staff.BranchID != nil {
+		transfers, err = branchService.transferRepository.ListByBranch(ctx, *staff.BranchID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var transferDTOs []dtos.TransferResponseDTO
+	for _, transfer := range transfers {
+		transferDTOs = append(transferDTOs, dtos.TransferToResponseDTO(*transfer))
+	}
+
+	return transferDTOs, nil
+}
+
+// ApproveTransfer aprova a transferência e move os exemplares.
+// Apenas a unidade de origem, que cede os exemplares, pode aprovar.
+func (branchService *branchService) ApproveTransfer(ctx context.Context, id uint, staffID uint) (*dtos.TransferResponseDTO, error) {
+	staff, transfer, err := branchService.loadTransferForStaff(ctx, id, staffID)
+	if err != nil {
+		return nil, err
+	}
+	if !canOperateAt(staff, transfer.FromBranchID) {
+		return nil, errOtherBranch
+	}
+
+	if err := branchService.transferRepository.Complete(ctx, id, staff.ID); err != nil {
+		return nil, err
+	}
+
+	return branchService.getTransfer(ctx, id)
+}
+
+// RejectTransfer recusa uma transferência pendente
+func (branchService *branchService) RejectTransfer(ctx context.Context, id uint, staffID uint) (*dtos.TransferResponseDTO, error) {
+	staff, _, err := branchService.loadTransferForStaff(ctx, id, staffID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := branchService.transferRepository.Reject(ctx, id, staff.ID); err != nil {
+		return nil, err
+	}
+
+	return branchService.getTransfer(ctx, id)
+}
+
+// loadTransferForStaff busca a transferência e garante que o usuário opera em uma das unidades envolvidas
+func (branchService *branchService) loadTransferForStaff(ctx context.Context, id uint, staffID uint) (*entities.User, *entities.TransferRequest, error) {
+	staff, err := loadStaff(ctx, branchService.userRepository, staffID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	transfer, err := branchService.transferRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	if transfer == nil {
+		return nil, nil, domainerrors.New("transferência não encontrada")
+	}
+
+	if !canOperateAt(staff, transfer.FromBranchID) && !canOperateAt(staff, transfer.ToBranchID) {
+		return nil, nil, errOtherBranch
+	}
+
+	return staff, transfer, nil
+}
+
+// getTransfer busca uma transferência e a converte para o DTO de resposta
+func (branchService *branchService) getTransfer(ctx context.Context, id uint) (*dtos.TransferResponseDTO, error) {
+	transfer, err := branchService.transferRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if transfer == nil {
+		return nil, domainerrors.New("transferência não encontrada")
+	}
+
+	responseDTO := dtos.TransferToResponseDTO(*transfer)
+	return &responseDTO, nil
+}
@@ -0,0 +1,351 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/repositories"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/services"
+	"github.com/henrygoeszanin/api_golang_estudos/application/workers"
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+	domainerrors "github.com/henrygoeszanin/api_golang_estudos/domain/errors"
+	"github.com/henrygoeszanin/api_golang_estudos/logging"
+)
+
+// asyncExportLoanThreshold é a quantidade de empréstimos a partir da qual a exportação é gerada em segundo plano
+const asyncExportLoanThreshold = 500
+
+// dataExportReadme acompanha o arquivo ZIP explicando o conteúdo de cada arquivo
+const dataExportReadme = `Dados pessoais exportados da biblioteca
+
+profile.json       Dados do cadastro
+loans.json         Histórico completo de empréstimos, incluindo os já devolvidos
+audit_events.json  Ações registradas na trilha de auditoria feitas por você ou sobre a sua conta
+
+A biblioteca não armazena reservas, multas nem sessões de acesso.
+`
+
+// dataExportService implementa a interface DataExportService
+type dataExportService struct {
+	userRepository       repositories.UserRepository
+	loanRepository       repositories.LoanRepository
+	auditService         services.AuditService
+	dataExportRepository repositories.DataExportRepository
+	pool                 *workers.Pool
+	directory            string
+	linkTTL              time.Duration
+	signingKey           []byte
+}
+
+// NewDataExportService cria uma nova instância do serviço de exportação de dados pessoais.
+// Os arquivos gerados em segundo plano ficam em directory e podem ser baixados durante linkTTL
+// por meio de um link assinado com signingKey.
+func NewDataExportService(
+	userRepository repositories.UserRepository,
+	loanRepository repositories.LoanRepository,
+	auditService services.AuditService,
+	dataExportRepository repositories.DataExportRepository,
+	pool *workers.Pool,
+	directory string,
+	linkTTL time.Duration,
+	signingKey []byte,
+) services.DataExportService {
+	return &dataExportService{
+		userRepository:       userRepository,
+		loanRepository:       loanRepository,
+		auditService:         auditService,
+		dataExportRepository: dataExportRepository,
+		pool:                 pool,
+		directory:            directory,
+		linkTTL:              linkTTL,
+		signingKey:           signingKey,
+	}
+}
+
+// RequiresAsync indica se o histórico do usuário é grande o bastante para ser exportado em segundo plano
+func (dataExportService *dataExportService) RequiresAsync(ctx context.Context, userID uint) (bool, error) {
+	count, err := dataExportService.loanRepository.CountByUserID(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return count >= asyncExportLoanThreshold, nil
+}
+
+// WriteBundle gera os dados pessoais do usuário no formato informado, gravando no destino
+func (dataExportService *dataExportService) WriteBundle(ctx context.Context, userID uint, format string, output io.Writer) error {
+	bundle, err := dataExportService.bundle(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case dtos.DataExportFormatJSON:
+		encoder := json.NewEncoder(output)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(bundle)
+	case dtos.DataExportFormatZIP:
+		return writeBundleZIP(bundle, output)
+	}
+	return fmt.Errorf("%w: formato de exportação não suportado: %q", domainerrors.ErrInvalidData, format)
+}
+
+// StartExport registra a exportação e a gera em segundo plano
+func (dataExportService *dataExportService) StartExport(ctx context.Context, userID uint, format string) (*dtos.DataExportResponseDTO, error) {
+	export := entities.DataExport{
+		UserID: userID,
+		Format: format,
+		Status: entities.DataExportStatusPending,
+	}
+	if err := dataExportService.dataExportRepository.Create(ctx, &export); err != nil {
+		return nil, err
+	}
+
+	dataExportService.pool.Go(func(poolCtx context.Context) {
+		dataExportService.runExport(logging.Detach(ctx, poolCtx), &export)
+	})
+
+	responseDTO := dtos.DataExportToResponseDTO(export, "")
+	return &responseDTO, nil
+}
+
+// GetExport busca uma exportação do usuário, com o link de download quando já concluída
+func (dataExportService *dataExportService) GetExport(ctx context.Context, userID uint, id uint) (*dtos.DataExportResponseDTO, error) {
+	export, err := dataExportService.dataExportRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if export == nil || export.UserID != userID {
+		return nil, fmt.Errorf("%w: exportação não encontrada", domainerrors.ErrNotFound)
+	}
+
+	responseDTO := dtos.DataExportToResponseDTO(*export, dataExportService.downloadURL(export))
+	return &responseDTO, nil
+}
+
+// ListExports retorna as exportações do usuário
+func (dataExportService *dataExportService) ListExports(ctx context.Context, userID uint) ([]dtos.DataExportResponseDTO, error) {
+	exports, err := dataExportService.dataExportRepository.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	exportDTOs := []dtos.DataExportResponseDTO{}
+	for _, export := range exports {
+		exportDTOs = append(exportDTOs, dtos.DataExportToResponseDTO(*export, dataExportService.downloadURL(export)))
+	}
+
+	return exportDTOs, nil
+}
+
+// OpenDownload valida o link assinado e retorna a exportação cujo arquivo pode ser enviado
+func (dataExportService *dataExportService) OpenDownload(ctx context.Context, id uint, expires int64, signature string) (*entities.DataExport, error) {
+	expected := dataExportService.sign(id, expires)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return nil, fmt.Errorf("%w: link de download inválido", domainerrors.ErrForbidden)
+	}
+	if time.Now().Unix() > expires {
+		return nil, fmt.Errorf("%w: link de download expirado", domainerrors.ErrForbidden)
+	}
+
+	export, err := dataExportService.dataExportRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if export == nil || export.Status != entities.DataExportStatusCompleted {
+		return nil, fmt.Errorf("%w: exportação não disponível", domainerrors.ErrNotFound)
+	}
+
+	return export, nil
+}
+
+// DeleteExpired remove os arquivos vencidos ou de usuários removidos e retorna quantos foram removidos
+func (dataExportService *dataExportService) DeleteExpired(ctx context.Context) (int, error) {
+	exports, err := dataExportService.dataExportRepository.ListExpired(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	var errs []error
+	for _, export := range exports {
+		if err := os.Remove(export.FilePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+			continue
+		}
+
+		export.Status = entities.DataExportStatusExpired
+		export.FilePath = ""
+		if err := dataExportService.dataExportRepository.Update(ctx, export); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		deleted++
+	}
+
+	return deleted, errors.Join(errs...)
+}
+
+// runExport gera o arquivo de uma exportação em segundo plano
+func (dataExportService *dataExportService) runExport(ctx context.Context, export *entities.DataExport) {
+	export.Status = entities.DataExportStatusRunning
+	_ = dataExportService.dataExportRepository.Update(ctx, export)
+
+	path, size, err := dataExportService.writeFile(ctx, export)
+	if err != nil {
+		logging.FromContext(ctx).Error("exportação de dados pessoais falhou", "export_id", export.ID, "error", err)
+		export.Status = entities.DataExportStatusFailed
+		export.Message = err.Error()
+		_ = dataExportService.dataExportRepository.Update(context.WithoutCancel(ctx), export)
+		return
+	}
+
+	completedAt := time.Now()
+	expiresAt := completedAt.Add(dataExportService.linkTTL)
+	export.Status = entities.DataExportStatusCompleted
+	export.FilePath = path
+	export.Size = size
+	export.CompletedAt = &completedAt
+	export.ExpiresAt = &expiresAt
+	_ = dataExportService.dataExportRepository.Update(ctx, export)
+}
+
+// writeFile grava o arquivo da exportação no diretório configurado, retornando o caminho e o tamanho
+func (dataExportService *dataExportService) writeFile(ctx context.Context, export *entities.DataExport) (string, int64, error) {
+	if err := os.MkdirAll(dataExportService.directory, 0o700); err != nil {
+		return "", 0, err
+	}
+
+	// Gravar em um arquivo temporário e renomear, para nunca expor um arquivo incompleto
+	file, err := os.CreateTemp(dataExportService.directory, "export-*.tmp")
+	if err != nil {
+		return "", 0, err
+	}
+	defer os.Remove(file.Name())
+
+	if err := dataExportService.WriteBundle(ctx, export.UserID, export.Format, file); err != nil {
+		file.Close()
+		return "", 0, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return "", 0, err
+	}
+	if err := file.Close(); err != nil {
+		return "", 0, err
+	}
+
+	path := filepath.Join(dataExportService.directory, fmt.Sprintf("export-%d.%s", export.ID, export.Format))
+	if err := os.Rename(file.Name(), path); err != nil {
+		return "", 0, err
+	}
+
+	return path, info.Size(), nil
+}
+
+// bundle reúne os dados pessoais do usuário
+func (dataExportService *dataExportService) bundle(ctx context.Context, userID uint) (*dtos.DataExportBundleDTO, error) {
+	user, err := dataExportService.userRepository.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, fmt.Errorf("%w: usuário não encontrado", domainerrors.ErrNotFound)
+	}
+
+	loans, err := dataExportService.loanRepository.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	auditEvents, err := dataExportService.auditService.ListByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := dtos.DataExportBundleDTO{
+		GeneratedAt: time.Now(),
+		Profile:     dtos.ToResponseDTO(*user),
+		Loans:       []dtos.LoanResponseDTO{},
+		AuditEvents: []dtos.AuditEventResponseDTO{},
+	}
+	for _, loan := range loans {
+		bundle.Loans = append(bundle.Loans, dtos.LoanToResponseDTO(*loan))
+	}
+	for _, event := range auditEvents {
+		// Email, endereço e navegador de quem agiu sobre a conta são dados de outra pessoa
+		if event.ActorID == nil || *event.ActorID != userID {
+			event.ActorEmail = ""
+			event.IP = ""
+			event.UserAgent = ""
+		}
+		bundle.AuditEvents = append(bundle.AuditEvents, event)
+	}
+
+	return &bundle, nil
+}
+
+// downloadURL monta o link assinado de download, válido até a exportação expirar
+func (dataExportService *dataExportService) downloadURL(export *entities.DataExport) string {
+	if export.Status != entities.DataExportStatusCompleted || export.ExpiresAt == nil {
+		return ""
+	}
+
+	expires := export.ExpiresAt.Unix()
+	return fmt.Sprintf("/api/exports/%d/download?expires=%d&signature=%s",
+		export.ID, expires, dataExportService.sign(export.ID, expires))
+}
+
+// sign calcula a assinatura HMAC-SHA256 do link de download
+func (dataExportService *dataExportService) sign(id uint, expires int64) string {
+	mac := hmac.New(sha256.New, dataExportService.signingKey)
+	mac.Write([]byte("data-export:" + strconv.FormatUint(uint64(id), 10) + ":" + strconv.FormatInt(expires, 10)))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// writeBundleZIP grava os dados pessoais como um ZIP com um arquivo JSON por categoria
+func writeBundleZIP(bundle *dtos.DataExportBundleDTO, output io.Writer) error {
+	archive := zip.NewWriter(output)
+
+	files := []struct {
+		name    string
+		content interface{}
+	}{
+		{"profile.json", bundle.Profile},
+		{"loans.json", bundle.Loans},
+		{"audit_events.json", bundle.AuditEvents},
+	}
+	for _, file := range files {
+		writer, err := archive.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Deflate, Modified: bundle.GeneratedAt})
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(writer)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.content); err != nil {
+			return err
+		}
+	}
+
+	writer, err := archive.CreateHeader(&zip.FileHeader{Name: "LEIAME.txt", Method: zip.Deflate, Modified: bundle.GeneratedAt})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(writer, dataExportReadme); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
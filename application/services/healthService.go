@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/health"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/services"
+	"github.com/henrygoeszanin/api_golang_estudos/logging"
+)
+
+// healthService implementa a interface HealthService
+type healthService struct {
+	checkers []health.Checker
+	timeout  time.Duration
+	draining atomic.Bool
+}
+
+// NewHealthService cria uma nova instância do serviço de saúde.
+// Cada verificação tem o tempo limite informado; todas são executadas em paralelo.
+func NewHealthService(timeout time.Duration, checkers ...health.Checker) services.HealthService {
+	return &healthService{
+		checkers: checkers,
+		timeout:  timeout,
+	}
+}
+
+// Live informa que o processo está em execução; não depende do banco nem de outros serviços
+func (healthService *healthService) Live(ctx context.Context) dtos.HealthReportDTO {
+	return dtos.HealthReportDTO{Status: dtos.HealthStatusOK}
+}
+
+// Ready verifica todas as dependências e informa se a aplicação pode receber tráfego.
+// Durante o encerramento a aplicação deixa de estar pronta, para que o balanceador pare de enviar requisições.
+func (healthService *healthService) Ready(ctx context.Context) (dtos.HealthReportDTO, bool) {
+	checks := make(map[string]dtos.HealthCheckDTO, len(healthService.checkers))
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, checker := range healthService.checkers {
+		wg.Add(1)
+		go func(checker health.Checker) {
+			defer wg.Done()
+			result := healthService.run(ctx, checker)
+			mutex.Lock()
+			checks[checker.Name()] = result
+			mutex.Unlock()
+		}(checker)
+	}
+	wg.Wait()
+
+	report := dtos.HealthReportDTO{Status: dtos.HealthStatusOK, Checks: checks}
+	for _, check := range checks {
+		if check.Status != dtos.HealthStatusOK {
+			report.Status = dtos.HealthStatusFail
+		}
+	}
+	if healthService.draining.Load() {
+		report.Status = dtos.HealthStatusDraining
+	}
+
+	return report, report.Status == dtos.HealthStatusOK
+}
+
+// StartDraining marca a aplicação como em encerramento; a partir daí Ready não a considera mais pronta
+func (healthService *healthService) StartDraining() {
+	healthService.draining.Store(true)
+}
+
+// run executa uma verificação dentro do tempo limite e mede a latência
+func (healthService *healthService) run(ctx context.Context, checker health.Checker) dtos.HealthCheckDTO {
+	ctx, cancel := context.WithTimeout(ctx, healthService.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := checker.Check(ctx)
+	result := dtos.HealthCheckDTO{
+		Status:    dtos.HealthStatusOK,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		logging.FromContext(ctx).Warn("verificação de saúde falhou", "check", checker.Name(), "error", err)
+		result.Status = dtos.HealthStatusFail
+		result.Error = err.Error()
+	}
+	return result
+}
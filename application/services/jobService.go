@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/repositories"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/services"
+	"github.com/henrygoeszanin/api_golang_estudos/application/scheduler"
+)
+
+// jobService implementa a interface JobService
+type jobService struct {
+	scheduler        *scheduler.Scheduler
+	jobRunRepository repositories.JobRunRepository
+}
+
+// NewJobService cria uma nova instância do serviço de tarefas agendadas
+func NewJobService(scheduler *scheduler.Scheduler, jobRunRepository repositories.JobRunRepository) services.JobService {
+	return &jobService{
+		scheduler:        scheduler,
+		jobRunRepository: jobRunRepository,
+	}
+}
+
+// List retorna as tarefas registradas com o próximo horário previsto e a última execução
+func (jobService *jobService) List(ctx context.Context) ([]dtos.JobDTO, error) {
+	now := time.Now()
+	jobs := jobService.scheduler.Jobs()
+	result := make([]dtos.JobDTO, 0, len(jobs))
+	for _, job := range jobs {
+		jobDTO := dtos.JobDTO{
+			Name:        job.Name,
+			Description: job.Description,
+			NextRunAt:   jobService.scheduler.Next(job, now),
+		}
+		if job.Schedule != nil {
+			jobDTO.Schedule = job.Schedule.String()
+		}
+
+		runs, err := jobService.jobRunRepository.List(ctx, repositories.JobRunFilter{JobName: job.Name, Limit: 1})
+		if err != nil {
+			return nil, err
+		}
+		if len(runs) > 0 {
+			lastRun := dtos.JobRunToDTO(*runs[0])
+			jobDTO.LastRun = &lastRun
+		}
+		result = append(result, jobDTO)
+	}
+	return result, nil
+}
+
+// ListRuns retorna o histórico de execuções, das mais recentes para as mais antigas
+func (jobService *jobService) ListRuns(ctx context.Context, filter dtos.JobRunFilterDTO) ([]dtos.JobRunDTO, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = dtos.JobRunDefaultLimit
+	}
+	if limit > dtos.JobRunMaxLimit {
+		limit = dtos.JobRunMaxLimit
+	}
+
+	runs, err := jobService.jobRunRepository.List(ctx, repositories.JobRunFilter{
+		JobName: filter.Job,
+		Status:  filter.Status,
+		Limit:   limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dtos.JobRunDTO, 0, len(runs))
+	for _, run := range runs {
+		result = append(result, dtos.JobRunToDTO(*run))
+	}
+	return result, nil
+}
+
+// Run inicia a tarefa imediatamente, fora do horário previsto. A execução continua em segundo plano.
+func (jobService *jobService) Run(ctx context.Context, name string) (*dtos.JobRunDTO, error) {
+	run, err := jobService.scheduler.Trigger(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	result := dtos.JobRunToDTO(*run)
+	return &result, nil
+}
+
+// PruneHistory remove as execuções concluídas há mais tempo que a retenção informada
+func (jobService *jobService) PruneHistory(ctx context.Context, retention time.Duration) (int64, error) {
+	return jobService.jobRunRepository.DeleteFinishedBefore(ctx, time.Now().Add(-retention))
+}
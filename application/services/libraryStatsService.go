@@ -0,0 +1,46 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/repositories"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/services"
+)
+
+// libraryStatsService implementa a interface LibraryStatsService
+type libraryStatsService struct {
+	bookRepository repositories.BookRepository
+	loanRepository repositories.LoanRepository
+}
+
+// NewLibraryStatsService cria uma nova instância do serviço de indicadores
+func NewLibraryStatsService(bookRepository repositories.BookRepository, loanRepository repositories.LoanRepository) services.LibraryStatsService {
+	return &libraryStatsService{
+		bookRepository: bookRepository,
+		loanRepository: loanRepository,
+	}
+}
+
+// Collect calcula os indicadores atuais de empréstimos e exemplares disponíveis
+func (libraryStatsService *libraryStatsService) Collect(ctx context.Context) (*dtos.LibraryStatsDTO, error) {
+	activeLoans, err := libraryStatsService.loanRepository.CountActive(ctx)
+	if err != nil {
+		return nil, err
+	}
+	overdueLoans, err := libraryStatsService.loanRepository.CountOverdue(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	booksAvailable, err := libraryStatsService.bookRepository.SumAvailable(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dtos.LibraryStatsDTO{
+		ActiveLoans:    activeLoans,
+		OverdueLoans:   overdueLoans,
+		BooksAvailable: booksAvailable,
+	}, nil
+}
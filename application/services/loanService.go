@@ -1,60 +1,107 @@
 package services
 
 import (
-	"errors"
+	"context"
 	"time"
 
 	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
 	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/repositories"
 	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/services"
 	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+	domainerrors "github.com/henrygoeszanin/api_golang_estudos/domain/errors"
 )
 
 // loanService implementa a interface LoanService
 type loanService struct {
 	loanRepository repositories.LoanRepository
 	bookRepository repositories.BookRepository
+	userRepository repositories.UserRepository
 }
 
 // NewLoanService cria uma nova instância do serviço de empréstimos
-func NewLoanService(loanRepository repositories.LoanRepository, bookRepository repositories.BookRepository) services.LoanService {
+func NewLoanService(
+	loanRepository repositories.LoanRepository,
+	bookRepository repositories.BookRepository,
+	userRepository repositories.UserRepository,
+) services.LoanService {
 	return &loanService{
 		loanRepository: loanRepository,
 		bookRepository: bookRepository,
+		userRepository: userRepository,
 	}
 }
 
 // Create cria um novo empréstimo
-func (loanService *loanService) Create(userID uint, loanDTO dtos.LoanCreateDTO) (*dtos.LoanResponseDTO, error) {
+func (loanService *loanService) Create(ctx context.Context, userID uint, loanDTO dtos.LoanCreateDTO) (*dtos.LoanResponseDTO, error) {
+	return loanService.createLoan(ctx, userID, loanDTO.BookID, loanDTO.BranchID, loanDTO.ReturnDate)
+}
+
+// CheckOut registra um empréstimo no balcão da unidade do bibliotecário
+func (loanService *loanService) CheckOut(ctx context.Context, staffID uint, loanDTO dtos.StaffLoanCreateDTO) (*dtos.LoanResponseDTO, error) {
+	staff, err := loadStaff(ctx, loanService.userRepository, staffID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Sem unidade informada, o empréstimo é feito na unidade do bibliotecário
+	branchID := loanDTO.BranchID
+	if branchID == nil {
+		branchID = staff.BranchID
+	}
+	if branchID == nil {
+		return nil, domainerrors.New("informe a unidade do empréstimo")
+	}
+	if !canOperateAt(staff, *branchID) {
+		return nil, errOtherBranch
+	}
+
+	return loanService.createLoan(ctx, loanDTO.UserID, loanDTO.BookID, branchID, loanDTO.ReturnDate)
+}
+
+// createLoan valida o usuário e a disponibilidade do livro e registra o empréstimo.
+// Usuários removidos não recebem empréstimos, que impediriam o apagamento dos dados.
+func (loanService *loanService) createLoan(ctx context.Context, userID, bookID uint, branchID *uint, returnDate time.Time) (*dtos.LoanResponseDTO, error) {
+	user, err := loanService.userRepository.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, domainerrors.New("usuário não encontrado")
+	}
+
 	// Verificar se o livro existe
-	book, err := loanService.bookRepository.FindByID(loanDTO.BookID)
+	book, err := loanService.bookRepository.FindByID(ctx, bookID)
 	if err != nil {
 		return nil, err
 	}
 	if book == nil {
-		return nil, errors.New("livro não encontrado")
+		return nil, domainerrors.New("livro não encontrado")
+	}
+	if book.Status == entities.BookStatusWithdrawn {
+		return nil, domainerrors.New("livro retirado do acervo")
 	}
 
 	// Verificar se há exemplares disponíveis
 	if book.Available <= 0 {
-		return nil, errors.New("livro não disponível para empréstimo")
+		return nil, domainerrors.New("livro não disponível para empréstimo")
 	}
 
 	// Criar empréstimo
 	loan := entities.Loan{
 		UserID:     userID,
-		BookID:     loanDTO.BookID,
+		BookID:     bookID,
+		BranchID:   branchID,
 		LoanDate:   time.Now(),
-		ReturnDate: loanDTO.ReturnDate,
+		ReturnDate: returnDate,
 		IsReturned: false,
 	}
 
-	if err := loanService.loanRepository.Create(&loan); err != nil {
+	if err := loanService.loanRepository.Create(ctx, &loan); err != nil {
 		return nil, err
 	}
 
 	// Carregar dados completos do empréstimo com livro e usuário
-	fullLoan, err := loanService.loanRepository.FindByID(loan.ID)
+	fullLoan, err := loanService.loanRepository.FindByID(ctx, loan.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -64,18 +111,18 @@ func (loanService *loanService) Create(userID uint, loanDTO dtos.LoanCreateDTO)
 }
 
 // GetByID busca um empréstimo pelo ID
-func (loanService *loanService) GetByID(id uint, userID uint) (*dtos.LoanResponseDTO, error) {
-	loan, err := loanService.loanRepository.FindByID(id)
+func (loanService *loanService) GetByID(ctx context.Context, id uint, userID uint) (*dtos.LoanResponseDTO, error) {
+	loan, err := loanService.loanRepository.FindByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 	if loan == nil {
-		return nil, errors.New("empréstimo não encontrado")
+		return nil, domainerrors.New("empréstimo não encontrado")
 	}
 
 	// Verificar se o empréstimo pertence ao usuário
 	if loan.UserID != userID {
-		return nil, errors.New("acesso negado a este empréstimo")
+		return nil, domainerrors.New("acesso negado a este empréstimo")
 	}
 
 	responseDTO := dtos.LoanToResponseDTO(*loan)
@@ -83,8 +130,8 @@ func (loanService *loanService) GetByID(id uint, userID uint) (*dtos.LoanRespons
 }
 
 // ListByUser retorna todos os empréstimos de um usuário
-func (loanService *loanService) ListByUser(userID uint) ([]dtos.LoanResponseDTO, error) {
-	loans, err := loanService.loanRepository.FindByUserID(userID)
+func (loanService *loanService) ListByUser(ctx context.Context, userID uint) ([]dtos.LoanResponseDTO, error) {
+	loans, err := loanService.loanRepository.FindByUserID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -98,32 +145,89 @@ func (loanService *loanService) ListByUser(userID uint) ([]dtos.LoanResponseDTO,
 }
 
 // ReturnLoan marca um empréstimo como devolvido
-func (loanService *loanService) ReturnLoan(id uint, userID uint) (*dtos.LoanResponseDTO, error) {
+func (loanService *loanService) ReturnLoan(ctx context.Context, id uint, userID uint) (*dtos.LoanResponseDTO, error) {
 	// Verificar se o empréstimo existe e pertence ao usuário
-	loan, err := loanService.loanRepository.FindByID(id)
+	loan, err := loanService.loanRepository.FindByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 	if loan == nil {
-		return nil, errors.New("empréstimo não encontrado")
+		return nil, domainerrors.New("empréstimo não encontrado")
 	}
 
 	if loan.UserID != userID {
-		return nil, errors.New("acesso negado a este empréstimo")
+		return nil, domainerrors.New("acesso negado a este empréstimo")
+	}
+
+	return loanService.returnLoan(ctx, loan)
+}
+
+// CheckIn registra no balcão a devolução de um empréstimo retirado na unidade do bibliotecário
+func (loanService *loanService) CheckIn(ctx context.Context, id uint, staffID uint) (*dtos.LoanResponseDTO, error) {
+	staff, err := loadStaff(ctx, loanService.userRepository, staffID)
+	if err != nil {
+		return nil, err
+	}
+
+	loan, err := loanService.loanRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if loan == nil {
+		return nil, domainerrors.New("empréstimo não encontrado")
+	}
+
+	// Empréstimos sem unidade só podem ser recebidos por administradores
+	if !staff.IsAdmin && (loan.BranchID == nil || !canOperateAt(staff, *loan.BranchID)) {
+		return nil, errOtherBranch
+	}
+
+	return loanService.returnLoan(ctx, loan)
+}
+
+// ForceReturn registra a devolução de um empréstimo sem conferir o usuário nem a unidade.
+// É usado pelas ferramentas operacionais, que rodam fora da API e sem usuário autenticado.
+func (loanService *loanService) ForceReturn(ctx context.Context, id uint) (*dtos.LoanResponseDTO, error) {
+	loan, err := loanService.loanRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
 	}
+	if loan == nil {
+		return nil, domainerrors.New("empréstimo não encontrado")
+	}
+
+	return loanService.returnLoan(ctx, loan)
+}
+
+// ListOverdue retorna os empréstimos em atraso, do mais atrasado ao mais recente
+func (loanService *loanService) ListOverdue(ctx context.Context) ([]dtos.LoanResponseDTO, error) {
+	loans, err := loanService.loanRepository.ListOverdue(ctx, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	var loanDTOs []dtos.LoanResponseDTO
+	for _, loan := range loans {
+		loanDTOs = append(loanDTOs, dtos.LoanToResponseDTO(*loan))
+	}
+
+	return loanDTOs, nil
+}
 
+// returnLoan processa a devolução de um empréstimo em aberto
+func (loanService *loanService) returnLoan(ctx context.Context, loan *entities.Loan) (*dtos.LoanResponseDTO, error) {
 	if loan.IsReturned {
-		return nil, errors.New("empréstimo já foi devolvido")
+		return nil, domainerrors.New("empréstimo já foi devolvido")
 	}
 
 	// Processar devolução
 	returnDate := time.Now()
-	if err := loanService.loanRepository.ReturnLoan(id, returnDate); err != nil {
+	if err := loanService.loanRepository.ReturnLoan(ctx, loan.ID, returnDate); err != nil {
 		return nil, err
 	}
 
 	// Obter empréstimo atualizado
-	updatedLoan, err := loanService.loanRepository.FindByID(id)
+	updatedLoan, err := loanService.loanRepository.FindByID(ctx, loan.ID)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,310 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/notifications"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/repositories"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/services"
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+	domainerrors "github.com/henrygoeszanin/api_golang_estudos/domain/errors"
+)
+
+const (
+	// notificationMaxAttempts é o número de tentativas de entrega de uma notificação por canal externo
+	notificationMaxAttempts = 5
+	// notificationDeliveryBatch é a quantidade de entregas pendentes feitas em cada varredura
+	notificationDeliveryBatch = 500
+)
+
+// notificationService implementa a interface NotificationService
+type notificationService struct {
+	notificationRepository repositories.NotificationRepository
+	loanRepository         repositories.LoanRepository
+	userRepository         repositories.UserRepository
+	emailSender            notifications.Sender
+	reminderDays           int
+	overdueDays            []int
+	location               *time.Location
+}
+
+// NewNotificationService cria uma nova instância do serviço de notificações. O lembrete é enviado
+// reminderDays dias antes da devolução (zero desliga) e cada item de overdueDays, em ordem crescente,
+// é um nível de aviso de atraso. As datas são contadas no fuso informado.
+func NewNotificationService(
+	notificationRepository repositories.NotificationRepository,
+	loanRepository repositories.LoanRepository,
+	userRepository repositories.UserRepository,
+	emailSender notifications.Sender,
+	reminderDays int,
+	overdueDays []int,
+	location *time.Location,
+) services.NotificationService {
+	return &notificationService{
+		notificationRepository: notificationRepository,
+		loanRepository:         loanRepository,
+		userRepository:         userRepository,
+		emailSender:            emailSender,
+		reminderDays:           reminderDays,
+		overdueDays:            overdueDays,
+		location:               location,
+	}
+}
+
+// SendLoanReminders cria os lembretes e avisos de atraso devidos hoje para os empréstimos em aberto,
+// pelos canais que cada usuário mantém ligados, e entrega os e-mails pendentes. Cada aviso é criado
+// uma única vez; se a varredura deixar de rodar alguns dias, só o nível de atraso atual é enviado.
+func (notificationService *notificationService) SendLoanReminders(ctx context.Context) (*dtos.NotificationScanResultDTO, error) {
+	now := time.Now().In(notificationService.location)
+	result := &dtos.NotificationScanResultDTO{}
+
+	// Empréstimos em aberto que vencem até o último dia coberto pelo lembrete, inclusive os já atrasados
+	cutoff := time.Date(now.Year(), now.Month(), now.Day()+notificationService.reminderDays+1, 0, 0, 0, 0, notificationService.location)
+	loans, err := notificationService.loanRepository.ListOverdue(ctx, cutoff)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, loan := range loans {
+		// Usuários removidos não aparecem no empréstimo e não recebem notificações
+		if loan.User.ID == 0 || loan.User.ErasedAt != nil {
+			continue
+		}
+
+		days := calendarDays(now, loan.ReturnDate.In(notificationService.location))
+		kind, level := notificationService.classify(days)
+		if kind == "" {
+			continue
+		}
+		result.Loans++
+
+		data := notificationData{
+			Name:    loan.User.Name,
+			Title:   loan.Book.Title,
+			DueDate: loan.ReturnDate.In(notificationService.location).Format("02/01/2006"),
+			Days:    days,
+			Level:   level,
+			Final:   level == len(notificationService.overdueDays),
+		}
+		if kind == entities.NotificationOverdue {
+			data.Days = -days
+		}
+		subject, body, err := renderNotification(kind, data)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, channel := range notificationChannels(loan.User) {
+			loanID := loan.ID
+			notification := &entities.Notification{
+				UserID:   loan.UserID,
+				LoanID:   &loanID,
+				Kind:     kind,
+				Level:    level,
+				Channel:  channel,
+				DedupKey: fmt.Sprintf("loan:%d:%s:%d:%s", loan.ID, kind, level, channel),
+				Subject:  subject,
+				Body:     body,
+			}
+			// A caixa de entrada é o próprio destino das notificações internas
+			if channel == entities.NotificationChannelInApp {
+				notification.SentAt = &now
+			}
+
+			created, err := notificationService.notificationRepository.Create(ctx, notification)
+			if err != nil {
+				return nil, err
+			}
+			if created {
+				result.Created++
+			}
+		}
+	}
+
+	sent, failed, err := notificationService.deliver(ctx, entities.NotificationChannelEmail, notificationService.emailSender)
+	result.Sent, result.Failed = sent, failed
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Inbox retorna as notificações internas do usuário, das mais recentes para as mais antigas
+func (notificationService *notificationService) Inbox(ctx context.Context, userID uint, filter dtos.NotificationFilterDTO) (*dtos.NotificationInboxDTO, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = dtos.NotificationDefaultLimit
+	}
+	if limit > dtos.NotificationMaxLimit {
+		limit = dtos.NotificationMaxLimit
+	}
+
+	items, err := notificationService.notificationRepository.ListInbox(ctx, userID, repositories.NotificationFilter{
+		UnreadOnly: filter.Unread,
+		Limit:      limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+	unread, err := notificationService.notificationRepository.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	inbox := &dtos.NotificationInboxDTO{Unread: unread, Notifications: []dtos.NotificationDTO{}}
+	for _, item := range items {
+		inbox.Notifications = append(inbox.Notifications, dtos.NotificationToDTO(*item))
+	}
+	return inbox, nil
+}
+
+// MarkRead marca uma notificação do usuário como lida
+func (notificationService *notificationService) MarkRead(ctx context.Context, userID uint, id uint) error {
+	found, err := notificationService.notificationRepository.MarkRead(ctx, userID, id, time.Now())
+	if err != nil {
+		return err
+	}
+	if !found {
+		return fmt.Errorf("%w: notificação não encontrada", domainerrors.ErrNotFound)
+	}
+	return nil
+}
+
+// MarkAllRead marca todas as notificações do usuário como lidas e retorna quantas foram marcadas
+func (notificationService *notificationService) MarkAllRead(ctx context.Context, userID uint) (int64, error) {
+	return notificationService.notificationRepository.MarkAllRead(ctx, userID, time.Now())
+}
+
+// GetPreferences retorna os canais pelos quais o usuário recebe notificações
+func (notificationService *notificationService) GetPreferences(ctx context.Context, userID uint) (*dtos.NotificationPreferencesDTO, error) {
+	user, err := notificationService.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	preferences := dtos.UserNotificationPreferencesDTO(*user)
+	return &preferences, nil
+}
+
+// UpdatePreferences liga ou desliga os canais informados
+func (notificationService *notificationService) UpdatePreferences(ctx context.Context, userID uint, preferencesDTO dtos.NotificationPreferencesUpdateDTO) (*dtos.NotificationPreferencesDTO, error) {
+	user, err := notificationService.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if preferencesDTO.Email != nil {
+		user.EmailNotificationsOff = !*preferencesDTO.Email
+	}
+	if preferencesDTO.InApp != nil {
+		user.InAppNotificationsOff = !*preferencesDTO.InApp
+	}
+	if err := notificationService.userRepository.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	preferences := dtos.UserNotificationPreferencesDTO(*user)
+	return &preferences, nil
+}
+
+// classify identifica o tipo e o nível da notificação pelos dias que faltam para a devolução
+// (negativos quando atrasada); retorna um tipo vazio quando nada é devido hoje
+func (notificationService *notificationService) classify(days int) (string, int) {
+	switch {
+	case days > 0 && days <= notificationService.reminderDays:
+		return entities.NotificationDueSoon, 0
+	case days == 0:
+		return entities.NotificationDueToday, 0
+	case days < 0:
+		level := 0
+		for _, threshold := range notificationService.overdueDays {
+			if -days >= threshold {
+				level++
+			}
+		}
+		if level > 0 {
+			return entities.NotificationOverdue, level
+		}
+	}
+	return "", 0
+}
+
+// deliver entrega pelo canal as notificações pendentes e retorna quantas foram entregues e quantas falharam
+func (notificationService *notificationService) deliver(ctx context.Context, channel string, sender notifications.Sender) (int, int, error) {
+	pending, err := notificationService.notificationRepository.ListPending(ctx, channel, notificationMaxAttempts, notificationDeliveryBatch)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	sent, failed := 0, 0
+	for _, notification := range pending {
+		notification.Attempts++
+
+		user, err := notificationService.userRepository.FindByID(ctx, notification.UserID)
+		switch {
+		case err != nil:
+			return sent, failed, err
+		case user == nil:
+			// Sem destinatário, não há por que tentar de novo
+			notification.Attempts = notificationMaxAttempts
+			notification.Error = "usuário removido"
+			failed++
+		default:
+			err = sender.Send(ctx, notifications.Message{
+				Channel: channel,
+				UserID:  user.ID,
+				To:      user.Email,
+				Subject: notification.Subject,
+				Body:    notification.Body,
+			})
+			if err != nil {
+				notification.Error = err.Error()
+				failed++
+				break
+			}
+			sentAt := time.Now()
+			notification.SentAt = &sentAt
+			notification.Error = ""
+			sent++
+		}
+
+		if err := notificationService.notificationRepository.Update(ctx, notification); err != nil {
+			return sent, failed, err
+		}
+	}
+	return sent, failed, nil
+}
+
+// findUser busca o usuário autenticado
+func (notificationService *notificationService) findUser(ctx context.Context, userID uint) (*entities.User, error) {
+	user, err := notificationService.userRepository.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, fmt.Errorf("%w: usuário não encontrado", domainerrors.ErrNotFound)
+	}
+	return user, nil
+}
+
+// notificationChannels retorna os canais que o usuário mantém ligados
+func notificationChannels(user entities.User) []string {
+	var channels []string
+	if !user.EmailNotificationsOff {
+		channels = append(channels, entities.NotificationChannelEmail)
+	}
+	if !user.InAppNotificationsOff {
+		channels = append(channels, entities.NotificationChannelInApp)
+	}
+	return channels
+}
+
+// calendarDays conta os dias de calendário entre duas datas, já convertidas para o mesmo fuso
+func calendarDays(from, to time.Time) int {
+	fromDate := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	toDate := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(toDate.Sub(fromDate).Hours() / 24)
+}
@@ -0,0 +1,411 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/repositories"
+	"github.com/henrygoeszanin/api_golang_estudos/config"
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+	"github.com/henrygoeszanin/api_golang_estudos/infrastructure/database"
+	"github.com/henrygoeszanin/api_golang_estudos/infrastructure/memory"
+	"github.com/henrygoeszanin/api_golang_estudos/infrastructure/notifications"
+	gormrepositories "github.com/henrygoeszanin/api_golang_estudos/infrastructure/repositories"
+)
+
+func TestNotificationClassify(t *testing.T) {
+	tests := []struct {
+		name         string
+		reminderDays int
+		overdueDays  []int
+		days         int
+		kind         string
+		level        int
+	}{
+		{"antes do lembrete", 2, []int{1, 7, 14}, 3, "", 0},
+		{"primeiro dia do lembrete", 2, []int{1, 7, 14}, 2, entities.NotificationDueSoon, 0},
+		{"véspera", 2, []int{1, 7, 14}, 1, entities.NotificationDueSoon, 0},
+		{"dia da devolução", 2, []int{1, 7, 14}, 0, entities.NotificationDueToday, 0},
+		{"um dia de atraso", 2, []int{1, 7, 14}, -1, entities.NotificationOverdue, 1},
+		{"antes do segundo nível", 2, []int{1, 7, 14}, -6, entities.NotificationOverdue, 1},
+		{"segundo nível", 2, []int{1, 7, 14}, -7, entities.NotificationOverdue, 2},
+		{"antes do último nível", 2, []int{1, 7, 14}, -13, entities.NotificationOverdue, 2},
+		{"último nível", 2, []int{1, 7, 14}, -14, entities.NotificationOverdue, 3},
+		{"depois do último nível", 2, []int{1, 7, 14}, -60, entities.NotificationOverdue, 3},
+		{"lembrete desligado", 0, []int{1, 7, 14}, 1, "", 0},
+		{"lembrete desligado no dia", 0, []int{1, 7, 14}, 0, entities.NotificationDueToday, 0},
+		{"atraso antes do primeiro nível", 2, []int{3}, -2, "", 0},
+		{"sem avisos de atraso", 2, nil, -5, "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &notificationService{reminderDays: tt.reminderDays, overdueDays: tt.overdueDays}
+			kind, level := service.classify(tt.days)
+			if kind != tt.kind || level != tt.level {
+				t.Errorf("classify(%d) = %q, %d; esperado %q, %d", tt.days, kind, level, tt.kind, tt.level)
+			}
+		})
+	}
+}
+
+func TestCalendarDays(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+	saoPaulo := mustLoadLocation(t, "America/Sao_Paulo")
+	auckland := mustLoadLocation(t, "Pacific/Auckland")
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		days     int
+	}{
+		{"mesmo dia", time.Date(2026, 5, 10, 0, 0, 0, 0, time.UTC), time.Date(2026, 5, 10, 23, 59, 0, 0, time.UTC), 0},
+		{"meia-noite seguinte", time.Date(2026, 5, 10, 23, 59, 0, 0, time.UTC), time.Date(2026, 5, 11, 0, 0, 0, 0, time.UTC), 1},
+		{"data anterior", time.Date(2026, 5, 10, 8, 0, 0, 0, time.UTC), time.Date(2026, 5, 3, 20, 0, 0, 0, time.UTC), -7},
+		{"virada do ano", time.Date(2026, 12, 31, 22, 0, 0, 0, time.UTC), time.Date(2027, 1, 1, 1, 0, 0, 0, time.UTC), 1},
+		{"ano bissexto", time.Date(2028, 2, 28, 12, 0, 0, 0, time.UTC), time.Date(2028, 3, 1, 12, 0, 0, 0, time.UTC), 2},
+		// Início do horário de verão: o dia tem 23 horas
+		{"início do horário de verão", time.Date(2026, 3, 7, 23, 30, 0, 0, newYork), time.Date(2026, 3, 8, 23, 30, 0, 0, newYork), 1},
+		{"dia de 23 horas", time.Date(2026, 3, 8, 0, 0, 0, 0, newYork), time.Date(2026, 3, 9, 0, 0, 0, 0, newYork), 1},
+		// Fim do horário de verão: o dia tem 25 horas, e 48 horas corridas ainda são um dia de calendário
+		{"fim do horário de verão", time.Date(2026, 10, 31, 0, 30, 0, 0, newYork), time.Date(2026, 11, 1, 23, 30, 0, 0, newYork), 1},
+		{"dia de 25 horas", time.Date(2026, 11, 1, 0, 0, 0, 0, newYork), time.Date(2026, 11, 2, 0, 0, 0, 0, newYork), 1},
+		{"horário de verão no hemisfério sul", time.Date(2026, 9, 26, 12, 0, 0, 0, auckland), time.Date(2026, 10, 3, 12, 0, 0, 0, auckland), 7},
+		// A mesma devolução cai em datas diferentes conforme o fuso: conta a data local
+		{"fuso local antes da meia-noite UTC",
+			time.Date(2026, 5, 9, 10, 0, 0, 0, saoPaulo),
+			time.Date(2026, 5, 10, 2, 0, 0, 0, time.UTC).In(saoPaulo), 0},
+		{"mesmos instantes em UTC",
+			time.Date(2026, 5, 9, 10, 0, 0, 0, saoPaulo).UTC(),
+			time.Date(2026, 5, 10, 2, 0, 0, 0, time.UTC), 1},
+		{"fuso à frente de UTC",
+			time.Date(2026, 5, 9, 23, 0, 0, 0, time.UTC).In(auckland),
+			time.Date(2026, 5, 10, 1, 0, 0, 0, time.UTC).In(auckland), 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if days := calendarDays(tt.from, tt.to); days != tt.days {
+				t.Errorf("calendarDays(%s, %s) = %d, esperado %d", tt.from, tt.to, days, tt.days)
+			}
+		})
+	}
+}
+
+func TestSendLoanRemindersEscalation(t *testing.T) {
+	tests := []struct {
+		name  string
+		due   int // Dias até a devolução, negativos quando atrasada
+		kind  string
+		level int
+	}{
+		{"fora do prazo do lembrete", 5, "", 0},
+		{"lembrete", 2, entities.NotificationDueSoon, 0},
+		{"dia da devolução", 0, entities.NotificationDueToday, 0},
+		{"primeiro aviso de atraso", -1, entities.NotificationOverdue, 1},
+		{"segundo aviso de atraso", -8, entities.NotificationOverdue, 2},
+		{"último aviso de atraso", -20, entities.NotificationOverdue, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixture := newNotificationFixture(t, time.UTC)
+			user := fixture.addUser(t, "leitora@biblioteca.test", false, true)
+			fixture.addLoan(t, user, tt.due)
+
+			result, err := fixture.service.SendLoanReminders(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			sent := fixture.sent(t)
+			if tt.kind == "" {
+				if result.Created != 0 || len(sent) != 0 {
+					t.Errorf("nenhuma notificação esperada: %+v, %d enviadas", result, len(sent))
+				}
+				return
+			}
+			if result.Created != 1 || result.Sent != 1 || len(sent) != 1 {
+				t.Fatalf("esperada uma notificação enviada: %+v, %d no arquivo", result, len(sent))
+			}
+			created := fixture.only(t, user.ID, entities.NotificationChannelEmail)
+			if created.Kind != tt.kind || created.Level != tt.level {
+				t.Errorf("notificação %q nível %d, esperada %q nível %d", created.Kind, created.Level, tt.kind, tt.level)
+			}
+			if created.SentAt == nil || created.Attempts != 1 {
+				t.Errorf("entrega não registrada: enviada em %v, %d tentativas", created.SentAt, created.Attempts)
+			}
+		})
+	}
+}
+
+func TestSendLoanRemindersIdempotent(t *testing.T) {
+	fixture := newNotificationFixture(t, time.UTC)
+	user := fixture.addUser(t, "leitora@biblioteca.test", false, false)
+	loan := fixture.addLoan(t, user, -8)
+
+	first, err := fixture.service.SendLoanReminders(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.Created != 2 || first.Sent != 1 {
+		t.Fatalf("primeira varredura: %+v, esperadas 2 notificações e 1 e-mail", first)
+	}
+
+	// Repetir a varredura no mesmo dia não cria nem envia nada de novo
+	for run := 2; run <= 3; run++ {
+		again, err := fixture.service.SendLoanReminders(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if again.Created != 0 || again.Sent != 0 || again.Failed != 0 {
+			t.Errorf("varredura %d: %+v, esperado nada de novo", run, again)
+		}
+	}
+	if sent := fixture.sent(t); len(sent) != 1 {
+		t.Errorf("%d e-mails enviados, esperado 1", len(sent))
+	}
+
+	// O próximo nível de atraso é um aviso novo, com outra chave
+	loan.ReturnDate = loan.ReturnDate.AddDate(0, 0, -7)
+	if err := fixture.loans.Update(context.Background(), loan); err != nil {
+		t.Fatal(err)
+	}
+	escalated, err := fixture.service.SendLoanReminders(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if escalated.Created != 2 || escalated.Sent != 1 {
+		t.Errorf("novo nível: %+v, esperadas 2 notificações e 1 e-mail", escalated)
+	}
+
+	keys := map[string]bool{}
+	for _, notification := range fixture.all(t, user.ID) {
+		if keys[notification.DedupKey] {
+			t.Errorf("chave repetida: %s", notification.DedupKey)
+		}
+		keys[notification.DedupKey] = true
+	}
+	expected := []string{"loan:1:overdue:2:email", "loan:1:overdue:2:in_app", "loan:1:overdue:3:email", "loan:1:overdue:3:in_app"}
+	for _, key := range expected {
+		if !keys[key] {
+			t.Errorf("chave %s não encontrada entre %v", key, keys)
+		}
+	}
+}
+
+func TestSendLoanRemindersChannels(t *testing.T) {
+	tests := []struct {
+		name     string
+		emailOff bool
+		inAppOff bool
+		channels []string
+	}{
+		{"todos os canais", false, false, []string{entities.NotificationChannelEmail, entities.NotificationChannelInApp}},
+		{"apenas e-mail", false, true, []string{entities.NotificationChannelEmail}},
+		{"apenas caixa de entrada", true, false, []string{entities.NotificationChannelInApp}},
+		{"nenhum canal", true, true, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fixture := newNotificationFixture(t, time.UTC)
+			user := fixture.addUser(t, "leitora@biblioteca.test", tt.emailOff, tt.inAppOff)
+			fixture.addLoan(t, user, 0)
+
+			result, err := fixture.service.SendLoanReminders(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var channels []string
+			for _, notification := range fixture.all(t, user.ID) {
+				channels = append(channels, notification.Channel)
+				if notification.SentAt == nil {
+					t.Errorf("notificação por %s não entregue", notification.Channel)
+				}
+			}
+			sort.Strings(channels)
+			if !equalStrings(channels, tt.channels) {
+				t.Errorf("canais %v, esperados %v", channels, tt.channels)
+			}
+			if result.Created != len(tt.channels) {
+				t.Errorf("%d notificações criadas, esperadas %d", result.Created, len(tt.channels))
+			}
+
+			sent := fixture.sent(t)
+			wantEmail := !tt.emailOff
+			if (len(sent) == 1) != wantEmail || len(sent) > 1 {
+				t.Fatalf("%d e-mails enviados, esperado e-mail: %v", len(sent), wantEmail)
+			}
+			if wantEmail && (sent[0].To != user.Email || sent[0].Channel != entities.NotificationChannelEmail) {
+				t.Errorf("e-mail enviado para %q pelo canal %q", sent[0].To, sent[0].Channel)
+			}
+		})
+	}
+}
+
+// notificationFixture reúne o serviço de notificações e os repositórios de um teste: empréstimos e usuários
+// em memória, notificações em um SQLite em memória e e-mails gravados em um arquivo
+type notificationFixture struct {
+	service       *notificationService
+	db            *gorm.DB
+	notifications repositories.NotificationRepository
+	users         repositories.UserRepository
+	books         repositories.BookRepository
+	loans         repositories.LoanRepository
+	location      *time.Location
+	outbox        string
+}
+
+// sentMessage é uma linha do arquivo de envios
+type sentMessage struct {
+	Channel string `json:"channel"`
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+}
+
+// newNotificationFixture monta o serviço com lembrete dois dias antes e avisos com 1, 7 e 14 dias de atraso
+func newNotificationFixture(t *testing.T, location *time.Location) *notificationFixture {
+	t.Helper()
+	db, err := database.SetupDatabase(config.Defaults(config.ProfileTest))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	store := memory.NewStore()
+	fixture := &notificationFixture{
+		db:            db,
+		notifications: gormrepositories.NewNotificationRepository(db),
+		users:         memory.NewUserRepository(store),
+		books:         memory.NewBookRepository(store),
+		loans:         memory.NewLoanRepository(store),
+		location:      location,
+		outbox:        filepath.Join(t.TempDir(), "notifications.jsonl"),
+	}
+	fixture.service = NewNotificationService(
+		fixture.notifications,
+		fixture.loans,
+		fixture.users,
+		notifications.NewFileSender(fixture.outbox),
+		2,
+		[]int{1, 7, 14},
+		location,
+	).(*notificationService)
+	return fixture
+}
+
+// addUser cadastra um usuário com as preferências de canal informadas
+func (fixture *notificationFixture) addUser(t *testing.T, email string, emailOff, inAppOff bool) *entities.User {
+	t.Helper()
+	user := &entities.User{Name: "Leitora", Email: email, Password: "hash", EmailNotificationsOff: emailOff, InAppNotificationsOff: inAppOff}
+	if err := fixture.users.Create(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+// addLoan registra um empréstimo em aberto com devolução ao meio-dia, daqui a due dias no fuso do serviço
+func (fixture *notificationFixture) addLoan(t *testing.T, user *entities.User, due int) *entities.Loan {
+	t.Helper()
+	book := &entities.Book{Title: "Dom Casmurro", Author: "Machado de Assis", Quantity: 1, Available: 1}
+	if err := fixture.books.Create(context.Background(), book); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now().In(fixture.location)
+	loan := &entities.Loan{
+		UserID:     user.ID,
+		BookID:     book.ID,
+		LoanDate:   now.AddDate(0, 0, -30),
+		ReturnDate: time.Date(now.Year(), now.Month(), now.Day()+due, 12, 0, 0, 0, fixture.location),
+	}
+	if err := fixture.loans.Update(context.Background(), loan); err != nil {
+		t.Fatal(err)
+	}
+	return loan
+}
+
+// all retorna todas as notificações do usuário, de todos os canais, na ordem de criação
+func (fixture *notificationFixture) all(t *testing.T, userID uint) []*entities.Notification {
+	t.Helper()
+	var found []*entities.Notification
+	if err := fixture.db.Where("user_id = ?", userID).Order("id").Find(&found).Error; err != nil {
+		t.Fatal(err)
+	}
+	return found
+}
+
+// only retorna a única notificação do usuário no canal
+func (fixture *notificationFixture) only(t *testing.T, userID uint, channel string) *entities.Notification {
+	t.Helper()
+	var found []*entities.Notification
+	for _, notification := range fixture.all(t, userID) {
+		if notification.Channel == channel {
+			found = append(found, notification)
+		}
+	}
+	if len(found) != 1 {
+		t.Fatalf("%d notificações por %s, esperada 1", len(found), channel)
+	}
+	return found[0]
+}
+
+// sent lê as mensagens gravadas pelo envio em arquivo
+func (fixture *notificationFixture) sent(t *testing.T) []sentMessage {
+	t.Helper()
+	file, err := os.Open(fixture.outbox)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	var messages []sentMessage
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var message sentMessage
+		if err := json.Unmarshal(scanner.Bytes(), &message); err != nil {
+			t.Fatal(err)
+		}
+		messages = append(messages, message)
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return messages
+}
+
+// mustLoadLocation carrega o fuso pelo nome da base IANA
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return location
+}
+
+// equalStrings compara duas listas, considerando iguais a lista vazia e a nula
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
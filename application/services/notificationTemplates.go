@@ -0,0 +1,72 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+)
+
+// notificationData reúne os valores disponíveis nos modelos de mensagem
+type notificationData struct {
+	Name    string
+	Title   string
+	DueDate string // Data de devolução no formato dd/mm/aaaa
+	Days    int    // Dias até a devolução nos lembretes; dias de atraso nos avisos
+	Level   int    // Nível do aviso de atraso, a partir de 1
+	Final   bool   // Último nível de aviso configurado
+}
+
+// notificationTemplate guarda os modelos do assunto e do corpo de um tipo de notificação
+type notificationTemplate struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+// notificationTemplates são os modelos de mensagem de cada tipo de notificação
+var notificationTemplates = map[string]notificationTemplate{
+	entities.NotificationDueSoon: newNotificationTemplate(
+		`Lembrete: devolva "{{.Title}}" até {{.DueDate}}`,
+		`Olá, {{.Name}}.
+
+O empréstimo de "{{.Title}}" vence em {{.Days}} {{if eq .Days 1}}dia{{else}}dias{{end}}, em {{.DueDate}}. Devolva o livro até essa data para evitar atrasos.`,
+	),
+	entities.NotificationDueToday: newNotificationTemplate(
+		`Hoje é o último dia para devolver "{{.Title}}"`,
+		`Olá, {{.Name}}.
+
+O empréstimo de "{{.Title}}" vence hoje, {{.DueDate}}. Devolva o livro ainda hoje para evitar atrasos.`,
+	),
+	entities.NotificationOverdue: newNotificationTemplate(
+		`{{if .Final}}Último aviso{{else if gt .Level 1}}{{.Level}}º aviso{{else}}Aviso{{end}}: a devolução de "{{.Title}}" está atrasada`,
+		`Olá, {{.Name}}.
+
+O empréstimo de "{{.Title}}" venceu em {{.DueDate}} e está atrasado há {{.Days}} {{if eq .Days 1}}dia{{else}}dias{{end}}. {{if .Final}}Este é o último aviso: procure a biblioteca para regularizar a devolução.{{else}}Devolva o livro o quanto antes.{{end}}`,
+	),
+}
+
+// newNotificationTemplate interpreta os modelos do assunto e do corpo
+func newNotificationTemplate(subject, body string) notificationTemplate {
+	return notificationTemplate{
+		subject: template.Must(template.New("subject").Parse(subject)),
+		body:    template.Must(template.New("body").Parse(body)),
+	}
+}
+
+// renderNotification monta o assunto e o corpo da notificação do tipo informado
+func renderNotification(kind string, data notificationData) (string, string, error) {
+	notificationTemplate, ok := notificationTemplates[kind]
+	if !ok {
+		return "", "", fmt.Errorf("modelo de notificação %q não encontrado", kind)
+	}
+
+	var subject, body strings.Builder
+	if err := notificationTemplate.subject.Execute(&subject, data); err != nil {
+		return "", "", err
+	}
+	if err := notificationTemplate.body.Execute(&body, data); err != nil {
+		return "", "", err
+	}
+	return subject.String(), body.String(), nil
+}
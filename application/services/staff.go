@@ -0,0 +1,37 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/repositories"
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+	domainerrors "github.com/henrygoeszanin/api_golang_estudos/domain/errors"
+)
+
+// loadStaff busca o usuário que executa uma operação de balcão e garante que ele é da equipe
+func loadStaff(ctx context.Context, userRepository repositories.UserRepository, staffID uint) (*entities.User, error) {
+	staff, err := userRepository.FindByID(ctx, staffID)
+	if err != nil {
+		return nil, err
+	}
+	if staff == nil {
+		return nil, domainerrors.New("usuário não encontrado")
+	}
+	if !staff.IsAdmin && !staff.IsLibrarian {
+		return nil, fmt.Errorf("%w: operação restrita à equipe da biblioteca", domainerrors.ErrForbidden)
+	}
+	return staff, nil
+}
+
+// canOperateAt indica se o usuário da equipe pode operar na unidade informada.
+// Administradores operam em qualquer unidade; bibliotecários apenas na sua.
+func canOperateAt(staff *entities.User, branchID uint) bool {
+	if staff.IsAdmin {
+		return true
+	}
+	return staff.IsLibrarian && staff.BranchID != nil && *staff.BranchID == branchID
+}
+
+// errOtherBranch é retornado quando um bibliotecário tenta operar fora da sua unidade
+var errOtherBranch = fmt.Errorf("%w: operação permitida apenas na unidade do bibliotecário", domainerrors.ErrForbidden)
@@ -1,36 +1,55 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
 	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/repositories"
 	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/services"
 	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+	domainerrors "github.com/henrygoeszanin/api_golang_estudos/domain/errors"
 	"golang.org/x/crypto/bcrypt"
 )
 
 // userService implementa a interface UserService
 type userService struct {
-	userRepository repositories.UserRepository
+	userRepository     repositories.UserRepository
+	branchRepository   repositories.BranchRepository
+	erasureGracePeriod time.Duration
+	firstUserAdmin     bool
 }
 
-// NewUserService cria uma nova instância do serviço de usuários
-func NewUserService(userRepository repositories.UserRepository) services.UserService {
+// NewUserService cria uma nova instância do serviço de usuários.
+// erasureGracePeriod é o prazo entre o pedido de exclusão da conta e o apagamento dos dados pessoais.
+// firstUserAdmin liga o comportamento legado, só aceito em desenvolvimento, em que o primeiro
+// cadastro do sistema vira administrador.
+func NewUserService(
+	userRepository repositories.UserRepository,
+	branchRepository repositories.BranchRepository,
+	erasureGracePeriod time.Duration,
+	firstUserAdmin bool,
+) services.UserService {
 	return &userService{
-		userRepository: userRepository,
+		userRepository:     userRepository,
+		branchRepository:   branchRepository,
+		erasureGracePeriod: erasureGracePeriod,
+		firstUserAdmin:     firstUserAdmin,
 	}
 }
 
-// Create cria um novo usuário
-func (userService *userService) Create(userDTO dtos.UserCreateDTO) (*dtos.UserResponseDTO, error) {
+// Create cria um novo usuário comum. Administradores são criados pelo BootstrapService ou promovidos
+// por outro administrador, exceto no comportamento legado, em que o primeiro cadastro é administrador.
+func (userService *userService) Create(ctx context.Context, userDTO dtos.UserCreateDTO) (*dtos.UserResponseDTO, error) {
 	// Verificar se o email já está em uso
-	existingUser, err := userService.userRepository.FindByEmail(userDTO.Email)
+	existingUser, err := userService.userRepository.FindByEmail(ctx, userDTO.Email)
 	if err != nil {
 		return nil, err
 	}
 	if existingUser != nil {
-		return nil, errors.New("email já está em uso")
+		return nil, domainerrors.New("email já está em uso")
 	}
 
 	// Hash da senha
@@ -46,8 +65,17 @@ func (userService *userService) Create(userDTO dtos.UserCreateDTO) (*dtos.UserRe
 		Password: string(hashedPassword),
 	}
 
+	// Comportamento legado: o primeiro usuário do sistema vira administrador
+	if userService.firstUserAdmin {
+		isFirst, err := userService.userRepository.IsFirstUser(ctx)
+		if err != nil {
+			return nil, err
+		}
+		user.IsAdmin = isFirst
+	}
+
 	// Salvar no banco de dados
-	if err := userService.userRepository.Create(&user); err != nil {
+	if err := userService.userRepository.Create(ctx, &user); err != nil {
 		return nil, err
 	}
 
@@ -57,14 +85,14 @@ func (userService *userService) Create(userDTO dtos.UserCreateDTO) (*dtos.UserRe
 	return &responseDTO, nil
 }
 
-// GetByID busca um usuário pelo ID
-func (userService *userService) GetByID(id uint) (*dtos.UserResponseDTO, error) {
-	user, err := userService.userRepository.FindByID(id)
+// GetByID busca um usuário pelo ID. Usuários removidos não são encontrados.
+func (userService *userService) GetByID(ctx context.Context, id uint) (*dtos.UserResponseDTO, error) {
+	user, err := userService.userRepository.FindByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 	if user == nil {
-		return nil, errors.New("usuário não encontrado")
+		return nil, fmt.Errorf("%w: usuário não encontrado", domainerrors.ErrNotFound)
 	}
 
 	responseDTO := dtos.ToResponseDTO(*user)
@@ -72,18 +100,18 @@ func (userService *userService) GetByID(id uint) (*dtos.UserResponseDTO, error)
 }
 
 // GetByEmail busca um usuário pelo email
-func (userService *userService) GetByEmail(email string) (*entities.User, error) {
-	return userService.userRepository.FindByEmail(email)
+func (userService *userService) GetByEmail(ctx context.Context, email string) (*entities.User, error) {
+	return userService.userRepository.FindByEmail(ctx, email)
 }
 
 // Update atualiza os dados de um usuário
-func (userService *userService) Update(id uint, userDTO dtos.UserUpdateDTO) (*dtos.UserResponseDTO, error) {
-	user, err := userService.userRepository.FindByID(id)
+func (userService *userService) Update(ctx context.Context, id uint, userDTO dtos.UserUpdateDTO) (*dtos.UserResponseDTO, error) {
+	user, err := userService.userRepository.FindByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 	if user == nil {
-		return nil, errors.New("usuário não encontrado")
+		return nil, domainerrors.New("usuário não encontrado")
 	}
 
 	// Atualizar campos se fornecidos
@@ -99,7 +127,7 @@ func (userService *userService) Update(id uint, userDTO dtos.UserUpdateDTO) (*dt
 	}
 
 	// Salvar alterações
-	if err := userService.userRepository.Update(user); err != nil {
+	if err := userService.userRepository.Update(ctx, user); err != nil {
 		return nil, err
 	}
 
@@ -108,13 +136,92 @@ func (userService *userService) Update(id uint, userDTO dtos.UserUpdateDTO) (*dt
 }
 
 // Delete remove um usuário
-func (userService *userService) Delete(id uint) error {
-	return userService.userRepository.Delete(id)
+func (userService *userService) Delete(ctx context.Context, id uint) error {
+	return userService.userRepository.Delete(ctx, id)
+}
+
+// RequestDeletion atende ao pedido de exclusão da própria conta: o acesso é encerrado na hora
+// e os dados pessoais são apagados ao fim do prazo de carência, se a remoção não for desfeita
+func (userService *userService) RequestDeletion(ctx context.Context, id uint) (*dtos.UserResponseDTO, error) {
+	eraseAt := time.Now().Add(userService.erasureGracePeriod)
+	if err := userService.userRepository.ScheduleErasure(ctx, id, eraseAt); err != nil {
+		return nil, err
+	}
+
+	return userService.findDeleted(ctx, id)
+}
+
+// ListDeleted retorna os usuários removidos
+func (userService *userService) ListDeleted(ctx context.Context) ([]dtos.UserResponseDTO, error) {
+	users, err := userService.userRepository.ListDeleted(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	userDTOs := []dtos.UserResponseDTO{}
+	for _, user := range users {
+		userDTOs = append(userDTOs, dtos.ToResponseDTO(*user))
+	}
+
+	return userDTOs, nil
+}
+
+// Restore desfaz a remoção de um usuário, cancelando o apagamento agendado
+func (userService *userService) Restore(ctx context.Context, id uint) (*dtos.UserResponseDTO, error) {
+	if err := userService.userRepository.Restore(ctx, id); err != nil {
+		return nil, err
+	}
+
+	return userService.GetByID(ctx, id)
+}
+
+// Erase apaga imediatamente os dados pessoais de um usuário, mantendo o histórico de empréstimos
+func (userService *userService) Erase(ctx context.Context, id uint) (*dtos.UserResponseDTO, error) {
+	if err := userService.userRepository.Erase(ctx, id, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return userService.findDeleted(ctx, id)
+}
+
+// EraseDue apaga os dados dos usuários cujo prazo de carência terminou e retorna quantos foram apagados.
+// Usuários que não puderem ser apagados agora são tentados novamente na próxima execução.
+func (userService *userService) EraseDue(ctx context.Context) (int, error) {
+	ids, err := userService.userRepository.ListErasureDue(ctx, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	erased := 0
+	var errs []error
+	for _, id := range ids {
+		if err := userService.userRepository.Erase(ctx, id, time.Now()); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		erased++
+	}
+
+	return erased, errors.Join(errs...)
+}
+
+// findDeleted busca um usuário, mesmo que removido
+func (userService *userService) findDeleted(ctx context.Context, id uint) (*dtos.UserResponseDTO, error) {
+	user, err := userService.userRepository.FindByIDWithDeleted(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, domainerrors.New("usuário não encontrado")
+	}
+
+	responseDTO := dtos.ToResponseDTO(*user)
+	return &responseDTO, nil
 }
 
 // List retorna todos os usuários
-func (userService *userService) List() ([]dtos.UserResponseDTO, error) {
-	users, err := userService.userRepository.List()
+func (userService *userService) List(ctx context.Context) ([]dtos.UserResponseDTO, error) {
+	users, err := userService.userRepository.List(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -128,17 +235,81 @@ func (userService *userService) List() ([]dtos.UserResponseDTO, error) {
 }
 
 // PromoteToAdmin promove um usuário para administrador
-func (userService *userService) PromoteToAdmin(id uint) (*dtos.UserResponseDTO, error) {
-	if err := userService.userRepository.PromoteToAdmin(id); err != nil {
+func (userService *userService) PromoteToAdmin(ctx context.Context, id uint) (*dtos.UserResponseDTO, error) {
+	if err := userService.userRepository.PromoteToAdmin(ctx, id); err != nil {
+		return nil, err
+	}
+
+	user, err := userService.userRepository.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, domainerrors.New("usuário não encontrado")
+	}
+
+	responseDTO := dtos.ToResponseDTO(*user)
+	return &responseDTO, nil
+}
+
+// DemoteFromAdmin remove o papel de administrador de um usuário.
+// O último administrador ativo não pode ser rebaixado, para que o sistema não fique sem nenhum.
+func (userService *userService) DemoteFromAdmin(ctx context.Context, id uint) (*dtos.UserResponseDTO, error) {
+	user, err := userService.userRepository.FindByID(ctx, id)
+	if err != nil {
 		return nil, err
 	}
+	if user == nil {
+		return nil, domainerrors.New("usuário não encontrado")
+	}
+
+	if user.IsAdmin {
+		admins, err := userService.userRepository.CountAdmins(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if admins <= 1 {
+			return nil, fmt.Errorf("%w: o último administrador não pode ser rebaixado", domainerrors.ErrConflict)
+		}
+
+		user.IsAdmin = false
+		if err := userService.userRepository.Update(ctx, user); err != nil {
+			return nil, err
+		}
+	}
+
+	responseDTO := dtos.ToResponseDTO(*user)
+	return &responseDTO, nil
+}
 
-	user, err := userService.userRepository.FindByID(id)
+// AssignBranch define a unidade e o papel de bibliotecário de um usuário
+func (userService *userService) AssignBranch(ctx context.Context, id uint, branchDTO dtos.UserBranchDTO) (*dtos.UserResponseDTO, error) {
+	user, err := userService.userRepository.FindByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 	if user == nil {
-		return nil, errors.New("usuário não encontrado")
+		return nil, domainerrors.New("usuário não encontrado")
+	}
+
+	if branchDTO.IsLibrarian && branchDTO.BranchID == nil {
+		return nil, domainerrors.New("bibliotecário precisa estar vinculado a uma unidade")
+	}
+	if branchDTO.BranchID != nil {
+		branch, err := userService.branchRepository.FindByID(ctx, *branchDTO.BranchID)
+		if err != nil {
+			return nil, err
+		}
+		if branch == nil {
+			return nil, domainerrors.New("unidade não encontrada")
+		}
+	}
+
+	user.BranchID = branchDTO.BranchID
+	user.IsLibrarian = branchDTO.IsLibrarian
+
+	if err := userService.userRepository.Update(ctx, user); err != nil {
+		return nil, err
 	}
 
 	responseDTO := dtos.ToResponseDTO(*user)
@@ -146,19 +317,19 @@ func (userService *userService) PromoteToAdmin(id uint) (*dtos.UserResponseDTO,
 }
 
 // AuthenticateUser autentica um usuário pelo email e senha
-func (userService *userService) AuthenticateUser(email, password string) (*entities.User, error) {
-	user, err := userService.userRepository.FindByEmail(email)
+func (userService *userService) AuthenticateUser(ctx context.Context, email, password string) (*entities.User, error) {
+	user, err := userService.userRepository.FindByEmail(ctx, email)
 	if err != nil {
 		return nil, err
 	}
 	if user == nil {
-		return nil, errors.New("credenciais inválidas")
+		return nil, domainerrors.New("credenciais inválidas")
 	}
 
 	// Verificar senha
 	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
 	if err != nil {
-		return nil, errors.New("credenciais inválidas")
+		return nil, domainerrors.New("credenciais inválidas")
 	}
 
 	return user, nil
@@ -0,0 +1,58 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/services"
+)
+
+// tracedAuditService registra um span para cada método de AuditService
+type tracedAuditService struct {
+	next services.AuditService
+}
+
+// NewAuditService envolve o serviço da trilha de auditoria com rastreamento
+func NewAuditService(next services.AuditService) services.AuditService {
+	return &tracedAuditService{next: next}
+}
+
+// Record rastreia AuditService.Record
+func (tracedAuditService *tracedAuditService) Record(ctx context.Context, record dtos.AuditRecordDTO) error {
+	ctx, span := start(ctx, "AuditService.Record")
+	err := tracedAuditService.next.Record(ctx, record)
+	end(span, err)
+	return err
+}
+
+// Snapshot rastreia AuditService.Snapshot
+func (tracedAuditService *tracedAuditService) Snapshot(ctx context.Context, targetType string, targetID string) (interface{}, error) {
+	ctx, span := start(ctx, "AuditService.Snapshot")
+	result, err := tracedAuditService.next.Snapshot(ctx, targetType, targetID)
+	end(span, err)
+	return result, err
+}
+
+// List rastreia AuditService.List
+func (tracedAuditService *tracedAuditService) List(ctx context.Context, filterDTO dtos.AuditListFilterDTO) (*dtos.AuditEventPageDTO, error) {
+	ctx, span := start(ctx, "AuditService.List")
+	result, err := tracedAuditService.next.List(ctx, filterDTO)
+	end(span, err)
+	return result, err
+}
+
+// ListByUser rastreia AuditService.ListByUser
+func (tracedAuditService *tracedAuditService) ListByUser(ctx context.Context, userID uint) ([]dtos.AuditEventResponseDTO, error) {
+	ctx, span := start(ctx, "AuditService.ListByUser")
+	result, err := tracedAuditService.next.ListByUser(ctx, userID)
+	end(span, err)
+	return result, err
+}
+
+// Verify rastreia AuditService.Verify
+func (tracedAuditService *tracedAuditService) Verify(ctx context.Context) (*dtos.AuditVerificationDTO, error) {
+	ctx, span := start(ctx, "AuditService.Verify")
+	result, err := tracedAuditService.next.Verify(ctx)
+	end(span, err)
+	return result, err
+}
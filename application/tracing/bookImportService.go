@@ -0,0 +1,51 @@
+package tracing
+
+import (
+	"context"
+	"io"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/services"
+)
+
+// tracedBookImportService registra um span para cada método de BookImportService
+type tracedBookImportService struct {
+	next services.BookImportService
+}
+
+// NewBookImportService envolve o serviço de importação de livros com rastreamento
+func NewBookImportService(next services.BookImportService) services.BookImportService {
+	return &tracedBookImportService{next: next}
+}
+
+// Import rastreia BookImportService.Import
+func (tracedBookImportService *tracedBookImportService) Import(ctx context.Context, format string, dryRun bool, input io.Reader) (*dtos.ImportReportDTO, error) {
+	ctx, span := start(ctx, "BookImportService.Import")
+	result, err := tracedBookImportService.next.Import(ctx, format, dryRun, input)
+	end(span, err)
+	return result, err
+}
+
+// StartImport rastreia BookImportService.StartImport
+func (tracedBookImportService *tracedBookImportService) StartImport(ctx context.Context, requestedByID uint, format string, dryRun bool, path string) (*dtos.ImportJobResponseDTO, error) {
+	ctx, span := start(ctx, "BookImportService.StartImport")
+	result, err := tracedBookImportService.next.StartImport(ctx, requestedByID, format, dryRun, path)
+	end(span, err)
+	return result, err
+}
+
+// GetJob rastreia BookImportService.GetJob
+func (tracedBookImportService *tracedBookImportService) GetJob(ctx context.Context, id uint) (*dtos.ImportJobResponseDTO, error) {
+	ctx, span := start(ctx, "BookImportService.GetJob")
+	result, err := tracedBookImportService.next.GetJob(ctx, id)
+	end(span, err)
+	return result, err
+}
+
+// ListJobs rastreia BookImportService.ListJobs
+func (tracedBookImportService *tracedBookImportService) ListJobs(ctx context.Context) ([]dtos.ImportJobResponseDTO, error) {
+	ctx, span := start(ctx, "BookImportService.ListJobs")
+	result, err := tracedBookImportService.next.ListJobs(ctx)
+	end(span, err)
+	return result, err
+}
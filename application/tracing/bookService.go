@@ -0,0 +1,131 @@
+package tracing
+
+import (
+	"context"
+	"io"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/services"
+)
+
+// tracedBookService registra um span para cada método de BookService
+type tracedBookService struct {
+	next services.BookService
+}
+
+// NewBookService envolve o serviço de livros com rastreamento
+func NewBookService(next services.BookService) services.BookService {
+	return &tracedBookService{next: next}
+}
+
+// Create rastreia BookService.Create
+func (tracedBookService *tracedBookService) Create(ctx context.Context, bookDTO dtos.BookCreateDTO) (*dtos.BookResponseDTO, error) {
+	ctx, span := start(ctx, "BookService.Create")
+	result, err := tracedBookService.next.Create(ctx, bookDTO)
+	end(span, err)
+	return result, err
+}
+
+// GetByID rastreia BookService.GetByID
+func (tracedBookService *tracedBookService) GetByID(ctx context.Context, id uint) (*dtos.BookResponseDTO, error) {
+	ctx, span := start(ctx, "BookService.GetByID")
+	result, err := tracedBookService.next.GetByID(ctx, id)
+	end(span, err)
+	return result, err
+}
+
+// GetByISBN rastreia BookService.GetByISBN
+func (tracedBookService *tracedBookService) GetByISBN(ctx context.Context, isbn string) (*dtos.BookResponseDTO, error) {
+	ctx, span := start(ctx, "BookService.GetByISBN")
+	result, err := tracedBookService.next.GetByISBN(ctx, isbn)
+	end(span, err)
+	return result, err
+}
+
+// List rastreia BookService.List
+func (tracedBookService *tracedBookService) List(ctx context.Context, filterDTO dtos.BookListFilterDTO) ([]dtos.BookResponseDTO, error) {
+	ctx, span := start(ctx, "BookService.List")
+	result, err := tracedBookService.next.List(ctx, filterDTO)
+	end(span, err)
+	return result, err
+}
+
+// AdminList rastreia BookService.AdminList
+func (tracedBookService *tracedBookService) AdminList(ctx context.Context, filterDTO dtos.AdminBookListFilterDTO) ([]dtos.BookResponseDTO, error) {
+	ctx, span := start(ctx, "BookService.AdminList")
+	result, err := tracedBookService.next.AdminList(ctx, filterDTO)
+	end(span, err)
+	return result, err
+}
+
+// Export rastreia BookService.Export
+func (tracedBookService *tracedBookService) Export(ctx context.Context, filterDTO dtos.BookListFilterDTO, format string, output io.Writer) error {
+	ctx, span := start(ctx, "BookService.Export")
+	err := tracedBookService.next.Export(ctx, filterDTO, format, output)
+	end(span, err)
+	return err
+}
+
+// Update rastreia BookService.Update
+func (tracedBookService *tracedBookService) Update(ctx context.Context, id uint, bookDTO dtos.BookUpdateDTO) (*dtos.BookResponseDTO, error) {
+	ctx, span := start(ctx, "BookService.Update")
+	result, err := tracedBookService.next.Update(ctx, id, bookDTO)
+	end(span, err)
+	return result, err
+}
+
+// AddCopies rastreia BookService.AddCopies
+func (tracedBookService *tracedBookService) AddCopies(ctx context.Context, id uint, quantity int) (*dtos.BookResponseDTO, error) {
+	ctx, span := start(ctx, "BookService.AddCopies")
+	result, err := tracedBookService.next.AddCopies(ctx, id, quantity)
+	end(span, err)
+	return result, err
+}
+
+// Withdraw rastreia BookService.Withdraw
+func (tracedBookService *tracedBookService) Withdraw(ctx context.Context, id uint) (*dtos.BookResponseDTO, error) {
+	ctx, span := start(ctx, "BookService.Withdraw")
+	result, err := tracedBookService.next.Withdraw(ctx, id)
+	end(span, err)
+	return result, err
+}
+
+// Reinstate rastreia BookService.Reinstate
+func (tracedBookService *tracedBookService) Reinstate(ctx context.Context, id uint) (*dtos.BookResponseDTO, error) {
+	ctx, span := start(ctx, "BookService.Reinstate")
+	result, err := tracedBookService.next.Reinstate(ctx, id)
+	end(span, err)
+	return result, err
+}
+
+// Delete rastreia BookService.Delete
+func (tracedBookService *tracedBookService) Delete(ctx context.Context, id uint) error {
+	ctx, span := start(ctx, "BookService.Delete")
+	err := tracedBookService.next.Delete(ctx, id)
+	end(span, err)
+	return err
+}
+
+// ListDeleted rastreia BookService.ListDeleted
+func (tracedBookService *tracedBookService) ListDeleted(ctx context.Context) ([]dtos.BookResponseDTO, error) {
+	ctx, span := start(ctx, "BookService.ListDeleted")
+	result, err := tracedBookService.next.ListDeleted(ctx)
+	end(span, err)
+	return result, err
+}
+
+// Restore rastreia BookService.Restore
+func (tracedBookService *tracedBookService) Restore(ctx context.Context, id uint) (*dtos.BookResponseDTO, error) {
+	ctx, span := start(ctx, "BookService.Restore")
+	result, err := tracedBookService.next.Restore(ctx, id)
+	end(span, err)
+	return result, err
+}
+
+// ReconcileInventory rastreia BookService.ReconcileInventory
+func (tracedBookService *tracedBookService) ReconcileInventory(ctx context.Context, repair bool) (*dtos.InventoryReportDTO, error) {
+	ctx, span := start(ctx, "BookService.ReconcileInventory")
+	result, err := tracedBookService.next.ReconcileInventory(ctx, repair)
+	end(span, err)
+	return result, err
+}
@@ -0,0 +1,42 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/services"
+)
+
+// tracedBootstrapService registra um span para cada método de BootstrapService
+type tracedBootstrapService struct {
+	next services.BootstrapService
+}
+
+// NewBootstrapService envolve o serviço de criação de administradores com rastreamento
+func NewBootstrapService(next services.BootstrapService) services.BootstrapService {
+	return &tracedBootstrapService{next: next}
+}
+
+// IssueSetupToken rastreia BootstrapService.IssueSetupToken
+func (tracedBootstrapService *tracedBootstrapService) IssueSetupToken(ctx context.Context) (string, error) {
+	ctx, span := start(ctx, "BootstrapService.IssueSetupToken")
+	result, err := tracedBootstrapService.next.IssueSetupToken(ctx)
+	end(span, err)
+	return result, err
+}
+
+// CompleteSetup rastreia BootstrapService.CompleteSetup
+func (tracedBootstrapService *tracedBootstrapService) CompleteSetup(ctx context.Context, setupDTO dtos.SetupDTO) (*dtos.UserResponseDTO, error) {
+	ctx, span := start(ctx, "BootstrapService.CompleteSetup")
+	result, err := tracedBootstrapService.next.CompleteSetup(ctx, setupDTO)
+	end(span, err)
+	return result, err
+}
+
+// CreateAdmin rastreia BootstrapService.CreateAdmin
+func (tracedBootstrapService *tracedBootstrapService) CreateAdmin(ctx context.Context, userDTO dtos.UserCreateDTO) (*dtos.UserResponseDTO, bool, error) {
+	ctx, span := start(ctx, "BootstrapService.CreateAdmin")
+	result, created, err := tracedBootstrapService.next.CreateAdmin(ctx, userDTO)
+	end(span, err)
+	return result, created, err
+}
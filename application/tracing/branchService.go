@@ -0,0 +1,98 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/services"
+)
+
+// tracedBranchService registra um span para cada método de BranchService
+type tracedBranchService struct {
+	next services.BranchService
+}
+
+// NewBranchService envolve o serviço de unidades com rastreamento
+func NewBranchService(next services.BranchService) services.BranchService {
+	return &tracedBranchService{next: next}
+}
+
+// Create rastreia BranchService.Create
+func (tracedBranchService *tracedBranchService) Create(ctx context.Context, branchDTO dtos.BranchCreateDTO) (*dtos.BranchResponseDTO, error) {
+	ctx, span := start(ctx, "BranchService.Create")
+	result, err := tracedBranchService.next.Create(ctx, branchDTO)
+	end(span, err)
+	return result, err
+}
+
+// GetByID rastreia BranchService.GetByID
+func (tracedBranchService *tracedBranchService) GetByID(ctx context.Context, id uint) (*dtos.BranchResponseDTO, error) {
+	ctx, span := start(ctx, "BranchService.GetByID")
+	result, err := tracedBranchService.next.GetByID(ctx, id)
+	end(span, err)
+	return result, err
+}
+
+// List rastreia BranchService.List
+func (tracedBranchService *tracedBranchService) List(ctx context.Context) ([]dtos.BranchResponseDTO, error) {
+	ctx, span := start(ctx, "BranchService.List")
+	result, err := tracedBranchService.next.List(ctx)
+	end(span, err)
+	return result, err
+}
+
+// Update rastreia BranchService.Update
+func (tracedBranchService *tracedBranchService) Update(ctx context.Context, id uint, branchDTO dtos.BranchUpdateDTO) (*dtos.BranchResponseDTO, error) {
+	ctx, span := start(ctx, "BranchService.Update")
+	result, err := tracedBranchService.next.Update(ctx, id, branchDTO)
+	end(span, err)
+	return result, err
+}
+
+// Delete rastreia BranchService.Delete
+func (tracedBranchService *tracedBranchService) Delete(ctx context.Context, id uint) error {
+	ctx, span := start(ctx, "BranchService.Delete")
+	err := tracedBranchService.next.Delete(ctx, id)
+	end(span, err)
+	return err
+}
+
+// SetHolding rastreia BranchService.SetHolding
+func (tracedBranchService *tracedBranchService) SetHolding(ctx context.Context, bookID, branchID uint, holdingDTO dtos.HoldingUpdateDTO) (*dtos.BookResponseDTO, error) {
+	ctx, span := start(ctx, "BranchService.SetHolding")
+	result, err := tracedBranchService.next.SetHolding(ctx, bookID, branchID, holdingDTO)
+	end(span, err)
+	return result, err
+}
+
+// RequestTransfer rastreia BranchService.RequestTransfer
+func (tracedBranchService *tracedBranchService) RequestTransfer(ctx context.Context, staffID uint, transferDTO dtos.TransferCreateDTO) (*dtos.TransferResponseDTO, error) {
+	ctx, span := start(ctx, "BranchService.RequestTransfer")
+	result, err := tracedBranchService.next.RequestTransfer(ctx, staffID, transferDTO)
+	end(span, err)
+	return result, err
+}
+
+// ListTransfers rastreia BranchService.ListTransfers
+func (tracedBranchService *tracedBranchService) ListTransfers(ctx context.Context, staffID uint) ([]dtos.TransferResponseDTO, error) {
+	ctx, span := start(ctx, "BranchService.ListTransfers")
+	result, err := tracedBranchService.next.ListTransfers(ctx, staffID)
+	end(span, err)
+	return result, err
+}
+
+// ApproveTransfer rastreia BranchService.ApproveTransfer
+func (tracedBranchService *tracedBranchService) ApproveTransfer(ctx context.Context, id uint, staffID uint) (*dtos.TransferResponseDTO, error) {
+	ctx, span := start(ctx, "BranchService.ApproveTransfer")
+	result, err := tracedBranchService.next.ApproveTransfer(ctx, id, staffID)
+	end(span, err)
+	return result, err
+}
+
+// RejectTransfer rastreia BranchService.RejectTransfer
+func (tracedBranchService *tracedBranchService) RejectTransfer(ctx context.Context, id uint, staffID uint) (*dtos.TransferResponseDTO, error) {
+	ctx, span := start(ctx, "BranchService.RejectTransfer")
+	result, err := tracedBranchService.next.RejectTransfer(ctx, id, staffID)
+	end(span, err)
+	return result, err
+}
@@ -0,0 +1,76 @@
+package tracing
+
+import (
+	"context"
+	"io"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/services"
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+)
+
+// tracedDataExportService registra um span para cada método de DataExportService
+type tracedDataExportService struct {
+	next services.DataExportService
+}
+
+// NewDataExportService envolve o serviço de exportação de dados pessoais com rastreamento
+func NewDataExportService(next services.DataExportService) services.DataExportService {
+	return &tracedDataExportService{next: next}
+}
+
+// RequiresAsync rastreia DataExportService.RequiresAsync
+func (tracedDataExportService *tracedDataExportService) RequiresAsync(ctx context.Context, userID uint) (bool, error) {
+	ctx, span := start(ctx, "DataExportService.RequiresAsync")
+	result, err := tracedDataExportService.next.RequiresAsync(ctx, userID)
+	end(span, err)
+	return result, err
+}
+
+// WriteBundle rastreia DataExportService.WriteBundle
+func (tracedDataExportService *tracedDataExportService) WriteBundle(ctx context.Context, userID uint, format string, output io.Writer) error {
+	ctx, span := start(ctx, "DataExportService.WriteBundle")
+	err := tracedDataExportService.next.WriteBundle(ctx, userID, format, output)
+	end(span, err)
+	return err
+}
+
+// StartExport rastreia DataExportService.StartExport
+func (tracedDataExportService *tracedDataExportService) StartExport(ctx context.Context, userID uint, format string) (*dtos.DataExportResponseDTO, error) {
+	ctx, span := start(ctx, "DataExportService.StartExport")
+	result, err := tracedDataExportService.next.StartExport(ctx, userID, format)
+	end(span, err)
+	return result, err
+}
+
+// GetExport rastreia DataExportService.GetExport
+func (tracedDataExportService *tracedDataExportService) GetExport(ctx context.Context, userID uint, id uint) (*dtos.DataExportResponseDTO, error) {
+	ctx, span := start(ctx, "DataExportService.GetExport")
+	result, err := tracedDataExportService.next.GetExport(ctx, userID, id)
+	end(span, err)
+	return result, err
+}
+
+// ListExports rastreia DataExportService.ListExports
+func (tracedDataExportService *tracedDataExportService) ListExports(ctx context.Context, userID uint) ([]dtos.DataExportResponseDTO, error) {
+	ctx, span := start(ctx, "DataExportService.ListExports")
+	result, err := tracedDataExportService.next.ListExports(ctx, userID)
+	end(span, err)
+	return result, err
+}
+
+// OpenDownload rastreia DataExportService.OpenDownload
+func (tracedDataExportService *tracedDataExportService) OpenDownload(ctx context.Context, id uint, expires int64, signature string) (*entities.DataExport, error) {
+	ctx, span := start(ctx, "DataExportService.OpenDownload")
+	result, err := tracedDataExportService.next.OpenDownload(ctx, id, expires, signature)
+	end(span, err)
+	return result, err
+}
+
+// DeleteExpired rastreia DataExportService.DeleteExpired
+func (tracedDataExportService *tracedDataExportService) DeleteExpired(ctx context.Context) (int, error) {
+	ctx, span := start(ctx, "DataExportService.DeleteExpired")
+	result, err := tracedDataExportService.next.DeleteExpired(ctx)
+	end(span, err)
+	return result, err
+}
@@ -0,0 +1,51 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/services"
+)
+
+// tracedJobService registra um span para cada método de JobService
+type tracedJobService struct {
+	next services.JobService
+}
+
+// NewJobService envolve o serviço de tarefas agendadas com rastreamento
+func NewJobService(next services.JobService) services.JobService {
+	return &tracedJobService{next: next}
+}
+
+// List rastreia JobService.List
+func (tracedJobService *tracedJobService) List(ctx context.Context) ([]dtos.JobDTO, error) {
+	ctx, span := start(ctx, "JobService.List")
+	result, err := tracedJobService.next.List(ctx)
+	end(span, err)
+	return result, err
+}
+
+// ListRuns rastreia JobService.ListRuns
+func (tracedJobService *tracedJobService) ListRuns(ctx context.Context, filter dtos.JobRunFilterDTO) ([]dtos.JobRunDTO, error) {
+	ctx, span := start(ctx, "JobService.ListRuns")
+	result, err := tracedJobService.next.ListRuns(ctx, filter)
+	end(span, err)
+	return result, err
+}
+
+// Run rastreia JobService.Run
+func (tracedJobService *tracedJobService) Run(ctx context.Context, name string) (*dtos.JobRunDTO, error) {
+	ctx, span := start(ctx, "JobService.Run")
+	result, err := tracedJobService.next.Run(ctx, name)
+	end(span, err)
+	return result, err
+}
+
+// PruneHistory rastreia JobService.PruneHistory
+func (tracedJobService *tracedJobService) PruneHistory(ctx context.Context, retention time.Duration) (int64, error) {
+	ctx, span := start(ctx, "JobService.PruneHistory")
+	result, err := tracedJobService.next.PruneHistory(ctx, retention)
+	end(span, err)
+	return result, err
+}
@@ -0,0 +1,26 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/services"
+)
+
+// tracedLibraryStatsService registra um span para cada método de LibraryStatsService
+type tracedLibraryStatsService struct {
+	next services.LibraryStatsService
+}
+
+// NewLibraryStatsService envolve o serviço de indicadores com rastreamento
+func NewLibraryStatsService(next services.LibraryStatsService) services.LibraryStatsService {
+	return &tracedLibraryStatsService{next: next}
+}
+
+// Collect rastreia LibraryStatsService.Collect
+func (tracedLibraryStatsService *tracedLibraryStatsService) Collect(ctx context.Context) (*dtos.LibraryStatsDTO, error) {
+	ctx, span := start(ctx, "LibraryStatsService.Collect")
+	result, err := tracedLibraryStatsService.next.Collect(ctx)
+	end(span, err)
+	return result, err
+}
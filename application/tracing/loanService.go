@@ -0,0 +1,82 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/services"
+)
+
+// tracedLoanService registra um span para cada método de LoanService
+type tracedLoanService struct {
+	next services.LoanService
+}
+
+// NewLoanService envolve o serviço de empréstimos com rastreamento
+func NewLoanService(next services.LoanService) services.LoanService {
+	return &tracedLoanService{next: next}
+}
+
+// Create rastreia LoanService.Create
+func (tracedLoanService *tracedLoanService) Create(ctx context.Context, userID uint, loanDTO dtos.LoanCreateDTO) (*dtos.LoanResponseDTO, error) {
+	ctx, span := start(ctx, "LoanService.Create")
+	result, err := tracedLoanService.next.Create(ctx, userID, loanDTO)
+	end(span, err)
+	return result, err
+}
+
+// GetByID rastreia LoanService.GetByID
+func (tracedLoanService *tracedLoanService) GetByID(ctx context.Context, id uint, userID uint) (*dtos.LoanResponseDTO, error) {
+	ctx, span := start(ctx, "LoanService.GetByID")
+	result, err := tracedLoanService.next.GetByID(ctx, id, userID)
+	end(span, err)
+	return result, err
+}
+
+// ListByUser rastreia LoanService.ListByUser
+func (tracedLoanService *tracedLoanService) ListByUser(ctx context.Context, userID uint) ([]dtos.LoanResponseDTO, error) {
+	ctx, span := start(ctx, "LoanService.ListByUser")
+	result, err := tracedLoanService.next.ListByUser(ctx, userID)
+	end(span, err)
+	return result, err
+}
+
+// ReturnLoan rastreia LoanService.ReturnLoan
+func (tracedLoanService *tracedLoanService) ReturnLoan(ctx context.Context, id uint, userID uint) (*dtos.LoanResponseDTO, error) {
+	ctx, span := start(ctx, "LoanService.ReturnLoan")
+	result, err := tracedLoanService.next.ReturnLoan(ctx, id, userID)
+	end(span, err)
+	return result, err
+}
+
+// CheckOut rastreia LoanService.CheckOut
+func (tracedLoanService *tracedLoanService) CheckOut(ctx context.Context, staffID uint, loanDTO dtos.StaffLoanCreateDTO) (*dtos.LoanResponseDTO, error) {
+	ctx, span := start(ctx, "LoanService.CheckOut")
+	result, err := tracedLoanService.next.CheckOut(ctx, staffID, loanDTO)
+	end(span, err)
+	return result, err
+}
+
+// CheckIn rastreia LoanService.CheckIn
+func (tracedLoanService *tracedLoanService) CheckIn(ctx context.Context, id uint, staffID uint) (*dtos.LoanResponseDTO, error) {
+	ctx, span := start(ctx, "LoanService.CheckIn")
+	result, err := tracedLoanService.next.CheckIn(ctx, id, staffID)
+	end(span, err)
+	return result, err
+}
+
+// ForceReturn rastreia LoanService.ForceReturn
+func (tracedLoanService *tracedLoanService) ForceReturn(ctx context.Context, id uint) (*dtos.LoanResponseDTO, error) {
+	ctx, span := start(ctx, "LoanService.ForceReturn")
+	result, err := tracedLoanService.next.ForceReturn(ctx, id)
+	end(span, err)
+	return result, err
+}
+
+// ListOverdue rastreia LoanService.ListOverdue
+func (tracedLoanService *tracedLoanService) ListOverdue(ctx context.Context) ([]dtos.LoanResponseDTO, error) {
+	ctx, span := start(ctx, "LoanService.ListOverdue")
+	result, err := tracedLoanService.next.ListOverdue(ctx)
+	end(span, err)
+	return result, err
+}
@@ -0,0 +1,66 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/services"
+)
+
+// tracedNotificationService registra um span para cada método de NotificationService
+type tracedNotificationService struct {
+	next services.NotificationService
+}
+
+// NewNotificationService envolve o serviço de notificações com rastreamento
+func NewNotificationService(next services.NotificationService) services.NotificationService {
+	return &tracedNotificationService{next: next}
+}
+
+// SendLoanReminders rastreia NotificationService.SendLoanReminders
+func (tracedNotificationService *tracedNotificationService) SendLoanReminders(ctx context.Context) (*dtos.NotificationScanResultDTO, error) {
+	ctx, span := start(ctx, "NotificationService.SendLoanReminders")
+	result, err := tracedNotificationService.next.SendLoanReminders(ctx)
+	end(span, err)
+	return result, err
+}
+
+// Inbox rastreia NotificationService.Inbox
+func (tracedNotificationService *tracedNotificationService) Inbox(ctx context.Context, userID uint, filter dtos.NotificationFilterDTO) (*dtos.NotificationInboxDTO, error) {
+	ctx, span := start(ctx, "NotificationService.Inbox")
+	result, err := tracedNotificationService.next.Inbox(ctx, userID, filter)
+	end(span, err)
+	return result, err
+}
+
+// MarkRead rastreia NotificationService.MarkRead
+func (tracedNotificationService *tracedNotificationService) MarkRead(ctx context.Context, userID uint, id uint) error {
+	ctx, span := start(ctx, "NotificationService.MarkRead")
+	err := tracedNotificationService.next.MarkRead(ctx, userID, id)
+	end(span, err)
+	return err
+}
+
+// MarkAllRead rastreia NotificationService.MarkAllRead
+func (tracedNotificationService *tracedNotificationService) MarkAllRead(ctx context.Context, userID uint) (int64, error) {
+	ctx, span := start(ctx, "NotificationService.MarkAllRead")
+	result, err := tracedNotificationService.next.MarkAllRead(ctx, userID)
+	end(span, err)
+	return result, err
+}
+
+// GetPreferences rastreia NotificationService.GetPreferences
+func (tracedNotificationService *tracedNotificationService) GetPreferences(ctx context.Context, userID uint) (*dtos.NotificationPreferencesDTO, error) {
+	ctx, span := start(ctx, "NotificationService.GetPreferences")
+	result, err := tracedNotificationService.next.GetPreferences(ctx, userID)
+	end(span, err)
+	return result, err
+}
+
+// UpdatePreferences rastreia NotificationService.UpdatePreferences
+func (tracedNotificationService *tracedNotificationService) UpdatePreferences(ctx context.Context, userID uint, preferences dtos.NotificationPreferencesUpdateDTO) (*dtos.NotificationPreferencesDTO, error) {
+	ctx, span := start(ctx, "NotificationService.UpdatePreferences")
+	result, err := tracedNotificationService.next.UpdatePreferences(ctx, userID, preferences)
+	end(span, err)
+	return result, err
+}
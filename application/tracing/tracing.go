@@ -0,0 +1,48 @@
+// Package tracing envolve os serviços da aplicação com spans do OpenTelemetry.
+// Cada método dos serviços gera um span filho do span da requisição, e as consultas
+// feitas pelos repositórios aparecem como filhos do span do serviço.
+package tracing
+
+import (
+	"context"
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	domainerrors "github.com/henrygoeszanin/api_golang_estudos/domain/errors"
+)
+
+// tracer cria os spans dos serviços; usa o provedor global configurado na inicialização
+var tracer = otel.Tracer("github.com/henrygoeszanin/api_golang_estudos/application/services")
+
+// start inicia o span de um método de serviço
+func start(ctx context.Context, name string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name)
+}
+
+// end encerra o span, registrando o erro retornado pelo método.
+// Erros de domínio (não encontrado, conflito, dados inválidos...) são respostas esperadas
+// e não marcam o span como falho.
+func end(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		if !isDomainError(err) {
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+	span.End()
+}
+
+// isDomainError indica se o erro representa uma regra de negócio e não uma falha
+func isDomainError(err error) bool {
+	var ruleError *domainerrors.RuleError
+	return errors.As(err, &ruleError) ||
+		errors.Is(err, domainerrors.ErrNotFound) ||
+		errors.Is(err, domainerrors.ErrAlreadyExists) ||
+		errors.Is(err, domainerrors.ErrInvalidData) ||
+		errors.Is(err, domainerrors.ErrUnauthorized) ||
+		errors.Is(err, domainerrors.ErrForbidden) ||
+		errors.Is(err, domainerrors.ErrConflict)
+}
@@ -0,0 +1,33 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	domainerrors "github.com/henrygoeszanin/api_golang_estudos/domain/errors"
+)
+
+func TestIsDomainError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"comum", fmt.Errorf("%w: livro", domainerrors.ErrNotFound), true},
+		{"duplicado", &domainerrors.DuplicateError{ExistingID: 1, Reason: "isbn"}, true},
+		{"regra de negócio", domainerrors.New("informe ao menos um autor"), true},
+		{"regra de negócio envolvida", fmt.Errorf("linha 3: %w", domainerrors.New("quantidade deve ser maior que zero")), true},
+		{"falha", errors.New("conexão recusada"), false},
+		{"prazo excedido", context.DeadlineExceeded, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isDomainError(tt.err); got != tt.want {
+				t.Errorf("isDomainError(%q) = %v, esperado %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/services"
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+)
+
+// tracedUserService registra um span para cada método de UserService
+type tracedUserService struct {
+	next services.UserService
+}
+
+// NewUserService envolve o serviço de usuários com rastreamento
+func NewUserService(next services.UserService) services.UserService {
+	return &tracedUserService{next: next}
+}
+
+// Create rastreia UserService.Create
+func (tracedUserService *tracedUserService) Create(ctx context.Context, userDTO dtos.UserCreateDTO) (*dtos.UserResponseDTO, error) {
+	ctx, span := start(ctx, "UserService.Create")
+	result, err := tracedUserService.next.Create(ctx, userDTO)
+	end(span, err)
+	return result, err
+}
+
+// GetByID rastreia UserService.GetByID
+func (tracedUserService *tracedUserService) GetByID(ctx context.Context, id uint) (*dtos.UserResponseDTO, error) {
+	ctx, span := start(ctx, "UserService.GetByID")
+	result, err := tracedUserService.next.GetByID(ctx, id)
+	end(span, err)
+	return result, err
+}
+
+// GetByEmail rastreia UserService.GetByEmail
+func (tracedUserService *tracedUserService) GetByEmail(ctx context.Context, email string) (*entities.User, error) {
+	ctx, span := start(ctx, "UserService.GetByEmail")
+	result, err := tracedUserService.next.GetByEmail(ctx, email)
+	end(span, err)
+	return result, err
+}
+
+// Update rastreia UserService.Update
+func (tracedUserService *tracedUserService) Update(ctx context.Context, id uint, userDTO dtos.UserUpdateDTO) (*dtos.UserResponseDTO, error) {
+	ctx, span := start(ctx, "UserService.Update")
+	result, err := tracedUserService.next.Update(ctx, id, userDTO)
+	end(span, err)
+	return result, err
+}
+
+// Delete rastreia UserService.Delete
+func (tracedUserService *tracedUserService) Delete(ctx context.Context, id uint) error {
+	ctx, span := start(ctx, "UserService.Delete")
+	err := tracedUserService.next.Delete(ctx, id)
+	end(span, err)
+	return err
+}
+
+// RequestDeletion rastreia UserService.RequestDeletion
+func (tracedUserService *tracedUserService) RequestDeletion(ctx context.Context, id uint) (*dtos.UserResponseDTO, error) {
+	ctx, span := start(ctx, "UserService.RequestDeletion")
+	result, err := tracedUserService.next.RequestDeletion(ctx, id)
+	end(span, err)
+	return result, err
+}
+
+// ListDeleted rastreia UserService.ListDeleted
+func (tracedUserService *tracedUserService) ListDeleted(ctx context.Context) ([]dtos.UserResponseDTO, error) {
+	ctx, span := start(ctx, "UserService.ListDeleted")
+	result, err := tracedUserService.next.ListDeleted(ctx)
+	end(span, err)
+	return result, err
+}
+
+// Restore rastreia UserService.Restore
+func (tracedUserService *tracedUserService) Restore(ctx context.Context, id uint) (*dtos.UserResponseDTO, error) {
+	ctx, span := start(ctx, "UserService.Restore")
+	result, err := tracedUserService.next.Restore(ctx, id)
+	end(span, err)
+	return result, err
+}
+
+// Erase rastreia UserService.Erase
+func (tracedUserService *tracedUserService) Erase(ctx context.Context, id uint) (*dtos.UserResponseDTO, error) {
+	ctx, span := start(ctx, "UserService.Erase")
+	result, err := tracedUserService.next.Erase(ctx, id)
+	end(span, err)
+	return result, err
+}
+
+// EraseDue rastreia UserService.EraseDue
+func (tracedUserService *tracedUserService) EraseDue(ctx context.Context) (int, error) {
+	ctx, span := start(ctx, "UserService.EraseDue")
+	result, err := tracedUserService.next.EraseDue(ctx)
+	end(span, err)
+	return result, err
+}
+
+// List rastreia UserService.List
+func (tracedUserService *tracedUserService) List(ctx context.Context) ([]dtos.UserResponseDTO, error) {
+	ctx, span := start(ctx, "UserService.List")
+	result, err := tracedUserService.next.List(ctx)
+	end(span, err)
+	return result, err
+}
+
+// PromoteToAdmin rastreia UserService.PromoteToAdmin
+func (tracedUserService *tracedUserService) PromoteToAdmin(ctx context.Context, id uint) (*dtos.UserResponseDTO, error) {
+	ctx, span := start(ctx, "UserService.PromoteToAdmin")
+	result, err := tracedUserService.next.PromoteToAdmin(ctx, id)
+	end(span, err)
+	return result, err
+}
+
+// DemoteFromAdmin rastreia UserService.DemoteFromAdmin
+func (tracedUserService *tracedUserService) DemoteFromAdmin(ctx context.Context, id uint) (*dtos.UserResponseDTO, error) {
+	ctx, span := start(ctx, "UserService.DemoteFromAdmin")
+	result, err := tracedUserService.next.DemoteFromAdmin(ctx, id)
+	end(span, err)
+	return result, err
+}
+
+// AssignBranch rastreia UserService.AssignBranch
+func (tracedUserService *tracedUserService) AssignBranch(ctx context.Context, id uint, branchDTO dtos.UserBranchDTO) (*dtos.UserResponseDTO, error) {
+	ctx, span := start(ctx, "UserService.AssignBranch")
+	result, err := tracedUserService.next.AssignBranch(ctx, id, branchDTO)
+	end(span, err)
+	return result, err
+}
+
+// AuthenticateUser rastreia UserService.AuthenticateUser
+func (tracedUserService *tracedUserService) AuthenticateUser(ctx context.Context, email, password string) (*entities.User, error) {
+	ctx, span := start(ctx, "UserService.AuthenticateUser")
+	result, err := tracedUserService.next.AuthenticateUser(ctx, email, password)
+	end(span, err)
+	return result, err
+}
@@ -0,0 +1,67 @@
+package workers
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Pool executa tarefas em segundo plano e permite encerrá-las de forma ordenada
+type Pool struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewPool cria um novo pool de tarefas em segundo plano
+func NewPool() *Pool {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Pool{
+		ctx:    ctx,
+		cancel: cancel,
+	}
+}
+
+// Go inicia a tarefa em segundo plano. O contexto recebido é cancelado no encerramento do pool.
+func (pool *Pool) Go(task func(ctx context.Context)) {
+	pool.wg.Add(1)
+	go func() {
+		defer pool.wg.Done()
+		task(pool.ctx)
+	}()
+}
+
+// Every executa a tarefa periodicamente, no intervalo informado, até o encerramento do pool
+func (pool *Pool) Every(interval time.Duration, task func(ctx context.Context)) {
+	pool.Go(func(ctx context.Context) {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				task(ctx)
+			}
+		}
+	})
+}
+
+// Shutdown cancela as tarefas em andamento e aguarda o término delas ou o fim do contexto
+func (pool *Pool) Shutdown(ctx context.Context) error {
+	pool.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		pool.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
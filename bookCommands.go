@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	"github.com/henrygoeszanin/api_golang_estudos/application/services"
+)
+
+// runBookImport executa o subcomando book import: importa livros de um arquivo CSV ou JSON Lines,
+// com as mesmas validações da importação pela API. Termina com falha se alguma linha for recusada.
+func runBookImport(args []string) int {
+	var path, format string
+	var dryRun bool
+	tool, code := loadTool("library-api book import", args, func(flagSet *flag.FlagSet) {
+		flagSet.StringVar(&path, "file", "", "arquivo a importar")
+		flagSet.StringVar(&format, "format", "", "formato do arquivo: csv ou jsonl (padrão: pela extensão)")
+		flagSet.BoolVar(&dryRun, "dry-run", false, "apenas valida o arquivo, sem gravar nada")
+	})
+	if tool == nil {
+		return code
+	}
+
+	if path == "" {
+		fmt.Fprintln(os.Stderr, "informe o arquivo em --file")
+		return 2
+	}
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".csv":
+			format = dtos.ImportFormatCSV
+		case ".jsonl", ".ndjson":
+			format = dtos.ImportFormatJSONL
+		default:
+			fmt.Fprintf(os.Stderr, "formato de %s não reconhecido: informe --format csv ou --format jsonl\n", path)
+			return 2
+		}
+	}
+	if format != dtos.ImportFormatCSV && format != dtos.ImportFormatJSONL {
+		fmt.Fprintf(os.Stderr, "--format inválido: %q (use csv ou jsonl)\n", format)
+		return 2
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	defer file.Close()
+
+	if err := tool.open(); err != nil {
+		return tool.fail("falha ao abrir o banco de dados", err)
+	}
+	defer tool.close()
+
+	ctx := context.Background()
+	var report *dtos.ImportReportDTO
+	run := func() (uint, error) {
+		var err error
+		report, err = tool.bookImportService().Import(ctx, format, dryRun, file)
+		return 0, err
+	}
+	if dryRun {
+		_, err = run()
+	} else {
+		err = tool.audited(ctx, "book import", services.AuditTargetBook, 0, run)
+	}
+	if err != nil {
+		return tool.fail("falha na importação", err, "file", path)
+	}
+
+	rows := make([][]string, 0, len(report.Rows))
+	for _, row := range report.Rows {
+		bookID := "-"
+		if row.BookID != 0 {
+			bookID = strconv.FormatUint(uint64(row.BookID), 10)
+		}
+		rows = append(rows, []string{strconv.Itoa(row.Row), row.Status, bookID, row.ISBN, row.Title, strings.Join(row.Errors, "; ")})
+	}
+	if err := tool.print(report, []string{"LINHA", "SITUAÇÃO", "LIVRO", "ISBN", "TÍTULO", "ERROS"}, rows); err != nil {
+		return tool.fail("falha ao escrever o resultado", err)
+	}
+
+	tool.logger.Info("importação concluída", "dry_run", dryRun, "total_rows", report.TotalRows,
+		"created", report.Created, "updated", report.Updated, "skipped", report.Skipped, "failed", report.Failed)
+	if report.Failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runBookExport executa o subcomando book export: exporta o acervo, com os mesmos filtros do catálogo,
+// para o arquivo informado ou para a saída padrão
+func runBookExport(args []string) int {
+	var path, format string
+	var filterDTO dtos.BookListFilterDTO
+	tool, code := loadTool("library-api book export", args, func(flagSet *flag.FlagSet) {
+		flagSet.StringVar(&path, "file", "", "arquivo de destino (padrão: saída padrão)")
+		flagSet.StringVar(&format, "format", dtos.ExportFormatCSV, "formato: csv, json, marc ou marcxml")
+		flagSet.StringVar(&filterDTO.Title, "title", "", "filtra pelo título")
+		flagSet.StringVar(&filterDTO.Author, "author", "", "filtra pelo autor")
+		flagSet.StringVar(&filterDTO.Subject, "subject", "", "filtra pelo assunto")
+		flagSet.StringVar(&filterDTO.Language, "language", "", "filtra pelo idioma")
+		flagSet.BoolVar(&filterDTO.Available, "available", false, "apenas livros com exemplares disponíveis")
+	})
+	if tool == nil {
+		return code
+	}
+
+	switch format {
+	case dtos.ExportFormatCSV, dtos.ExportFormatJSON, dtos.ExportFormatMARC, dtos.ExportFormatMARCXML:
+	default:
+		fmt.Fprintf(os.Stderr, "--format inválido: %q (use csv, json, marc ou marcxml)\n", format)
+		return 2
+	}
+
+	if err := tool.open(); err != nil {
+		return tool.fail("falha ao abrir o banco de dados", err)
+	}
+	defer tool.close()
+
+	var output io.Writer = os.Stdout
+	if path != "" {
+		file, err := os.Create(path)
+		if err != nil {
+			return tool.fail("falha ao criar o arquivo", err, "file", path)
+		}
+		defer file.Close()
+		output = file
+	}
+
+	if err := tool.bookService().Export(context.Background(), filterDTO, format, output); err != nil {
+		return tool.fail("falha na exportação", err)
+	}
+	return 0
+}
+
+// runBookReconcile executa o subcomando book reconcile: confere os exemplares disponíveis de cada livro e
+// unidade com os empréstimos em aberto. Sem --repair, apenas lista as divergências e termina com falha se
+// houver alguma, para uso em verificações periódicas; com --repair, grava os valores calculados.
+func runBookReconcile(args []string) int {
+	var repair bool
+	tool, code := loadTool("library-api book reconcile", args, func(flagSet *flag.FlagSet) {
+		flagSet.BoolVar(&repair, "repair", false, "corrige os contadores divergentes")
+	})
+	if tool == nil {
+		return code
+	}
+
+	if err := tool.open(); err != nil {
+		return tool.fail("falha ao abrir o banco de dados", err)
+	}
+	defer tool.close()
+
+	ctx := context.Background()
+	var report *dtos.InventoryReportDTO
+	run := func() (uint, error) {
+		var err error
+		report, err = tool.bookService().ReconcileInventory(ctx, repair)
+		return 0, err
+	}
+	var err error
+	if repair {
+		err = tool.audited(ctx, "book reconcile", services.AuditTargetBook, 0, run)
+	} else {
+		_, err = run()
+	}
+	if err != nil {
+		return tool.fail("falha na conferência do acervo", err)
+	}
+
+	rows := make([][]string, 0, len(report.Discrepancies))
+	for _, discrepancy := range report.Discrepancies {
+		branch := "-"
+		if discrepancy.BranchID != nil {
+			branch = strconv.FormatUint(uint64(*discrepancy.BranchID), 10)
+		}
+		rows = append(rows, []string{
+			strconv.FormatUint(uint64(discrepancy.BookID), 10),
+			discrepancy.Title,
+			branch,
+			strconv.Itoa(discrepancy.Quantity),
+			strconv.Itoa(discrepancy.OpenLoans),
+			strconv.Itoa(discrepancy.Available),
+			strconv.Itoa(discrepancy.Expected),
+		})
+	}
+	if err := tool.print(report, []string{"LIVRO", "TÍTULO", "UNIDADE", "QUANTIDADE", "EMPRESTADOS", "DISPONÍVEIS", "CALCULADO"}, rows); err != nil {
+		return tool.fail("falha ao escrever o resultado", err)
+	}
+
+	tool.logger.Info("conferência do acervo concluída", "discrepancies", len(report.Discrepancies), "repaired", report.Repaired)
+	if len(report.Discrepancies) > 0 && !repair {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,333 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	serviceinterfaces "github.com/henrygoeszanin/api_golang_estudos/application/interfaces/services"
+	"github.com/henrygoeszanin/api_golang_estudos/application/services"
+	"github.com/henrygoeszanin/api_golang_estudos/application/workers"
+	"github.com/henrygoeszanin/api_golang_estudos/config"
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+	"github.com/henrygoeszanin/api_golang_estudos/infrastructure/database"
+	"github.com/henrygoeszanin/api_golang_estudos/logging"
+	"github.com/henrygoeszanin/api_golang_estudos/presentation/routes"
+)
+
+// Formatos de saída dos subcomandos administrativos
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// command descreve um subcomando da linha de comando. Grupos, como user e book, têm subcomandos no lugar de run.
+type command struct {
+	name        string
+	summary     string
+	run         func(args []string) int
+	subcommands []command
+}
+
+// commands lista os subcomandos do binário, na ordem em que aparecem na ajuda
+var commands = []command{
+	{name: "serve", summary: "inicia o servidor da API (padrão quando nenhum comando é informado)", run: runServe},
+	{name: "migrate", summary: "aplica as migrações do banco e lista as migrações versionadas", run: runMigrate},
+	{name: "seed", summary: "carrega dados de demonstração de uma fixture ou sintéticos", run: runSeed},
+	{name: "create-admin", summary: "cria um administrador ou promove um usuário existente", run: runCreateAdmin},
+	{name: "user", summary: "administra usuários", subcommands: []command{
+		{name: "list", summary: "lista os usuários ativos ou, com --deleted, os removidos", run: runUserList},
+		{name: "promote", summary: "promove um usuário a administrador", run: runUserPromote},
+		{name: "demote", summary: "remove o papel de administrador de um usuário", run: runUserDemote},
+		{name: "reset-password", summary: "troca a senha de um usuário", run: runUserResetPassword},
+	}},
+	{name: "book", summary: "importa, exporta e confere o acervo", subcommands: []command{
+		{name: "import", summary: "importa livros de um arquivo CSV ou JSON Lines", run: runBookImport},
+		{name: "export", summary: "exporta o acervo em CSV, JSON, MARC ou MARCXML", run: runBookExport},
+		{name: "reconcile", summary: "confere os exemplares disponíveis com os empréstimos em aberto e, com --repair, corrige", run: runBookReconcile},
+	}},
+	{name: "loan", summary: "administra empréstimos", subcommands: []command{
+		{name: "list-overdue", summary: "lista os empréstimos em atraso", run: runLoanListOverdue},
+		{name: "force-return", summary: "registra a devolução de um empréstimo sem conferir usuário ou unidade", run: runLoanForceReturn},
+	}},
+}
+
+// dispatch executa o subcomando indicado pelos argumentos. Sem subcomando, ou quando o primeiro
+// argumento já é uma opção, inicia o servidor, como nas versões anteriores. Retorna o código de saída.
+func dispatch(args []string) int {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return runServe(args)
+	}
+	return dispatchCommand("library-api", commands, args)
+}
+
+// dispatchCommand procura o subcomando na lista e o executa, descendo pelos grupos
+func dispatchCommand(prefix string, list []command, args []string) int {
+	if len(args) == 0 {
+		printUsage(os.Stderr, prefix, list)
+		return 2
+	}
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage(os.Stdout, prefix, list)
+		return 0
+	}
+
+	for _, cmd := range list {
+		if cmd.name != args[0] {
+			continue
+		}
+		if cmd.subcommands != nil {
+			return dispatchCommand(prefix+" "+cmd.name, cmd.subcommands, args[1:])
+		}
+		return cmd.run(args[1:])
+	}
+
+	fmt.Fprintf(os.Stderr, "comando desconhecido: %s %s\n\n", prefix, args[0])
+	printUsage(os.Stderr, prefix, list)
+	return 2
+}
+
+// printUsage lista os subcomandos disponíveis
+func printUsage(output io.Writer, prefix string, list []command) {
+	fmt.Fprintf(output, "uso: %s <comando> [argumentos]\n\ncomandos:\n", prefix)
+	writer := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0)
+	for _, cmd := range list {
+		fmt.Fprintf(writer, "  %s\t%s\n", cmd.name, cmd.summary)
+	}
+	writer.Flush()
+	fmt.Fprintf(output, "\nUse \"%s <comando> -h\" para ver os argumentos de cada comando.\n", prefix)
+}
+
+// tool reúne o que os subcomandos administrativos usam: configuração, logger, banco, repositórios e
+// formato de saída. Os serviços são os mesmos da API, sem o rastreamento.
+type tool struct {
+	cfg    *config.Config
+	logger *slog.Logger
+	output string
+
+	db    *gorm.DB
+	repos routes.Repositories
+	pool  *workers.Pool
+}
+
+// loadTool lê a configuração do subcomando, com os argumentos próprios definidos em define e --output,
+// e configura o logger. Retorna nil e o código de saída quando o subcomando deve terminar sem executar.
+func loadTool(name string, args []string, define func(flagSet *flag.FlagSet)) (*tool, int) {
+	output := outputTable
+	cfg, err := config.LoadCommand(name, args, func(flagSet *flag.FlagSet) {
+		flagSet.StringVar(&output, "output", outputTable, "formato da saída: table ou json")
+		if define != nil {
+			define(flagSet)
+		}
+	})
+	if errors.Is(err, flag.ErrHelp) {
+		return nil, 0
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return nil, 2
+	}
+	if output != outputTable && output != outputJSON {
+		fmt.Fprintf(os.Stderr, "--output inválido: %q (use table ou json)\n", output)
+		return nil, 2
+	}
+
+	// Os logs vão para a saída de erro, para não se misturar ao resultado do comando
+	logger := logging.New(os.Stderr, cfg.LogLevel, cfg.LogFormat)
+	slog.SetDefault(logger)
+	return &tool{cfg: cfg, logger: logger, output: output}, 0
+}
+
+// open conecta ao banco, aplicando as migrações pendentes, e cria os repositórios
+func (tool *tool) open() error {
+	db, err := database.SetupDatabase(tool.cfg)
+	if err != nil {
+		return fmt.Errorf("falha ao conectar ao banco de dados: %w", err)
+	}
+	tool.db = db
+	tool.repos = routes.GormRepositories(db)
+	tool.pool = workers.NewPool()
+	return nil
+}
+
+// close encerra as tarefas em segundo plano e fecha o banco
+func (tool *tool) close() {
+	if tool.pool != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := tool.pool.Shutdown(ctx); err != nil {
+			tool.logger.Error("tarefas em segundo plano interrompidas", "error", err)
+		}
+	}
+	if tool.db != nil {
+		database.Close(tool.db)
+	}
+}
+
+// fail registra o erro e retorna o código de saída de falha na execução
+func (tool *tool) fail(message string, err error, args ...interface{}) int {
+	tool.logger.Error(message, append(args, "error", err)...)
+	return 1
+}
+
+// userService cria o serviço de usuários; o comportamento legado do primeiro administrador não vale fora da API
+func (tool *tool) userService() serviceinterfaces.UserService {
+	return services.NewUserService(tool.repos.User, tool.repos.Branch, time.Duration(tool.cfg.AccountErasureGraceDays)*24*time.Hour, false)
+}
+
+// bookService cria o serviço de livros
+func (tool *tool) bookService() serviceinterfaces.BookService {
+	return services.NewBookService(tool.repos.Book)
+}
+
+// bookImportService cria o serviço de importação de livros
+func (tool *tool) bookImportService() serviceinterfaces.BookImportService {
+	return services.NewBookImportService(tool.repos.Book, tool.repos.ImportJob, tool.pool)
+}
+
+// loanService cria o serviço de empréstimos
+func (tool *tool) loanService() serviceinterfaces.LoanService {
+	return services.NewLoanService(tool.repos.Loan, tool.repos.Book, tool.repos.User)
+}
+
+// auditService cria o serviço da trilha de auditoria
+func (tool *tool) auditService() serviceinterfaces.AuditService {
+	return services.NewAuditService(
+		tool.repos.AuditEvent,
+		tool.repos.Book,
+		tool.repos.User,
+		tool.repos.Loan,
+		tool.repos.Branch,
+		tool.repos.Transfer,
+	)
+}
+
+// audited executa uma ação que altera estado e a registra na trilha de auditoria, com o estado do alvo
+// antes e depois, como o middleware de auditoria faz na API. run retorna o ID do alvo, usado quando ele
+// só passa a existir com a ação. A ação é identificada como "CLI <comando>" e o autor pelo usuário do
+// sistema operacional. Ações que falham não alteram nada e não são registradas.
+func (tool *tool) audited(ctx context.Context, action, targetType string, targetID uint, run func() (uint, error)) error {
+	auditService := tool.auditService()
+	snapshot := func(moment string) interface{} {
+		if targetID == 0 {
+			return nil
+		}
+		state, err := auditService.Snapshot(ctx, targetType, strconv.FormatUint(uint64(targetID), 10))
+		if err != nil {
+			tool.logger.Error("auditoria: falha ao capturar o estado "+moment, "target_type", targetType, "target_id", targetID, "error", err)
+		}
+		return state
+	}
+
+	before := snapshot("anterior")
+	id, err := run()
+	if err != nil {
+		return err
+	}
+	if targetID == 0 {
+		targetID = id
+	}
+	after := snapshot("posterior")
+
+	record := dtos.AuditRecordDTO{
+		Operator:   operator(),
+		Action:     "CLI " + action,
+		TargetType: targetType,
+		Before:     before,
+		After:      after,
+		UserAgent:  "library-api",
+	}
+	if targetID != 0 {
+		record.TargetID = strconv.FormatUint(uint64(targetID), 10)
+	}
+	if err := auditService.Record(ctx, record); err != nil {
+		// A ação já foi concluída; a falha no registro não deve mudar o resultado
+		tool.logger.Error("auditoria: falha ao registrar a ação", "action", action, "error", err)
+	}
+	return nil
+}
+
+// operator identifica quem executa o comando pelo usuário do sistema operacional
+func operator() string {
+	if current, err := user.Current(); err == nil && current.Username != "" {
+		return "cli:" + current.Username
+	}
+	return "cli"
+}
+
+// print escreve o resultado do comando: value em JSON ou as linhas como tabela, com o cabeçalho informado
+func (tool *tool) print(value interface{}, header []string, rows [][]string) error {
+	if tool.output == outputJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(writer, strings.Join(row, "\t"))
+	}
+	return writer.Flush()
+}
+
+// findUser busca o usuário ativo pelo ID ou pelo email; exatamente um dos dois deve ser informado
+func (tool *tool) findUser(ctx context.Context, id uint, email string) (*entities.User, error) {
+	if (id == 0) == (email == "") {
+		return nil, errors.New("informe o usuário por --id ou por --email")
+	}
+
+	var found *entities.User
+	var err error
+	if id != 0 {
+		found, err = tool.repos.User.FindByID(ctx, id)
+	} else {
+		found, err = tool.repos.User.FindByEmail(ctx, email)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if found == nil {
+		return nil, errors.New("usuário não encontrado")
+	}
+	return found, nil
+}
+
+// readPassword lê a senha da primeira linha da entrada padrão, para que ela não fique no histórico do shell
+func readPassword() (string, error) {
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", errors.New("informe a senha em --password ou na entrada padrão")
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// formatTime formata datas nas tabelas; datas ausentes aparecem como "-"
+func formatTime(value *time.Time) string {
+	if value == nil || value.IsZero() {
+		return "-"
+	}
+	return value.Local().Format("2006-01-02 15:04")
+}
+
+// formatBool formata valores lógicos nas tabelas
+func formatBool(value bool) string {
+	if value {
+		return "sim"
+	}
+	return "não"
+}
@@ -2,41 +2,253 @@ package config
 
 import (
 	"os"
+	"path/filepath"
 )
 
-// Config contém todas as configurações da aplicação
+// Perfis de ambiente, que definem os valores padrão de cada configuração
+const (
+	ProfileDev  = "dev"
+	ProfileTest = "test"
+	ProfileProd = "prod"
+)
+
+// devJWTSecret é o segredo padrão do perfil de teste; não é aceito em nenhum outro perfil
+const devJWTSecret = "chave_secreta_padrao"
+
+// Config contém todas as configurações da aplicação.
+// Cada campo é identificado pela chave usada no arquivo de configuração (tag key, em seções separadas
+// por ponto) e pela variável de ambiente correspondente (tag env). O argumento de linha de comando
+// equivalente é a chave com hífens no lugar dos pontos e sublinhados, por exemplo --database-host.
 type Config struct {
+	// Perfil de ambiente: dev, test ou prod
+	Profile string `key:"profile" env:"APP_ENV"`
+
+	// Banco de dados: driver (postgres ou sqlite) e, no SQLite, o arquivo do banco (":memory:" para um banco em memória)
+	DBDriver     string `key:"database.driver" env:"DB_DRIVER"`
+	DBSQLitePath string `key:"database.sqlite_path" env:"DB_SQLITE_PATH"`
+
 	// Configurações do banco de dados
-	DBHost     string
-	DBPort     string
-	DBUser     string
-	DBPassword string
-	DBName     string
+	DBHost     string `key:"database.host" env:"DB_HOST"`
+	DBPort     string `key:"database.port" env:"DB_PORT"`
+	DBUser     string `key:"database.user" env:"DB_USER"`
+	DBPassword string `key:"database.password" env:"DB_PASSWORD"`
+	DBName     string `key:"database.name" env:"DB_NAME"`
+
+	// Conexão com o PostgreSQL: modo SSL (disable, allow, prefer, require, verify-ca ou verify-full),
+	// certificados, fuso horário da sessão e tempo limite para abrir cada conexão
+	DBSSLMode               string `key:"database.sslmode" env:"DB_SSLMODE"`
+	DBSSLRootCert           string `key:"database.sslrootcert" env:"DB_SSLROOTCERT"`
+	DBSSLCert               string `key:"database.sslcert" env:"DB_SSLCERT"`
+	DBSSLKey                string `key:"database.sslkey" env:"DB_SSLKEY"`
+	DBTimeZone              string `key:"database.timezone" env:"DB_TIMEZONE"`
+	DBConnectTimeoutSeconds int    `key:"database.connect_timeout_seconds" env:"DB_CONNECT_TIMEOUT_SECONDS"`
+
+	// Pool de conexões: máximo de conexões abertas e ociosas (0 = sem limite de abertas) e
+	// tempo máximo de vida e de ociosidade de cada conexão, em segundos
+	DBMaxOpenConns           int `key:"database.max_open_conns" env:"DB_MAX_OPEN_CONNS"`
+	DBMaxIdleConns           int `key:"database.max_idle_conns" env:"DB_MAX_IDLE_CONNS"`
+	DBConnMaxLifetimeSeconds int `key:"database.conn_max_lifetime_seconds" env:"DB_CONN_MAX_LIFETIME_SECONDS"`
+	DBConnMaxIdleTimeSeconds int `key:"database.conn_max_idle_time_seconds" env:"DB_CONN_MAX_IDLE_TIME_SECONDS"`
+
+	// Tentativas de conexão na inicialização e espera inicial entre elas, em segundos, dobrada a cada falha
+	DBConnectAttempts       int `key:"database.connect_attempts" env:"DB_CONNECT_ATTEMPTS"`
+	DBConnectBackoffSeconds int `key:"database.connect_backoff_seconds" env:"DB_CONNECT_BACKOFF_SECONDS"`
+
+	// Réplicas de leitura ("host" ou "host:porta"), com as mesmas credenciais do banco principal.
+	// Consultas fora de transações vão às réplicas; escritas e transações, ao principal.
+	DBReplicaHosts []string `key:"database.replica_hosts" env:"DB_REPLICA_HOSTS"`
 
 	// Configurações do servidor
-	ServerPort string
+	ServerPort string `key:"server.port" env:"SERVER_PORT"`
+
+	// Porta do servidor interno que publica /metrics, separado da API para não expor as métricas
+	// junto com as rotas públicas (vazio desliga)
+	MetricsPort string `key:"server.metrics_port" env:"METRICS_PORT"`
+
+	// Limites do servidor HTTP, em segundos: leitura dos cabeçalhos, leitura da requisição,
+	// escrita da resposta e conexões ociosas; e tamanho máximo dos cabeçalhos, em bytes
+	ServerReadHeaderTimeoutSeconds int `key:"server.read_header_timeout_seconds" env:"SERVER_READ_HEADER_TIMEOUT_SECONDS"`
+	ServerReadTimeoutSeconds       int `key:"server.read_timeout_seconds" env:"SERVER_READ_TIMEOUT_SECONDS"`
+	ServerWriteTimeoutSeconds      int `key:"server.write_timeout_seconds" env:"SERVER_WRITE_TIMEOUT_SECONDS"`
+	ServerIdleTimeoutSeconds       int `key:"server.idle_timeout_seconds" env:"SERVER_IDLE_TIMEOUT_SECONDS"`
+	ServerMaxHeaderBytes           int `key:"server.max_header_bytes" env:"SERVER_MAX_HEADER_BYTES"`
+
+	// Encerramento: tempo em que o servidor segue atendendo já fora da prontidão, para que o
+	// balanceador deixe de enviar requisições, e prazo para concluir as requisições em andamento
+	ShutdownDrainSeconds   int `key:"server.shutdown_drain_seconds" env:"SHUTDOWN_DRAIN_SECONDS"`
+	ShutdownTimeoutSeconds int `key:"server.shutdown_timeout_seconds" env:"SHUTDOWN_TIMEOUT_SECONDS"`
+
+	// Configurações de autenticação: segredo de assinatura, validade do token e prazo máximo de renovação
+	JWTSecret          string `key:"auth.jwt_secret" env:"JWT_SECRET"`
+	JWTTimeoutHours    int    `key:"auth.token_timeout_hours" env:"JWT_TIMEOUT_HOURS"`
+	JWTMaxRefreshHours int    `key:"auth.max_refresh_hours" env:"JWT_MAX_REFRESH_HOURS"`
+
+	// Cookie do token: domínio, envio apenas por HTTPS, acesso bloqueado a scripts e
+	// política SameSite (default, lax, strict ou none)
+	CookieDomain   string `key:"auth.cookie_domain" env:"COOKIE_DOMAIN"`
+	CookieSecure   bool   `key:"auth.cookie_secure" env:"COOKIE_SECURE"`
+	CookieHTTPOnly bool   `key:"auth.cookie_http_only" env:"COOKIE_HTTP_ONLY"`
+	CookieSameSite string `key:"auth.cookie_same_site" env:"COOKIE_SAME_SITE"`
+
+	// Comportamento legado em que o primeiro cadastro vira administrador; só aceito nos perfis dev e test.
+	// Fora dele, o primeiro administrador é criado com o token de instalação ou com o subcomando create-admin.
+	AuthFirstUserAdmin bool `key:"auth.first_user_admin" env:"AUTH_FIRST_USER_ADMIN"`
+
+	// CORS: origens aceitas (vazio desliga o CORS), envio de credenciais e cache da verificação prévia
+	CORSAllowedOrigins   []string `key:"cors.allowed_origins" env:"CORS_ALLOWED_ORIGINS"`
+	CORSAllowCredentials bool     `key:"cors.allow_credentials" env:"CORS_ALLOW_CREDENTIALS"`
+	CORSMaxAgeSeconds    int      `key:"cors.max_age_seconds" env:"CORS_MAX_AGE_SECONDS"`
+
+	// Configurações de log: nível (debug, info, warn, error) e formato (json ou text)
+	LogLevel  string `key:"log.level" env:"LOG_LEVEL"`
+	LogFormat string `key:"log.format" env:"LOG_FORMAT"`
+
+	// Rastreamento distribuído (OpenTelemetry): exportador ("none", "stdout" ou "otlp"),
+	// endereço do coletor OTLP/HTTP e fração das requisições rastreadas (0 a 1)
+	ServiceName        string  `key:"tracing.service_name" env:"SERVICE_NAME"`
+	TracingExporter    string  `key:"tracing.exporter" env:"TRACING_EXPORTER"`
+	OTLPEndpoint       string  `key:"tracing.otlp_endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"`
+	OTLPInsecure       bool    `key:"tracing.otlp_insecure" env:"OTEL_EXPORTER_OTLP_INSECURE"`
+	TracingSampleRatio float64 `key:"tracing.sample_ratio" env:"TRACING_SAMPLE_RATIO"`
+
+	// Prazos, em segundos, de cada requisição à API e de cada instrução enviada ao banco (0 desliga)
+	RequestTimeoutSeconds     int `key:"server.request_timeout_seconds" env:"REQUEST_TIMEOUT_SECONDS"`
+	DBStatementTimeoutSeconds int `key:"database.statement_timeout_seconds" env:"DB_STATEMENT_TIMEOUT_SECONDS"`
+
+	// Prazo, em segundos, das rotas que transmitem arquivos (exportações, downloads e importação síncrona),
+	// que substitui o prazo das requisições e os limites de leitura e escrita do servidor (0 desliga)
+	StreamTimeoutSeconds int `key:"server.stream_timeout_seconds" env:"STREAM_TIMEOUT_SECONDS"`
 
-	// Configurações de autenticação
-	JWTSecret string
+	// Tempo limite, em segundos, de cada verificação de dependência em /api/health/ready
+	HealthCheckTimeoutSeconds int `key:"health.check_timeout_seconds" env:"HEALTH_CHECK_TIMEOUT_SECONDS"`
+
+	// Dias entre o pedido de exclusão da conta e o apagamento dos dados pessoais
+	AccountErasureGraceDays int `key:"accounts.erasure_grace_days" env:"ACCOUNT_ERASURE_GRACE_DAYS"`
+
+	// Exportação de dados pessoais: diretório dos arquivos e validade do link de download
+	DataExportDir          string `key:"data_export.dir" env:"DATA_EXPORT_DIR"`
+	DataExportLinkTTLHours int    `key:"data_export.link_ttl_hours" env:"DATA_EXPORT_LINK_TTL_HOURS"`
+
+	// Tarefas agendadas: liga o agendador no servidor, fuso das expressões cron, novas tentativas
+	// após uma falha e dias de histórico mantidos
+	SchedulerEnabled             bool   `key:"scheduler.enabled" env:"SCHEDULER_ENABLED"`
+	SchedulerTimezone            string `key:"scheduler.timezone" env:"SCHEDULER_TIMEZONE"`
+	SchedulerMaxAttempts         int    `key:"scheduler.max_attempts" env:"SCHEDULER_MAX_ATTEMPTS"`
+	SchedulerRetryBackoffSeconds int    `key:"scheduler.retry_backoff_seconds" env:"SCHEDULER_RETRY_BACKOFF_SECONDS"`
+	SchedulerHistoryDays         int    `key:"scheduler.history_days" env:"SCHEDULER_HISTORY_DAYS"`
+
+	// Expressões cron de cada tarefa; vazia, a tarefa só roda a pedido de um administrador
+	SchedulerAccountErasure    string `key:"scheduler.account_erasure" env:"SCHEDULER_ACCOUNT_ERASURE"`
+	SchedulerDataExportCleanup string `key:"scheduler.data_export_cleanup" env:"SCHEDULER_DATA_EXPORT_CLEANUP"`
+	SchedulerOverdueScan       string `key:"scheduler.overdue_scan" env:"SCHEDULER_OVERDUE_SCAN"`
+	SchedulerInventoryReport   string `key:"scheduler.inventory_report" env:"SCHEDULER_INVENTORY_REPORT"`
+	SchedulerHistoryCleanup    string `key:"scheduler.history_cleanup" env:"SCHEDULER_HISTORY_CLEANUP"`
+
+	// Notificações de empréstimos: dias de antecedência do lembrete, dias de atraso de cada aviso
+	// e entrega dos e-mails (log ou arquivo)
+	NotificationReminderDays int    `key:"notifications.reminder_days" env:"NOTIFICATION_REMINDER_DAYS"`
+	NotificationOverdueDays  []int  `key:"notifications.overdue_days" env:"NOTIFICATION_OVERDUE_DAYS"`
+	NotificationEmailSender  string `key:"notifications.email_sender" env:"NOTIFICATION_EMAIL_SENDER"`
+	NotificationFilePath     string `key:"notifications.file_path" env:"NOTIFICATION_FILE_PATH"`
 }
 
-// LoadConfig carrega as configurações do ambiente
-func LoadConfig() *Config {
-	return &Config{
-		DBHost:     getEnv("DB_HOST", "localhost"),
-		DBPort:     getEnv("DB_PORT", "5432"),
-		DBUser:     getEnv("DB_USER", "postgres"),
-		DBPassword: getEnv("DB_PASSWORD", "postgres"),
-		DBName:     getEnv("DB_NAME", "library_api"),
-		ServerPort: getEnv("SERVER_PORT", "8080"),
-		JWTSecret:  getEnv("JWT_SECRET", "chave_secreta_padrao"),
+// Defaults retorna os valores padrão do perfil informado.
+// Os perfis dev e test trazem credenciais locais do banco, e o test usa um SQLite em memória e um segredo do JWT fixo;
+// o perfil dev exige o segredo do JWT, e o prod também as credenciais do banco e liga os cookies seguros.
+func Defaults(profile string) *Config {
+	cfg := &Config{
+		Profile: profile,
+
+		DBDriver:     "postgres",
+		DBSQLitePath: "library.db",
+
+		DBHost: "localhost",
+		DBPort: "5432",
+		DBName: "library_api",
+
+		DBSSLMode:                "disable",
+		DBTimeZone:               "America/Sao_Paulo",
+		DBConnectTimeoutSeconds:  5,
+		DBMaxOpenConns:           25,
+		DBMaxIdleConns:           10,
+		DBConnMaxLifetimeSeconds: 30 * 60,
+		DBConnMaxIdleTimeSeconds: 5 * 60,
+		DBConnectAttempts:        5,
+		DBConnectBackoffSeconds:  1,
+
+		ServerPort:                     "8080",
+		MetricsPort:                    "9090",
+		ServerReadHeaderTimeoutSeconds: 10,
+		ServerReadTimeoutSeconds:       60,
+		ServerWriteTimeoutSeconds:      60,
+		ServerIdleTimeoutSeconds:       120,
+		ServerMaxHeaderBytes:           1 << 20,
+		ShutdownDrainSeconds:           5,
+		ShutdownTimeoutSeconds:         30,
+
+		JWTTimeoutHours:    24,
+		JWTMaxRefreshHours: 24 * 7,
+		CookieHTTPOnly:     true,
+		CookieSameSite:     "default",
+
+		CORSMaxAgeSeconds: 600,
+
+		LogLevel:  "info",
+		LogFormat: "json",
+
+		ServiceName:        "library-api",
+		TracingExporter:    "none",
+		OTLPEndpoint:       "localhost:4318",
+		TracingSampleRatio: 1,
+
+		RequestTimeoutSeconds:     30,
+		DBStatementTimeoutSeconds: 10,
+		StreamTimeoutSeconds:      600,
+		HealthCheckTimeoutSeconds: 2,
+
+		AccountErasureGraceDays: 30,
+		DataExportDir:           filepath.Join(os.TempDir(), "library-exports"),
+		DataExportLinkTTLHours:  24,
+
+		SchedulerEnabled:             true,
+		SchedulerTimezone:            "America/Sao_Paulo",
+		SchedulerMaxAttempts:         3,
+		SchedulerRetryBackoffSeconds: 30,
+		SchedulerHistoryDays:         30,
+		SchedulerAccountErasure:      "0 * * * *",
+		SchedulerDataExportCleanup:   "30 * * * *",
+		SchedulerOverdueScan:         "0 8 * * *",
+		SchedulerInventoryReport:     "0 3 * * *",
+		SchedulerHistoryCleanup:      "0 4 * * *",
+
+		NotificationReminderDays: 3,
+		NotificationOverdueDays:  []int{1, 7, 14},
+		NotificationEmailSender:  "log",
+		NotificationFilePath:     filepath.Join(os.TempDir(), "library-notifications.jsonl"),
 	}
-}
 
-// getEnv retorna a variável de ambiente ou o valor padrão
-func getEnv(key, defaultValue string) string {
-	if value, exists := os.LookupEnv(key); exists {
-		return value
+	switch profile {
+	case ProfileDev:
+		cfg.DBUser = "postgres"
+		cfg.DBPassword = "postgres"
+		cfg.LogLevel = "debug"
+		cfg.LogFormat = "text"
+		cfg.ShutdownDrainSeconds = 0
+	case ProfileTest:
+		cfg.DBUser = "postgres"
+		cfg.DBPassword = "postgres"
+		cfg.DBName = "library_api_test"
+		cfg.DBDriver = "sqlite"
+		cfg.DBSQLitePath = ":memory:"
+		cfg.JWTSecret = devJWTSecret
+		cfg.LogLevel = "warn"
+		cfg.ShutdownDrainSeconds = 0
+		cfg.SchedulerEnabled = false
+	case ProfileProd:
+		cfg.DBSSLMode = "require"
+		cfg.CookieSecure = true
+		cfg.CookieSameSite = "lax"
 	}
-	return defaultValue
+
+	return cfg
 }
@@ -0,0 +1,336 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Variável de ambiente e argumento que indicam o arquivo de configuração
+const (
+	configFileEnv  = "CONFIG_FILE"
+	configFileFlag = "config"
+)
+
+// fileEnvSuffix identifica as variáveis que apontam para um arquivo com o valor, como os segredos do Docker
+const fileEnvSuffix = "_FILE"
+
+// field descreve um campo configurável de Config
+type field struct {
+	key   string
+	env   string
+	flag  string
+	index int
+}
+
+// fields lista os campos de Config que têm chave de configuração
+func fields() []field {
+	configType := reflect.TypeOf(Config{})
+	list := make([]field, 0, configType.NumField())
+	for i := 0; i < configType.NumField(); i++ {
+		structField := configType.Field(i)
+		key := structField.Tag.Get("key")
+		if key == "" {
+			continue
+		}
+		list = append(list, field{
+			key:   key,
+			env:   structField.Tag.Get("env"),
+			flag:  strings.NewReplacer(".", "-", "_", "-").Replace(key),
+			index: i,
+		})
+	}
+	return list
+}
+
+// Load monta a configuração juntando, da menor para a maior precedência: os valores padrão do perfil,
+// o arquivo de configuração (--config ou CONFIG_FILE, em YAML, TOML ou JSON), as variáveis de ambiente
+// (ou a variante _FILE, com o valor lido de um arquivo) e os argumentos de linha de comando.
+// A configuração resultante é validada; todos os problemas encontrados são informados juntos.
+func Load(args []string) (*Config, error) {
+	return LoadCommand("library-api", args, nil)
+}
+
+// LoadCommand monta a configuração como Load para um subcomando, que registra os próprios argumentos
+// em define. Os argumentos do subcomando e os de configuração podem ser informados juntos, em qualquer ordem.
+func LoadCommand(name string, args []string, define func(flagSet *flag.FlagSet)) (*Config, error) {
+	fieldList := fields()
+
+	flagValues, configFile, err := parseFlags(name, args, fieldList, define)
+	if err != nil {
+		return nil, err
+	}
+
+	envValues, err := readEnv(fieldList)
+	if err != nil {
+		return nil, err
+	}
+
+	if configFile == "" {
+		configFile = os.Getenv(configFileEnv)
+	}
+	var fileValues map[string]string
+	if configFile != "" {
+		fileValues, err = readFile(configFile, fieldList)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// O perfil define os valores padrão, por isso é resolvido antes das demais chaves. Sem perfil informado
+	// valem as exigências de produção: um servidor mal configurado não sobe com os padrões de desenvolvimento
+	profile := ProfileProd
+	for _, values := range []map[string]string{fileValues, envValues, flagValues} {
+		if value, ok := values["profile"]; ok && value != "" {
+			profile = strings.ToLower(value)
+		}
+	}
+
+	cfg := Defaults(profile)
+	sources := []struct {
+		values map[string]string
+		origin func(field field) string
+	}{
+		{fileValues, func(field field) string { return "arquivo " + configFile + ", chave " + field.key }},
+		{envValues, func(field field) string { return "variável " + field.env }},
+		{flagValues, func(field field) string { return "argumento --" + field.flag }},
+	}
+
+	var problems []string
+	for _, source := range sources {
+		for _, field := range fieldList {
+			value, ok := source.values[field.key]
+			if !ok {
+				continue
+			}
+			if err := setField(cfg, field, value); err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", source.origin(field), err))
+			}
+		}
+	}
+	cfg.Profile = profile
+	if len(problems) > 0 {
+		return nil, invalidConfig(problems)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// parseFlags lê os argumentos de linha de comando. Só os argumentos informados entram no resultado,
+// para que não sobrescrevam as demais fontes com valores vazios.
+func parseFlags(name string, args []string, fieldList []field, define func(flagSet *flag.FlagSet)) (map[string]string, string, error) {
+	flagSet := flag.NewFlagSet(name, flag.ContinueOnError)
+	flagSet.SetOutput(io.Discard)
+	if define != nil {
+		define(flagSet)
+	}
+
+	configFile := flagSet.String(configFileFlag, "", "arquivo de configuração (YAML, TOML ou JSON); equivale a "+configFileEnv)
+	values := make(map[string]*flagValue, len(fieldList))
+	configType := reflect.TypeOf(Config{})
+	for _, field := range fieldList {
+		value := &flagValue{boolean: configType.Field(field.index).Type.Kind() == reflect.Bool}
+		values[field.key] = value
+		flagSet.Var(value, field.flag, "equivale a "+field.env)
+	}
+
+	if err := flagSet.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			flagSet.SetOutput(os.Stderr)
+			flagSet.PrintDefaults()
+			return nil, "", err
+		}
+		return nil, "", fmt.Errorf("argumentos inválidos: %w", err)
+	}
+	if flagSet.NArg() > 0 {
+		return nil, "", fmt.Errorf("argumentos inválidos: argumento inesperado %q", flagSet.Arg(0))
+	}
+
+	result := make(map[string]string)
+	for key, value := range values {
+		if value.set {
+			result[key] = value.value
+		}
+	}
+	return result, *configFile, nil
+}
+
+// readEnv lê as variáveis de ambiente de cada campo. Quando existe a variante _FILE,
+// o valor é lido do arquivo indicado; informar as duas formas é um erro.
+func readEnv(fieldList []field) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, field := range fieldList {
+		value, hasValue := os.LookupEnv(field.env)
+		path, hasFile := os.LookupEnv(field.env + fileEnvSuffix)
+		switch {
+		case hasValue && hasFile:
+			return nil, fmt.Errorf("configuração inválida: informe %s ou %s, não as duas", field.env, field.env+fileEnvSuffix)
+		case hasFile:
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("falha ao ler %s: %w", field.env+fileEnvSuffix, err)
+			}
+			values[field.key] = strings.TrimRight(string(content), "\r\n")
+		case hasValue:
+			values[field.key] = value
+		}
+	}
+	return values, nil
+}
+
+// readFile lê o arquivo de configuração, no formato indicado pela extensão, e o converte em chaves
+// separadas por ponto. Chaves desconhecidas são rejeitadas, para que erros de digitação não passem despercebidos.
+func readFile(path string, fieldList []field) (map[string]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao ler o arquivo de configuração: %w", err)
+	}
+
+	var document map[string]interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &document)
+	case ".toml":
+		err = toml.Unmarshal(content, &document)
+	case ".json":
+		err = json.Unmarshal(content, &document)
+	default:
+		return nil, fmt.Errorf("formato do arquivo de configuração não suportado: %s (use .yaml, .toml ou .json)", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("falha ao interpretar o arquivo de configuração %s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	flatten("", document, values)
+
+	known := make(map[string]bool, len(fieldList))
+	for _, field := range fieldList {
+		known[field.key] = true
+	}
+	var unknown []string
+	for key := range values {
+		if !known[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return nil, fmt.Errorf("chaves desconhecidas no arquivo de configuração %s: %s", path, strings.Join(unknown, ", "))
+	}
+	return values, nil
+}
+
+// flatten converte as seções aninhadas do arquivo em chaves separadas por ponto.
+// Listas viram valores separados por vírgula, o mesmo formato aceito nas variáveis de ambiente.
+func flatten(prefix string, value interface{}, values map[string]string) {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range typed {
+			if prefix != "" {
+				key = prefix + "." + key
+			}
+			flatten(key, nested, values)
+		}
+	case []interface{}:
+		items := make([]string, 0, len(typed))
+		for _, item := range typed {
+			items = append(items, fmt.Sprint(item))
+		}
+		values[prefix] = strings.Join(items, ",")
+	case nil:
+		values[prefix] = ""
+	default:
+		values[prefix] = fmt.Sprint(typed)
+	}
+}
+
+// setField converte o texto para o tipo do campo e o grava na configuração
+func setField(cfg *Config, field field, value string) error {
+	target := reflect.ValueOf(cfg).Elem().Field(field.index)
+	value = strings.TrimSpace(value)
+
+	switch target.Kind() {
+	case reflect.String:
+		target.SetString(value)
+	case reflect.Int:
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("valor %q inválido: esperado um número inteiro", value)
+		}
+		target.SetInt(int64(parsed))
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("valor %q inválido: esperado true ou false", value)
+		}
+		target.SetBool(parsed)
+	case reflect.Float64:
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return fmt.Errorf("valor %q inválido: esperado um número", value)
+		}
+		target.SetFloat(parsed)
+	case reflect.Slice:
+		items := reflect.Zero(target.Type())
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			if target.Type().Elem().Kind() == reflect.Int {
+				parsed, err := strconv.Atoi(item)
+				if err != nil {
+					return fmt.Errorf("valor %q inválido: esperada uma lista de números inteiros", value)
+				}
+				items = reflect.Append(items, reflect.ValueOf(parsed))
+				continue
+			}
+			items = reflect.Append(items, reflect.ValueOf(item))
+		}
+		target.Set(items)
+	default:
+		return fmt.Errorf("tipo %s não suportado", target.Kind())
+	}
+	return nil
+}
+
+// flagValue guarda o valor de um argumento e se ele foi informado
+type flagValue struct {
+	value   string
+	set     bool
+	boolean bool
+}
+
+// String retorna o valor informado
+func (value *flagValue) String() string {
+	if value == nil {
+		return ""
+	}
+	return value.value
+}
+
+// Set registra o valor informado no argumento
+func (value *flagValue) Set(text string) error {
+	value.value = text
+	value.set = true
+	return nil
+}
+
+// IsBoolFlag permite informar os argumentos booleanos sem valor, como --auth-cookie-secure
+func (value *flagValue) IsBoolFlag() bool {
+	return value.boolean
+}
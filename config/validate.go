@@ -0,0 +1,223 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	_ "time/tzdata" // Base de fusos embutida, para imagens sem /usr/share/zoneinfo
+
+	"github.com/henrygoeszanin/api_golang_estudos/domain/cron"
+	domainerrors "github.com/henrygoeszanin/api_golang_estudos/domain/errors"
+)
+
+// Validate confere os campos obrigatórios, os intervalos aceitos e as combinações exigidas pelo perfil.
+// Retorna um único erro listando todos os problemas encontrados.
+func (cfg *Config) Validate() error {
+	var problems []string
+	problem := func(key, format string, args ...interface{}) {
+		problems = append(problems, describe(key)+": "+fmt.Sprintf(format, args...))
+	}
+	required := func(key, value string) {
+		if strings.TrimSpace(value) == "" {
+			problem(key, "obrigatório")
+		}
+	}
+	minimum := func(key string, value, min int) {
+		if value < min {
+			problem(key, "deve ser maior ou igual a %d (recebido %d)", min, value)
+		}
+	}
+	oneOf := func(key, value string, accepted ...string) {
+		for _, option := range accepted {
+			if value == option {
+				return
+			}
+		}
+		problem(key, "valor %q inválido: use %s", value, strings.Join(accepted, ", "))
+	}
+	port := func(key, value string) {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 65535 {
+			problem(key, "porta %q inválida: use um número entre 1 e 65535", value)
+		}
+	}
+
+	oneOf("profile", cfg.Profile, ProfileDev, ProfileTest, ProfileProd)
+	production := cfg.Profile == ProfileProd
+
+	oneOf("database.driver", cfg.DBDriver, "postgres", "sqlite")
+	if cfg.DBDriver == "sqlite" {
+		required("database.sqlite_path", cfg.DBSQLitePath)
+		if len(cfg.DBReplicaHosts) > 0 {
+			problem("database.replica_hosts", "réplicas de leitura só são aceitas com o driver postgres")
+		}
+	} else {
+		required("database.host", cfg.DBHost)
+		port("database.port", cfg.DBPort)
+		required("database.user", cfg.DBUser)
+		required("database.name", cfg.DBName)
+		if production {
+			required("database.password", cfg.DBPassword)
+		}
+	}
+	minimum("database.statement_timeout_seconds", cfg.DBStatementTimeoutSeconds, 0)
+	oneOf("database.sslmode", cfg.DBSSLMode, "disable", "allow", "prefer", "require", "verify-ca", "verify-full")
+	if (cfg.DBSSLCert == "") != (cfg.DBSSLKey == "") {
+		problem("database.sslcert", "informe o certificado e a chave do cliente juntos (database.sslkey)")
+	}
+	required("database.timezone", cfg.DBTimeZone)
+	minimum("database.connect_timeout_seconds", cfg.DBConnectTimeoutSeconds, 0)
+	minimum("database.max_open_conns", cfg.DBMaxOpenConns, 0)
+	minimum("database.max_idle_conns", cfg.DBMaxIdleConns, 0)
+	if cfg.DBMaxOpenConns > 0 && cfg.DBMaxIdleConns > cfg.DBMaxOpenConns {
+		problem("database.max_idle_conns", "não pode ser maior que database.max_open_conns (%d)", cfg.DBMaxOpenConns)
+	}
+	minimum("database.conn_max_lifetime_seconds", cfg.DBConnMaxLifetimeSeconds, 0)
+	minimum("database.conn_max_idle_time_seconds", cfg.DBConnMaxIdleTimeSeconds, 0)
+	minimum("database.connect_attempts", cfg.DBConnectAttempts, 1)
+	minimum("database.connect_backoff_seconds", cfg.DBConnectBackoffSeconds, 0)
+	for _, replica := range cfg.DBReplicaHosts {
+		if _, _, err := SplitHostPort(replica, cfg.DBPort); err != nil {
+			problem("database.replica_hosts", "%v", err)
+		}
+	}
+
+	port("server.port", cfg.ServerPort)
+	if cfg.MetricsPort != "" {
+		port("server.metrics_port", cfg.MetricsPort)
+		if cfg.MetricsPort == cfg.ServerPort {
+			problem("server.metrics_port", "deve ser diferente de server.port")
+		}
+	}
+	minimum("server.read_header_timeout_seconds", cfg.ServerReadHeaderTimeoutSeconds, 0)
+	minimum("server.read_timeout_seconds", cfg.ServerReadTimeoutSeconds, 0)
+	minimum("server.write_timeout_seconds", cfg.ServerWriteTimeoutSeconds, 0)
+	minimum("server.idle_timeout_seconds", cfg.ServerIdleTimeoutSeconds, 0)
+	minimum("server.max_header_bytes", cfg.ServerMaxHeaderBytes, 4096)
+	minimum("server.shutdown_drain_seconds", cfg.ShutdownDrainSeconds, 0)
+	minimum("server.shutdown_timeout_seconds", cfg.ShutdownTimeoutSeconds, 1)
+	minimum("server.request_timeout_seconds", cfg.RequestTimeoutSeconds, 0)
+	minimum("server.stream_timeout_seconds", cfg.StreamTimeoutSeconds, 0)
+
+	required("auth.jwt_secret", cfg.JWTSecret)
+	if cfg.JWTSecret == devJWTSecret && cfg.Profile != ProfileTest {
+		problem("auth.jwt_secret", "o segredo padrão só pode ser usado no perfil test")
+	} else if production && cfg.JWTSecret != "" && len(cfg.JWTSecret) < 32 {
+		problem("auth.jwt_secret", "deve ter ao menos 32 caracteres no perfil prod")
+	}
+	minimum("auth.token_timeout_hours", cfg.JWTTimeoutHours, 1)
+	minimum("auth.max_refresh_hours", cfg.JWTMaxRefreshHours, 0)
+	oneOf("auth.cookie_same_site", cfg.CookieSameSite, "default", "lax", "strict", "none")
+	if cfg.CookieSameSite == "none" && !cfg.CookieSecure {
+		problem("auth.cookie_same_site", "o valor none exige auth.cookie_secure=true")
+	}
+	if production && !cfg.CookieSecure {
+		problem("auth.cookie_secure", "deve ser true no perfil prod")
+	}
+	if production && cfg.AuthFirstUserAdmin {
+		problem("auth.first_user_admin", "o primeiro cadastro não pode virar administrador no perfil prod")
+	}
+
+	for _, origin := range cfg.CORSAllowedOrigins {
+		if origin == "*" {
+			if cfg.CORSAllowCredentials {
+				problem("cors.allowed_origins", "a origem * não pode ser usada com cors.allow_credentials=true")
+			}
+			continue
+		}
+		parsed, err := url.Parse(origin)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" || (parsed.Path != "" && parsed.Path != "/") {
+			problem("cors.allowed_origins", "origem %q inválida: use o formato https://exemplo.com", origin)
+		}
+	}
+	minimum("cors.max_age_seconds", cfg.CORSMaxAgeSeconds, 0)
+
+	oneOf("log.level", strings.ToLower(cfg.LogLevel), "debug", "info", "warn", "error")
+	oneOf("log.format", strings.ToLower(cfg.LogFormat), "json", "text")
+
+	required("tracing.service_name", cfg.ServiceName)
+	oneOf("tracing.exporter", strings.ToLower(cfg.TracingExporter), "none", "stdout", "otlp")
+	if strings.ToLower(cfg.TracingExporter) == "otlp" {
+		required("tracing.otlp_endpoint", cfg.OTLPEndpoint)
+	}
+	if cfg.TracingSampleRatio < 0 || cfg.TracingSampleRatio > 1 {
+		problem("tracing.sample_ratio", "deve estar entre 0 e 1 (recebido %g)", cfg.TracingSampleRatio)
+	}
+
+	minimum("health.check_timeout_seconds", cfg.HealthCheckTimeoutSeconds, 1)
+	minimum("accounts.erasure_grace_days", cfg.AccountErasureGraceDays, 0)
+	required("data_export.dir", cfg.DataExportDir)
+	minimum("data_export.link_ttl_hours", cfg.DataExportLinkTTLHours, 1)
+
+	if _, err := time.LoadLocation(cfg.SchedulerTimezone); err != nil || cfg.SchedulerTimezone == "" {
+		problem("scheduler.timezone", "fuso %q inválido: use um nome da base IANA, como America/Sao_Paulo ou UTC", cfg.SchedulerTimezone)
+	}
+	minimum("scheduler.max_attempts", cfg.SchedulerMaxAttempts, 1)
+	minimum("scheduler.retry_backoff_seconds", cfg.SchedulerRetryBackoffSeconds, 0)
+	minimum("scheduler.history_days", cfg.SchedulerHistoryDays, 1)
+	schedules := []struct{ key, expression string }{
+		{"scheduler.account_erasure", cfg.SchedulerAccountErasure},
+		{"scheduler.data_export_cleanup", cfg.SchedulerDataExportCleanup},
+		{"scheduler.overdue_scan", cfg.SchedulerOverdueScan},
+		{"scheduler.inventory_report", cfg.SchedulerInventoryReport},
+		{"scheduler.history_cleanup", cfg.SchedulerHistoryCleanup},
+	}
+	for _, schedule := range schedules {
+		if strings.TrimSpace(schedule.expression) == "" {
+			continue
+		}
+		if _, err := cron.Parse(schedule.expression); err != nil {
+			problem(schedule.key, "%s", strings.TrimPrefix(err.Error(), domainerrors.ErrInvalidData.Error()+": "))
+		}
+	}
+
+	minimum("notifications.reminder_days", cfg.NotificationReminderDays, 0)
+	for i, days := range cfg.NotificationOverdueDays {
+		if days < 1 || (i > 0 && days <= cfg.NotificationOverdueDays[i-1]) {
+			problem("notifications.overdue_days", "use dias de atraso maiores que zero em ordem crescente, como 1,7,14 (recebido %v)", cfg.NotificationOverdueDays)
+			break
+		}
+	}
+	oneOf("notifications.email_sender", cfg.NotificationEmailSender, "log", "file")
+	if cfg.NotificationEmailSender == "file" {
+		required("notifications.file_path", cfg.NotificationFilePath)
+	}
+
+	if len(problems) > 0 {
+		return invalidConfig(problems)
+	}
+	return nil
+}
+
+// describe identifica a chave junto da variável de ambiente equivalente
+func describe(key string) string {
+	for _, field := range fields() {
+		if field.key == key {
+			return key + " (" + field.env + ")"
+		}
+	}
+	return key
+}
+
+// invalidConfig reúne os problemas encontrados em um único erro
+func invalidConfig(problems []string) error {
+	return fmt.Errorf("configuração inválida:\n  - %s", strings.Join(problems, "\n  - "))
+}
+
+// SplitHostPort separa o endereço de uma réplica em host e porta, usando a porta padrão quando omitida
+func SplitHostPort(address, defaultPort string) (string, string, error) {
+	if !strings.Contains(address, ":") {
+		return address, defaultPort, nil
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return "", "", fmt.Errorf("endereço %q inválido: use host ou host:porta", address)
+	}
+	if parsed, err := strconv.Atoi(port); err != nil || parsed < 1 || parsed > 65535 {
+		return "", "", fmt.Errorf("endereço %q inválido: porta fora do intervalo 1-65535", address)
+	}
+	return host, port, nil
+}
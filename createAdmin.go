@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	"github.com/henrygoeszanin/api_golang_estudos/application/services"
+)
+
+// runCreateAdmin executa o subcomando create-admin: cria um administrador no banco configurado ou,
+// se o email já estiver cadastrado, promove o usuário existente. Sem --password, a senha é lida
+// da primeira linha da entrada padrão. Retorna o código de saída do processo.
+func runCreateAdmin(args []string) int {
+	var userDTO dtos.UserCreateDTO
+	tool, code := loadTool("library-api create-admin", args, func(flagSet *flag.FlagSet) {
+		flagSet.StringVar(&userDTO.Name, "name", "", "nome do administrador")
+		flagSet.StringVar(&userDTO.Email, "email", "", "email do administrador")
+		flagSet.StringVar(&userDTO.Password, "password", "", "senha do administrador (sem ela, é lida da entrada padrão)")
+	})
+	if tool == nil {
+		return code
+	}
+
+	if userDTO.Password == "" {
+		password, err := readPassword()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		userDTO.Password = password
+	}
+	if problems := dtos.Validate(userDTO); len(problems) > 0 {
+		fmt.Fprintf(os.Stderr, "administrador inválido:\n  - %s\n", strings.Join(problems, "\n  - "))
+		return 2
+	}
+
+	if err := tool.open(); err != nil {
+		return tool.fail("falha ao abrir o banco de dados", err)
+	}
+	defer tool.close()
+
+	ctx := context.Background()
+	var targetID uint
+	existing, err := tool.repos.User.FindByEmail(ctx, userDTO.Email)
+	if err != nil {
+		return tool.fail("falha ao buscar o usuário", err)
+	}
+	if existing != nil {
+		targetID = existing.ID
+	}
+
+	bootstrapService := services.NewBootstrapService(tool.userService(), tool.repos.User)
+	var admin *dtos.UserResponseDTO
+	var created bool
+	err = tool.audited(ctx, "create-admin", services.AuditTargetUser, targetID, func() (uint, error) {
+		var err error
+		admin, created, err = bootstrapService.CreateAdmin(ctx, userDTO)
+		if err != nil {
+			return 0, err
+		}
+		return admin.ID, nil
+	})
+	if err != nil {
+		return tool.fail("falha ao criar o administrador", err, "email", userDTO.Email)
+	}
+
+	if created {
+		tool.logger.Info("administrador criado", "id", admin.ID, "email", admin.Email)
+	} else {
+		tool.logger.Info("usuário existente promovido a administrador", "id", admin.ID, "email", admin.Email)
+	}
+	return printUsers(tool, []dtos.UserResponseDTO{*admin})
+}
@@ -0,0 +1,214 @@
+// Package cron interpreta expressões cron de cinco campos (minuto, hora, dia do mês, mês e dia da semana)
+// e calcula os próximos horários em que elas ocorrem.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	domainerrors "github.com/henrygoeszanin/api_golang_estudos/domain/errors"
+)
+
+// searchLimit é até onde Next procura uma ocorrência antes de desistir
+const searchLimit = 5 * 366 * 24 * time.Hour
+
+// macros são os atalhos aceitos no lugar dos cinco campos
+var macros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// field descreve os valores aceitos em um campo da expressão
+type field struct {
+	name     string
+	min, max int
+	names    []string // Nomes aceitos no lugar dos números, a partir de min
+}
+
+var (
+	minuteField = field{name: "minuto", min: 0, max: 59}
+	hourField   = field{name: "hora", min: 0, max: 23}
+	dayField    = field{name: "dia do mês", min: 1, max: 31}
+	monthField  = field{name: "mês", min: 1, max: 12,
+		names: []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}}
+	// O domingo pode ser 0 ou 7
+	weekdayField = field{name: "dia da semana", min: 0, max: 7,
+		names: []string{"SUN", "MON", "TUE", "WED", "THU", "FRI", "SAT"}}
+)
+
+// Schedule é uma expressão cron interpretada. Cada campo guarda os valores aceitos em um mapa de bits.
+type Schedule struct {
+	expression string
+	minutes    uint64
+	hours      uint64
+	days       uint64
+	months     uint64
+	weekdays   uint64
+	// Com dia do mês e dia da semana restritos, basta um deles coincidir, como no cron tradicional
+	anyDay     bool
+	anyWeekday bool
+}
+
+// Parse interpreta a expressão: cinco campos separados por espaço, cada um com *, números, intervalos (1-5),
+// listas (1,15) e passos (*/10, 8-18/2), ou um dos atalhos @hourly, @daily, @weekly, @monthly e @yearly.
+// Meses e dias da semana aceitam os nomes em inglês (JAN, MON).
+func Parse(expression string) (*Schedule, error) {
+	expression = strings.TrimSpace(expression)
+	fields := strings.Fields(expression)
+	if macro, ok := macros[strings.ToLower(expression)]; ok {
+		fields = strings.Fields(macro)
+	}
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("%w: expressão cron %q deve ter 5 campos (minuto, hora, dia do mês, mês e dia da semana)", domainerrors.ErrInvalidData, expression)
+	}
+
+	schedule := &Schedule{expression: expression}
+	var err error
+	if schedule.minutes, err = parseField(fields[0], minuteField); err != nil {
+		return nil, fmt.Errorf("%w: expressão cron %q: %v", domainerrors.ErrInvalidData, expression, err)
+	}
+	if schedule.hours, err = parseField(fields[1], hourField); err != nil {
+		return nil, fmt.Errorf("%w: expressão cron %q: %v", domainerrors.ErrInvalidData, expression, err)
+	}
+	if schedule.days, err = parseField(fields[2], dayField); err != nil {
+		return nil, fmt.Errorf("%w: expressão cron %q: %v", domainerrors.ErrInvalidData, expression, err)
+	}
+	if schedule.months, err = parseField(fields[3], monthField); err != nil {
+		return nil, fmt.Errorf("%w: expressão cron %q: %v", domainerrors.ErrInvalidData, expression, err)
+	}
+	if schedule.weekdays, err = parseField(fields[4], weekdayField); err != nil {
+		return nil, fmt.Errorf("%w: expressão cron %q: %v", domainerrors.ErrInvalidData, expression, err)
+	}
+	if schedule.weekdays&(1<<7) != 0 {
+		schedule.weekdays |= 1 << 0
+	}
+	schedule.anyDay = strings.HasPrefix(fields[2], "*")
+	schedule.anyWeekday = strings.HasPrefix(fields[4], "*")
+
+	// Combinações como 30 de fevereiro são válidas campo a campo, mas nunca ocorrem
+	if schedule.Next(time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)).IsZero() {
+		return nil, fmt.Errorf("%w: expressão cron %q nunca ocorre", domainerrors.ErrInvalidData, expression)
+	}
+	return schedule, nil
+}
+
+// String retorna a expressão como foi informada
+func (schedule *Schedule) String() string {
+	return schedule.expression
+}
+
+// Next retorna o primeiro horário depois de after em que a expressão ocorre, no fuso de after,
+// ou o tempo zero se não houver nenhum nos próximos cinco anos
+func (schedule *Schedule) Next(after time.Time) time.Time {
+	location := after.Location()
+	// Segundos são ignorados: a próxima ocorrência começa no minuto seguinte
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	limit := after.Add(searchLimit)
+
+	for t.Before(limit) {
+		if !has(schedule.months, int(t.Month())) {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, location)
+			continue
+		}
+		if !schedule.matchesDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, location)
+			continue
+		}
+		if !has(schedule.hours, t.Hour()) {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, location)
+			continue
+		}
+		if !has(schedule.minutes, t.Minute()) {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// matchesDay confere o dia do mês e o dia da semana
+func (schedule *Schedule) matchesDay(t time.Time) bool {
+	day := has(schedule.days, t.Day())
+	weekday := has(schedule.weekdays, int(t.Weekday()))
+	if schedule.anyDay || schedule.anyWeekday {
+		return day && weekday
+	}
+	return day || weekday
+}
+
+// has informa se o valor está no mapa de bits
+func has(bits uint64, value int) bool {
+	return bits&(1<<uint(value)) != 0
+}
+
+// parseField interpreta um campo da expressão e retorna os valores aceitos em um mapa de bits
+func parseField(text string, spec field) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(text, ",") {
+		rangeText, stepText, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			parsed, err := strconv.Atoi(stepText)
+			if err != nil || parsed < 1 {
+				return 0, fmt.Errorf("passo %q inválido no campo %s", stepText, spec.name)
+			}
+			step = parsed
+		}
+
+		var low, high int
+		switch {
+		case rangeText == "*":
+			low, high = spec.min, spec.max
+		case strings.Contains(rangeText, "-"):
+			lowText, highText, _ := strings.Cut(rangeText, "-")
+			var err error
+			if low, err = parseValue(lowText, spec); err != nil {
+				return 0, err
+			}
+			if high, err = parseValue(highText, spec); err != nil {
+				return 0, err
+			}
+			if low > high {
+				return 0, fmt.Errorf("intervalo %q invertido no campo %s", rangeText, spec.name)
+			}
+		default:
+			value, err := parseValue(rangeText, spec)
+			if err != nil {
+				return 0, err
+			}
+			// Com passo, um valor isolado vale como início do intervalo até o máximo (5/15 = 5,20,35,50)
+			low, high = value, value
+			if hasStep {
+				high = spec.max
+			}
+		}
+
+		for value := low; value <= high; value += step {
+			bits |= 1 << uint(value)
+		}
+	}
+	return bits, nil
+}
+
+// parseValue interpreta um número ou nome do campo, conferindo os limites
+func parseValue(text string, spec field) (int, error) {
+	for i, name := range spec.names {
+		if strings.EqualFold(text, name) {
+			return spec.min + i, nil
+		}
+	}
+	value, err := strconv.Atoi(text)
+	if err != nil || value < spec.min || value > spec.max {
+		return 0, fmt.Errorf("valor %q inválido no campo %s: use de %d a %d", text, spec.name, spec.min, spec.max)
+	}
+	return value, nil
+}
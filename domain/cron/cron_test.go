@@ -0,0 +1,147 @@
+package cron
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	domainerrors "github.com/henrygoeszanin/api_golang_estudos/domain/errors"
+)
+
+// values lista os valores do mapa de bits em ordem crescente
+func values(bits uint64) []int {
+	var list []int
+	for value := 0; value < 64; value++ {
+		if has(bits, value) {
+			list = append(list, value)
+		}
+	}
+	return list
+}
+
+func TestParseField(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		spec field
+		want []int
+	}{
+		{"valor", "5", minuteField, []int{5}},
+		{"lista", "1,15,30", minuteField, []int{1, 15, 30}},
+		{"intervalo", "8-12", hourField, []int{8, 9, 10, 11, 12}},
+		{"passo", "*/15", minuteField, []int{0, 15, 30, 45}},
+		{"intervalo com passo", "8-18/4", hourField, []int{8, 12, 16}},
+		{"valor com passo", "5/20", minuteField, []int{5, 25, 45}},
+		{"lista de intervalos", "1-3,20-22", dayField, []int{1, 2, 3, 20, 21, 22}},
+		{"todos os dias", "*", dayField, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31}},
+		{"meses por nome", "JAN,jul,Dec", monthField, []int{1, 7, 12}},
+		{"intervalo de meses por nome", "MAR-MAY", monthField, []int{3, 4, 5}},
+		{"trimestres", "*/3", monthField, []int{1, 4, 7, 10}},
+		{"dias úteis por nome", "MON-FRI", weekdayField, []int{1, 2, 3, 4, 5}},
+		{"domingo como 7", "7", weekdayField, []int{7}},
+		{"fim de semana", "SAT,SUN", weekdayField, []int{0, 6}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bits, err := parseField(tt.text, tt.spec)
+			if err != nil {
+				t.Fatalf("parseField(%q): %v", tt.text, err)
+			}
+			if got := values(bits); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("parseField(%q) = %v, esperado %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+	}{
+		{"vazia", ""},
+		{"quatro campos", "0 0 * *"},
+		{"seis campos", "0 0 0 * * *"},
+		{"atalho desconhecido", "@never"},
+		{"minuto fora do limite", "60 * * * *"},
+		{"hora fora do limite", "0 24 * * *"},
+		{"dia zero", "0 0 0 * *"},
+		{"mês fora do limite", "0 0 1 13 *"},
+		{"dia da semana fora do limite", "0 0 * * 8"},
+		{"intervalo invertido", "0 18-8 * * *"},
+		{"passo zero", "*/0 * * * *"},
+		{"passo não numérico", "*/x * * * *"},
+		{"nome desconhecido", "0 0 1 FOO *"},
+		{"nome em outro campo", "MON 0 * * *"},
+		{"dia que nunca ocorre", "0 0 30 2 *"},
+		{"dia 31 em meses de 30 dias", "0 0 31 4,6,9,11 *"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := Parse(tt.expression)
+			if !errors.Is(err, domainerrors.ErrInvalidData) {
+				t.Errorf("Parse(%q) = %v, %v, esperado ErrInvalidData", tt.expression, schedule, err)
+			}
+		})
+	}
+}
+
+func TestNext(t *testing.T) {
+	brt := time.FixedZone("BRT", -3*60*60)
+	at := func(year int, month time.Month, day, hour, minute, second int) time.Time {
+		return time.Date(year, month, day, hour, minute, second, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name       string
+		expression string
+		after      time.Time
+		want       time.Time
+	}{
+		{"a cada 15 minutos", "*/15 * * * *", at(2024, time.March, 8, 10, 7, 30), at(2024, time.March, 8, 10, 15, 0)},
+		{"segundos ignorados", "@hourly", at(2024, time.March, 8, 10, 59, 59), at(2024, time.March, 8, 11, 0, 0)},
+		{"horário exato fica para o dia seguinte", "30 2 * * *", at(2024, time.March, 8, 2, 30, 0), at(2024, time.March, 9, 2, 30, 0)},
+		{"dias úteis depois de sexta", "0 9 * * MON-FRI", at(2024, time.March, 8, 10, 0, 0), at(2024, time.March, 11, 9, 0, 0)},
+		{"domingo como 7", "0 0 * * 7", at(2024, time.March, 8, 10, 0, 0), at(2024, time.March, 10, 0, 0, 0)},
+		{"passos em minutos e horas", "5/20 8-18/5 * * *", at(2024, time.March, 8, 18, 46, 0), at(2024, time.March, 9, 8, 5, 0)},
+		{"meses por nome", "0 12 * JAN,JUL *", at(2024, time.February, 10, 0, 0, 0), at(2024, time.July, 1, 12, 0, 0)},
+		{"dia 31 pula meses curtos", "0 0 31 * *", at(2024, time.April, 1, 0, 0, 0), at(2024, time.May, 31, 0, 0, 0)},
+		{"29 de fevereiro", "0 0 29 2 *", at(2024, time.March, 1, 0, 0, 0), at(2028, time.February, 29, 0, 0, 0)},
+		{"virada do ano", "@yearly", at(2024, time.December, 31, 23, 59, 0), at(2025, time.January, 1, 0, 0, 0)},
+		// Com dia do mês e dia da semana restritos, vale o primeiro que coincidir
+		{"dia do mês ou da semana: sexta", "0 0 1,15 * FRI", at(2024, time.March, 2, 0, 0, 0), at(2024, time.March, 8, 0, 0, 0)},
+		{"dia do mês ou da semana: dia 15", "0 0 1,15 * FRI", at(2024, time.March, 9, 0, 0, 0), at(2024, time.March, 15, 0, 0, 0)},
+		// Com um dos dois livre, o outro precisa coincidir
+		{"dia da semana com passo", "0 0 1 * */2", at(2024, time.March, 1, 12, 0, 0), at(2024, time.June, 1, 0, 0, 0)},
+		{"fuso de after", "0 9 * * *", time.Date(2024, time.March, 8, 10, 0, 0, 0, brt), time.Date(2024, time.March, 9, 9, 0, 0, 0, brt)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			schedule, err := Parse(tt.expression)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.expression, err)
+			}
+			got := schedule.Next(tt.after)
+			if !got.Equal(tt.want) || got.Location() != tt.want.Location() {
+				t.Errorf("Next(%s) = %s, esperado %s", tt.after, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseKeepsExpression(t *testing.T) {
+	schedule, err := Parse("  @Daily ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if schedule.String() != "@Daily" {
+		t.Errorf("String() = %q, esperado %q", schedule.String(), "@Daily")
+	}
+	if next := schedule.Next(time.Date(2024, time.March, 8, 10, 0, 0, 0, time.UTC)); !next.Equal(time.Date(2024, time.March, 9, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("@daily deveria ocorrer à meia-noite, ocorreu em %s", next)
+	}
+}
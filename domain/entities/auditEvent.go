@@ -0,0 +1,83 @@
+package entities
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// AuditEvent registra uma ação que alterou o estado do sistema.
+// Os eventos formam uma cadeia: cada um guarda o hash do anterior, de modo que
+// qualquer alteração ou remoção de um registro antigo quebra a cadeia.
+type AuditEvent struct {
+	ID        uint      `gorm:"primarykey"`
+	CreatedAt time.Time `gorm:"not null;index"`
+	ActorID   *uint     `gorm:"index"`
+	// Autor sem cadastro, como "cli:operador"; o e-mail dos usuários não é gravado, é buscado pelo ActorID na leitura
+	ActorEmail string `gorm:"size:100"`
+	Action     string `gorm:"size:150;not null;index"` // Ex.: "PUT /api/admin/books/:id/withdraw"
+	TargetType string `gorm:"size:30;index:idx_audit_events_target"`
+	TargetID   string `gorm:"size:50;index:idx_audit_events_target"`
+	StatusCode int
+	Before     string `gorm:"type:text"` // Estado do alvo antes da ação, em JSON
+	After      string `gorm:"type:text"` // Estado do alvo depois da ação, em JSON
+	Changes    string `gorm:"type:text"` // Campos alterados, em JSON: {"campo": {"from": ..., "to": ...}}
+	IP         string `gorm:"size:45"`
+	UserAgent  string `gorm:"size:255"`
+	RequestID  string `gorm:"size:64;index"`
+	PrevHash   string `gorm:"size:64;not null"`
+	Hash       string `gorm:"size:64;not null;uniqueIndex"`
+}
+
+// AuditChainHead guarda o último elo da cadeia de auditoria.
+// A linha única é bloqueada a cada novo evento, o que serializa a gravação da cadeia.
+type AuditChainHead struct {
+	ID          uint   `gorm:"primarykey"`
+	LastEventID uint   `gorm:"not null;default:0"`
+	LastHash    string `gorm:"size:64;not null;default:''"`
+}
+
+// ComputeHash calcula o hash do evento a partir do hash anterior e do seu conteúdo
+func (event *AuditEvent) ComputeHash() string {
+	var actorID uint
+	if event.ActorID != nil {
+		actorID = *event.ActorID
+	}
+
+	// A ordem dos campos é fixa, o que garante uma serialização estável
+	content, _ := json.Marshal(struct {
+		PrevHash   string
+		CreatedAt  string
+		ActorID    uint
+		ActorEmail string
+		Action     string
+		TargetType string
+		TargetID   string
+		StatusCode int
+		Before     string
+		After      string
+		Changes    string
+		IP         string
+		UserAgent  string
+		RequestID  string
+	}{
+		PrevHash:   event.PrevHash,
+		CreatedAt:  event.CreatedAt.UTC().Format(time.RFC3339Nano),
+		ActorID:    actorID,
+		ActorEmail: event.ActorEmail,
+		Action:     event.Action,
+		TargetType: event.TargetType,
+		TargetID:   event.TargetID,
+		StatusCode: event.StatusCode,
+		Before:     event.Before,
+		After:      event.After,
+		Changes:    event.Changes,
+		IP:         event.IP,
+		UserAgent:  event.UserAgent,
+		RequestID:  event.RequestID,
+	})
+
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,50 @@
+package entities
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Author representa um autor de livros
+type Author struct {
+	gorm.Model
+	Name  string `gorm:"size:100;not null;uniqueIndex"`
+	Books []Book `gorm:"many2many:book_authors"`
+}
+
+// Subject representa um assunto (categoria) usado para classificar livros
+type Subject struct {
+	gorm.Model
+	Name  string `gorm:"size:100;not null;uniqueIndex"`
+	Books []Book `gorm:"many2many:book_subjects"`
+}
+
+// SplitAuthorNames separa um texto livre com vários autores em nomes individuais.
+// Aceita ";", "&", " e " e " and " como separadores; a vírgula só separa autores
+// quando todas as partes são nomes completos, preservando o formato "Sobrenome, Nome".
+func SplitAuthorNames(raw string) []string {
+	normalized := strings.NewReplacer(";", "\x00", " & ", "\x00", " e ", "\x00", " and ", "\x00").Replace(raw)
+
+	var names []string
+	for _, part := range strings.Split(normalized, "\x00") {
+		commaParts := strings.Split(part, ",")
+		splitOnComma := len(commaParts) > 1
+		for _, commaPart := range commaParts {
+			if !strings.Contains(strings.TrimSpace(commaPart), " ") {
+				splitOnComma = false
+			}
+		}
+
+		if !splitOnComma {
+			commaParts = []string{part}
+		}
+		for _, name := range commaParts {
+			name = strings.Join(strings.Fields(name), " ")
+			if name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
@@ -1,16 +1,85 @@
 package entities
 
 import (
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
 	"gorm.io/gorm"
 )
 
+// Situações de um livro no acervo
+const (
+	BookStatusActive    = "active"    // Visível no catálogo e disponível para empréstimo
+	BookStatusWithdrawn = "withdrawn" // Retirado do acervo: fora do catálogo, mas mantém o histórico
+)
+
 // Book representa um livro na biblioteca
 type Book struct {
 	gorm.Model
-	Title       string `gorm:"size:200;not null"`
-	Author      string `gorm:"size:100;not null"`
-	Description string `gorm:"type:text"`
-	Quantity    int    `gorm:"default:1"`
-	Available   int    `gorm:"default:1"`
-	Loans       []Loan
+	Title           string  `gorm:"size:200;not null"`
+	Author          string  `gorm:"size:255;not null"` // Autores em texto, na ordem de exibição
+	DedupKey        string  `gorm:"size:500;index"`    // Título e autores normalizados, ver BookDedupKey
+	Description     string  `gorm:"type:text"`
+	ISBN            *string `gorm:"column:isbn;size:13;uniqueIndex:idx_books_isbn_active,where:deleted_at IS NULL"` // Sempre no formato ISBN-13
+	Publisher       string  `gorm:"size:150"`
+	PublicationYear int
+	Edition         string `gorm:"size:50"`
+	Language        string `gorm:"size:35"` // Tag de idioma BCP 47, ex.: "pt-BR"
+	PageCount       int
+	CoverURL        string `gorm:"size:500"`
+	Quantity        int    `gorm:"default:1"`
+	Available       int    `gorm:"default:1;check:chk_books_available,available >= 0 AND available <= quantity"`
+	Status          string `gorm:"size:20;not null;default:active;index"`
+	WithdrawnAt     *time.Time
+	Authors         []Author  `gorm:"many2many:book_authors"`
+	Subjects        []Subject `gorm:"many2many:book_subjects"`
+	Loans           []Loan
+	Holdings        []BookHolding
+}
+
+// ExpectedAvailable calcula quantos exemplares deveriam estar disponíveis diante dos empréstimos em aberto,
+// limitado ao intervalo aceito pelo banco: de zero à quantidade de exemplares
+func ExpectedAvailable(quantity, openLoans int) int {
+	expected := quantity - openLoans
+	if expected < 0 {
+		return 0
+	}
+	if expected > quantity {
+		return quantity
+	}
+	return expected
+}
+
+// BookDedupKey gera a chave usada para detectar livros repetidos: título e autores
+// sem acentos, pontuação ou diferença de maiúsculas, com os autores em ordem alfabética.
+func BookDedupKey(title string, authors []string) string {
+	normalizedAuthors := make([]string, 0, len(authors))
+	for _, author := range authors {
+		normalizedAuthors = append(normalizedAuthors, normalizeForKey(author))
+	}
+	sort.Strings(normalizedAuthors)
+
+	return normalizeForKey(title) + "|" + strings.Join(normalizedAuthors, ";")
+}
+
+// normalizeForKey remove acentos e pontuação, converte para minúsculas e reduz os espaços
+func normalizeForKey(value string) string {
+	stripped, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), value)
+	if err != nil {
+		stripped = value
+	}
+
+	cleaned := strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, stripped)
+
+	return strings.Join(strings.Fields(cleaned), " ")
 }
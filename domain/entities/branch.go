@@ -0,0 +1,50 @@
+package entities
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Branch representa uma unidade (filial) da biblioteca
+type Branch struct {
+	gorm.Model
+	Name     string `gorm:"size:100;not null;unique"`
+	Code     string `gorm:"size:20;not null;unique"`
+	Address  string `gorm:"size:255"`
+	Holdings []BookHolding
+}
+
+// BookHolding representa os exemplares de um livro alocados em uma unidade
+type BookHolding struct {
+	gorm.Model
+	BookID    uint   `gorm:"not null;uniqueIndex:idx_book_holdings_book_branch"`
+	Book      Book   `gorm:"foreignKey:BookID"`
+	BranchID  uint   `gorm:"not null;uniqueIndex:idx_book_holdings_book_branch"`
+	Branch    Branch `gorm:"foreignKey:BranchID"`
+	Quantity  int    `gorm:"default:0"`
+	Available int    `gorm:"default:0;check:chk_book_holdings_available,available >= 0 AND available <= quantity"`
+}
+
+// Status possíveis de uma transferência entre unidades
+const (
+	TransferStatusPending   = "pending"
+	TransferStatusCompleted = "completed"
+	TransferStatusRejected  = "rejected"
+)
+
+// TransferRequest representa um pedido de transferência de exemplares entre unidades
+type TransferRequest struct {
+	gorm.Model
+	BookID        uint
+	Book          Book `gorm:"foreignKey:BookID"`
+	FromBranchID  uint
+	FromBranch    Branch `gorm:"foreignKey:FromBranchID"`
+	ToBranchID    uint
+	ToBranch      Branch `gorm:"foreignKey:ToBranchID"`
+	Quantity      int    `gorm:"not null"`
+	Status        string `gorm:"size:20;not null;default:pending"`
+	RequestedByID uint
+	ResolvedByID  *uint
+	ResolvedAt    *time.Time
+}
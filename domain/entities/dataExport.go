@@ -0,0 +1,29 @@
+package entities
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Status possíveis de uma exportação de dados pessoais
+const (
+	DataExportStatusPending   = "pending"
+	DataExportStatusRunning   = "running"
+	DataExportStatusCompleted = "completed"
+	DataExportStatusFailed    = "failed"
+	DataExportStatusExpired   = "expired" // Arquivo já removido
+)
+
+// DataExport representa a exportação dos dados pessoais de um usuário, gerada em segundo plano
+type DataExport struct {
+	gorm.Model
+	UserID      uint   `gorm:"not null;index"`
+	Format      string `gorm:"size:10;not null"`
+	Status      string `gorm:"size:20;not null;default:pending"`
+	FilePath    string `gorm:"size:500"`
+	Size        int64
+	Message     string `gorm:"size:500"`
+	CompletedAt *time.Time
+	ExpiresAt   *time.Time // Depois desta data o arquivo é removido e o link deixa de valer
+}
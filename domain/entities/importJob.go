@@ -0,0 +1,34 @@
+package entities
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Status possíveis de uma importação assíncrona
+const (
+	ImportStatusPending   = "pending"
+	ImportStatusRunning   = "running"
+	ImportStatusCompleted = "completed"
+	ImportStatusFailed    = "failed"
+)
+
+// ImportJob representa uma importação de livros processada em segundo plano
+type ImportJob struct {
+	gorm.Model
+	Format        string `gorm:"size:10;not null"`
+	DryRun        bool
+	Status        string `gorm:"size:20;not null;default:pending"`
+	TotalRows     int
+	ProcessedRows int
+	CreatedRows   int
+	UpdatedRows   int
+	SkippedRows   int
+	FailedRows    int
+	Issues        string `gorm:"type:text"` // Linhas com erro ou ignoradas, em JSON
+	Message       string `gorm:"size:500"`
+	RequestedByID uint
+	StartedAt     *time.Time
+	FinishedAt    *time.Time
+}
@@ -0,0 +1,35 @@
+package entities
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Situações de uma execução de tarefa agendada
+const (
+	JobRunStatusRunning   = "running"
+	JobRunStatusSucceeded = "succeeded"
+	JobRunStatusFailed    = "failed"
+)
+
+// Origens de uma execução de tarefa agendada
+const (
+	JobTriggerSchedule = "schedule" // Horário previsto na expressão cron
+	JobTriggerManual   = "manual"   // Pedido de um administrador
+)
+
+// JobRun registra uma execução de tarefa agendada. O par tarefa e horário previsto é único: a instância
+// que grava o registro primeiro executa a tarefa, e as demais réplicas deixam aquele horário de lado.
+type JobRun struct {
+	gorm.Model
+	JobName      string    `gorm:"size:100;not null;uniqueIndex:idx_job_runs_slot"`
+	ScheduledFor time.Time `gorm:"not null;uniqueIndex:idx_job_runs_slot"`
+	Trigger      string    `gorm:"column:triggered_by;size:20;not null"`
+	Status       string    `gorm:"size:20;not null;index"`
+	Attempts     int
+	Error        string `gorm:"type:text"` // Erro da última tentativa que falhou
+	Instance     string `gorm:"size:255"`  // Réplica que executou a tarefa
+	StartedAt    time.Time
+	FinishedAt   *time.Time
+}
@@ -13,6 +13,8 @@ type Loan struct {
 	User       User `gorm:"foreignKey:UserID"`
 	BookID     uint
 	Book       Book      `gorm:"foreignKey:BookID"`
+	BranchID   *uint     // Unidade de retirada e devolução
+	Branch     *Branch   `gorm:"foreignKey:BranchID"`
 	LoanDate   time.Time `gorm:"not null"`
 	ReturnDate time.Time `gorm:"not null"` // Data prevista para devolução
 	ReturnedAt *time.Time
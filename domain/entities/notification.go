@@ -0,0 +1,38 @@
+package entities
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Tipos de notificação sobre empréstimos
+const (
+	NotificationDueSoon  = "due_soon"  // Lembrete dias antes da data de devolução
+	NotificationDueToday = "due_today" // Lembrete no dia da devolução
+	NotificationOverdue  = "overdue"   // Aviso de atraso, mais enfático a cada nível
+)
+
+// Canais de entrega das notificações
+const (
+	NotificationChannelEmail = "email"
+	NotificationChannelInApp = "in_app" // Caixa de entrada em /api/users/me/notifications
+)
+
+// Notification registra uma notificação enviada a um usuário por um canal. A chave de deduplicação
+// identifica o empréstimo, o tipo, o nível e o canal, para que a varredura diária não repita avisos.
+type Notification struct {
+	gorm.Model
+	UserID   uint   `gorm:"not null;index"`
+	LoanID   *uint  `gorm:"index"`
+	Kind     string `gorm:"size:20;not null"`
+	Level    int    // Nível do aviso de atraso, a partir de 1; zero nos lembretes
+	Channel  string `gorm:"size:20;not null"`
+	DedupKey string `gorm:"size:150;not null;uniqueIndex"`
+	Subject  string `gorm:"size:255;not null"`
+	Body     string `gorm:"type:text;not null"`
+	Attempts int    // Tentativas de entrega pelos canais externos
+	Error    string `gorm:"type:text"` // Erro da última tentativa de entrega
+	SentAt   *time.Time
+	ReadAt   *time.Time
+}
@@ -1,15 +1,42 @@
 package entities
 
 import (
+	"fmt"
+	"time"
+
 	"gorm.io/gorm"
 )
 
 // User representa um usuário do sistema de biblioteca
 type User struct {
 	gorm.Model
-	Name     string `gorm:"size:100;not null"`
-	Email    string `gorm:"size:100;not null;unique"`
-	Password string `gorm:"size:255;not null"`
-	IsAdmin  bool   `gorm:"default:false"`
-	Loans    []Loan
+	Name        string  `gorm:"size:100;not null"`
+	Email       string  `gorm:"size:100;not null;uniqueIndex:idx_users_email_active,where:deleted_at IS NULL"`
+	Password    string  `gorm:"size:255;not null"`
+	IsAdmin     bool    `gorm:"default:false"`
+	IsLibrarian bool    `gorm:"default:false"` // Opera empréstimos apenas na sua unidade
+	BranchID    *uint   // Unidade de lotação do bibliotecário
+	Branch      *Branch `gorm:"foreignKey:BranchID"`
+	Loans       []Loan
+
+	// Canais de notificação desligados pelo usuário. Guardados pelo avesso para que os usuários
+	// já cadastrados, e os novos, recebam por todos os canais até mudarem a preferência.
+	EmailNotificationsOff bool `gorm:"default:false"`
+	InAppNotificationsOff bool `gorm:"default:false"`
+
+	ErasureScheduledAt *time.Time // Fim do prazo para desistir da exclusão pedida pelo usuário
+	ErasedAt           *time.Time // Dados pessoais apagados; o registro permanece pelo histórico de empréstimos
+}
+
+// Anonymize apaga os dados pessoais do usuário, mantendo o registro para o histórico de empréstimos.
+// A senha deixa de ser um hash válido, impedindo qualquer login.
+func (user *User) Anonymize(erasedAt time.Time) {
+	user.Name = "Usuário removido"
+	user.Email = fmt.Sprintf("usuario-%d@removido.invalid", user.ID)
+	user.Password = "!"
+	user.IsAdmin = false
+	user.IsLibrarian = false
+	user.BranchID = nil
+	user.ErasureScheduledAt = nil
+	user.ErasedAt = &erasedAt
 }
@@ -9,4 +9,37 @@ var (
 	ErrInvalidData   = errors.New("dados inválidos")
 	ErrUnauthorized  = errors.New("não autorizado")
 	ErrForbidden     = errors.New("acesso proibido")
+	ErrConflict      = errors.New("operação conflita com o estado atual do registro")
 )
+
+// DuplicateError indica que o registro já existe e identifica o registro existente
+type DuplicateError struct {
+	ExistingID uint
+	Reason     string
+}
+
+// Error retorna a mensagem do erro
+func (e *DuplicateError) Error() string {
+	return ErrAlreadyExists.Error() + ": " + e.Reason
+}
+
+// Unwrap permite comparar o erro com ErrAlreadyExists via errors.Is
+func (e *DuplicateError) Unwrap() error {
+	return ErrAlreadyExists
+}
+
+// RuleError indica uma regra de negócio violada que não se encaixa nos erros comuns.
+// A mensagem é a própria regra; o status da resposta fica a cargo de quem trata o erro.
+type RuleError struct {
+	Message string
+}
+
+// Error retorna a mensagem do erro
+func (e *RuleError) Error() string {
+	return e.Message
+}
+
+// New cria um erro de regra de negócio com a mensagem informada
+func New(message string) error {
+	return &RuleError{Message: message}
+}
@@ -0,0 +1,87 @@
+package isbn
+
+import (
+	"fmt"
+	"strings"
+
+	domainerrors "github.com/henrygoeszanin/api_golang_estudos/domain/errors"
+)
+
+// ErrInvalid é retornado quando o ISBN não tem formato ou dígito verificador válido
+var ErrInvalid = fmt.Errorf("%w: ISBN inválido", domainerrors.ErrInvalidData)
+
+// Normalize valida um ISBN-10 ou ISBN-13 e o devolve no formato ISBN-13, apenas com dígitos.
+// Hífens e espaços são ignorados.
+func Normalize(raw string) (string, error) {
+	digits := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(raw)))
+
+	switch len(digits) {
+	case 10:
+		if !validISBN10(digits) {
+			return "", ErrInvalid
+		}
+		return toISBN13(digits), nil
+	case 13:
+		if !validISBN13(digits) {
+			return "", ErrInvalid
+		}
+		return digits, nil
+	default:
+		return "", ErrInvalid
+	}
+}
+
+// IsValid indica se o valor é um ISBN-10 ou ISBN-13 válido
+func IsValid(raw string) bool {
+	_, err := Normalize(raw)
+	return err == nil
+}
+
+// validISBN10 confere o dígito verificador de um ISBN-10 (módulo 11, com X valendo 10)
+func validISBN10(digits string) bool {
+	sum := 0
+	for i, r := range digits {
+		var value int
+		switch {
+		case r >= '0' && r <= '9':
+			value = int(r - '0')
+		case r == 'X' && i == 9:
+			value = 10
+		default:
+			return false
+		}
+		sum += value * (10 - i)
+	}
+	return sum%11 == 0
+}
+
+// validISBN13 confere o dígito verificador de um ISBN-13 (módulo 10, pesos 1 e 3)
+func validISBN13(digits string) bool {
+	sum := 0
+	for i, r := range digits {
+		if r < '0' || r > '9' {
+			return false
+		}
+		if i%2 == 0 {
+			sum += int(r - '0')
+		} else {
+			sum += int(r-'0') * 3
+		}
+	}
+	return sum%10 == 0
+}
+
+// toISBN13 converte um ISBN-10 válido para ISBN-13 com o prefixo 978
+func toISBN13(isbn10 string) string {
+	base := "978" + isbn10[:9]
+	sum := 0
+	for i, r := range base {
+		if i%2 == 0 {
+			sum += int(r - '0')
+		} else {
+			sum += int(r-'0') * 3
+		}
+	}
+	check := (10 - sum%10) % 10
+	return fmt.Sprintf("%s%d", base, check)
+}
@@ -0,0 +1,98 @@
+package isbn
+
+import (
+	"errors"
+	"testing"
+
+	domainerrors "github.com/henrygoeszanin/api_golang_estudos/domain/errors"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want string
+	}{
+		{"ISBN-13", "9788535910667", "9788535910667"},
+		{"ISBN-13 com hífens", "978-0-306-40615-7", "9780306406157"},
+		{"ISBN-13 com espaços", " 978 0 19 852663 6 ", "9780198526636"},
+		{"ISBN-10", "0306406152", "9780306406157"},
+		{"ISBN-10 com hífens", "0-19-852663-6", "9780198526636"},
+		{"ISBN-10 com X", "080442957X", "9780804429573"},
+		{"ISBN-10 com x minúsculo", "0-439-42089-x", "9780439420891"},
+		{"ISBN-10 com X e espaços", "1 55404 295 X", "9781554042951"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Normalize(tt.raw)
+			if err != nil {
+				t.Fatalf("Normalize(%q): %v", tt.raw, err)
+			}
+			if got != tt.want {
+				t.Errorf("Normalize(%q) = %q, esperado %q", tt.raw, got, tt.want)
+			}
+			if !IsValid(tt.raw) {
+				t.Errorf("IsValid(%q) = false, esperado true", tt.raw)
+			}
+		})
+	}
+}
+
+func TestNormalizeInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+	}{
+		{"vazio", ""},
+		{"curto", "030640615"},
+		{"entre os tamanhos", "97803064061"},
+		{"longo", "97803064061570"},
+		{"ISBN-10 com dígito errado", "0306406153"},
+		{"ISBN-13 com dígito errado", "9780306406158"},
+		{"X fora da última posição", "X306406152"},
+		{"X no ISBN-13", "978030640615X"},
+		{"letras", "03064O6152"},
+		{"outros separadores", "0.306.40615.2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Normalize(tt.raw)
+			if !errors.Is(err, ErrInvalid) {
+				t.Errorf("Normalize(%q) = %q, %v, esperado ErrInvalid", tt.raw, got, err)
+			}
+			if !errors.Is(err, domainerrors.ErrInvalidData) {
+				t.Errorf("Normalize(%q): erro %v deveria equivaler a ErrInvalidData", tt.raw, err)
+			}
+			if IsValid(tt.raw) {
+				t.Errorf("IsValid(%q) = true, esperado false", tt.raw)
+			}
+		})
+	}
+}
+
+func TestToISBN13(t *testing.T) {
+	tests := []struct {
+		isbn10 string
+		want   string
+	}{
+		{"0306406152", "9780306406157"},
+		{"0198526636", "9780198526636"},
+		{"080442957X", "9780804429573"},
+		{"043942089X", "9780439420891"},
+		// Dígito verificador zero no ISBN-13
+		{"8535910654", "9788535910650"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.isbn10, func(t *testing.T) {
+			if got := toISBN13(tt.isbn10); got != tt.want {
+				t.Errorf("toISBN13(%q) = %q, esperado %q", tt.isbn10, got, tt.want)
+			}
+			if !validISBN13(tt.want) {
+				t.Errorf("validISBN13(%q) = false, esperado true", tt.want)
+			}
+		})
+	}
+}
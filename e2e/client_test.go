@@ -0,0 +1,70 @@
+package e2e
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+)
+
+// response guarda a resposta de uma requisição do roteiro
+type response struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// decode interpreta o corpo JSON da resposta
+func (response *response) decode(target interface{}) error {
+	if err := json.Unmarshal(response.body, target); err != nil {
+		return fmt.Errorf("resposta não é o JSON esperado: %w: %s", err, response.body)
+	}
+	return nil
+}
+
+// request descreve uma requisição do roteiro
+type request struct {
+	method      string
+	path        string
+	token       string
+	body        interface{} // Enviado como JSON, exceto quando já for []byte
+	contentType string
+}
+
+// do envia a requisição diretamente ao handler da API, sem abrir uma porta
+func do(handler http.Handler, request request) *response {
+	var body io.Reader
+	contentType := request.contentType
+	switch typed := request.body.(type) {
+	case nil:
+	case []byte:
+		body = bytes.NewReader(typed)
+	default:
+		encoded, err := json.Marshal(typed)
+		if err != nil {
+			panic("e2e: corpo da requisição inválido: " + err.Error())
+		}
+		body = bytes.NewReader(encoded)
+		if contentType == "" {
+			contentType = "application/json"
+		}
+	}
+
+	httpRequest := httptest.NewRequest(request.method, request.path, body)
+	if contentType != "" {
+		httpRequest.Header.Set("Content-Type", contentType)
+	}
+	if request.token != "" {
+		httpRequest.Header.Set("Authorization", "Bearer "+request.token)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httpRequest)
+	return &response{
+		status: recorder.Code,
+		header: recorder.Header(),
+		body:   recorder.Body.Bytes(),
+	}
+}
@@ -0,0 +1,135 @@
+// Package e2e sobe a API completa, com todas as rotas de presentation/routes, sobre um banco SQLite
+// em memória ou sobre os repositórios em memória, e executa um roteiro de ponta a ponta que passa por cada uma delas.
+// O roteiro roda com go test ./e2e/... e não depende de um PostgreSQL.
+package e2e
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+
+	"github.com/henrygoeszanin/api_golang_estudos/config"
+	"github.com/henrygoeszanin/api_golang_estudos/infrastructure/database"
+	"github.com/henrygoeszanin/api_golang_estudos/infrastructure/memory"
+	"github.com/henrygoeszanin/api_golang_estudos/logging"
+	"github.com/henrygoeszanin/api_golang_estudos/presentation/middlewares"
+	"github.com/henrygoeszanin/api_golang_estudos/presentation/routes"
+)
+
+// server é a API montada para o roteiro de ponta a ponta
+type server struct {
+	handler http.Handler
+	db      *gorm.DB
+	runtime *routes.Runtime
+	router  *gin.Engine
+
+	mutex   sync.Mutex
+	visited map[string]bool
+}
+
+// newGormServer monta a API com a configuração do perfil test: banco SQLite em memória, novo a cada chamada,
+// e exportações de dados pessoais em um diretório temporário. Os logs da API são descartados.
+func newGormServer(t *testing.T) *server {
+	return newServer(t, routes.GormRepositories)
+}
+
+// newMemoryServer monta a API como newGormServer, mas com livros, empréstimos, usuários, unidades e
+// transferências nos repositórios em memória. Importações, exportações e auditoria continuam no SQLite em memória.
+func newMemoryServer(t *testing.T) *server {
+	return newServer(t, func(db *gorm.DB) routes.Repositories {
+		store := memory.NewStore()
+		repos := routes.GormRepositories(db)
+		repos.User = memory.NewUserRepository(store)
+		repos.Book = memory.NewBookRepository(store)
+		repos.Loan = memory.NewLoanRepository(store)
+		repos.Branch = memory.NewBranchRepository(store)
+		repos.Transfer = memory.NewTransferRepository(store)
+		return repos
+	})
+}
+
+// newServer monta a API com os repositórios criados por newRepositories sobre o banco do perfil test.
+// As tarefas em segundo plano e o banco são encerrados ao fim do teste.
+func newServer(t *testing.T, newRepositories func(db *gorm.DB) routes.Repositories) *server {
+	t.Helper()
+
+	cfg := config.Defaults(config.ProfileTest)
+	cfg.DataExportDir = t.TempDir()
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := database.SetupDatabase(cfg)
+	if err != nil {
+		t.Fatalf("falha ao abrir o banco de dados: %v", err)
+	}
+
+	server := &server{
+		db:      db,
+		visited: make(map[string]bool),
+	}
+
+	logger := logging.New(io.Discard, cfg.LogLevel, cfg.LogFormat)
+	router := gin.New()
+	router.Use(
+		server.recordRoute,
+		middlewares.RequestID(logger),
+		middlewares.RequestLogger(),
+		middlewares.Recovery(),
+	)
+	server.runtime = routes.SetupRoutesWithRepositories(router, db, newRepositories(db), cfg)
+	server.router = router
+	server.handler = router
+	t.Cleanup(func() { server.close(t) })
+	return server
+}
+
+// recordRoute anota cada rota atendida, para que o roteiro aponte as rotas que não exercitou
+func (server *server) recordRoute(c *gin.Context) {
+	c.Next()
+	if c.FullPath() == "" {
+		return
+	}
+	server.mutex.Lock()
+	server.visited[c.Request.Method+" "+c.FullPath()] = true
+	server.mutex.Unlock()
+}
+
+// unvisited lista as rotas registradas que ainda não receberam nenhuma requisição
+func (server *server) unvisited() []string {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	var unvisited []string
+	for _, route := range server.router.Routes() {
+		key := route.Method + " " + route.Path
+		if !server.visited[key] {
+			unvisited = append(unvisited, key)
+		}
+	}
+	return unvisited
+}
+
+// close encerra as tarefas em segundo plano e fecha o banco
+func (server *server) close(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := server.runtime.BackgroundPool.Shutdown(ctx); err != nil {
+		t.Errorf("falha ao encerrar as tarefas em segundo plano: %v", err)
+	}
+	sqlDB, err := server.db.DB()
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	if err := sqlDB.Close(); err != nil {
+		t.Error(err)
+	}
+}
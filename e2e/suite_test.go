@@ -0,0 +1,544 @@
+package e2e
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TestEndToEnd executa o roteiro com os repositórios GORM e com os repositórios em memória.
+// As etapas dependem umas das outras (usuários, unidades e livros criados no início são usados depois)
+// e seguem sendo executadas mesmo após uma falha, para que o resultado mostre todos os problemas.
+// Ao final, toda rota registrada deve ter recebido ao menos uma requisição.
+func TestEndToEnd(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	backends := []struct {
+		name      string
+		newServer func(t *testing.T) *server
+	}{
+		{"gorm", newGormServer},
+		{"memory", newMemoryServer},
+	}
+	for _, backend := range backends {
+		t.Run(backend.name, func(t *testing.T) {
+			server := backend.newServer(t)
+			run := func(name string, section func(s *suite)) {
+				t.Run(name, func(t *testing.T) {
+					section(&suite{t: t, server: server})
+				})
+			}
+
+			var admin, reader string
+			var centro, norte, bookID uint
+			run("health", func(s *suite) { s.health() })
+			run("accounts", func(s *suite) { admin, reader = s.accounts() })
+			run("users", func(s *suite) { s.users(admin, reader) })
+			run("branches", func(s *suite) { centro, norte = s.branches(admin) })
+			run("books", func(s *suite) { bookID = s.books(admin) })
+			run("imports", func(s *suite) { s.imports(admin) })
+			run("staff", func(s *suite) { s.staff(admin, reader, bookID, centro, norte) })
+			run("loans", func(s *suite) { s.loans(reader, bookID) })
+			run("inventory", func(s *suite) { s.inventory(admin) })
+			run("dataExports", func(s *suite) { s.dataExports(reader) })
+			run("jobs", func(s *suite) { s.jobs(admin, reader) })
+			run("notifications", func(s *suite) { s.notifications(admin, reader, bookID) })
+			run("audit", func(s *suite) { s.audit(admin) })
+
+			for _, route := range server.unvisited() {
+				t.Errorf("rota sem cobertura: %s", route)
+			}
+		})
+	}
+}
+
+// suite guarda o teste da seção em andamento e a API compartilhada entre as seções do roteiro
+type suite struct {
+	t      *testing.T
+	server *server
+}
+
+// call envia a requisição e confere o código de status
+func (s *suite) call(name string, expected int, request request) *response {
+	s.t.Helper()
+	response := do(s.server.handler, request)
+	if response.status != expected {
+		s.t.Errorf("%s [%s %s]: status %d, esperado %d: %s", name, request.method, request.path, response.status, expected, truncate(response.body))
+	}
+	return response
+}
+
+// check confere o conteúdo de uma resposta
+func (s *suite) check(name string, ok bool, format string, args ...interface{}) {
+	s.t.Helper()
+	if !ok {
+		s.t.Errorf("%s: %s", name, fmt.Sprintf(format, args...))
+	}
+}
+
+// decode interpreta a resposta e registra uma falha quando ela não é o JSON esperado
+func (s *suite) decode(name string, response *response, target interface{}) bool {
+	s.t.Helper()
+	if response.status >= 300 {
+		return false
+	}
+	if err := response.decode(target); err != nil {
+		s.check(name, false, "%v", err)
+		return false
+	}
+	return true
+}
+
+// entity é o formato comum das respostas que identificam um registro
+type entity struct {
+	ID     uint   `json:"id"`
+	Status string `json:"status"`
+}
+
+func (s *suite) health() {
+	s.call("health legado", http.StatusOK, request{method: "GET", path: "/api/health"})
+	s.call("liveness", http.StatusOK, request{method: "GET", path: "/api/health/live"})
+	s.call("readiness", http.StatusOK, request{method: "GET", path: "/api/health/ready"})
+	s.call("métricas fora da API", http.StatusNotFound, request{method: "GET", path: "/metrics"})
+	response := do(s.server.runtime.Metrics, request{method: "GET", path: "/metrics"})
+	s.check("métricas no servidor interno", response.status == http.StatusOK && strings.Contains(string(response.body), "library_http_requests_total"),
+		"status %d: %s", response.status, truncate(response.body))
+}
+
+// accounts cria o administrador com o token de instalação, cadastra os demais usuários do roteiro
+// e retorna os tokens do administrador e do leitor
+func (s *suite) accounts() (string, string) {
+	admin := map[string]string{"name": "Administradora", "email": "admin@biblioteca.test", "password": "senha123"}
+	s.call("instalação com token errado", http.StatusForbidden, request{method: "POST", path: "/api/auth/setup",
+		body: withToken(admin, "token-errado")})
+	s.call("instalação do primeiro administrador", http.StatusCreated, request{method: "POST", path: "/api/auth/setup",
+		body: withToken(admin, s.server.runtime.SetupToken)})
+	s.call("token de instalação já utilizado", http.StatusForbidden, request{method: "POST", path: "/api/auth/setup",
+		body: withToken(map[string]string{"name": "Intrusa", "email": "intrusa@biblioteca.test", "password": "senha123"}, s.server.runtime.SetupToken)})
+
+	for _, account := range []struct{ name, email string }{
+		{"Bibliotecário", "bibliotecario@biblioteca.test"},
+		{"Leitora", "leitora@biblioteca.test"},
+	} {
+		response := s.call("cadastro de "+account.email, http.StatusCreated, request{method: "POST", path: "/api/auth/register",
+			body: map[string]string{"name": account.name, "email": account.email, "password": "senha123"}})
+		var registered struct {
+			IsAdmin bool `json:"is_admin"`
+		}
+		if s.decode("cadastro de "+account.email, response, &registered) {
+			s.check("cadastro de "+account.email+" sem privilégios", !registered.IsAdmin, "o cadastro público não deveria criar administradores")
+		}
+	}
+	s.call("cadastro com e-mail inválido", http.StatusBadRequest, request{method: "POST", path: "/api/auth/register",
+		body: map[string]string{"name": "Fulano", "email": "invalido", "password": "senha123"}})
+	s.call("login com senha errada", http.StatusUnauthorized, request{method: "POST", path: "/api/auth/login",
+		body: map[string]string{"email": "leitora@biblioteca.test", "password": "errada"}})
+
+	adminToken := s.login("admin@biblioteca.test", "senha123")
+	reader := s.login("leitora@biblioteca.test", "senha123")
+
+	response := s.call("renovação do token", http.StatusOK, request{method: "GET", path: "/api/auth/refresh", token: reader})
+	var refreshed struct {
+		Token string `json:"token"`
+	}
+	if s.decode("renovação do token", response, &refreshed) {
+		s.check("renovação do token", refreshed.Token != "", "token renovado vazio")
+	}
+	return adminToken, reader
+}
+
+// withToken acrescenta o token de instalação aos dados do cadastro
+func withToken(account map[string]string, token string) map[string]string {
+	body := map[string]string{"token": token}
+	for key, value := range account {
+		body[key] = value
+	}
+	return body
+}
+
+// login autentica o usuário e retorna o token
+func (s *suite) login(email, password string) string {
+	response := s.call("login de "+email, http.StatusOK, request{method: "POST", path: "/api/auth/login",
+		body: map[string]string{"email": email, "password": password}})
+	var body struct {
+		Token string `json:"token"`
+	}
+	s.decode("login de "+email, response, &body)
+	return body.Token
+}
+
+func (s *suite) users(admin, reader string) {
+	response := s.call("perfil do administrador", http.StatusOK, request{method: "GET", path: "/api/users/me", token: admin})
+	var me struct {
+		IsAdmin bool `json:"is_admin"`
+	}
+	if s.decode("perfil do administrador", response, &me) {
+		s.check("administrador da instalação", me.IsAdmin, "o usuário criado com o token de instalação deveria ser administrador")
+	}
+
+	s.call("atualização do próprio perfil", http.StatusOK, request{method: "PUT", path: "/api/users/me", token: reader,
+		body: map[string]string{"name": "Leitora Atualizada"}})
+	s.call("leitor fora da área administrativa", http.StatusForbidden, request{method: "GET", path: "/api/admin/users/", token: reader})
+	s.call("lista de usuários", http.StatusOK, request{method: "GET", path: "/api/admin/users/", token: admin})
+	s.call("usuário por ID", http.StatusOK, request{method: "GET", path: "/api/admin/users/3", token: admin})
+	s.call("atualização de usuário", http.StatusOK, request{method: "PUT", path: "/api/admin/users/3", token: admin,
+		body: map[string]string{"name": "Leitora"}})
+
+	// Conta removida pelo administrador, restaurada e depois apagada
+	s.call("cadastro de usuário temporário", http.StatusCreated, request{method: "POST", path: "/api/auth/register",
+		body: map[string]string{"name": "Temporário", "email": "temporario@biblioteca.test", "password": "senha123"}})
+	s.call("remoção de usuário", http.StatusOK, request{method: "DELETE", path: "/api/admin/users/4", token: admin})
+	s.call("usuários removidos", http.StatusOK, request{method: "GET", path: "/api/admin/users/deleted", token: admin})
+	s.call("restauração de usuário", http.StatusOK, request{method: "PUT", path: "/api/admin/users/4/restore", token: admin})
+	s.call("promoção a administrador", http.StatusOK, request{method: "PUT", path: "/api/admin/users/4/promote", token: admin})
+	s.call("apagamento dos dados pessoais", http.StatusOK, request{method: "POST", path: "/api/admin/users/4/erase", token: admin})
+
+	// Conta removida pelo próprio usuário
+	s.call("cadastro de usuário que sai", http.StatusCreated, request{method: "POST", path: "/api/auth/register",
+		body: map[string]string{"name": "De Saída", "email": "saida@biblioteca.test", "password": "senha123"}})
+	leaving := s.login("saida@biblioteca.test", "senha123")
+	s.call("pedido de exclusão da conta", http.StatusAccepted, request{method: "DELETE", path: "/api/users/me", token: leaving})
+	s.call("login após a exclusão", http.StatusUnauthorized, request{method: "POST", path: "/api/auth/login",
+		body: map[string]string{"email": "saida@biblioteca.test", "password": "senha123"}})
+}
+
+// branches cadastra as unidades do roteiro e retorna os IDs das unidades Centro e Norte
+func (s *suite) branches(admin string) (uint, uint) {
+	var ids []uint
+	for _, branch := range []map[string]string{
+		{"name": "Centro", "code": "CEN"},
+		{"name": "Norte", "code": "NOR"},
+		{"name": "Provisória", "code": "PRV"},
+	} {
+		response := s.call("cadastro da unidade "+branch["code"], http.StatusCreated, request{method: "POST", path: "/api/admin/branches/", token: admin, body: branch})
+		var created entity
+		s.decode("cadastro da unidade "+branch["code"], response, &created)
+		ids = append(ids, created.ID)
+	}
+	s.call("código de unidade repetido", http.StatusBadRequest, request{method: "POST", path: "/api/admin/branches/", token: admin,
+		body: map[string]string{"name": "Outra", "code": "CEN"}})
+
+	s.call("lista de unidades", http.StatusOK, request{method: "GET", path: "/api/branches/"})
+	s.call("unidade por ID", http.StatusOK, request{method: "GET", path: fmt.Sprintf("/api/branches/%d", ids[0])})
+	s.call("atualização de unidade", http.StatusOK, request{method: "PUT", path: fmt.Sprintf("/api/admin/branches/%d", ids[0]), token: admin,
+		body: map[string]string{"address": "Praça Central, 1"}})
+	s.call("remoção de unidade", http.StatusOK, request{method: "DELETE", path: fmt.Sprintf("/api/admin/branches/%d", ids[2]), token: admin})
+
+	s.call("lotação do bibliotecário", http.StatusOK, request{method: "PUT", path: "/api/admin/users/2/branch", token: admin,
+		body: map[string]interface{}{"branch_id": ids[1], "is_librarian": true}})
+	return ids[0], ids[1]
+}
+
+// books cobre o catálogo e a administração do acervo e retorna o ID do livro usado nos empréstimos
+func (s *suite) books(admin string) uint {
+	response := s.call("cadastro de livro", http.StatusCreated, request{method: "POST", path: "/api/admin/books/", token: admin,
+		body: map[string]interface{}{
+			"title":    "Memórias Póstumas de Brás Cubas",
+			"author":   "Machado de Assis",
+			"isbn":     "9788535910667",
+			"language": "pt",
+			"subjects": []string{"Romance"},
+			"quantity": 5,
+		}})
+	var book entity
+	s.decode("cadastro de livro", response, &book)
+	s.call("ISBN repetido", http.StatusConflict, request{method: "POST", path: "/api/admin/books/", token: admin,
+		body: map[string]interface{}{"title": "Outra edição", "author": "Machado de Assis", "isbn": "9788535910667", "quantity": 1}})
+
+	response = s.call("livro para remoção", http.StatusCreated, request{method: "POST", path: "/api/admin/books/", token: admin,
+		body: map[string]interface{}{"title": "Ópera dos Mortos", "author": "Autran Dourado", "quantity": 1}})
+	var removable entity
+	s.decode("livro para remoção", response, &removable)
+
+	// A busca ignora maiúsculas também em letras acentuadas ("ópera" encontra "Ópera")
+	response = s.call("busca no catálogo", http.StatusOK, request{method: "GET", path: "/api/books/?title=" + url.QueryEscape("ópera")})
+	var found []entity
+	if s.decode("busca no catálogo", response, &found) {
+		s.check("busca com acentos", len(found) == 1, "esperado 1 livro com o título Ópera, encontrados %d", len(found))
+	}
+
+	s.call("livro por ID", http.StatusOK, request{method: "GET", path: fmt.Sprintf("/api/books/%d", book.ID)})
+	s.call("livro inexistente", http.StatusNotFound, request{method: "GET", path: "/api/books/9999"})
+	s.call("livro por ISBN", http.StatusOK, request{method: "GET", path: "/api/books/isbn/9788535910667"})
+	s.call("lista administrativa", http.StatusOK, request{method: "GET", path: "/api/admin/books/", token: admin})
+	s.call("atualização de livro", http.StatusOK, request{method: "PUT", path: fmt.Sprintf("/api/admin/books/%d", book.ID), token: admin,
+		body: map[string]interface{}{"publisher": "Companhia das Letras"}})
+	s.call("retirada do acervo", http.StatusOK, request{method: "PUT", path: fmt.Sprintf("/api/admin/books/%d/withdraw", removable.ID), token: admin})
+	s.call("livro retirado não aparece no catálogo", http.StatusNotFound, request{method: "GET", path: fmt.Sprintf("/api/books/%d", removable.ID)})
+	s.call("retorno ao acervo", http.StatusOK, request{method: "PUT", path: fmt.Sprintf("/api/admin/books/%d/reinstate", removable.ID), token: admin})
+	s.call("remoção de livro", http.StatusOK, request{method: "DELETE", path: fmt.Sprintf("/api/admin/books/%d", removable.ID), token: admin})
+	s.call("remoção de livro já removido", http.StatusNotFound, request{method: "DELETE", path: fmt.Sprintf("/api/admin/books/%d", removable.ID), token: admin})
+	s.call("livros removidos", http.StatusOK, request{method: "GET", path: "/api/admin/books/deleted", token: admin})
+	s.call("restauração de livro", http.StatusOK, request{method: "PUT", path: fmt.Sprintf("/api/admin/books/%d/restore", removable.ID), token: admin})
+
+	response = s.call("exportação do acervo", http.StatusOK, request{method: "GET", path: "/api/admin/books/export?format=csv", token: admin})
+	s.check("exportação do acervo em CSV", strings.HasPrefix(response.header.Get("Content-Type"), "text/csv"),
+		"Content-Type %q, esperado text/csv", response.header.Get("Content-Type"))
+	return book.ID
+}
+
+func (s *suite) imports(admin string) {
+	csv := []byte("title,author,quantity\nO Cortiço,Aluísio Azevedo,2\nIracema,José de Alencar,1\n")
+	response := s.call("importação síncrona", http.StatusOK, request{method: "POST", path: "/api/admin/books/import?format=csv", token: admin, body: csv})
+	var report struct {
+		Created int `json:"created"`
+	}
+	if s.decode("importação síncrona", response, &report) {
+		s.check("livros importados", report.Created == 2, "esperados 2 livros criados, criados %d", report.Created)
+	}
+
+	csv = []byte("title,author,quantity\nSenhora,José de Alencar,1\n")
+	response = s.call("importação em segundo plano", http.StatusAccepted, request{method: "POST", path: "/api/admin/books/import?format=csv&async=true", token: admin, body: csv})
+	var accepted struct {
+		Job entity `json:"job"`
+	}
+	if s.decode("importação em segundo plano", response, &accepted) {
+		s.await("andamento da importação", fmt.Sprintf("/api/admin/books/import/%d", accepted.Job.ID), admin)
+	}
+	s.call("lista de importações", http.StatusOK, request{method: "GET", path: "/api/admin/books/import", token: admin})
+}
+
+func (s *suite) staff(admin, reader string, bookID, centro, norte uint) {
+	// O papel de bibliotecário vai no token, por isso o login é feito depois da lotação na unidade Norte
+	librarian := s.login("bibliotecario@biblioteca.test", "senha123")
+	s.call("exemplares no Centro", http.StatusOK, request{method: "PUT", path: fmt.Sprintf("/api/admin/books/%d/holdings/%d", bookID, centro), token: admin,
+		body: map[string]int{"quantity": 3}})
+
+	transfer := func(name string) uint {
+		response := s.call(name, http.StatusCreated, request{method: "POST", path: "/api/staff/transfers", token: librarian,
+			body: map[string]interface{}{"book_id": bookID, "from_branch_id": centro, "to_branch_id": norte, "quantity": 1}})
+		var created entity
+		s.decode(name, response, &created)
+		return created.ID
+	}
+	approved := transfer("pedido de transferência")
+	s.call("aprovação pela unidade de origem", http.StatusOK, request{method: "PUT", path: fmt.Sprintf("/api/staff/transfers/%d/approve", approved), token: admin})
+	rejected := transfer("segundo pedido de transferência")
+	s.call("recusa de transferência", http.StatusOK, request{method: "PUT", path: fmt.Sprintf("/api/staff/transfers/%d/reject", rejected), token: admin})
+	s.call("lista de transferências", http.StatusOK, request{method: "GET", path: "/api/staff/transfers", token: librarian})
+
+	s.call("leitor fora do balcão", http.StatusForbidden, request{method: "POST", path: "/api/staff/loans", token: reader,
+		body: map[string]interface{}{"user_id": 3, "book_id": bookID, "return_date": returnDate()}})
+	response := s.call("empréstimo no balcão", http.StatusCreated, request{method: "POST", path: "/api/staff/loans", token: librarian,
+		body: map[string]interface{}{"user_id": 3, "book_id": bookID, "return_date": returnDate()}})
+	var loan entity
+	s.decode("empréstimo no balcão", response, &loan)
+	s.call("devolução no balcão", http.StatusOK, request{method: "PUT", path: fmt.Sprintf("/api/staff/loans/%d/return", loan.ID), token: librarian})
+}
+
+func (s *suite) loans(reader string, bookID uint) {
+	response := s.call("empréstimo pelo leitor", http.StatusCreated, request{method: "POST", path: "/api/loans/", token: reader,
+		body: map[string]interface{}{"book_id": bookID, "return_date": returnDate()}})
+	var loan entity
+	s.decode("empréstimo pelo leitor", response, &loan)
+
+	s.call("empréstimos do leitor", http.StatusOK, request{method: "GET", path: "/api/loans/", token: reader})
+	s.call("empréstimo por ID", http.StatusOK, request{method: "GET", path: fmt.Sprintf("/api/loans/%d", loan.ID), token: reader})
+	s.call("devolução pelo leitor", http.StatusOK, request{method: "PUT", path: fmt.Sprintf("/api/loans/%d/return", loan.ID), token: reader})
+	s.call("devolução repetida", http.StatusBadRequest, request{method: "PUT", path: fmt.Sprintf("/api/loans/%d/return", loan.ID), token: reader})
+}
+
+// inventory confere os exemplares disponíveis depois dos empréstimos e devoluções do roteiro,
+// que não devem deixar nenhum contador divergente
+func (s *suite) inventory(admin string) {
+	var report struct {
+		Discrepancies []struct {
+			BookID uint `json:"book_id"`
+		} `json:"discrepancies"`
+	}
+	response := s.call("conferência do acervo", http.StatusOK, request{method: "GET", path: "/api/admin/books/inventory", token: admin})
+	if s.decode("conferência do acervo", response, &report) {
+		s.check("contadores conferem", len(report.Discrepancies) == 0, "%d contadores divergentes", len(report.Discrepancies))
+	}
+	response = s.call("correção do acervo", http.StatusOK, request{method: "POST", path: "/api/admin/books/inventory/reconcile", token: admin})
+	if s.decode("correção do acervo", response, &report) {
+		s.check("nada a corrigir", len(report.Discrepancies) == 0, "%d contadores corrigidos", len(report.Discrepancies))
+	}
+}
+
+func (s *suite) dataExports(reader string) {
+	response := s.call("exportação dos dados pessoais", http.StatusOK, request{method: "GET", path: "/api/users/me/export?format=json", token: reader})
+	var bundle struct {
+		Loans []entity `json:"loans"`
+	}
+	if s.decode("exportação dos dados pessoais", response, &bundle) {
+		s.check("empréstimos na exportação", len(bundle.Loans) == 2, "esperados 2 empréstimos, exportados %d", len(bundle.Loans))
+	}
+
+	response = s.call("exportação em segundo plano", http.StatusAccepted, request{method: "GET", path: "/api/users/me/export?async=true", token: reader})
+	var accepted struct {
+		Export entity `json:"export"`
+	}
+	if !s.decode("exportação em segundo plano", response, &accepted) {
+		return
+	}
+	s.await("andamento da exportação", fmt.Sprintf("/api/users/me/exports/%d", accepted.Export.ID), reader)
+
+	response = s.call("lista de exportações", http.StatusOK, request{method: "GET", path: "/api/users/me/exports", token: reader})
+	var exports []struct {
+		Status      string `json:"status"`
+		DownloadURL string `json:"download_url"`
+	}
+	if !s.decode("lista de exportações", response, &exports) || len(exports) == 0 {
+		s.check("lista de exportações", false, "nenhuma exportação listada")
+		return
+	}
+	response = s.call("download da exportação", http.StatusOK, request{method: "GET", path: exports[0].DownloadURL})
+	s.check("arquivo ZIP", strings.HasPrefix(string(response.body), "PK"), "o download não é um arquivo ZIP")
+}
+
+// jobs executa a conferência do acervo pelo agendador e acompanha a execução no histórico
+func (s *suite) jobs(admin, reader string) {
+	response := s.call("tarefas agendadas", http.StatusOK, request{method: "GET", path: "/api/admin/jobs", token: admin})
+	var jobs []struct {
+		Name string `json:"name"`
+	}
+	if s.decode("tarefas agendadas", response, &jobs) {
+		s.check("tarefas registradas", len(jobs) > 0, "nenhuma tarefa registrada")
+	}
+	s.call("tarefas sem permissão", http.StatusForbidden, request{method: "GET", path: "/api/admin/jobs", token: reader})
+	s.call("tarefa inexistente", http.StatusNotFound, request{method: "POST", path: "/api/admin/jobs/nao-existe/run", token: admin})
+
+	response = s.call("execução a pedido", http.StatusAccepted, request{method: "POST", path: "/api/admin/jobs/inventory-report/run", token: admin})
+	var started entity
+	if !s.decode("execução a pedido", response, &started) {
+		return
+	}
+
+	s.awaitRun("histórico de execuções", "inventory-report", started.ID, admin)
+}
+
+// notifications confere as preferências de canal e o lembrete de um empréstimo que vence em dois dias,
+// criado pela tarefa de varredura executada a pedido
+func (s *suite) notifications(admin, reader string, bookID uint) {
+	var preferences struct {
+		Email bool `json:"email"`
+		InApp bool `json:"in_app"`
+	}
+	response := s.call("preferências de notificação", http.StatusOK, request{method: "GET", path: "/api/users/me/notification-preferences", token: reader})
+	if s.decode("preferências de notificação", response, &preferences) {
+		s.check("canais ligados por padrão", preferences.Email && preferences.InApp, "canais desligados: %+v", preferences)
+	}
+	response = s.call("desligar e-mails", http.StatusOK, request{method: "PUT", path: "/api/users/me/notification-preferences", token: reader,
+		body: map[string]bool{"email": false}})
+	if s.decode("desligar e-mails", response, &preferences) {
+		s.check("apenas a caixa de entrada", !preferences.Email && preferences.InApp, "canais inesperados: %+v", preferences)
+	}
+
+	s.call("empréstimo perto do vencimento", http.StatusCreated, request{method: "POST", path: "/api/loans/", token: reader,
+		body: map[string]interface{}{"book_id": bookID, "return_date": time.Now().Add(48 * time.Hour).UTC().Format(time.RFC3339)}})
+	response = s.call("varredura de lembretes", http.StatusAccepted, request{method: "POST", path: "/api/admin/jobs/overdue-scan/run", token: admin})
+	var started entity
+	if !s.decode("varredura de lembretes", response, &started) {
+		return
+	}
+	s.awaitRun("varredura concluída", "overdue-scan", started.ID, admin)
+
+	var inbox struct {
+		Unread        int64 `json:"unread"`
+		Notifications []struct {
+			ID   uint   `json:"id"`
+			Kind string `json:"kind"`
+		} `json:"notifications"`
+	}
+	response = s.call("caixa de entrada", http.StatusOK, request{method: "GET", path: "/api/users/me/notifications", token: reader})
+	if !s.decode("caixa de entrada", response, &inbox) {
+		return
+	}
+	received := inbox.Unread == 1 && len(inbox.Notifications) == 1 && inbox.Notifications[0].Kind == "due_soon"
+	s.check("lembrete recebido", received, "esperado um lembrete não lido: %s", truncate(response.body))
+	if !received {
+		return
+	}
+
+	s.call("notificação de outro usuário", http.StatusNotFound, request{method: "PUT", path: "/api/users/me/notifications/999999/read", token: reader})
+	s.call("marcar como lida", http.StatusOK, request{method: "PUT", path: fmt.Sprintf("/api/users/me/notifications/%d/read", inbox.Notifications[0].ID), token: reader})
+	s.call("marcar todas como lidas", http.StatusOK, request{method: "PUT", path: "/api/users/me/notifications/read", token: reader})
+	response = s.call("não lidas", http.StatusOK, request{method: "GET", path: "/api/users/me/notifications?unread=true", token: reader})
+	if s.decode("não lidas", response, &inbox) {
+		s.check("nada a ler", inbox.Unread == 0 && len(inbox.Notifications) == 0, "ainda há notificações não lidas: %s", truncate(response.body))
+	}
+}
+
+// awaitRun consulta o histórico da tarefa até que a execução informada termine, e confere que ela teve sucesso
+func (s *suite) awaitRun(name, job string, id uint, admin string) {
+	s.t.Helper()
+	path := "/api/admin/jobs/runs?job=" + job + "&limit=1"
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		response := do(s.server.handler, request{method: "GET", path: path, token: admin})
+		var runs []entity
+		if response.status != http.StatusOK || response.decode(&runs) != nil || len(runs) == 0 ||
+			runs[0].Status != "running" || time.Now().After(deadline) {
+			switch {
+			case response.status != http.StatusOK:
+				s.t.Errorf("%s [GET %s]: status %d, esperado 200: %s", name, path, response.status, truncate(response.body))
+			case len(runs) == 0 || runs[0].ID != id:
+				s.t.Errorf("%s [GET %s]: execução %d fora do histórico: %s", name, path, id, truncate(response.body))
+			case runs[0].Status != "succeeded":
+				s.t.Errorf("%s [GET %s]: tarefa terminou com a situação %q: %s", name, path, runs[0].Status, truncate(response.body))
+			}
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func (s *suite) audit(admin string) {
+	response := s.call("trilha de auditoria", http.StatusOK, request{method: "GET", path: "/api/admin/audit", token: admin})
+	var page struct {
+		Total int64 `json:"total"`
+	}
+	if s.decode("trilha de auditoria", response, &page) {
+		s.check("eventos auditados", page.Total > 0, "nenhum evento de auditoria registrado")
+	}
+
+	response = s.call("verificação da trilha", http.StatusOK, request{method: "GET", path: "/api/admin/audit/verify", token: admin})
+	var result struct {
+		Valid  bool   `json:"valid"`
+		Reason string `json:"reason"`
+	}
+	if s.decode("verificação da trilha", response, &result) {
+		s.check("trilha íntegra", result.Valid, "a cadeia de auditoria não confere: %s", result.Reason)
+	}
+}
+
+// await consulta o andamento de uma tarefa em segundo plano até que ela termine, e confere que ela foi concluída
+func (s *suite) await(name, path, token string) {
+	s.t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		response := do(s.server.handler, request{method: "GET", path: path, token: token})
+		var job entity
+		if response.status != http.StatusOK || response.decode(&job) != nil ||
+			(job.Status != "pending" && job.Status != "running") || time.Now().After(deadline) {
+			switch {
+			case response.status != http.StatusOK:
+				s.t.Errorf("%s [GET %s]: status %d, esperado 200: %s", name, path, response.status, truncate(response.body))
+			case job.Status != "completed":
+				s.t.Errorf("%s [GET %s]: tarefa terminou com a situação %q: %s", name, path, job.Status, truncate(response.body))
+			}
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+// returnDate é a data de devolução usada nos empréstimos do roteiro
+func returnDate() string {
+	return time.Now().Add(14 * 24 * time.Hour).UTC().Format(time.RFC3339)
+}
+
+// truncate encurta corpos de resposta longos nas mensagens de falha
+func truncate(body []byte) string {
+	const limit = 300
+	if len(body) > limit {
+		return string(body[:limit]) + "..."
+	}
+	return string(body)
+}
@@ -0,0 +1,818 @@
+package contract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/repositories"
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+	domainerrors "github.com/henrygoeszanin/api_golang_estudos/domain/errors"
+)
+
+// cases lista os casos do roteiro, na ordem de execução
+var cases = []struct {
+	name string
+	run  func(t *testing.T, ctx context.Context, repos Repositories)
+}{
+	{"usuários: cadastro grava o papel informado", createKeepsRole},
+	{"usuários: removidos contam para o primeiro cadastro, não como administradores", deletedUsers},
+	{"usuários: buscas com e sem removidos", findUsers},
+	{"usuários: email único entre os ativos", uniqueActiveEmail},
+	{"usuários: remoção e promoção de inexistentes", deleteAndPromoteMissingUser},
+	{"usuários: restauração", restoreUser},
+	{"usuários: exclusão agendada e apagamento", eraseUser},
+	{"livros: cadastro e busca", createAndFindBook},
+	{"livros: ISBN único entre os não removidos", uniqueActiveISBN},
+	{"livros: filtros da listagem", filterBooks},
+	{"livros: listagem em lotes", listBooksInBatches},
+	{"livros: atualização substitui autores", updateBook},
+	{"livros: lote gravado por inteiro ou nada", saveBatchAtomically},
+	{"livros: inclusão de exemplares", addCopies},
+	{"livros: remoção e restauração", deleteAndRestoreBook},
+	{"livros: soma dos disponíveis em circulação", sumAvailable},
+	{"livros: disponíveis entre zero e a quantidade", availabilityRange},
+	{"livros: conferência dos disponíveis com os empréstimos", reconcileInventory},
+	{"empréstimos: contadores de disponíveis", loanCounters},
+	{"empréstimos: recusas", refuseLoans},
+	{"empréstimos: concorrência pelo último exemplar", concurrentLoans},
+	{"empréstimos: devolução", returnLoan},
+	{"empréstimos: contagens", countLoans},
+	{"empréstimos: listagem dos atrasados", listOverdueLoans},
+	{"empréstimos: livro removido continua no histórico", loanKeepsDeletedBook},
+	{"unidades: cadastro, listagem e remoção", branches},
+	{"unidades: alocação de exemplares", allocateCopies},
+	{"unidades: empréstimo na unidade", loanAtBranch},
+	{"transferências: conclusão e recusa", transfers},
+	{"contexto cancelado", canceledContext},
+}
+
+func createKeepsRole(t *testing.T, ctx context.Context, repos Repositories) {
+	first, err := repos.User.IsFirstUser(ctx)
+	succeed(t, "IsFirstUser", err)
+	expect(t, first, "IsFirstUser deveria ser verdadeiro sem usuários")
+
+	reader := createUser(t, ctx, repos, "leitor@exemplo.com")
+	admin := &entities.User{Name: "Administrador", Email: "admin@exemplo.com", Password: "hash", IsAdmin: true}
+	succeed(t, "Create", repos.User.Create(ctx, admin))
+	expect(t, admin.ID != 0 && reader.ID != 0 && admin.ID != reader.ID, "usuários deveriam receber IDs distintos")
+
+	stored, err := repos.User.FindByID(ctx, reader.ID)
+	succeed(t, "FindByID", err)
+	expect(t, stored != nil && !stored.IsAdmin, "o primeiro cadastro não deveria virar administrador")
+	stored, err = repos.User.FindByID(ctx, admin.ID)
+	succeed(t, "FindByID", err)
+	expect(t, stored != nil && stored.IsAdmin, "o cadastro deveria manter o papel de administrador informado")
+
+	admins, err := repos.User.CountAdmins(ctx)
+	succeed(t, "CountAdmins", err)
+	expect(t, admins == 1, "CountAdmins retornou %d, esperado 1", admins)
+
+	succeed(t, "PromoteToAdmin", repos.User.PromoteToAdmin(ctx, reader.ID))
+	promoted, err := repos.User.FindByID(ctx, reader.ID)
+	succeed(t, "FindByID", err)
+	expect(t, promoted != nil && promoted.IsAdmin, "usuário promovido deveria ser administrador")
+	admins, err = repos.User.CountAdmins(ctx)
+	succeed(t, "CountAdmins", err)
+	expect(t, admins == 2, "CountAdmins retornou %d depois da promoção, esperado 2", admins)
+}
+
+func deletedUsers(t *testing.T, ctx context.Context, repos Repositories) {
+	admin := &entities.User{Name: "Administrador", Email: "admin@exemplo.com", Password: "hash", IsAdmin: true}
+	succeed(t, "Create", repos.User.Create(ctx, admin))
+	succeed(t, "Delete", repos.User.Delete(ctx, admin.ID))
+
+	first, err := repos.User.IsFirstUser(ctx)
+	succeed(t, "IsFirstUser", err)
+	expect(t, !first, "IsFirstUser deveria considerar usuários removidos")
+
+	admins, err := repos.User.CountAdmins(ctx)
+	succeed(t, "CountAdmins", err)
+	expect(t, admins == 0, "CountAdmins não deveria contar administradores removidos (retornou %d)", admins)
+}
+
+func findUsers(t *testing.T, ctx context.Context, repos Repositories) {
+	var created []*entities.User
+	for _, email := range []string{"a@exemplo.com", "b@exemplo.com", "c@exemplo.com"} {
+		user := createUser(t, ctx, repos, email)
+		created = append(created, user)
+	}
+	succeed(t, "Delete", repos.User.Delete(ctx, created[1].ID))
+
+	byEmail, err := repos.User.FindByEmail(ctx, "a@exemplo.com")
+	succeed(t, "FindByEmail", err)
+	expect(t, byEmail != nil && byEmail.ID == created[0].ID, "FindByEmail deveria encontrar o usuário ativo")
+
+	deleted, err := repos.User.FindByEmail(ctx, "b@exemplo.com")
+	succeed(t, "FindByEmail", err)
+	expect(t, deleted == nil, "FindByEmail não deveria encontrar usuário removido")
+
+	deleted, err = repos.User.FindByID(ctx, created[1].ID)
+	succeed(t, "FindByID", err)
+	expect(t, deleted == nil, "FindByID não deveria encontrar usuário removido")
+
+	deleted, err = repos.User.FindByIDWithDeleted(ctx, created[1].ID)
+	succeed(t, "FindByIDWithDeleted", err)
+	expect(t, deleted != nil && deleted.DeletedAt.Valid, "FindByIDWithDeleted deveria encontrar usuário removido")
+
+	missing, err := repos.User.FindByID(ctx, 999)
+	succeed(t, "FindByID", err)
+	expect(t, missing == nil, "FindByID de usuário inexistente deveria retornar nil")
+
+	users, err := repos.User.List(ctx)
+	succeed(t, "List", err)
+	expect(t, len(users) == 2 && users[0].ID == created[0].ID && users[1].ID == created[2].ID,
+		"List deveria retornar os usuários ativos em ordem de ID, recebeu %d", len(users))
+
+	removed, err := repos.User.ListDeleted(ctx)
+	succeed(t, "ListDeleted", err)
+	expect(t, len(removed) == 1 && removed[0].ID == created[1].ID, "ListDeleted deveria retornar apenas o usuário removido")
+}
+
+func uniqueActiveEmail(t *testing.T, ctx context.Context, repos Repositories) {
+	user := createUser(t, ctx, repos, "unico@exemplo.com")
+	err := repos.User.Create(ctx, &entities.User{Name: "Outro", Email: "unico@exemplo.com", Password: "hash"})
+	expectFailure(t, "Create com email repetido", err)
+
+	succeed(t, "Delete", repos.User.Delete(ctx, user.ID))
+	createUser(t, ctx, repos, "unico@exemplo.com")
+}
+
+func deleteAndPromoteMissingUser(t *testing.T, ctx context.Context, repos Repositories) {
+	expectMessage(t, "Delete inexistente", repos.User.Delete(ctx, 999), "usuário não encontrado")
+
+	user := createUser(t, ctx, repos, "leitor@exemplo.com")
+	succeed(t, "Delete", repos.User.Delete(ctx, user.ID))
+	expectMessage(t, "Delete repetido", repos.User.Delete(ctx, user.ID), "usuário não encontrado")
+	expectMessage(t, "PromoteToAdmin inexistente", repos.User.PromoteToAdmin(ctx, 999), "usuário não encontrado")
+}
+
+func restoreUser(t *testing.T, ctx context.Context, repos Repositories) {
+	user := createUser(t, ctx, repos, "volta@exemplo.com")
+	expectIs(t, "Restore de usuário ativo", repos.User.Restore(ctx, user.ID), domainerrors.ErrNotFound)
+
+	succeed(t, "ScheduleErasure", repos.User.ScheduleErasure(ctx, user.ID, time.Now().Add(time.Hour)))
+	other := createUser(t, ctx, repos, "volta@exemplo.com")
+
+	err := repos.User.Restore(ctx, user.ID)
+	var duplicate *domainerrors.DuplicateError
+	if !errors.As(err, &duplicate) || duplicate.ExistingID != other.ID {
+		t.Errorf("Restore com email em uso: esperava DuplicateError do usuário %d, recebeu %v", other.ID, err)
+	}
+
+	succeed(t, "Delete", repos.User.Delete(ctx, other.ID))
+	succeed(t, "Restore", repos.User.Restore(ctx, user.ID))
+	restored, err := repos.User.FindByID(ctx, user.ID)
+	succeed(t, "FindByID", err)
+	expect(t, restored != nil && restored.ErasureScheduledAt == nil, "usuário restaurado deveria estar ativo e sem exclusão agendada")
+}
+
+func eraseUser(t *testing.T, ctx context.Context, repos Repositories) {
+	createUser(t, ctx, repos, "admin@exemplo.com")
+	reader := createUser(t, ctx, repos, "leitor@exemplo.com")
+	book := createBook(t, ctx, repos, "Dom Casmurro", "", 1)
+	loan := newLoan(reader.ID, book.ID, nil)
+	succeed(t, "Create do empréstimo", repos.Loan.Create(ctx, loan))
+
+	eraseAt := time.Now().Add(-time.Minute)
+	expectIs(t, "ScheduleErasure com empréstimo aberto", repos.User.ScheduleErasure(ctx, reader.ID, eraseAt), domainerrors.ErrConflict)
+	expectIs(t, "Erase com empréstimo aberto", repos.User.Erase(ctx, reader.ID, time.Now()), domainerrors.ErrConflict)
+	succeed(t, "ReturnLoan", repos.Loan.ReturnLoan(ctx, loan.ID, time.Now()))
+
+	expectMessage(t, "ScheduleErasure inexistente", repos.User.ScheduleErasure(ctx, 999, eraseAt), "usuário não encontrado")
+	succeed(t, "ScheduleErasure", repos.User.ScheduleErasure(ctx, reader.ID, eraseAt))
+
+	due, err := repos.User.ListErasureDue(ctx, time.Now())
+	succeed(t, "ListErasureDue", err)
+	expect(t, len(due) == 1 && due[0] == reader.ID, "ListErasureDue deveria retornar o usuário agendado, recebeu %v", due)
+
+	succeed(t, "Erase", repos.User.Erase(ctx, reader.ID, time.Now()))
+	erased, err := repos.User.FindByIDWithDeleted(ctx, reader.ID)
+	succeed(t, "FindByIDWithDeleted", err)
+	expect(t, erased != nil && erased.ErasedAt != nil && erased.Email == fmt.Sprintf("usuario-%d@removido.invalid", reader.ID) && erased.DeletedAt.Valid,
+		"usuário apagado deveria estar anonimizado e removido")
+
+	due, err = repos.User.ListErasureDue(ctx, time.Now())
+	succeed(t, "ListErasureDue", err)
+	expect(t, len(due) == 0, "ListErasureDue não deveria retornar usuários já apagados")
+
+	expectIs(t, "Erase repetido", repos.User.Erase(ctx, reader.ID, time.Now()), domainerrors.ErrConflict)
+	expectIs(t, "Restore de usuário apagado", repos.User.Restore(ctx, reader.ID), domainerrors.ErrConflict)
+	expectIs(t, "Erase inexistente", repos.User.Erase(ctx, 999, time.Now()), domainerrors.ErrNotFound)
+}
+
+func createAndFindBook(t *testing.T, ctx context.Context, repos Repositories) {
+	book := createBook(t, ctx, repos, "Dom Casmurro", "9788535910663", 3)
+	expect(t, book.ID != 0 && book.Available == 3 && book.Status == entities.BookStatusActive,
+		"livro cadastrado deveria ter ID, 3 disponíveis e situação ativa")
+
+	// Quantidade zero recebe o valor padrão da coluna
+	single := createBook(t, ctx, repos, "Memórias Póstumas", "", 0)
+	expect(t, single.Quantity == 1 && single.Available == 1, "livro sem quantidade deveria ter 1 exemplar, recebeu %d/%d", single.Available, single.Quantity)
+
+	found, err := repos.Book.FindByID(ctx, book.ID)
+	succeed(t, "FindByID", err)
+	expect(t, found != nil && len(found.Authors) == 1 && found.Authors[0].ID != 0 && found.Authors[0].Name == "Machado de Assis",
+		"FindByID deveria carregar os autores")
+	expect(t, single.Authors[0].ID == book.Authors[0].ID, "autores de mesmo nome deveriam ser reaproveitados")
+
+	byISBN, err := repos.Book.FindByISBN(ctx, "9788535910663")
+	succeed(t, "FindByISBN", err)
+	expect(t, byISBN != nil && byISBN.ID == book.ID, "FindByISBN deveria encontrar o livro")
+
+	missing, err := repos.Book.FindByID(ctx, 999)
+	succeed(t, "FindByID", err)
+	expect(t, missing == nil, "FindByID de livro inexistente deveria retornar nil")
+
+	byKey, err := repos.Book.FindByDedupKey(ctx, book.DedupKey)
+	succeed(t, "FindByDedupKey", err)
+	expect(t, len(byKey) == 1 && byKey[0].ID == book.ID, "FindByDedupKey deveria encontrar o livro")
+
+	byKeys, err := repos.Book.FindByDedupKeys(ctx, []string{book.DedupKey, single.DedupKey, "inexistente"})
+	succeed(t, "FindByDedupKeys", err)
+	expect(t, len(byKeys) == 2 && byKeys[0].ID == book.ID && byKeys[1].ID == single.ID, "FindByDedupKeys deveria encontrar os dois livros em ordem de ID")
+
+	none, err := repos.Book.FindByISBNs(ctx, nil)
+	succeed(t, "FindByISBNs", err)
+	expect(t, len(none) == 0, "FindByISBNs sem ISBNs não deveria encontrar livros")
+}
+
+func uniqueActiveISBN(t *testing.T, ctx context.Context, repos Repositories) {
+	book := createBook(t, ctx, repos, "Dom Casmurro", "9788535910663", 1)
+	isbn := "9788535910663"
+	err := repos.Book.Create(ctx, &entities.Book{Title: "Outro", Author: "Machado de Assis", ISBN: &isbn, Quantity: 1})
+	var taken *domainerrors.DuplicateError
+	if !errors.As(err, &taken) || taken.ExistingID != book.ID {
+		t.Errorf("Create com ISBN repetido: esperava DuplicateError do livro %d, recebeu %v", book.ID, err)
+	}
+
+	succeed(t, "Delete", repos.Book.Delete(ctx, book.ID))
+	replacement := createBook(t, ctx, repos, "Dom Casmurro, nova edição", "9788535910663", 1)
+
+	err = repos.Book.Restore(ctx, book.ID)
+	var duplicate *domainerrors.DuplicateError
+	if !errors.As(err, &duplicate) || duplicate.ExistingID != replacement.ID {
+		t.Errorf("Restore com ISBN em uso: esperava DuplicateError do livro %d, recebeu %v", replacement.ID, err)
+	}
+}
+
+func filterBooks(t *testing.T, ctx context.Context, repos Repositories) {
+	books := []*entities.Book{
+		{Title: "Dom Casmurro", Author: "Machado de Assis", Publisher: "Editora Ática", Language: "pt-BR", PublicationYear: 1899, Quantity: 1,
+			Authors: []entities.Author{{Name: "Machado de Assis"}}, Subjects: []entities.Subject{{Name: "Romance"}}},
+		{Title: "O Cortiço", Author: "Aluísio Azevedo", Publisher: "Editora Ática", Language: "pt-BR", PublicationYear: 1890, Quantity: 1,
+			Authors: []entities.Author{{Name: "Aluísio Azevedo"}}, Subjects: []entities.Subject{{Name: "Naturalismo"}}},
+		{Title: "Dom Quixote", Author: "Miguel de Cervantes", Publisher: "Penguin", Language: "es", PublicationYear: 1605, Quantity: 2,
+			Authors: []entities.Author{{Name: "Miguel de Cervantes"}}, Subjects: []entities.Subject{{Name: "Romance"}}},
+	}
+	for _, book := range books {
+		succeed(t, "Create "+book.Title, repos.Book.Create(ctx, book))
+	}
+
+	// Deixar "O Cortiço" sem exemplares disponíveis
+	reader := createUser(t, ctx, repos, "leitor@exemplo.com")
+	succeed(t, "Create do empréstimo", repos.Loan.Create(ctx, newLoan(reader.ID, books[1].ID, nil)))
+
+	filters := []struct {
+		name   string
+		filter repositories.BookFilter
+		want   []uint
+	}{
+		{"sem filtro", repositories.BookFilter{}, []uint{books[0].ID, books[1].ID, books[2].ID}},
+		{"título", repositories.BookFilter{Title: "  DOM "}, []uint{books[0].ID, books[2].ID}},
+		{"autor", repositories.BookFilter{Author: "azevedo"}, []uint{books[1].ID}},
+		{"assunto", repositories.BookFilter{Subject: "roman"}, []uint{books[0].ID, books[2].ID}},
+		{"editora", repositories.BookFilter{Publisher: "ática"}, []uint{books[0].ID, books[1].ID}},
+		{"idioma", repositories.BookFilter{Language: "PT-br"}, []uint{books[0].ID, books[1].ID}},
+		{"ano", repositories.BookFilter{YearFrom: 1800, YearTo: 1895}, []uint{books[1].ID}},
+		{"disponíveis", repositories.BookFilter{AvailableOnly: true}, []uint{books[0].ID, books[2].ID}},
+		{"situação", repositories.BookFilter{Status: entities.BookStatusWithdrawn}, nil},
+	}
+	for _, testCase := range filters {
+		found, err := repos.Book.List(ctx, testCase.filter)
+		succeed(t, "List "+testCase.name, err)
+		ids := make([]uint, 0, len(found))
+		for _, book := range found {
+			ids = append(ids, book.ID)
+		}
+		expect(t, fmt.Sprint(ids) == fmt.Sprint(append([]uint{}, testCase.want...)),
+			"List com filtro de %s: esperava %v, recebeu %v", testCase.name, testCase.want, ids)
+	}
+}
+
+func listBooksInBatches(t *testing.T, ctx context.Context, repos Repositories) {
+	for i := 1; i <= 5; i++ {
+		createBook(t, ctx, repos, fmt.Sprintf("Livro %d", i), "", 1)
+	}
+
+	var sizes []int
+	var lastID uint
+	err := repos.Book.ListInBatches(ctx, repositories.BookFilter{}, 2, func(books []*entities.Book) error {
+		sizes = append(sizes, len(books))
+		for _, book := range books {
+			if book.ID <= lastID {
+				return fmt.Errorf("lotes fora da ordem de ID")
+			}
+			lastID = book.ID
+		}
+		return nil
+	})
+	succeed(t, "ListInBatches", err)
+	expect(t, fmt.Sprint(sizes) == "[2 2 1]", "ListInBatches deveria entregar lotes de 2, 2 e 1, entregou %v", sizes)
+
+	stop := errors.New("interrompido")
+	err = repos.Book.ListInBatches(ctx, repositories.BookFilter{}, 2, func(books []*entities.Book) error {
+		return stop
+	})
+	expectIs(t, "ListInBatches interrompido", err, stop)
+}
+
+func updateBook(t *testing.T, ctx context.Context, repos Repositories) {
+	book := createBook(t, ctx, repos, "Dom Casmurro", "", 2)
+
+	book.Title = "Dom Casmurro (edição revista)"
+	book.Authors = []entities.Author{{Name: "J. M. Machado de Assis"}}
+	book.Subjects = []entities.Subject{{Name: "Romance"}}
+	succeed(t, "Update", repos.Book.Update(ctx, book))
+
+	found, err := repos.Book.FindByID(ctx, book.ID)
+	succeed(t, "FindByID", err)
+	expect(t, found != nil && found.Title == book.Title && len(found.Authors) == 1 && found.Authors[0].Name == "J. M. Machado de Assis" &&
+		len(found.Subjects) == 1 && found.Available == 2,
+		"Update deveria gravar o título e substituir autores e assuntos")
+}
+
+func saveBatchAtomically(t *testing.T, ctx context.Context, repos Repositories) {
+	existing := createBook(t, ctx, repos, "Existente", "9788535910663", 1)
+
+	first, second := "9788535902778", "9788535902778"
+	err := repos.Book.SaveBatch(ctx, []*entities.Book{
+		{Title: "Primeiro", Author: "A", ISBN: &first, Quantity: 1},
+		{Title: "Segundo", Author: "B", ISBN: &second, Quantity: 1},
+	}, nil)
+	expectFailure(t, "SaveBatch com ISBN repetido", err)
+	all, err := repos.Book.List(ctx, repositories.BookFilter{})
+	succeed(t, "List", err)
+	expect(t, len(all) == 1, "SaveBatch com erro não deveria gravar nenhum livro, há %d", len(all))
+
+	existing.Title = "Existente atualizado"
+	third := "9788535914849"
+	created := &entities.Book{Title: "Novo", Author: "C", ISBN: &third, Quantity: 4}
+	succeed(t, "SaveBatch", repos.Book.SaveBatch(ctx, []*entities.Book{created}, []*entities.Book{existing}))
+	expect(t, created.ID != 0 && created.Available == 4, "SaveBatch deveria cadastrar o livro com todos os exemplares disponíveis")
+
+	found, err := repos.Book.FindByISBNs(ctx, []string{"9788535910663", third})
+	succeed(t, "FindByISBNs", err)
+	expect(t, len(found) == 2 && found[0].Title == "Existente atualizado", "SaveBatch deveria cadastrar e atualizar os livros")
+}
+
+func addCopies(t *testing.T, ctx context.Context, repos Repositories) {
+	book := createBook(t, ctx, repos, "Dom Casmurro", "", 2)
+	succeed(t, "AddCopies", repos.Book.AddCopies(ctx, book.ID, 3))
+	found, err := repos.Book.FindByID(ctx, book.ID)
+	succeed(t, "FindByID", err)
+	expect(t, found != nil && found.Quantity == 5 && found.Available == 5, "AddCopies deveria somar exemplares e disponíveis")
+	expectIs(t, "AddCopies inexistente", repos.Book.AddCopies(ctx, 999, 1), domainerrors.ErrNotFound)
+}
+
+func deleteAndRestoreBook(t *testing.T, ctx context.Context, repos Repositories) {
+	reader := createUser(t, ctx, repos, "leitor@exemplo.com")
+	first := createBook(t, ctx, repos, "Dom Casmurro", "9788535910663", 1)
+	second := createBook(t, ctx, repos, "Quincas Borba", "", 1)
+
+	loan := newLoan(reader.ID, first.ID, nil)
+	succeed(t, "Create do empréstimo", repos.Loan.Create(ctx, loan))
+	err := repos.Book.Delete(ctx, first.ID)
+	expectIs(t, "Delete com empréstimo aberto", err, domainerrors.ErrConflict)
+	expectMessage(t, "Delete com empréstimo aberto", err, domainerrors.ErrConflict.Error()+": livro possui 1 empréstimo(s) em aberto")
+	succeed(t, "ReturnLoan", repos.Loan.ReturnLoan(ctx, loan.ID, time.Now()))
+
+	succeed(t, "Delete", repos.Book.Delete(ctx, first.ID))
+	succeed(t, "Delete", repos.Book.Delete(ctx, second.ID))
+	expectIs(t, "Delete repetido", repos.Book.Delete(ctx, first.ID), domainerrors.ErrNotFound)
+
+	found, err := repos.Book.FindByID(ctx, first.ID)
+	succeed(t, "FindByID", err)
+	expect(t, found == nil, "FindByID não deveria encontrar livro removido")
+
+	deleted, err := repos.Book.ListDeleted(ctx)
+	succeed(t, "ListDeleted", err)
+	expect(t, len(deleted) == 2 && deleted[0].ID == second.ID && deleted[1].ID == first.ID,
+		"ListDeleted deveria listar os removidos do mais recente ao mais antigo")
+
+	expectIs(t, "Restore de livro ativo", repos.Book.Restore(ctx, 999), domainerrors.ErrNotFound)
+	succeed(t, "Restore", repos.Book.Restore(ctx, first.ID))
+	expectIs(t, "Restore repetido", repos.Book.Restore(ctx, first.ID), domainerrors.ErrNotFound)
+	restored, err := repos.Book.FindByID(ctx, first.ID)
+	succeed(t, "FindByID", err)
+	expect(t, restored != nil && restored.Available == 1, "livro restaurado deveria voltar com os exemplares disponíveis")
+}
+
+func sumAvailable(t *testing.T, ctx context.Context, repos Repositories) {
+	createBook(t, ctx, repos, "Dom Casmurro", "", 2)
+	withdrawn := createBook(t, ctx, repos, "Helena", "", 3)
+	deleted := createBook(t, ctx, repos, "Iaiá Garcia", "", 4)
+
+	now := time.Now()
+	withdrawn.Status = entities.BookStatusWithdrawn
+	withdrawn.WithdrawnAt = &now
+	succeed(t, "Update", repos.Book.Update(ctx, withdrawn))
+	succeed(t, "Delete", repos.Book.Delete(ctx, deleted.ID))
+
+	total, err := repos.Book.SumAvailable(ctx)
+	succeed(t, "SumAvailable", err)
+	expect(t, total == 2, "SumAvailable deveria considerar apenas livros ativos não removidos, somou %d", total)
+}
+
+func availabilityRange(t *testing.T, ctx context.Context, repos Repositories) {
+	book := createBook(t, ctx, repos, "Dom Casmurro", "", 2)
+
+	for _, invalid := range []int{3, -1} {
+		stored, err := repos.Book.FindByID(ctx, book.ID)
+		succeed(t, "FindByID", err)
+		stored.Available = invalid
+		expectFailure(t, fmt.Sprintf("Update com %d disponíveis", invalid), repos.Book.Update(ctx, stored))
+	}
+
+	availableCopies := available(t, ctx, repos, book.ID)
+	expect(t, availableCopies == 2, "atualizações recusadas não deveriam alterar os disponíveis, encontrados %d", availableCopies)
+}
+
+func reconcileInventory(t *testing.T, ctx context.Context, repos Repositories) {
+	norte := createBranch(t, ctx, repos, "Norte", "NOR")
+	book := createBook(t, ctx, repos, "Dom Casmurro", "", 3)
+	reader := createUser(t, ctx, repos, "leitor@exemplo.com")
+	succeed(t, "SetHoldingQuantity", repos.Branch.SetHoldingQuantity(ctx, book.ID, norte.ID, 2))
+	for _, loan := range []*entities.Loan{newLoan(reader.ID, book.ID, &norte.ID), newLoan(reader.ID, book.ID, nil)} {
+		succeed(t, "Create", repos.Loan.Create(ctx, loan))
+	}
+
+	discrepancies, err := repos.Book.ReconcileInventory(ctx, false)
+	succeed(t, "ReconcileInventory", err)
+	expect(t, len(discrepancies) == 0, "contadores mantidos pelos empréstimos não deveriam divergir, encontradas %d divergências", len(discrepancies))
+
+	// Contador do livro fora de sincronia: 3 disponíveis com 2 empréstimos em aberto
+	stored, err := repos.Book.FindByID(ctx, book.ID)
+	succeed(t, "FindByID", err)
+	stored.Available = 3
+	succeed(t, "Update", repos.Book.Update(ctx, stored))
+
+	for _, repair := range []bool{false, true} {
+		discrepancies, err := repos.Book.ReconcileInventory(ctx, repair)
+		succeed(t, "ReconcileInventory", err)
+		if len(discrepancies) != 1 {
+			t.Fatalf("ReconcileInventory(%v) retornou %d divergências, esperada 1", repair, len(discrepancies))
+		}
+		found := discrepancies[0]
+		expect(t, found.BookID == book.ID && found.BranchID == nil && found.Title == "Dom Casmurro" &&
+			found.Quantity == 3 && found.Available == 3 && found.OpenLoans == 2 && found.Expected == 1,
+			"divergência inesperada: %+v", found)
+	}
+
+	availableCopies := available(t, ctx, repos, book.ID)
+	expect(t, availableCopies == 1, "a correção deveria gravar 1 disponível, encontrados %d", availableCopies)
+	if _, holdingAvailable := holding(t, ctx, repos, book.ID, norte.ID); holdingAvailable != 1 {
+		t.Errorf("a correção não deveria alterar a unidade, que confere: %d disponíveis", holdingAvailable)
+	}
+
+	discrepancies, err = repos.Book.ReconcileInventory(ctx, false)
+	succeed(t, "ReconcileInventory", err)
+	expect(t, len(discrepancies) == 0, "depois da correção não deveria haver divergências, encontradas %d", len(discrepancies))
+}
+
+func loanCounters(t *testing.T, ctx context.Context, repos Repositories) {
+	reader := createUser(t, ctx, repos, "leitor@exemplo.com")
+	book := createBook(t, ctx, repos, "Dom Casmurro", "", 2)
+
+	loan := newLoan(reader.ID, book.ID, nil)
+	succeed(t, "Create", repos.Loan.Create(ctx, loan))
+	expect(t, loan.ID != 0, "empréstimo cadastrado deveria receber ID")
+	if count := available(t, ctx, repos, book.ID); count != 1 {
+		t.Errorf("empréstimo deveria consumir um exemplar: %d disponíveis", count)
+	}
+
+	succeed(t, "Create", repos.Loan.Create(ctx, newLoan(reader.ID, book.ID, nil)))
+	err := repos.Loan.Create(ctx, newLoan(reader.ID, book.ID, nil))
+	expectMessage(t, "Create sem exemplares", err, "livro não disponível para empréstimo")
+	if count := available(t, ctx, repos, book.ID); count != 0 {
+		t.Errorf("empréstimo recusado não deveria alterar os disponíveis: %d disponíveis", count)
+	}
+
+	found, err := repos.Loan.FindByID(ctx, loan.ID)
+	succeed(t, "FindByID", err)
+	expect(t, found != nil && found.Book.ID == book.ID && found.User.ID == reader.ID && found.Branch == nil && !found.IsReturned,
+		"FindByID deveria carregar livro e usuário do empréstimo")
+}
+
+func refuseLoans(t *testing.T, ctx context.Context, repos Repositories) {
+	reader := createUser(t, ctx, repos, "leitor@exemplo.com")
+	expectIs(t, "Create com livro inexistente", repos.Loan.Create(ctx, newLoan(reader.ID, 999, nil)), gorm.ErrRecordNotFound)
+
+	book := createBook(t, ctx, repos, "Helena", "", 1)
+	now := time.Now()
+	book.Status = entities.BookStatusWithdrawn
+	book.WithdrawnAt = &now
+	succeed(t, "Update", repos.Book.Update(ctx, book))
+	expectMessage(t, "Create com livro retirado", repos.Loan.Create(ctx, newLoan(reader.ID, book.ID, nil)), "livro retirado do acervo")
+
+	deleted := createBook(t, ctx, repos, "Iaiá Garcia", "", 1)
+	succeed(t, "Delete", repos.Book.Delete(ctx, deleted.ID))
+	expectIs(t, "Create com livro removido", repos.Loan.Create(ctx, newLoan(reader.ID, deleted.ID, nil)), gorm.ErrRecordNotFound)
+}
+
+func concurrentLoans(t *testing.T, ctx context.Context, repos Repositories) {
+	reader := createUser(t, ctx, repos, "leitor@exemplo.com")
+	book := createBook(t, ctx, repos, "Dom Casmurro", "", 1)
+
+	const attempts = 8
+	var wg sync.WaitGroup
+	errs := make(chan error, attempts)
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- repos.Loan.Create(ctx, newLoan(reader.ID, book.ID, nil))
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	succeeded := 0
+	for err := range errs {
+		if err == nil {
+			succeeded++
+		}
+	}
+	expect(t, succeeded == 1, "apenas um empréstimo concorrente deveria ser aceito, foram %d", succeeded)
+	if count := available(t, ctx, repos, book.ID); count != 0 {
+		t.Errorf("empréstimos concorrentes deveriam deixar 0 disponíveis: %d", count)
+	}
+}
+
+func returnLoan(t *testing.T, ctx context.Context, repos Repositories) {
+	reader := createUser(t, ctx, repos, "leitor@exemplo.com")
+	book := createBook(t, ctx, repos, "Dom Casmurro", "", 1)
+	loan := newLoan(reader.ID, book.ID, nil)
+	succeed(t, "Create", repos.Loan.Create(ctx, loan))
+
+	returnedAt := time.Now()
+	succeed(t, "ReturnLoan", repos.Loan.ReturnLoan(ctx, loan.ID, returnedAt))
+	if count := available(t, ctx, repos, book.ID); count != 1 {
+		t.Errorf("devolução deveria liberar o exemplar: %d disponíveis", count)
+	}
+
+	found, err := repos.Loan.FindByID(ctx, loan.ID)
+	succeed(t, "FindByID", err)
+	expect(t, found != nil && found.IsReturned && found.ReturnedAt != nil && found.ReturnedAt.Sub(returnedAt).Abs() < time.Second,
+		"empréstimo devolvido deveria registrar a data de devolução")
+
+	expectMessage(t, "ReturnLoan repetido", repos.Loan.ReturnLoan(ctx, loan.ID, time.Now()), "empréstimo já foi devolvido")
+	expectIs(t, "ReturnLoan inexistente", repos.Loan.ReturnLoan(ctx, 999, time.Now()), gorm.ErrRecordNotFound)
+
+	missing, err := repos.Loan.FindByID(ctx, 999)
+	succeed(t, "FindByID", err)
+	expect(t, missing == nil, "FindByID de empréstimo inexistente deveria retornar nil")
+}
+
+func countLoans(t *testing.T, ctx context.Context, repos Repositories) {
+	reader := createUser(t, ctx, repos, "leitor@exemplo.com")
+	other := createUser(t, ctx, repos, "outro@exemplo.com")
+	book := createBook(t, ctx, repos, "Dom Casmurro", "", 5)
+
+	overdue := newLoan(reader.ID, book.ID, nil)
+	overdue.LoanDate = time.Now().Add(-30 * 24 * time.Hour)
+	overdue.ReturnDate = time.Now().Add(-16 * 24 * time.Hour)
+	returned := newLoan(reader.ID, book.ID, nil)
+	open := newLoan(other.ID, book.ID, nil)
+	for _, loan := range []*entities.Loan{overdue, returned, open} {
+		succeed(t, "Create", repos.Loan.Create(ctx, loan))
+	}
+	succeed(t, "ReturnLoan", repos.Loan.ReturnLoan(ctx, returned.ID, time.Now()))
+
+	byUser, err := repos.Loan.CountByUserID(ctx, reader.ID)
+	succeed(t, "CountByUserID", err)
+	activeLoans, err := repos.Loan.CountActive(ctx)
+	succeed(t, "CountActive", err)
+	overdueLoans, err := repos.Loan.CountOverdue(ctx, time.Now())
+	succeed(t, "CountOverdue", err)
+	expect(t, byUser == 2 && activeLoans == 2 && overdueLoans == 1,
+		"contagens esperadas 2/2/1, recebidas %d/%d/%d", byUser, activeLoans, overdueLoans)
+
+	loans, err := repos.Loan.FindByUserID(ctx, reader.ID)
+	succeed(t, "FindByUserID", err)
+	expect(t, len(loans) == 2 && loans[0].ID == overdue.ID && loans[0].Book.Title == "Dom Casmurro",
+		"FindByUserID deveria retornar os empréstimos do usuário com o livro")
+
+	// Update grava a prorrogação do prazo
+	overdue.ReturnDate = time.Now().Add(7 * 24 * time.Hour)
+	succeed(t, "Update", repos.Loan.Update(ctx, overdue))
+	overdueLoans, err = repos.Loan.CountOverdue(ctx, time.Now())
+	succeed(t, "CountOverdue", err)
+	expect(t, overdueLoans == 0, "empréstimo prorrogado não deveria estar atrasado")
+}
+
+func listOverdueLoans(t *testing.T, ctx context.Context, repos Repositories) {
+	reader := createUser(t, ctx, repos, "leitor@exemplo.com")
+	book := createBook(t, ctx, repos, "Dom Casmurro", "", 5)
+
+	// Criados fora da ordem de atraso: a listagem começa pelo mais atrasado
+	lessLate := newLoan(reader.ID, book.ID, nil)
+	lessLate.ReturnDate = time.Now().Add(-2 * 24 * time.Hour)
+	mostLate := newLoan(reader.ID, book.ID, nil)
+	mostLate.ReturnDate = time.Now().Add(-10 * 24 * time.Hour)
+	returned := newLoan(reader.ID, book.ID, nil)
+	returned.ReturnDate = time.Now().Add(-5 * 24 * time.Hour)
+	onTime := newLoan(reader.ID, book.ID, nil)
+	for _, loan := range []*entities.Loan{lessLate, mostLate, returned, onTime} {
+		succeed(t, "Create", repos.Loan.Create(ctx, loan))
+	}
+	succeed(t, "ReturnLoan", repos.Loan.ReturnLoan(ctx, returned.ID, time.Now()))
+
+	loans, err := repos.Loan.ListOverdue(ctx, time.Now())
+	succeed(t, "ListOverdue", err)
+	if len(loans) != 2 {
+		t.Fatalf("ListOverdue retornou %d empréstimos, esperados 2", len(loans))
+	}
+	expect(t, loans[0].ID == mostLate.ID && loans[1].ID == lessLate.ID && loans[0].Book.Title == "Dom Casmurro" && loans[0].User.Email == reader.Email,
+		"ListOverdue deveria listar do mais atrasado ao mais recente, com o livro e o usuário")
+}
+
+func loanKeepsDeletedBook(t *testing.T, ctx context.Context, repos Repositories) {
+	reader := createUser(t, ctx, repos, "leitor@exemplo.com")
+	book := createBook(t, ctx, repos, "Dom Casmurro", "", 1)
+	loan := newLoan(reader.ID, book.ID, nil)
+	succeed(t, "Create", repos.Loan.Create(ctx, loan))
+	succeed(t, "ReturnLoan", repos.Loan.ReturnLoan(ctx, loan.ID, time.Now()))
+	succeed(t, "Delete", repos.Book.Delete(ctx, book.ID))
+
+	found, err := repos.Loan.FindByID(ctx, loan.ID)
+	succeed(t, "FindByID", err)
+	expect(t, found != nil && found.Book.ID == book.ID && found.Book.Title == "Dom Casmurro",
+		"empréstimo deveria carregar o livro mesmo depois da remoção")
+}
+
+func branches(t *testing.T, ctx context.Context, repos Repositories) {
+	norte := createBranch(t, ctx, repos, "Norte", "NOR")
+	centro := createBranch(t, ctx, repos, "Centro", "CEN")
+	expectFailure(t, "Create com código repetido", repos.Branch.Create(ctx, &entities.Branch{Name: "Outra", Code: "NOR"}))
+	expectFailure(t, "Create com nome repetido", repos.Branch.Create(ctx, &entities.Branch{Name: "Norte", Code: "OUT"}))
+
+	list, err := repos.Branch.List(ctx)
+	succeed(t, "List", err)
+	expect(t, len(list) == 2 && list[0].ID == centro.ID && list[1].ID == norte.ID, "List deveria ordenar as unidades pelo nome")
+
+	centro.Address = "Praça Central, 1"
+	succeed(t, "Update", repos.Branch.Update(ctx, centro))
+	found, err := repos.Branch.FindByID(ctx, centro.ID)
+	succeed(t, "FindByID", err)
+	expect(t, found != nil && found.Address == "Praça Central, 1", "Update deveria gravar o endereço")
+
+	book := createBook(t, ctx, repos, "Dom Casmurro", "", 2)
+	succeed(t, "SetHoldingQuantity", repos.Branch.SetHoldingQuantity(ctx, book.ID, norte.ID, 1))
+	expectMessage(t, "Delete com exemplares", repos.Branch.Delete(ctx, norte.ID), "unidade possui exemplares alocados")
+	succeed(t, "SetHoldingQuantity", repos.Branch.SetHoldingQuantity(ctx, book.ID, norte.ID, 0))
+	succeed(t, "Delete", repos.Branch.Delete(ctx, norte.ID))
+	expectMessage(t, "Delete repetido", repos.Branch.Delete(ctx, norte.ID), "unidade não encontrada")
+
+	deleted, err := repos.Branch.FindByID(ctx, norte.ID)
+	succeed(t, "FindByID", err)
+	expect(t, deleted == nil, "FindByID não deveria encontrar unidade removida")
+
+	// Nome e código continuam reservados pela unidade removida
+	expectFailure(t, "Create com código de unidade removida", repos.Branch.Create(ctx, &entities.Branch{Name: "Norte Nova", Code: "NOR"}))
+}
+
+func allocateCopies(t *testing.T, ctx context.Context, repos Repositories) {
+	norte := createBranch(t, ctx, repos, "Norte", "NOR")
+	sul := createBranch(t, ctx, repos, "Sul", "SUL")
+	book := createBook(t, ctx, repos, "Dom Casmurro", "", 4)
+
+	expectMessage(t, "SetHoldingQuantity com livro inexistente", repos.Branch.SetHoldingQuantity(ctx, 999, norte.ID, 1), "livro não encontrado")
+	expectMessage(t, "SetHoldingQuantity com unidade inexistente", repos.Branch.SetHoldingQuantity(ctx, book.ID, 999, 1), "unidade não encontrada")
+
+	succeed(t, "SetHoldingQuantity", repos.Branch.SetHoldingQuantity(ctx, book.ID, norte.ID, 3))
+	expectMessage(t, "SetHoldingQuantity acima do total", repos.Branch.SetHoldingQuantity(ctx, book.ID, sul.ID, 2),
+		"quantidade excede o total de exemplares do livro")
+
+	// Com o único exemplar sem unidade emprestado, não há o que alocar no Sul
+	reader := createUser(t, ctx, repos, "leitor@exemplo.com")
+	succeed(t, "Create do empréstimo", repos.Loan.Create(ctx, newLoan(reader.ID, book.ID, nil)))
+	expectMessage(t, "Create do empréstimo sem unidade", repos.Loan.Create(ctx, newLoan(reader.ID, book.ID, nil)),
+		"livro disponível apenas em unidades, informe a unidade de retirada")
+	expectMessage(t, "SetHoldingQuantity sem exemplares livres", repos.Branch.SetHoldingQuantity(ctx, book.ID, sul.ID, 1),
+		"não há exemplares livres suficientes para alocar na unidade")
+
+	found, err := repos.Branch.FindHolding(ctx, book.ID, norte.ID)
+	succeed(t, "FindHolding", err)
+	expect(t, found != nil && found.Quantity == 3 && found.Available == 3 && found.Branch.Name == "Norte",
+		"FindHolding deveria retornar os exemplares com a unidade")
+
+	missing, err := repos.Branch.FindHolding(ctx, book.ID, sul.ID)
+	succeed(t, "FindHolding", err)
+	expect(t, missing == nil, "FindHolding sem exemplares na unidade deveria retornar nil")
+
+	preloaded, err := repos.Book.FindByID(ctx, book.ID)
+	succeed(t, "FindByID", err)
+	expect(t, preloaded != nil && len(preloaded.Holdings) == 1 && preloaded.Holdings[0].Branch.Code == "NOR",
+		"FindByID do livro deveria carregar os exemplares por unidade")
+}
+
+func loanAtBranch(t *testing.T, ctx context.Context, repos Repositories) {
+	norte := createBranch(t, ctx, repos, "Norte", "NOR")
+	sul := createBranch(t, ctx, repos, "Sul", "SUL")
+	book := createBook(t, ctx, repos, "Dom Casmurro", "", 2)
+	reader := createUser(t, ctx, repos, "leitor@exemplo.com")
+	succeed(t, "SetHoldingQuantity", repos.Branch.SetHoldingQuantity(ctx, book.ID, norte.ID, 1))
+
+	expectMessage(t, "Create na unidade sem exemplares", repos.Loan.Create(ctx, newLoan(reader.ID, book.ID, &sul.ID)),
+		"livro não possui exemplares nesta unidade")
+
+	loan := newLoan(reader.ID, book.ID, &norte.ID)
+	succeed(t, "Create na unidade", repos.Loan.Create(ctx, loan))
+	if _, holdingAvailable := holding(t, ctx, repos, book.ID, norte.ID); holdingAvailable != 0 {
+		t.Errorf("empréstimo deveria consumir o exemplar da unidade: %d disponíveis", holdingAvailable)
+	}
+	expectMessage(t, "Create na unidade esgotada", repos.Loan.Create(ctx, newLoan(reader.ID, book.ID, &norte.ID)),
+		"livro não disponível para empréstimo nesta unidade")
+	expectMessage(t, "SetHoldingQuantity abaixo dos emprestados", repos.Branch.SetHoldingQuantity(ctx, book.ID, norte.ID, 0),
+		"quantidade menor que o número de exemplares emprestados na unidade")
+
+	found, err := repos.Loan.FindByID(ctx, loan.ID)
+	succeed(t, "FindByID", err)
+	expect(t, found != nil && found.Branch != nil && found.Branch.ID == norte.ID, "FindByID deveria carregar a unidade do empréstimo")
+
+	succeed(t, "ReturnLoan", repos.Loan.ReturnLoan(ctx, loan.ID, time.Now()))
+	if _, holdingAvailable := holding(t, ctx, repos, book.ID, norte.ID); holdingAvailable != 1 {
+		t.Errorf("devolução deveria liberar o exemplar na unidade: %d disponíveis", holdingAvailable)
+	}
+	if count := available(t, ctx, repos, book.ID); count != 2 {
+		t.Errorf("devolução deveria liberar o exemplar do livro: %d disponíveis", count)
+	}
+}
+
+func transfers(t *testing.T, ctx context.Context, repos Repositories) {
+	norte := createBranch(t, ctx, repos, "Norte", "NOR")
+	sul := createBranch(t, ctx, repos, "Sul", "SUL")
+	leste := createBranch(t, ctx, repos, "Leste", "LES")
+	book := createBook(t, ctx, repos, "Dom Casmurro", "", 3)
+	admin := createUser(t, ctx, repos, "admin@exemplo.com")
+	succeed(t, "SetHoldingQuantity", repos.Branch.SetHoldingQuantity(ctx, book.ID, norte.ID, 3))
+
+	complete := &entities.TransferRequest{BookID: book.ID, FromBranchID: norte.ID, ToBranchID: sul.ID, Quantity: 2, RequestedByID: admin.ID}
+	succeed(t, "Create", repos.Transfer.Create(ctx, complete))
+	expect(t, complete.ID != 0 && complete.Status == entities.TransferStatusPending, "transferência deveria ser criada pendente")
+	tooMany := &entities.TransferRequest{BookID: book.ID, FromBranchID: norte.ID, ToBranchID: leste.ID, Quantity: 2, RequestedByID: admin.ID}
+	succeed(t, "Create", repos.Transfer.Create(ctx, tooMany))
+	empty := &entities.TransferRequest{BookID: book.ID, FromBranchID: leste.ID, ToBranchID: norte.ID, Quantity: 1, RequestedByID: admin.ID}
+	succeed(t, "Create", repos.Transfer.Create(ctx, empty))
+
+	succeed(t, "Complete", repos.Transfer.Complete(ctx, complete.ID, admin.ID))
+	if quantity, holdingAvailable := holding(t, ctx, repos, book.ID, sul.ID); quantity != 2 || holdingAvailable != 2 {
+		t.Errorf("transferência deveria levar 2 exemplares ao Sul: %d/%d", holdingAvailable, quantity)
+	}
+	if quantity, holdingAvailable := holding(t, ctx, repos, book.ID, norte.ID); quantity != 1 || holdingAvailable != 1 {
+		t.Errorf("transferência deveria deixar 1 exemplar no Norte: %d/%d", holdingAvailable, quantity)
+	}
+	expectMessage(t, "Complete repetido", repos.Transfer.Complete(ctx, complete.ID, admin.ID), "transferência já foi resolvida")
+	expectMessage(t, "Complete sem exemplares suficientes", repos.Transfer.Complete(ctx, tooMany.ID, admin.ID),
+		"unidade de origem não possui exemplares disponíveis suficientes")
+	expectMessage(t, "Complete sem exemplares na origem", repos.Transfer.Complete(ctx, empty.ID, admin.ID),
+		"unidade de origem não possui exemplares do livro")
+	expectIs(t, "Complete inexistente", repos.Transfer.Complete(ctx, 999, admin.ID), gorm.ErrRecordNotFound)
+
+	succeed(t, "Reject", repos.Transfer.Reject(ctx, tooMany.ID, admin.ID))
+	expectMessage(t, "Reject repetido", repos.Transfer.Reject(ctx, tooMany.ID, admin.ID), "transferência já foi resolvida")
+
+	found, err := repos.Transfer.FindByID(ctx, complete.ID)
+	succeed(t, "FindByID", err)
+	expect(t, found != nil && found.Status == entities.TransferStatusCompleted && found.ResolvedByID != nil && *found.ResolvedByID == admin.ID &&
+		found.ResolvedAt != nil && found.Book.Title == "Dom Casmurro" && found.FromBranch.Code == "NOR" && found.ToBranch.Code == "SUL",
+		"FindByID deveria carregar a transferência concluída com livro e unidades")
+
+	all, err := repos.Transfer.List(ctx)
+	succeed(t, "List", err)
+	expect(t, len(all) == 3, "List deveria retornar as 3 transferências, retornou %d", len(all))
+	atSouth, err := repos.Transfer.ListByBranch(ctx, sul.ID)
+	succeed(t, "ListByBranch", err)
+	expect(t, len(atSouth) == 1 && atSouth[0].ID == complete.ID, "ListByBranch deveria retornar apenas as transferências da unidade")
+}
+
+func canceledContext(t *testing.T, ctx context.Context, repos Repositories) {
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+
+	if _, err := repos.User.List(canceled); !errors.Is(err, context.Canceled) {
+		t.Errorf("List com contexto cancelado: esperava context.Canceled, recebeu %v", err)
+	}
+	err := repos.Book.Create(canceled, &entities.Book{Title: "Cancelado", Author: "Ninguém", Quantity: 1})
+	expectFailure(t, "Create com contexto cancelado", err)
+
+	books, err := repos.Book.List(ctx, repositories.BookFilter{})
+	succeed(t, "List", err)
+	expect(t, len(books) == 0, "Create com contexto cancelado não deveria gravar o livro")
+}
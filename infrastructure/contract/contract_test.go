@@ -0,0 +1,99 @@
+// Package contract reúne o roteiro de contrato dos repositórios de usuários, livros, empréstimos,
+// unidades e transferências. Os mesmos casos são executados sobre os repositórios GORM, com um SQLite
+// em memória novo a cada caso, e sobre os repositórios em memória, garantindo que as duas
+// implementações têm a mesma semântica.
+package contract
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/repositories"
+	"github.com/henrygoeszanin/api_golang_estudos/config"
+	"github.com/henrygoeszanin/api_golang_estudos/infrastructure/database"
+	"github.com/henrygoeszanin/api_golang_estudos/infrastructure/memory"
+	gormrepositories "github.com/henrygoeszanin/api_golang_estudos/infrastructure/repositories"
+	"github.com/henrygoeszanin/api_golang_estudos/logging"
+)
+
+// Repositories reúne os repositórios verificados pelo roteiro
+type Repositories struct {
+	User     repositories.UserRepository
+	Book     repositories.BookRepository
+	Loan     repositories.LoanRepository
+	Branch   repositories.BranchRepository
+	Transfer repositories.TransferRepository
+}
+
+// factory cria repositórios vazios para um caso do roteiro; os recursos são liberados ao fim do caso
+type factory func(t *testing.T) Repositories
+
+// newMemoryRepositories cria os repositórios em memória sobre um armazenamento novo
+func newMemoryRepositories(t *testing.T) Repositories {
+	store := memory.NewStore()
+	return Repositories{
+		User:     memory.NewUserRepository(store),
+		Book:     memory.NewBookRepository(store),
+		Loan:     memory.NewLoanRepository(store),
+		Branch:   memory.NewBranchRepository(store),
+		Transfer: memory.NewTransferRepository(store),
+	}
+}
+
+// newGormRepositories cria os repositórios GORM sobre um banco SQLite em memória novo, com as migrações do perfil test
+func newGormRepositories(t *testing.T) Repositories {
+	t.Helper()
+	db, err := database.SetupDatabase(config.Defaults(config.ProfileTest))
+	if err != nil {
+		t.Fatalf("falha ao abrir o banco de dados: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err := sqlDB.Close(); err != nil {
+			t.Error(err)
+		}
+	})
+	return Repositories{
+		User:     gormrepositories.NewUserRepository(db),
+		Book:     gormrepositories.NewBookRepository(db),
+		Loan:     gormrepositories.NewLoanRepository(db),
+		Branch:   gormrepositories.NewBranchRepository(db),
+		Transfer: gormrepositories.NewTransferRepository(db),
+	}
+}
+
+// TestRepositoryContract executa todos os casos do roteiro sobre as duas implementações,
+// cada caso sobre repositórios novos
+func TestRepositoryContract(t *testing.T) {
+	implementations := []struct {
+		name    string
+		factory factory
+	}{
+		{"gorm", newGormRepositories},
+		{"memory", newMemoryRepositories},
+	}
+	for _, implementation := range implementations {
+		t.Run(implementation.name, func(t *testing.T) {
+			for _, contractCase := range cases {
+				t.Run(contractCase.name, func(t *testing.T) {
+					repos := implementation.factory(t)
+
+					// Prazo para não travar o roteiro
+					ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+					defer cancel()
+
+					// Os casos provocam falhas de propósito; os logs das consultas recusadas pelo banco são descartados
+					ctx = logging.WithLogger(ctx, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+					contractCase.run(t, ctx, repos)
+				})
+			}
+		})
+	}
+}
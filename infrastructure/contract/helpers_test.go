@@ -0,0 +1,122 @@
+package contract
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+)
+
+// expect registra a falha com a mensagem informada quando a condição não é atendida
+func expect(t *testing.T, condition bool, format string, args ...interface{}) {
+	t.Helper()
+	if !condition {
+		t.Errorf(format, args...)
+	}
+}
+
+// expectMessage verifica que a operação falhou com exatamente a mensagem informada
+func expectMessage(t *testing.T, operation string, err error, message string) {
+	t.Helper()
+	if err == nil {
+		t.Errorf("%s: esperava o erro %q, mas a operação foi aceita", operation, message)
+		return
+	}
+	if err.Error() != message {
+		t.Errorf("%s: esperava o erro %q, recebeu %q", operation, message, err.Error())
+	}
+}
+
+// expectIs verifica que a operação falhou com um erro equivalente a target
+func expectIs(t *testing.T, operation string, err error, target error) {
+	t.Helper()
+	if !errors.Is(err, target) {
+		t.Errorf("%s: esperava um erro %q, recebeu %v", operation, target, err)
+	}
+}
+
+// expectFailure verifica apenas que a operação falhou, para regras garantidas pelas restrições do banco,
+// cujas mensagens dependem do driver
+func expectFailure(t *testing.T, operation string, err error) {
+	t.Helper()
+	if err == nil {
+		t.Errorf("%s: esperava um erro, mas a operação foi aceita", operation)
+	}
+}
+
+// succeed interrompe o caso quando a operação falha, já que os passos seguintes dependem dela
+func succeed(t *testing.T, operation string, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("%s: %v", operation, err)
+	}
+}
+
+// createUser cadastra um usuário com o email informado
+func createUser(t *testing.T, ctx context.Context, repos Repositories, email string) *entities.User {
+	t.Helper()
+	user := &entities.User{Name: "Usuário " + email, Email: email, Password: "hash"}
+	succeed(t, "cadastrar usuário "+email, repos.User.Create(ctx, user))
+	return user
+}
+
+// createBook cadastra um livro com o título, o ISBN (vazio para nenhum) e a quantidade informados
+func createBook(t *testing.T, ctx context.Context, repos Repositories, title, isbn string, quantity int) *entities.Book {
+	t.Helper()
+	book := &entities.Book{
+		Title:    title,
+		Author:   "Machado de Assis",
+		DedupKey: entities.BookDedupKey(title, []string{"Machado de Assis"}),
+		Quantity: quantity,
+		Authors:  []entities.Author{{Name: "Machado de Assis"}},
+	}
+	if isbn != "" {
+		book.ISBN = &isbn
+	}
+	succeed(t, "cadastrar livro "+title, repos.Book.Create(ctx, book))
+	return book
+}
+
+// createBranch cadastra uma unidade com o nome e o código informados
+func createBranch(t *testing.T, ctx context.Context, repos Repositories, name, code string) *entities.Branch {
+	t.Helper()
+	branch := &entities.Branch{Name: name, Code: code}
+	succeed(t, "cadastrar unidade "+name, repos.Branch.Create(ctx, branch))
+	return branch
+}
+
+// newLoan monta um empréstimo de 14 dias a partir de agora
+func newLoan(userID, bookID uint, branchID *uint) *entities.Loan {
+	now := time.Now()
+	return &entities.Loan{
+		UserID:     userID,
+		BookID:     bookID,
+		BranchID:   branchID,
+		LoanDate:   now,
+		ReturnDate: now.Add(14 * 24 * time.Hour),
+	}
+}
+
+// available busca o livro e retorna quantos exemplares estão disponíveis
+func available(t *testing.T, ctx context.Context, repos Repositories, bookID uint) int {
+	t.Helper()
+	book, err := repos.Book.FindByID(ctx, bookID)
+	succeed(t, "FindByID", err)
+	if book == nil {
+		t.Fatalf("livro %d não encontrado", bookID)
+	}
+	return book.Available
+}
+
+// holding busca a quantidade e a disponibilidade dos exemplares do livro na unidade
+func holding(t *testing.T, ctx context.Context, repos Repositories, bookID, branchID uint) (quantity int, availableCopies int) {
+	t.Helper()
+	found, err := repos.Branch.FindHolding(ctx, bookID, branchID)
+	succeed(t, "FindHolding", err)
+	if found == nil {
+		return 0, 0
+	}
+	return found.Quantity, found.Available
+}
@@ -0,0 +1,31 @@
+package database
+
+import "gorm.io/gorm"
+
+// registerAround registra, para cada tipo de operação do GORM, uma callback antes e outra depois
+// da execução. Os nomes das callbacks recebem o prefixo do plugin.
+func registerAround(db *gorm.DB, plugin string, before func(*gorm.DB), after func(operation string) func(*gorm.DB)) error {
+	callback := db.Callback()
+	processors := []struct {
+		operation string
+		before    func(name string, fn func(*gorm.DB)) error
+		after     func(name string, fn func(*gorm.DB)) error
+	}{
+		{"create", callback.Create().Before("gorm:create").Register, callback.Create().After("gorm:create").Register},
+		{"query", callback.Query().Before("gorm:query").Register, callback.Query().After("gorm:query").Register},
+		{"update", callback.Update().Before("gorm:update").Register, callback.Update().After("gorm:update").Register},
+		{"delete", callback.Delete().Before("gorm:delete").Register, callback.Delete().After("gorm:delete").Register},
+		{"row", callback.Row().Before("gorm:row").Register, callback.Row().After("gorm:row").Register},
+		{"raw", callback.Raw().Before("gorm:raw").Register, callback.Raw().After("gorm:raw").Register},
+	}
+
+	for _, processor := range processors {
+		if err := processor.before(plugin+":before_"+processor.operation, before); err != nil {
+			return err
+		}
+		if err := processor.after(plugin+":after_"+processor.operation, after(processor.operation)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,40 +1,328 @@
 package database
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
 
 	"github.com/henrygoeszanin/api_golang_estudos/config"
 	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
 )
 
-// SetupDatabase configura a conexão com o banco de dados PostgreSQL
+// maxConnectBackoff limita a espera entre as tentativas de conexão na inicialização
+const maxConnectBackoff = 30 * time.Second
+
+// SetupDatabase configura a conexão com o banco de dados, PostgreSQL ou SQLite conforme database.driver
 func SetupDatabase(config *config.Config) (*gorm.DB, error) {
-	dsn := fmt.Sprintf(
-		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=America/Sao_Paulo",
-		config.DBHost,
-		config.DBUser,
-		config.DBPassword,
-		config.DBName,
-		config.DBPort,
-	)
-
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
-	if err != nil {
-		return nil, fmt.Errorf("falha ao conectar ao PostgreSQL: %w", err)
+	var db *gorm.DB
+	var err error
+	switch config.DBDriver {
+	case DriverSQLite:
+		db, err = openSQLite(config.DBSQLitePath)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao abrir o SQLite: %w", err)
+		}
+	default:
+		db, err = setupPostgres(config)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Limitar a duração de cada instrução enviada ao banco
+	if err := db.Use(NewStatementTimeoutPlugin(time.Duration(config.DBStatementTimeoutSeconds) * time.Second)); err != nil {
+		return nil, fmt.Errorf("falha ao configurar o tempo limite das consultas: %w", err)
+	}
+
+	// Registrar cada consulta como um span, filho do span da requisição
+	if err := db.Use(NewTracingPlugin()); err != nil {
+		return nil, fmt.Errorf("falha ao configurar o rastreamento do banco: %w", err)
+	}
+
+	if err := migrate(db); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// models lista as entidades com tabela no banco
+var models = []interface{}{
+	&entities.User{},
+	&entities.Book{},
+	&entities.Loan{},
+	&entities.Branch{},
+	&entities.BookHolding{},
+	&entities.TransferRequest{},
+	&entities.Author{},
+	&entities.Subject{},
+	&entities.ImportJob{},
+	&entities.DataExport{},
+	&entities.AuditEvent{},
+	&entities.AuditChainHead{},
+	&entities.JobRun{},
+	&entities.Notification{},
+}
+
+// migrate cria as tabelas a partir das entidades e aplica as migrações versionadas
+func migrate(db *gorm.DB) error {
+	if err := clampAvailability(db); err != nil {
+		return fmt.Errorf("falha na migração do banco: %w", err)
 	}
 
 	// Auto Migrate - cria tabelas baseadas nas entidades
-	err = db.AutoMigrate(
+	if err := autoMigrate(db); err != nil {
+		return fmt.Errorf("falha na migração do banco: %w", err)
+	}
+
+	// Migrações de dados versionadas
+	if err := runMigrations(db); err != nil {
+		return fmt.Errorf("falha na migração do banco: %w", err)
+	}
+	return nil
+}
+
+// autoMigrate cria e atualiza as tabelas das entidades. No SQLite, incluir uma restrição em uma tabela
+// existente exige recriá-la, o que as chaves estrangeiras das tabelas dependentes impediriam: como recomenda
+// a documentação do SQLite, a verificação fica desligada na conexão usada pela migração e as referências
+// são conferidas ao final.
+func autoMigrate(db *gorm.DB) error {
+	if db.Dialector.Name() != DriverSQLite {
+		return db.AutoMigrate(models...)
+	}
+
+	return db.Connection(func(conn *gorm.DB) error {
+		if err := conn.Exec("PRAGMA foreign_keys = OFF").Error; err != nil {
+			return err
+		}
+		defer conn.Exec("PRAGMA foreign_keys = ON")
+
+		if err := conn.AutoMigrate(models...); err != nil {
+			return err
+		}
+
+		var violations []map[string]interface{}
+		if err := conn.Raw("PRAGMA foreign_key_check").Scan(&violations).Error; err != nil {
+			return err
+		}
+		if len(violations) > 0 {
+			return fmt.Errorf("%d referências inválidas depois de recriar as tabelas", len(violations))
+		}
+		return nil
+	})
+}
+
+// clampAvailability traz para o intervalo de zero à quantidade os contadores de exemplares disponíveis
+// de livros e unidades, antes que o AutoMigrate crie nas tabelas existentes as restrições que exigem isso.
+// Só age enquanto a restrição não existe; os valores corretos são calculados depois pela reconciliação
+// do acervo (go run . book reconcile --repair).
+func clampAvailability(db *gorm.DB) error {
+	constraints := []struct {
+		model interface{}
+		name  string
+	}{
+		{&entities.Book{}, "chk_books_available"},
+		{&entities.BookHolding{}, "chk_book_holdings_available"},
+	}
+
+	for _, constraint := range constraints {
+		if !db.Migrator().HasTable(constraint.model) || db.Migrator().HasConstraint(constraint.model, constraint.name) {
+			continue
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Unscoped().Model(constraint.model).Where("available < 0").
+				Update("available", 0).Error; err != nil {
+				return err
+			}
+			return tx.Unscoped().Model(constraint.model).Where("available > quantity").
+				Update("available", gorm.Expr("quantity")).Error
+		})
+		if err != nil {
+			return fmt.Errorf("restrição %s: %w", constraint.name, err)
+		}
+	}
+	return nil
+}
+
+// Reset apaga todas as tabelas, inclusive o controle de migrações, e cria o esquema novamente, vazio.
+// Destinado apenas a bancos de desenvolvimento: quem chama deve conferir o perfil da configuração.
+func Reset(db *gorm.DB) error {
+	// Tabelas dependentes primeiro, para respeitar as chaves estrangeiras também no SQLite
+	tables := []interface{}{
+		"book_authors",
+		"book_subjects",
+		&entities.Notification{},
+		&entities.JobRun{},
+		&entities.AuditChainHead{},
+		&entities.AuditEvent{},
+		&entities.DataExport{},
+		&entities.ImportJob{},
+		&entities.TransferRequest{},
+		&entities.BookHolding{},
+		&entities.Loan{},
 		&entities.User{},
 		&entities.Book{},
-		&entities.Loan{},
-	)
+		&entities.Author{},
+		&entities.Subject{},
+		&entities.Branch{},
+		&schemaMigration{},
+	}
+
+	if err := db.Migrator().DropTable(tables...); err != nil {
+		return fmt.Errorf("falha ao apagar as tabelas: %w", err)
+	}
+	return migrate(db)
+}
+
+// setupPostgres conecta ao PostgreSQL e configura o pool de conexões e as réplicas de leitura
+func setupPostgres(config *config.Config) (*gorm.DB, error) {
+	db, err := openWithRetry(config)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao conectar ao PostgreSQL: %w", err)
+	}
+
+	// Configurar o pool de conexões do banco principal
+	sqlDB, err := db.DB()
 	if err != nil {
-		return nil, fmt.Errorf("falha na migração do banco: %w", err)
+		return nil, fmt.Errorf("falha ao configurar o pool de conexões: %w", err)
 	}
+	sqlDB.SetMaxOpenConns(config.DBMaxOpenConns)
+	sqlDB.SetMaxIdleConns(config.DBMaxIdleConns)
+	sqlDB.SetConnMaxLifetime(time.Duration(config.DBConnMaxLifetimeSeconds) * time.Second)
+	sqlDB.SetConnMaxIdleTime(time.Duration(config.DBConnMaxIdleTimeSeconds) * time.Second)
 
+	// Enviar as leituras às réplicas, quando configuradas
+	if len(config.DBReplicaHosts) > 0 {
+		if err := setupReplicas(db, config); err != nil {
+			return nil, fmt.Errorf("falha ao configurar as réplicas de leitura: %w", err)
+		}
+	}
 	return db, nil
 }
+
+// Close fecha as conexões com o banco principal e com as réplicas de leitura, se houver
+func Close(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	pools := []gorm.ConnPool{sqlDB}
+
+	// As réplicas têm pools próprios, abertos pelo dbresolver, que db.DB() não alcança
+	for _, plugin := range db.Config.Plugins {
+		if resolver, ok := plugin.(*dbresolver.DBResolver); ok {
+			_ = resolver.Call(func(pool gorm.ConnPool) error {
+				pools = append(pools, pool)
+				return nil
+			})
+		}
+	}
+
+	var errs []error
+	closed := make(map[gorm.ConnPool]bool)
+	for _, pool := range pools {
+		closer, ok := pool.(io.Closer)
+		if !ok || closed[pool] {
+			continue
+		}
+		closed[pool] = true
+		if err := closer.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// openWithRetry abre a conexão com o banco principal, tentando novamente com espera crescente.
+// Evita que a aplicação desista quando sobe junto com o banco, como no docker compose.
+func openWithRetry(config *config.Config) (*gorm.DB, error) {
+	dsn := PostgresDSN(config, config.DBHost, config.DBPort)
+	backoff := time.Duration(config.DBConnectBackoffSeconds) * time.Second
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		var db *gorm.DB
+		db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: newGormLogger()})
+		if err == nil {
+			return db, nil
+		}
+		if attempt >= config.DBConnectAttempts {
+			return nil, err
+		}
+
+		slog.Warn("falha ao conectar ao banco, tentando novamente",
+			"attempt", attempt,
+			"max_attempts", config.DBConnectAttempts,
+			"retry_in", backoff.String(),
+			"error", err,
+		)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, maxConnectBackoff)
+	}
+}
+
+// setupReplicas registra as réplicas de leitura com as mesmas credenciais e limites de pool do banco principal.
+// Consultas fora de transações vão às réplicas; escritas, transações e consultas com FOR UPDATE vão ao principal.
+func setupReplicas(db *gorm.DB, cfg *config.Config) error {
+	replicas := make([]gorm.Dialector, 0, len(cfg.DBReplicaHosts))
+	for _, address := range cfg.DBReplicaHosts {
+		host, port, err := config.SplitHostPort(address, cfg.DBPort)
+		if err != nil {
+			return err
+		}
+		replicas = append(replicas, postgres.Open(PostgresDSN(cfg, host, port)))
+	}
+
+	resolver := dbresolver.Register(dbresolver.Config{
+		Replicas: replicas,
+		Policy:   dbresolver.RandomPolicy{},
+	}).
+		SetMaxOpenConns(cfg.DBMaxOpenConns).
+		SetMaxIdleConns(cfg.DBMaxIdleConns).
+		SetConnMaxLifetime(time.Duration(cfg.DBConnMaxLifetimeSeconds) * time.Second).
+		SetConnMaxIdleTime(time.Duration(cfg.DBConnMaxIdleTimeSeconds) * time.Second)
+	if err := db.Use(resolver); err != nil {
+		return err
+	}
+
+	// Depois de uma escrita, as leituras da mesma requisição passam a ir ao principal
+	return db.Use(NewReadYourWritesPlugin())
+}
+
+// PostgresDSN monta a string de conexão com o host e a porta informados e as demais opções da configuração.
+// Os valores são colocados entre aspas, o que permite senhas com espaços e caracteres especiais.
+func PostgresDSN(config *config.Config, host, port string) string {
+	options := []struct{ key, value string }{
+		{"host", host},
+		{"port", port},
+		{"user", config.DBUser},
+		{"password", config.DBPassword},
+		{"dbname", config.DBName},
+		{"sslmode", config.DBSSLMode},
+		{"sslrootcert", config.DBSSLRootCert},
+		{"sslcert", config.DBSSLCert},
+		{"sslkey", config.DBSSLKey},
+		{"TimeZone", config.DBTimeZone},
+	}
+	if config.DBConnectTimeoutSeconds > 0 {
+		options = append(options, struct{ key, value string }{"connect_timeout", fmt.Sprint(config.DBConnectTimeoutSeconds)})
+	}
+
+	parts := make([]string, 0, len(options))
+	quote := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	for _, option := range options {
+		if option.value == "" {
+			continue
+		}
+		parts = append(parts, option.key+"='"+quote.Replace(option.value)+"'")
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,45 @@
+package database
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+)
+
+func TestCloseClosesReplicas(t *testing.T) {
+	db, err := openSQLite(sqliteMemoryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resolver := dbresolver.Register(dbresolver.Config{
+		Replicas: []gorm.Dialector{sqlite.Open(SQLiteDSN(sqliteMemoryPath))},
+	})
+	if err := db.Use(resolver); err != nil {
+		t.Fatal(err)
+	}
+
+	var pools []*sql.DB
+	if err := resolver.Call(func(pool gorm.ConnPool) error {
+		if sqlDB, ok := pool.(*sql.DB); ok {
+			pools = append(pools, sqlDB)
+		}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(pools) < 2 {
+		t.Fatalf("esperados os pools do principal e da réplica, encontrados %d", len(pools))
+	}
+
+	if err := Close(db); err != nil {
+		t.Fatal(err)
+	}
+	for i, pool := range pools {
+		if err := pool.Ping(); err == nil {
+			t.Errorf("pool %d continua aberto depois de Close", i)
+		}
+	}
+}
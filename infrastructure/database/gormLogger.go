@@ -0,0 +1,81 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+
+	"github.com/henrygoeszanin/api_golang_estudos/logging"
+)
+
+// slowQueryThreshold define a partir de quanto tempo uma consulta é registrada como lenta
+const slowQueryThreshold = 200 * time.Millisecond
+
+// slogGormLogger envia os logs do GORM para o logger da requisição guardado no contexto.
+// As consultas são registradas sem os valores dos parâmetros, que podem conter senhas e dados pessoais.
+type slogGormLogger struct {
+	level gormlogger.LogLevel
+}
+
+// newGormLogger cria o logger do GORM; as consultas só aparecem no nível debug, exceto erros e lentidão
+func newGormLogger() gormlogger.Interface {
+	return &slogGormLogger{level: gormlogger.Info}
+}
+
+// LogMode define o nível de log do GORM
+func (gormLogger *slogGormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	return &slogGormLogger{level: level}
+}
+
+// Info registra mensagens informativas do GORM
+func (gormLogger *slogGormLogger) Info(ctx context.Context, message string, args ...interface{}) {
+	if gormLogger.level >= gormlogger.Info {
+		logging.FromContext(ctx).InfoContext(ctx, fmt.Sprintf(message, args...))
+	}
+}
+
+// Warn registra avisos do GORM
+func (gormLogger *slogGormLogger) Warn(ctx context.Context, message string, args ...interface{}) {
+	if gormLogger.level >= gormlogger.Warn {
+		logging.FromContext(ctx).WarnContext(ctx, fmt.Sprintf(message, args...))
+	}
+}
+
+// Error registra erros do GORM
+func (gormLogger *slogGormLogger) Error(ctx context.Context, message string, args ...interface{}) {
+	if gormLogger.level >= gormlogger.Error {
+		logging.FromContext(ctx).ErrorContext(ctx, fmt.Sprintf(message, args...))
+	}
+}
+
+// Trace registra cada consulta executada: erros e consultas lentas como aviso, as demais em debug
+func (gormLogger *slogGormLogger) Trace(ctx context.Context, begin time.Time, fc func() (sql string, rowsAffected int64), err error) {
+	if gormLogger.level <= gormlogger.Silent {
+		return
+	}
+
+	logger := logging.FromContext(ctx)
+	elapsed := time.Since(begin)
+
+	switch {
+	case err != nil && !errors.Is(err, gorm.ErrRecordNotFound) && gormLogger.level >= gormlogger.Error:
+		sql, rows := fc()
+		logger.ErrorContext(ctx, "falha na consulta ao banco", "sql", sql, "rows", rows, "duration_ms", elapsed.Milliseconds(), "error", err)
+	case elapsed > slowQueryThreshold && gormLogger.level >= gormlogger.Warn:
+		sql, rows := fc()
+		logger.WarnContext(ctx, "consulta lenta ao banco", "sql", sql, "rows", rows, "duration_ms", elapsed.Milliseconds())
+	case logger.Enabled(ctx, slog.LevelDebug):
+		sql, rows := fc()
+		logger.DebugContext(ctx, "consulta ao banco", "sql", sql, "rows", rows, "duration_ms", elapsed.Milliseconds())
+	}
+}
+
+// ParamsFilter mantém os marcadores no lugar dos valores ao montar o SQL registrado
+func (gormLogger *slogGormLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
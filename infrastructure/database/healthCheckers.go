@@ -0,0 +1,62 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/health"
+)
+
+// pingChecker verifica se o banco responde
+type pingChecker struct {
+	db *gorm.DB
+}
+
+// NewPingChecker cria a verificação de conectividade com o banco
+func NewPingChecker(db *gorm.DB) health.Checker {
+	return &pingChecker{db: db}
+}
+
+// Name identifica a verificação no relatório de saúde
+func (pingChecker *pingChecker) Name() string {
+	return "database"
+}
+
+// Check envia um ping ao banco usando uma conexão do pool
+func (pingChecker *pingChecker) Check(ctx context.Context) error {
+	sqlDB, err := pingChecker.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// migrationsChecker verifica se todas as migrações conhecidas por esta versão já foram aplicadas
+type migrationsChecker struct {
+	db *gorm.DB
+}
+
+// NewMigrationsChecker cria a verificação das migrações do banco
+func NewMigrationsChecker(db *gorm.DB) health.Checker {
+	return &migrationsChecker{db: db}
+}
+
+// Name identifica a verificação no relatório de saúde
+func (migrationsChecker *migrationsChecker) Name() string {
+	return "migrations"
+}
+
+// Check falha enquanto houver migrações pendentes
+func (migrationsChecker *migrationsChecker) Check(ctx context.Context) error {
+	pending, err := PendingMigrations(ctx, migrationsChecker.db)
+	if err != nil {
+		return err
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("migrações pendentes: %s", strings.Join(pending, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package database
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/metrics"
+)
+
+// metricsStartedAtKey guarda, na instância da consulta, o momento em que ela começou
+const metricsStartedAtKey = "metrics:started_at"
+
+// metricsPlugin mede a duração de cada consulta do GORM e a envia ao coletor de métricas
+type metricsPlugin struct {
+	recorder metrics.Recorder
+}
+
+// NewMetricsPlugin cria o plugin do GORM que registra a duração das consultas.
+// Deve ser instalado com db.Use.
+func NewMetricsPlugin(recorder metrics.Recorder) gorm.Plugin {
+	return &metricsPlugin{recorder: recorder}
+}
+
+// Name identifica o plugin no GORM
+func (plugin *metricsPlugin) Name() string {
+	return "metrics"
+}
+
+// Initialize registra as callbacks antes e depois de cada tipo de operação
+func (plugin *metricsPlugin) Initialize(db *gorm.DB) error {
+	return registerAround(db, "metrics", plugin.start, plugin.observe)
+}
+
+// start marca o início da consulta
+func (plugin *metricsPlugin) start(db *gorm.DB) {
+	db.InstanceSet(metricsStartedAtKey, time.Now())
+}
+
+// observe registra a duração da consulta ao fim da operação informada
+func (plugin *metricsPlugin) observe(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(metricsStartedAtKey)
+		if !ok {
+			return
+		}
+		startedAt, ok := value.(time.Time)
+		if !ok {
+			return
+		}
+
+		err := db.Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			err = nil // Não encontrar o registro é um resultado, não uma falha do banco
+		}
+
+		table := db.Statement.Table
+		if table == "" {
+			table = "unknown"
+		}
+		plugin.recorder.ObserveDBQuery(operation, table, time.Since(startedAt), err)
+	}
+}
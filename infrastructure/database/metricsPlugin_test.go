@@ -0,0 +1,65 @@
+package database
+
+import (
+	"testing"
+
+	"gorm.io/gorm"
+
+	"github.com/henrygoeszanin/api_golang_estudos/infrastructure/metrics"
+)
+
+// metricsRecord é a tabela usada para conferir as consultas medidas pelo plugin
+type metricsRecord struct {
+	ID   uint
+	Name string
+}
+
+func TestMetricsPluginCountsErrors(t *testing.T) {
+	db, err := openSQLite(sqliteMemoryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	if err := db.AutoMigrate(&metricsRecord{}); err != nil {
+		t.Fatal(err)
+	}
+	recorder := metrics.NewMemoryRecorder()
+	if err := db.Use(NewMetricsPlugin(recorder)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		run       func(db *gorm.DB) error
+		failing   bool // A operação deve retornar erro
+		operation string
+		table     string
+		errors    int // Erros que o coletor deve contar
+	}{
+		{"cadastro", func(db *gorm.DB) error { return db.Create(&metricsRecord{Name: "um"}).Error }, false, "create", "metrics_records", 0},
+		{"busca encontrada", func(db *gorm.DB) error { return db.First(&metricsRecord{}, 1).Error }, false, "query", "metrics_records", 0},
+		// Não encontrar o registro é um resultado, não uma falha do banco
+		{"registro inexistente", func(db *gorm.DB) error { return db.First(&metricsRecord{}, 999).Error }, true, "query", "metrics_records", 0},
+		{"tabela inexistente", func(db *gorm.DB) error { return db.Table("nao_existe").Find(&[]metricsRecord{}).Error }, true, "query", "nao_existe", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			queries := recorder.DBQueries(tt.operation, tt.table)
+			errorsBefore := recorder.DBErrors()
+
+			if err := tt.run(db); (err != nil) != tt.failing {
+				t.Fatalf("erro %v, esperado erro: %v", err, tt.failing)
+			}
+			if got := recorder.DBQueries(tt.operation, tt.table); got != queries+1 {
+				t.Errorf("%s %s: %d consultas medidas, esperadas %d", tt.operation, tt.table, got, queries+1)
+			}
+			if got := recorder.DBErrors() - errorsBefore; got != tt.errors {
+				t.Errorf("%d erros contados, esperados %d", got, tt.errors)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+)
+
+// migration representa uma alteração de dados ou de esquema que o AutoMigrate não cobre.
+// Cada migração é aplicada uma única vez, em ordem, dentro de uma transação.
+type migration struct {
+	ID      string
+	Migrate func(tx *gorm.DB) error
+}
+
+// schemaMigration registra as migrações já aplicadas
+type schemaMigration struct {
+	ID        string `gorm:"primaryKey;size:100"`
+	AppliedAt time.Time
+}
+
+// TableName define o nome da tabela de controle de migrações
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// migrations lista as migrações na ordem em que devem ser aplicadas
+var migrations = []migration{
+	{ID: "0001_split_book_authors", Migrate: splitBookAuthors},
+	{ID: "0002_book_dedup_keys", Migrate: fillBookDedupKeys},
+	{ID: "0003_book_isbn_active_unique", Migrate: dropBookISBNUniqueIndex},
+	{ID: "0004_audit_chain_head", Migrate: createAuditChainHead},
+}
+
+// runMigrations aplica as migrações ainda não registradas
+func runMigrations(db *gorm.DB) error {
+	if err := db.AutoMigrate(&schemaMigration{}); err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		var applied int64
+		if err := db.Model(&schemaMigration{}).Where("id = ?", m.ID).Count(&applied).Error; err != nil {
+			return err
+		}
+		if applied > 0 {
+			continue
+		}
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Migrate(tx); err != nil {
+				return err
+			}
+			return tx.Create(&schemaMigration{ID: m.ID, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("migração %s: %w", m.ID, err)
+		}
+	}
+
+	return nil
+}
+
+// PendingMigrations lista as migrações desta versão que ainda não foram aplicadas ao banco
+func PendingMigrations(ctx context.Context, db *gorm.DB) ([]string, error) {
+	var applied []string
+	if err := db.WithContext(ctx).Model(&schemaMigration{}).Pluck("id", &applied).Error; err != nil {
+		return nil, err
+	}
+
+	appliedIDs := make(map[string]bool, len(applied))
+	for _, id := range applied {
+		appliedIDs[id] = true
+	}
+
+	var pending []string
+	for _, m := range migrations {
+		if !appliedIDs[m.ID] {
+			pending = append(pending, m.ID)
+		}
+	}
+	return pending, nil
+}
+
+// MigrationStatus indica se uma migração versionada já foi aplicada e quando
+type MigrationStatus struct {
+	ID        string     `json:"id"`
+	AppliedAt *time.Time `json:"applied_at"`
+}
+
+// Migrations lista as migrações versionadas desta versão, na ordem de aplicação, com a data em que foram aplicadas
+func Migrations(ctx context.Context, db *gorm.DB) ([]MigrationStatus, error) {
+	var applied []schemaMigration
+	if err := db.WithContext(ctx).Find(&applied).Error; err != nil {
+		return nil, err
+	}
+
+	appliedAt := make(map[string]time.Time, len(applied))
+	for _, m := range applied {
+		appliedAt[m.ID] = m.AppliedAt
+	}
+
+	statuses := make([]MigrationStatus, 0, len(migrations))
+	for _, m := range migrations {
+		status := MigrationStatus{ID: m.ID}
+		if at, ok := appliedAt[m.ID]; ok {
+			status.AppliedAt = &at
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// splitBookAuthors cria registros de autor a partir do texto livre de cada livro
+func splitBookAuthors(tx *gorm.DB) error {
+	var books []entities.Book
+	return tx.Preload("Authors").FindInBatches(&books, 200, func(batch *gorm.DB, _ int) error {
+		for i := range books {
+			if len(books[i].Authors) > 0 {
+				continue
+			}
+
+			var authors []entities.Author
+			for _, name := range entities.SplitAuthorNames(books[i].Author) {
+				author := entities.Author{Name: name}
+				if err := tx.Where(entities.Author{Name: name}).FirstOrCreate(&author).Error; err != nil {
+					return err
+				}
+				authors = append(authors, author)
+			}
+			if len(authors) == 0 {
+				continue
+			}
+
+			if err := tx.Model(&books[i]).Association("Authors").Append(authors); err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
+
+// fillBookDedupKeys calcula a chave de detecção de repetidos dos livros já cadastrados
+func fillBookDedupKeys(tx *gorm.DB) error {
+	var books []entities.Book
+	return tx.Preload("Authors").FindInBatches(&books, 200, func(batch *gorm.DB, _ int) error {
+		for i := range books {
+			names := make([]string, 0, len(books[i].Authors))
+			for _, author := range books[i].Authors {
+				names = append(names, author.Name)
+			}
+
+			key := entities.BookDedupKey(books[i].Title, names)
+			if err := tx.Model(&entities.Book{}).Where("id = ?", books[i].ID).Update("dedup_key", key).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}).Error
+}
+
+// dropBookISBNUniqueIndex remove o índice único de ISBN que incluía livros excluídos.
+// O AutoMigrate já criou o índice que considera apenas livros não excluídos.
+func dropBookISBNUniqueIndex(tx *gorm.DB) error {
+	if !tx.Migrator().HasIndex(&entities.Book{}, "idx_books_isbn") {
+		return nil
+	}
+	return tx.Migrator().DropIndex(&entities.Book{}, "idx_books_isbn")
+}
+
+// createAuditChainHead cria a linha única que guarda o último elo da cadeia de auditoria.
+// Com ela já existente, a gravação de eventos concorrentes se resume a bloquear essa linha.
+func createAuditChainHead(tx *gorm.DB) error {
+	return tx.FirstOrCreate(&entities.AuditChainHead{}, entities.AuditChainHead{ID: 1}).Error
+}
@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+	"gorm.io/plugin/dbresolver"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/repositories"
+)
+
+// sessionKey identifica, no contexto, a sessão usada para ler as próprias escritas
+type sessionKey struct{}
+
+// session registra se a requisição já escreveu no banco principal
+type session struct {
+	wrote atomic.Bool
+}
+
+// WithSession inicia no contexto uma sessão de leitura das próprias escritas.
+// Com réplicas configuradas, as consultas feitas depois de uma escrita no mesmo contexto vão ao
+// banco principal, evitando que a resposta mostre dados anteriores à escrita por causa do atraso da réplica.
+func WithSession(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sessionKey{}, &session{})
+}
+
+// sessionFrom retorna a sessão do contexto da instrução, se houver
+func sessionFrom(db *gorm.DB) *session {
+	if db.Statement.Context == nil {
+		return nil
+	}
+	current, _ := db.Statement.Context.Value(sessionKey{}).(*session)
+	return current
+}
+
+// readYourWritesPlugin encaminha ao banco principal as leituras de uma sessão que já escreveu
+// e as marcadas com repositories.WithPrimary
+type readYourWritesPlugin struct{}
+
+// NewReadYourWritesPlugin cria o plugin do GORM que mantém as leituras no banco principal depois de uma escrita.
+// Deve ser instalado com db.Use depois do dbresolver.
+func NewReadYourWritesPlugin() gorm.Plugin {
+	return &readYourWritesPlugin{}
+}
+
+// Name identifica o plugin no GORM
+func (plugin *readYourWritesPlugin) Name() string {
+	return "read_your_writes"
+}
+
+// Initialize registra as callbacks antes da escolha do banco feita pelo dbresolver.
+// Como as duas usam Before("*"), o GORM executa primeiro a registrada por último; por isso o plugin
+// precisa ser instalado depois do dbresolver.
+func (plugin *readYourWritesPlugin) Initialize(db *gorm.DB) error {
+	callback := db.Callback()
+	registrations := []func(name string, fn func(*gorm.DB)) error{
+		callback.Create().Before("*").Register,
+		callback.Update().Before("*").Register,
+		callback.Delete().Before("*").Register,
+	}
+	for _, register := range registrations {
+		if err := register("read_your_writes:mark", plugin.markWrite); err != nil {
+			return err
+		}
+	}
+
+	if err := callback.Query().Before("*").Register("read_your_writes:route", plugin.route); err != nil {
+		return err
+	}
+	if err := callback.Row().Before("*").Register("read_your_writes:route", plugin.route); err != nil {
+		return err
+	}
+	return callback.Raw().Before("*").Register("read_your_writes:route_raw", plugin.routeRaw)
+}
+
+// markWrite registra que a sessão escreveu no banco principal
+func (plugin *readYourWritesPlugin) markWrite(db *gorm.DB) {
+	if current := sessionFrom(db); current != nil {
+		current.wrote.Store(true)
+	}
+}
+
+// route envia a leitura ao banco principal quando a sessão já escreveu ou quando o contexto pede o principal
+func (plugin *readYourWritesPlugin) route(db *gorm.DB) {
+	if db.Statement.Context != nil && repositories.ReadsPrimary(db.Statement.Context) {
+		dbresolver.Write.ModifyStatement(db.Statement)
+		return
+	}
+	if current := sessionFrom(db); current != nil && current.wrote.Load() {
+		dbresolver.Write.ModifyStatement(db.Statement)
+	}
+}
+
+// routeRaw trata as instruções escritas à mão: as que não são SELECT contam como escrita
+func (plugin *readYourWritesPlugin) routeRaw(db *gorm.DB) {
+	sql := strings.TrimSpace(db.Statement.SQL.String())
+	if len(sql) < 6 || !strings.EqualFold(sql[:6], "select") {
+		plugin.markWrite(db)
+	}
+	plugin.route(db)
+}
@@ -0,0 +1,91 @@
+package database
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	sqlitedriver "github.com/glebarez/go-sqlite"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// Drivers de banco aceitos em database.driver
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// sqliteMemoryPath é o caminho que pede um banco SQLite em memória
+const sqliteMemoryPath = ":memory:"
+
+// sqliteMemoryCount dá um nome próprio a cada banco em memória aberto pelo processo
+var sqliteMemoryCount atomic.Int64
+
+// registerSQLiteFunctions garante que as funções do SQLite sejam registradas uma única vez
+var registerSQLiteFunctions sync.Once
+
+// openSQLite abre o banco SQLite indicado na configuração.
+// Um banco em memória usa uma única conexão, que nunca é fechada: fechar a última conexão apagaria o banco.
+func openSQLite(path string) (*gorm.DB, error) {
+	var err error
+	registerSQLiteFunctions.Do(func() {
+		err = sqlitedriver.RegisterDeterministicScalarFunction("lower", 1, sqliteLower)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("falha ao registrar as funções do SQLite: %w", err)
+	}
+
+	db, err := gorm.Open(sqlite.Open(SQLiteDSN(path)), &gorm.Config{Logger: newGormLogger()})
+	if err != nil {
+		return nil, err
+	}
+
+	if path == sqliteMemoryPath {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, err
+		}
+		sqlDB.SetMaxOpenConns(1)
+		sqlDB.SetMaxIdleConns(1)
+		sqlDB.SetConnMaxLifetime(0)
+		sqlDB.SetConnMaxIdleTime(0)
+	}
+	return db, nil
+}
+
+// SQLiteDSN monta a string de conexão do SQLite para o arquivo informado, ou um banco em memória novo para ":memory:".
+// As transações começam com BEGIN IMMEDIATE, reservando o banco para escrita: é o equivalente, no SQLite,
+// ao SELECT ... FOR UPDATE que os repositórios usam no PostgreSQL. Conexões concorrentes esperam o bloqueio
+// em vez de falhar, e as chaves estrangeiras são verificadas, como no PostgreSQL.
+func SQLiteDSN(path string) string {
+	query := url.Values{}
+	query.Add("_pragma", "busy_timeout(5000)")
+	query.Add("_pragma", "foreign_keys(1)")
+	query.Set("_txlock", "immediate")
+
+	if path == sqliteMemoryPath {
+		query.Set("mode", "memory")
+		return fmt.Sprintf("file:library-%d?%s", sqliteMemoryCount.Add(1), query.Encode())
+	}
+	query.Add("_pragma", "journal_mode(WAL)")
+	return "file:" + path + "?" + query.Encode()
+}
+
+// sqliteLower substitui a função lower do SQLite, que só converte letras ASCII, para que as buscas
+// com LOWER(...) LIKE tratem acentos ("Ó" e "ó") da mesma forma que no PostgreSQL
+func sqliteLower(ctx *sqlitedriver.FunctionContext, args []driver.Value) (driver.Value, error) {
+	switch value := args[0].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return strings.ToLower(value), nil
+	case []byte:
+		return strings.ToLower(string(value)), nil
+	default:
+		return strings.ToLower(fmt.Sprint(value)), nil
+	}
+}
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Chaves que guardam, na instância da consulta, o contexto original e o cancelamento do tempo limite
+const (
+	timeoutParentContextKey = "timeout:parent_context"
+	timeoutCancelKey        = "timeout:cancel"
+)
+
+// timeoutPlugin limita a duração de cada instrução enviada ao banco.
+// O limite se soma ao prazo do contexto da requisição: vale o que terminar primeiro.
+type timeoutPlugin struct {
+	timeout time.Duration
+}
+
+// NewStatementTimeoutPlugin cria o plugin do GORM que cancela as instruções que passarem do tempo informado.
+// Deve ser instalado com db.Use; um tempo zero ou negativo desliga o limite.
+func NewStatementTimeoutPlugin(timeout time.Duration) gorm.Plugin {
+	return &timeoutPlugin{timeout: timeout}
+}
+
+// Name identifica o plugin no GORM
+func (plugin *timeoutPlugin) Name() string {
+	return "statement_timeout"
+}
+
+// Initialize registra as callbacks antes e depois de cada tipo de operação, exceto Row/Rows.
+// Nelas, e em Scan, que usa Rows, as linhas são lidas depois da callback e não há como saber quando
+// são fechadas para liberar o limite; essas leituras ficam apenas com o prazo do contexto da requisição.
+func (plugin *timeoutPlugin) Initialize(db *gorm.DB) error {
+	if plugin.timeout <= 0 {
+		return nil
+	}
+	if err := registerAround(db, "statement_timeout", plugin.start, plugin.finish); err != nil {
+		return err
+	}
+
+	row := db.Callback().Row()
+	if err := row.Remove("statement_timeout:before_row"); err != nil {
+		return err
+	}
+	return row.Remove("statement_timeout:after_row")
+}
+
+// start troca o contexto da instrução por um com o tempo limite
+func (plugin *timeoutPlugin) start(db *gorm.DB) {
+	parent := db.Statement.Context
+	if parent == nil {
+		parent = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(parent, plugin.timeout)
+	db.InstanceSet(timeoutParentContextKey, parent)
+	db.InstanceSet(timeoutCancelKey, cancel)
+	db.Statement.Context = ctx
+}
+
+// finish devolve o contexto original à instância, que pode ser reaproveitada em outra consulta da mesma cadeia,
+// e libera o tempo limite
+func (plugin *timeoutPlugin) finish(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		if parent, ok := db.InstanceGet(timeoutParentContextKey); ok {
+			db.Statement.Context = parent.(context.Context)
+		}
+		if cancel, ok := db.InstanceGet(timeoutCancelKey); ok {
+			cancel.(context.CancelFunc)()
+		}
+	}
+}
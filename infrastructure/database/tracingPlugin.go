@@ -0,0 +1,69 @@
+package database
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// tracingSpanKey guarda, na instância da consulta, o span aberto para ela
+const tracingSpanKey = "tracing:span"
+
+// tracingPlugin cria um span para cada consulta do GORM, filho do span presente no contexto da consulta
+type tracingPlugin struct {
+	tracer trace.Tracer
+}
+
+// NewTracingPlugin cria o plugin do GORM que registra cada consulta como um span.
+// Deve ser instalado com db.Use; os repositórios precisam usar db.WithContext(ctx).
+func NewTracingPlugin() gorm.Plugin {
+	return &tracingPlugin{tracer: otel.Tracer("gorm.io/gorm")}
+}
+
+// Name identifica o plugin no GORM
+func (plugin *tracingPlugin) Name() string {
+	return "tracing"
+}
+
+// Initialize registra as callbacks antes e depois de cada tipo de operação
+func (plugin *tracingPlugin) Initialize(db *gorm.DB) error {
+	return registerAround(db, "tracing", plugin.start, plugin.finish)
+}
+
+// start abre o span da consulta
+func (plugin *tracingPlugin) start(db *gorm.DB) {
+	_, span := plugin.tracer.Start(db.Statement.Context, "gorm", trace.WithSpanKind(trace.SpanKindClient))
+	db.InstanceSet(tracingSpanKey, span)
+}
+
+// finish completa o span com a instrução executada (sem os valores dos parâmetros) e o resultado
+func (plugin *tracingPlugin) finish(operation string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		value, ok := db.InstanceGet(tracingSpanKey)
+		if !ok {
+			return
+		}
+		span, ok := value.(trace.Span)
+		if !ok {
+			return
+		}
+		defer span.End()
+
+		span.SetName("gorm." + operation + " " + db.Statement.Table)
+		span.SetAttributes(
+			attribute.String("db.system", db.Dialector.Name()),
+			attribute.String("db.operation.name", operation),
+			attribute.String("db.collection.name", db.Statement.Table),
+			attribute.String("db.query.text", db.Statement.SQL.String()),
+			attribute.Int64("db.rows_affected", db.RowsAffected),
+		)
+		if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+			span.RecordError(db.Error)
+			span.SetStatus(codes.Error, db.Error.Error())
+		}
+	}
+}
@@ -0,0 +1,486 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/repositories"
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+	domainerrors "github.com/henrygoeszanin/api_golang_estudos/domain/errors"
+)
+
+// bookRepository implementa a interface BookRepository em memória
+type bookRepository struct {
+	store *Store
+}
+
+// NewBookRepository cria uma nova instância do repositório de livros em memória
+func NewBookRepository(store *Store) repositories.BookRepository {
+	return &bookRepository{
+		store: store,
+	}
+}
+
+// Create cria um novo livro
+func (bookRepository *bookRepository) Create(ctx context.Context, book *entities.Book) error {
+	// Garantir que disponível = quantidade inicialmente
+	book.Available = book.Quantity
+
+	return bookRepository.store.write(ctx, func(now time.Time) error {
+		if err := bookRepository.checkISBNs([]*entities.Book{book}, nil); err != nil {
+			return err
+		}
+		bookRepository.create(book, now)
+		return nil
+	})
+}
+
+// FindByID busca um livro pelo seu ID
+func (bookRepository *bookRepository) FindByID(ctx context.Context, id uint) (*entities.Book, error) {
+	var found *entities.Book
+	err := bookRepository.store.read(ctx, func() error {
+		if book, ok := bookRepository.store.books[id]; ok && active(book.Model) {
+			found = bookRepository.store.preloadedBook(book)
+		}
+		return nil
+	})
+	return found, err
+}
+
+// FindByISBN busca um livro pelo ISBN-13
+func (bookRepository *bookRepository) FindByISBN(ctx context.Context, isbn string) (*entities.Book, error) {
+	books, err := bookRepository.FindByISBNs(ctx, []string{isbn})
+	if err != nil || len(books) == 0 {
+		return nil, err
+	}
+	return books[0], nil
+}
+
+// FindByDedupKey busca os livros com o mesmo título e autores normalizados
+func (bookRepository *bookRepository) FindByDedupKey(ctx context.Context, key string) ([]*entities.Book, error) {
+	return bookRepository.find(ctx, true, func(book *entities.Book) bool {
+		return book.DedupKey == key
+	})
+}
+
+// FindByISBNs busca os livros com qualquer um dos ISBN-13 informados
+func (bookRepository *bookRepository) FindByISBNs(ctx context.Context, isbns []string) ([]*entities.Book, error) {
+	wanted := make(map[string]bool, len(isbns))
+	for _, isbn := range isbns {
+		wanted[isbn] = true
+	}
+	return bookRepository.find(ctx, true, func(book *entities.Book) bool {
+		return book.ISBN != nil && wanted[*book.ISBN]
+	})
+}
+
+// FindByDedupKeys busca os livros com qualquer uma das chaves de detecção de repetidos
+func (bookRepository *bookRepository) FindByDedupKeys(ctx context.Context, keys []string) ([]*entities.Book, error) {
+	wanted := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		wanted[key] = true
+	}
+	return bookRepository.find(ctx, false, func(book *entities.Book) bool {
+		return wanted[book.DedupKey]
+	})
+}
+
+// List retorna os livros que atendem ao filtro
+func (bookRepository *bookRepository) List(ctx context.Context, filter repositories.BookFilter) ([]*entities.Book, error) {
+	return bookRepository.find(ctx, true, func(book *entities.Book) bool {
+		return matches(book, filter)
+	})
+}
+
+// ListInBatches percorre os livros que atendem ao filtro em lotes ordenados pelo ID.
+// Os lotes são processados fora do bloqueio, para que o processamento possa usar os repositórios.
+func (bookRepository *bookRepository) ListInBatches(ctx context.Context, filter repositories.BookFilter, batchSize int, process func(books []*entities.Book) error) error {
+	books, err := bookRepository.List(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	for start := 0; start < len(books); start += batchSize {
+		end := min(start+batchSize, len(books))
+		if err := process(books[start:end]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Update atualiza os dados de um livro, substituindo autores e assuntos
+func (bookRepository *bookRepository) Update(ctx context.Context, book *entities.Book) error {
+	return bookRepository.store.write(ctx, func(now time.Time) error {
+		if err := bookRepository.checkISBNs(nil, []*entities.Book{book}); err != nil {
+			return err
+		}
+		if err := checkAvailability([]*entities.Book{book}); err != nil {
+			return err
+		}
+		bookRepository.update(book, now)
+		return nil
+	})
+}
+
+// AddCopies adiciona exemplares ao livro, todos disponíveis e sem unidade definida
+func (bookRepository *bookRepository) AddCopies(ctx context.Context, id uint, quantity int) error {
+	return bookRepository.store.write(ctx, func(now time.Time) error {
+		book, ok := bookRepository.store.books[id]
+		if !ok || !active(book.Model) {
+			return fmt.Errorf("%w: livro não encontrado", domainerrors.ErrNotFound)
+		}
+		book.Quantity += quantity
+		book.Available += quantity
+		book.UpdatedAt = now
+		return nil
+	})
+}
+
+// SaveBatch cria e atualiza um lote de livros de uma só vez: com qualquer erro, nada é gravado
+func (bookRepository *bookRepository) SaveBatch(ctx context.Context, creates []*entities.Book, updates []*entities.Book) error {
+	for _, book := range creates {
+		book.Available = book.Quantity
+	}
+
+	return bookRepository.store.write(ctx, func(now time.Time) error {
+		if err := bookRepository.checkISBNs(creates, updates); err != nil {
+			return err
+		}
+		if err := checkAvailability(updates); err != nil {
+			return err
+		}
+		for _, book := range creates {
+			bookRepository.create(book, now)
+		}
+		for _, book := range updates {
+			bookRepository.update(book, now)
+		}
+		return nil
+	})
+}
+
+// Delete remove um livro pelo seu ID.
+// Livros com empréstimos em aberto não podem ser removidos.
+func (bookRepository *bookRepository) Delete(ctx context.Context, id uint) error {
+	return bookRepository.store.write(ctx, func(now time.Time) error {
+		book, ok := bookRepository.store.books[id]
+		if !ok || !active(book.Model) {
+			return fmt.Errorf("%w: livro não encontrado", domainerrors.ErrNotFound)
+		}
+
+		openLoans := 0
+		for _, loan := range bookRepository.store.loans {
+			if loan.BookID == id && !loan.IsReturned {
+				openLoans++
+			}
+		}
+		if openLoans > 0 {
+			return fmt.Errorf("%w: livro possui %d empréstimo(s) em aberto", domainerrors.ErrConflict, openLoans)
+		}
+
+		book.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+		return nil
+	})
+}
+
+// ListDeleted retorna os livros removidos, do mais recente ao mais antigo
+func (bookRepository *bookRepository) ListDeleted(ctx context.Context) ([]*entities.Book, error) {
+	var books []*entities.Book
+	err := bookRepository.store.read(ctx, func() error {
+		for _, id := range sortedIDs(bookRepository.store.books) {
+			if book := bookRepository.store.books[id]; !active(book.Model) {
+				books = append(books, bookRepository.store.preloadedBook(book))
+			}
+		}
+		return nil
+	})
+	sort.SliceStable(books, func(i, j int) bool {
+		return books[i].DeletedAt.Time.After(books[j].DeletedAt.Time)
+	})
+	return books, err
+}
+
+// SumAvailable soma os exemplares disponíveis dos livros em circulação
+func (bookRepository *bookRepository) SumAvailable(ctx context.Context) (int64, error) {
+	var total int64
+	err := bookRepository.store.read(ctx, func() error {
+		for _, book := range bookRepository.store.books {
+			if active(book.Model) && book.Status == entities.BookStatusActive {
+				total += int64(book.Available)
+			}
+		}
+		return nil
+	})
+	return total, err
+}
+
+// Restore desfaz a remoção de um livro.
+// Falha se outro livro passou a usar o mesmo ISBN depois da remoção.
+func (bookRepository *bookRepository) Restore(ctx context.Context, id uint) error {
+	return bookRepository.store.write(ctx, func(now time.Time) error {
+		book, ok := bookRepository.store.books[id]
+		if !ok || active(book.Model) {
+			return fmt.Errorf("%w: livro removido não encontrado", domainerrors.ErrNotFound)
+		}
+
+		if book.ISBN != nil {
+			if existing := bookRepository.activeByISBN(*book.ISBN, 0); existing != nil {
+				return &domainerrors.DuplicateError{ExistingID: existing.ID, Reason: "ISBN já cadastrado em outro livro"}
+			}
+		}
+
+		book.DeletedAt = gorm.DeletedAt{}
+		book.UpdatedAt = now
+		return nil
+	})
+}
+
+// ReconcileInventory compara os contadores de exemplares disponíveis dos livros e das unidades com os
+// empréstimos em aberto e retorna os que não conferem. Com repair, grava os valores calculados.
+func (bookRepository *bookRepository) ReconcileInventory(ctx context.Context, repair bool) ([]repositories.InventoryDiscrepancy, error) {
+	var discrepancies []repositories.InventoryDiscrepancy
+	reconcile := func(now time.Time) error {
+		store := bookRepository.store
+
+		// Empréstimos em aberto por livro e unidade de retirada
+		openByBook := make(map[uint]int)
+		openByHolding := make(map[[2]uint]int)
+		for _, loan := range store.loans {
+			if !active(loan.Model) || loan.IsReturned {
+				continue
+			}
+			openByBook[loan.BookID]++
+			if loan.BranchID != nil {
+				openByHolding[[2]uint{loan.BookID, *loan.BranchID}]++
+			}
+		}
+
+		for _, id := range sortedIDs(store.books) {
+			book := store.books[id]
+			if !active(book.Model) {
+				continue
+			}
+
+			expected := entities.ExpectedAvailable(book.Quantity, openByBook[book.ID])
+			if book.Available != expected {
+				discrepancies = append(discrepancies, repositories.InventoryDiscrepancy{
+					BookID:    book.ID,
+					Title:     book.Title,
+					Quantity:  book.Quantity,
+					Available: book.Available,
+					OpenLoans: openByBook[book.ID],
+					Expected:  expected,
+				})
+				if repair {
+					book.Available = expected
+					book.UpdatedAt = now
+				}
+			}
+
+			var holdings []*entities.BookHolding
+			for _, holding := range store.holdings {
+				if holding.BookID == book.ID && active(holding.Model) {
+					holdings = append(holdings, holding)
+				}
+			}
+			sort.Slice(holdings, func(i, j int) bool { return holdings[i].BranchID < holdings[j].BranchID })
+
+			for _, holding := range holdings {
+				branchID := holding.BranchID
+				open := openByHolding[[2]uint{book.ID, branchID}]
+				expected := entities.ExpectedAvailable(holding.Quantity, open)
+				if holding.Available == expected {
+					continue
+				}
+				discrepancies = append(discrepancies, repositories.InventoryDiscrepancy{
+					BookID:    book.ID,
+					Title:     book.Title,
+					BranchID:  &branchID,
+					Quantity:  holding.Quantity,
+					Available: holding.Available,
+					OpenLoans: open,
+					Expected:  expected,
+				})
+				if repair {
+					holding.Available = expected
+					holding.UpdatedAt = now
+				}
+			}
+		}
+		return nil
+	}
+
+	var err error
+	if repair {
+		err = bookRepository.store.write(ctx, reconcile)
+	} else {
+		err = bookRepository.store.read(ctx, func() error { return reconcile(time.Now()) })
+	}
+	if err != nil {
+		return nil, err
+	}
+	return discrepancies, nil
+}
+
+// find retorna os livros não removidos aceitos pelo critério, em ordem de ID
+func (bookRepository *bookRepository) find(ctx context.Context, preload bool, accept func(book *entities.Book) bool) ([]*entities.Book, error) {
+	var books []*entities.Book
+	err := bookRepository.store.read(ctx, func() error {
+		for _, id := range sortedIDs(bookRepository.store.books) {
+			book := bookRepository.store.books[id]
+			if !active(book.Model) || !accept(book) {
+				continue
+			}
+			if preload {
+				books = append(books, bookRepository.store.preloadedBook(book))
+			} else {
+				books = append(books, bookRepository.store.book(book))
+			}
+		}
+		return nil
+	})
+	return books, err
+}
+
+// checkISBNs garante que os livros a gravar não repetem o ISBN de outro livro não removido,
+// a mesma regra do índice único do banco
+func (bookRepository *bookRepository) checkISBNs(creates []*entities.Book, updates []*entities.Book) error {
+	claimed := make(map[string]uint)
+	for _, book := range append(append([]*entities.Book(nil), creates...), updates...) {
+		if book.ISBN == nil || !active(book.Model) {
+			continue
+		}
+		if _, ok := claimed[*book.ISBN]; ok {
+			return fmt.Errorf("%w: ISBN %s repetido no lote", domainerrors.ErrAlreadyExists, *book.ISBN)
+		}
+		claimed[*book.ISBN] = book.ID
+		if existing := bookRepository.activeByISBN(*book.ISBN, book.ID); existing != nil {
+			return &domainerrors.DuplicateError{ExistingID: existing.ID, Reason: "ISBN já cadastrado em outro livro"}
+		}
+	}
+	return nil
+}
+
+// checkAvailability garante que os livros a gravar têm de zero à quantidade de exemplares disponíveis,
+// a mesma regra da restrição chk_books_available do banco
+func checkAvailability(books []*entities.Book) error {
+	for _, book := range books {
+		if book.Available < 0 || book.Available > book.Quantity {
+			return fmt.Errorf("livro %d: exemplares disponíveis fora do intervalo de 0 a %d", book.ID, book.Quantity)
+		}
+	}
+	return nil
+}
+
+// activeByISBN busca o livro não removido com o ISBN informado, ignorando o livro de ID exceptID
+func (bookRepository *bookRepository) activeByISBN(isbn string, exceptID uint) *entities.Book {
+	for _, book := range bookRepository.store.books {
+		if book.ID != exceptID && active(book.Model) && book.ISBN != nil && *book.ISBN == isbn {
+			return book
+		}
+	}
+	return nil
+}
+
+// create grava o novo livro, aplicando os valores padrão das colunas
+func (bookRepository *bookRepository) create(book *entities.Book, now time.Time) {
+	// Colunas com valor padrão no banco recebem o padrão quando gravadas com o valor zero
+	if book.Quantity == 0 {
+		book.Quantity = 1
+	}
+	if book.Available == 0 {
+		book.Available = 1
+	}
+	if book.Status == "" {
+		book.Status = entities.BookStatusActive
+	}
+
+	bookRepository.resolve(book, now)
+	bookRepository.store.create("books", &book.Model, now)
+	bookRepository.store.books[book.ID] = bookRepository.store.book(book)
+}
+
+// update substitui o livro gravado, mantendo os exemplares por unidade
+func (bookRepository *bookRepository) update(book *entities.Book, now time.Time) {
+	bookRepository.resolve(book, now)
+	book.UpdatedAt = now
+	bookRepository.store.books[book.ID] = bookRepository.store.book(book)
+}
+
+// resolve substitui autores e assuntos pelo registro existente de mesmo nome, criando os que faltam
+func (bookRepository *bookRepository) resolve(book *entities.Book, now time.Time) {
+	for i := range book.Authors {
+		author, ok := bookRepository.store.authors[book.Authors[i].Name]
+		if !ok {
+			author = entities.Author{Name: book.Authors[i].Name}
+			bookRepository.store.create("authors", &author.Model, now)
+			bookRepository.store.authors[author.Name] = author
+		}
+		book.Authors[i] = author
+	}
+	for i := range book.Subjects {
+		subject, ok := bookRepository.store.subjects[book.Subjects[i].Name]
+		if !ok {
+			subject = entities.Subject{Name: book.Subjects[i].Name}
+			bookRepository.store.create("subjects", &subject.Model, now)
+			bookRepository.store.subjects[subject.Name] = subject
+		}
+		book.Subjects[i] = subject
+	}
+}
+
+// matches aplica os critérios do filtro, com as mesmas regras das consultas do repositório GORM
+func matches(book *entities.Book, filter repositories.BookFilter) bool {
+	if filter.Title != "" && !contains(book.Title, filter.Title) {
+		return false
+	}
+	if filter.Author != "" && !anyName(book.Authors, func(author entities.Author) string { return author.Name }, filter.Author) {
+		return false
+	}
+	if filter.Subject != "" && !anyName(book.Subjects, func(subject entities.Subject) string { return subject.Name }, filter.Subject) {
+		return false
+	}
+	if filter.ISBN != "" && (book.ISBN == nil || *book.ISBN != filter.ISBN) {
+		return false
+	}
+	if filter.Publisher != "" && !contains(book.Publisher, filter.Publisher) {
+		return false
+	}
+	if filter.Language != "" && strings.ToLower(book.Language) != strings.ToLower(filter.Language) {
+		return false
+	}
+	if filter.YearFrom > 0 && book.PublicationYear < filter.YearFrom {
+		return false
+	}
+	if filter.YearTo > 0 && book.PublicationYear > filter.YearTo {
+		return false
+	}
+	if filter.AvailableOnly && book.Available <= 0 {
+		return false
+	}
+	if filter.Status != "" && book.Status != filter.Status {
+		return false
+	}
+	return true
+}
+
+// contains faz a busca parcial sem diferenciar maiúsculas, como LOWER(...) LIKE '%valor%'
+func contains(value, search string) bool {
+	return strings.Contains(strings.ToLower(value), strings.ToLower(strings.TrimSpace(search)))
+}
+
+// anyName informa se algum dos registros tem nome que contém o valor buscado
+func anyName[T any](records []T, name func(record T) string, search string) bool {
+	for _, record := range records {
+		if contains(name(record), search) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,185 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/repositories"
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+	domainerrors "github.com/henrygoeszanin/api_golang_estudos/domain/errors"
+)
+
+// branchRepository implementa a interface BranchRepository em memória
+type branchRepository struct {
+	store *Store
+}
+
+// NewBranchRepository cria uma nova instância do repositório de unidades em memória
+func NewBranchRepository(store *Store) repositories.BranchRepository {
+	return &branchRepository{
+		store: store,
+	}
+}
+
+// Create cria uma nova unidade
+func (branchRepository *branchRepository) Create(ctx context.Context, branch *entities.Branch) error {
+	return branchRepository.store.write(ctx, func(now time.Time) error {
+		if err := branchRepository.checkUnique(branch); err != nil {
+			return err
+		}
+		branchRepository.store.create("branches", &branch.Model, now)
+		plainBranch := branchRepository.store.plainBranch(branch)
+		branchRepository.store.branches[branch.ID] = &plainBranch
+		return nil
+	})
+}
+
+// FindByID busca uma unidade pelo seu ID
+func (branchRepository *branchRepository) FindByID(ctx context.Context, id uint) (*entities.Branch, error) {
+	var found *entities.Branch
+	err := branchRepository.store.read(ctx, func() error {
+		if branch, ok := branchRepository.store.branches[id]; ok && active(branch.Model) {
+			plainBranch := branchRepository.store.plainBranch(branch)
+			found = &plainBranch
+		}
+		return nil
+	})
+	return found, err
+}
+
+// List retorna todas as unidades, em ordem de nome
+func (branchRepository *branchRepository) List(ctx context.Context) ([]*entities.Branch, error) {
+	var branches []*entities.Branch
+	err := branchRepository.store.read(ctx, func() error {
+		for _, id := range sortedIDs(branchRepository.store.branches) {
+			if branch := branchRepository.store.branches[id]; active(branch.Model) {
+				plainBranch := branchRepository.store.plainBranch(branch)
+				branches = append(branches, &plainBranch)
+			}
+		}
+		return nil
+	})
+	sort.SliceStable(branches, func(i, j int) bool {
+		return branches[i].Name < branches[j].Name
+	})
+	return branches, err
+}
+
+// Update atualiza os dados de uma unidade
+func (branchRepository *branchRepository) Update(ctx context.Context, branch *entities.Branch) error {
+	return branchRepository.store.write(ctx, func(now time.Time) error {
+		if err := branchRepository.checkUnique(branch); err != nil {
+			return err
+		}
+		if branch.ID == 0 {
+			branchRepository.store.create("branches", &branch.Model, now)
+		}
+		branch.UpdatedAt = now
+		plainBranch := branchRepository.store.plainBranch(branch)
+		branchRepository.store.branches[branch.ID] = &plainBranch
+		return nil
+	})
+}
+
+// Delete remove uma unidade pelo seu ID, desde que não possua exemplares alocados
+func (branchRepository *branchRepository) Delete(ctx context.Context, id uint) error {
+	return branchRepository.store.write(ctx, func(now time.Time) error {
+		for _, holding := range branchRepository.store.holdings {
+			if holding.BranchID == id && holding.Quantity > 0 {
+				return errors.New("unidade possui exemplares alocados")
+			}
+		}
+
+		branch, ok := branchRepository.store.branches[id]
+		if !ok || !active(branch.Model) {
+			return errors.New("unidade não encontrada")
+		}
+		branch.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+		return nil
+	})
+}
+
+// FindHolding busca os exemplares de um livro alocados em uma unidade
+func (branchRepository *branchRepository) FindHolding(ctx context.Context, bookID, branchID uint) (*entities.BookHolding, error) {
+	var found *entities.BookHolding
+	err := branchRepository.store.read(ctx, func() error {
+		if holding := branchRepository.store.findHolding(bookID, branchID); holding != nil {
+			found = branchRepository.store.holding(holding)
+		}
+		return nil
+	})
+	return found, err
+}
+
+// SetHoldingQuantity define quantos exemplares de um livro ficam alocados em uma unidade.
+// Os exemplares alocados saem do total do livro; o restante continua sem unidade definida.
+func (branchRepository *branchRepository) SetHoldingQuantity(ctx context.Context, bookID, branchID uint, quantity int) error {
+	return branchRepository.store.write(ctx, func(now time.Time) error {
+		book, ok := branchRepository.store.books[bookID]
+		if !ok || !active(book.Model) {
+			return errors.New("livro não encontrado")
+		}
+
+		// Somar o que já está alocado nas demais unidades
+		var holding *entities.BookHolding
+		allocated, allocatedAvailable := 0, 0
+		for _, other := range branchRepository.store.holdings {
+			if other.BookID != bookID {
+				continue
+			}
+			if other.BranchID == branchID {
+				holding = other
+				continue
+			}
+			allocated += other.Quantity
+			allocatedAvailable += other.Available
+		}
+
+		quantityBefore, availableBefore := 0, 0
+		if holding != nil {
+			quantityBefore, availableBefore = holding.Quantity, holding.Available
+		} else if branch, ok := branchRepository.store.branches[branchID]; !ok || !active(branch.Model) {
+			return errors.New("unidade não encontrada")
+		}
+
+		if quantity < quantityBefore-availableBefore {
+			return errors.New("quantidade menor que o número de exemplares emprestados na unidade")
+		}
+		if allocated+quantity > book.Quantity {
+			return errors.New("quantidade excede o total de exemplares do livro")
+		}
+
+		// Novos exemplares só podem vir dos disponíveis sem unidade definida
+		delta := quantity - quantityBefore
+		unallocatedAvailable := book.Available - allocatedAvailable - availableBefore
+		if delta > unallocatedAvailable {
+			return errors.New("não há exemplares livres suficientes para alocar na unidade")
+		}
+
+		if holding == nil {
+			holding = &entities.BookHolding{BookID: bookID, BranchID: branchID}
+			branchRepository.store.create("book_holdings", &holding.Model, now)
+			branchRepository.store.holdings[holding.ID] = holding
+		}
+		holding.Quantity = quantity
+		holding.Available += delta
+		holding.UpdatedAt = now
+		return nil
+	})
+}
+
+// checkUnique garante que nenhuma outra unidade, mesmo removida, usa o nome ou o código,
+// a mesma regra dos índices únicos do banco
+func (branchRepository *branchRepository) checkUnique(branch *entities.Branch) error {
+	for _, other := range branchRepository.store.branches {
+		if other.ID != branch.ID && (other.Name == branch.Name || other.Code == branch.Code) {
+			return fmt.Errorf("%w: unidade com o mesmo nome ou código já cadastrada", domainerrors.ErrAlreadyExists)
+		}
+	}
+	return nil
+}
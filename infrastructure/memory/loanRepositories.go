@@ -0,0 +1,232 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/repositories"
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+)
+
+// loanRepository implementa a interface LoanRepository em memória
+type loanRepository struct {
+	store *Store
+}
+
+// NewLoanRepository cria uma nova instância do repositório de empréstimos em memória
+func NewLoanRepository(store *Store) repositories.LoanRepository {
+	return &loanRepository{
+		store: store,
+	}
+}
+
+// Create cria um novo empréstimo, consumindo um exemplar do livro e, se informada, da unidade de retirada
+func (loanRepository *loanRepository) Create(ctx context.Context, loan *entities.Loan) error {
+	return loanRepository.store.write(ctx, func(now time.Time) error {
+		book, ok := loanRepository.store.books[loan.BookID]
+		if !ok || !active(book.Model) {
+			return gorm.ErrRecordNotFound
+		}
+
+		if book.Status == entities.BookStatusWithdrawn {
+			return errors.New("livro retirado do acervo")
+		}
+
+		if book.Available <= 0 {
+			return errors.New("livro não disponível para empréstimo")
+		}
+
+		if user, ok := loanRepository.store.users[loan.UserID]; !ok || !active(user.Model) {
+			return errors.New("usuário não encontrado")
+		}
+
+		var holding *entities.BookHolding
+		if loan.BranchID != nil {
+			// Retirada em uma unidade: consumir um exemplar da unidade
+			holding = loanRepository.store.findHolding(loan.BookID, *loan.BranchID)
+			if holding == nil {
+				return errors.New("livro não possui exemplares nesta unidade")
+			}
+			if holding.Available <= 0 {
+				return errors.New("livro não disponível para empréstimo nesta unidade")
+			}
+		} else {
+			// Sem unidade: apenas exemplares não alocados podem ser emprestados
+			allocatedAvailable := 0
+			for _, holding := range loanRepository.store.holdings {
+				if holding.BookID == loan.BookID {
+					allocatedAvailable += holding.Available
+				}
+			}
+			if book.Available <= allocatedAvailable {
+				return errors.New("livro disponível apenas em unidades, informe a unidade de retirada")
+			}
+		}
+
+		if holding != nil {
+			holding.Available--
+			holding.UpdatedAt = now
+		}
+		book.Available--
+		book.UpdatedAt = now
+
+		loanRepository.store.create("loans", &loan.Model, now)
+		loanRepository.store.loans[loan.ID] = loanRepository.plain(loan)
+		return nil
+	})
+}
+
+// FindByID busca um empréstimo pelo seu ID
+func (loanRepository *loanRepository) FindByID(ctx context.Context, id uint) (*entities.Loan, error) {
+	var found *entities.Loan
+	err := loanRepository.store.read(ctx, func() error {
+		if loan, ok := loanRepository.store.loans[id]; ok && active(loan.Model) {
+			found = loanRepository.preloaded(loan)
+		}
+		return nil
+	})
+	return found, err
+}
+
+// FindByUserID busca todos os empréstimos de um usuário
+func (loanRepository *loanRepository) FindByUserID(ctx context.Context, userID uint) ([]*entities.Loan, error) {
+	var loans []*entities.Loan
+	err := loanRepository.store.read(ctx, func() error {
+		for _, id := range sortedIDs(loanRepository.store.loans) {
+			if loan := loanRepository.store.loans[id]; active(loan.Model) && loan.UserID == userID {
+				loans = append(loans, loanRepository.preloaded(loan))
+			}
+		}
+		return nil
+	})
+	return loans, err
+}
+
+// CountByUserID conta os empréstimos de um usuário, devolvidos ou não
+func (loanRepository *loanRepository) CountByUserID(ctx context.Context, userID uint) (int64, error) {
+	return loanRepository.count(ctx, func(loan *entities.Loan) bool {
+		return loan.UserID == userID
+	})
+}
+
+// CountActive conta os empréstimos ainda não devolvidos
+func (loanRepository *loanRepository) CountActive(ctx context.Context) (int64, error) {
+	return loanRepository.count(ctx, func(loan *entities.Loan) bool {
+		return !loan.IsReturned
+	})
+}
+
+// CountOverdue conta os empréstimos não devolvidos cuja data prevista de devolução já passou
+func (loanRepository *loanRepository) CountOverdue(ctx context.Context, now time.Time) (int64, error) {
+	return loanRepository.count(ctx, func(loan *entities.Loan) bool {
+		return !loan.IsReturned && loan.ReturnDate.Before(now)
+	})
+}
+
+// ListOverdue retorna os empréstimos não devolvidos cuja data prevista de devolução já passou,
+// do mais atrasado ao mais recente
+func (loanRepository *loanRepository) ListOverdue(ctx context.Context, now time.Time) ([]*entities.Loan, error) {
+	var loans []*entities.Loan
+	err := loanRepository.store.read(ctx, func() error {
+		for _, id := range sortedIDs(loanRepository.store.loans) {
+			if loan := loanRepository.store.loans[id]; active(loan.Model) && !loan.IsReturned && loan.ReturnDate.Before(now) {
+				loans = append(loans, loanRepository.preloaded(loan))
+			}
+		}
+		return nil
+	})
+	sort.SliceStable(loans, func(i, j int) bool {
+		return loans[i].ReturnDate.Before(loans[j].ReturnDate)
+	})
+	return loans, err
+}
+
+// Update atualiza os dados de um empréstimo
+func (loanRepository *loanRepository) Update(ctx context.Context, loan *entities.Loan) error {
+	return loanRepository.store.write(ctx, func(now time.Time) error {
+		if loan.ID == 0 {
+			loanRepository.store.create("loans", &loan.Model, now)
+		}
+		loan.UpdatedAt = now
+		loanRepository.store.loans[loan.ID] = loanRepository.plain(loan)
+		return nil
+	})
+}
+
+// ReturnLoan marca um empréstimo como devolvido e atualiza o estoque do livro
+func (loanRepository *loanRepository) ReturnLoan(ctx context.Context, id uint, returnDate time.Time) error {
+	return loanRepository.store.write(ctx, func(now time.Time) error {
+		loan, ok := loanRepository.store.loans[id]
+		if !ok || !active(loan.Model) {
+			return gorm.ErrRecordNotFound
+		}
+
+		if loan.IsReturned {
+			return errors.New("empréstimo já foi devolvido")
+		}
+
+		loan.IsReturned = true
+		loan.ReturnedAt = &returnDate
+		loan.UpdatedAt = now
+
+		// Aumentar disponibilidade do livro, mesmo que ele tenha sido removido depois do empréstimo
+		if book, ok := loanRepository.store.books[loan.BookID]; ok {
+			book.Available++
+			book.UpdatedAt = now
+		}
+
+		// Devolver o exemplar à unidade de retirada
+		if loan.BranchID != nil {
+			if holding := loanRepository.store.findHolding(loan.BookID, *loan.BranchID); holding != nil {
+				holding.Available++
+				holding.UpdatedAt = now
+			}
+		}
+		return nil
+	})
+}
+
+// count conta os empréstimos aceitos pelo critério
+func (loanRepository *loanRepository) count(ctx context.Context, accept func(loan *entities.Loan) bool) (int64, error) {
+	var count int64
+	err := loanRepository.store.read(ctx, func() error {
+		for _, loan := range loanRepository.store.loans {
+			if active(loan.Model) && accept(loan) {
+				count++
+			}
+		}
+		return nil
+	})
+	return count, err
+}
+
+// plain copia apenas as colunas do empréstimo
+func (loanRepository *loanRepository) plain(loan *entities.Loan) *entities.Loan {
+	copied := *loan
+	copied.User = entities.User{}
+	copied.Book = entities.Book{}
+	copied.Branch = nil
+	return &copied
+}
+
+// preloaded copia o empréstimo com o livro, mesmo removido, o usuário e a unidade carregados
+func (loanRepository *loanRepository) preloaded(loan *entities.Loan) *entities.Loan {
+	copied := loanRepository.plain(loan)
+	if book, ok := loanRepository.store.books[loan.BookID]; ok {
+		copied.Book = loanRepository.store.plainBook(book)
+	}
+	if user, ok := loanRepository.store.users[loan.UserID]; ok && active(user.Model) {
+		copied.User = *loanRepository.store.user(user)
+	}
+	if loan.BranchID != nil {
+		if branch, ok := loanRepository.store.branches[*loan.BranchID]; ok && active(branch.Model) {
+			plainBranch := loanRepository.store.plainBranch(branch)
+			copied.Branch = &plainBranch
+		}
+	}
+	return copied
+}
@@ -0,0 +1,162 @@
+// Package memory implementa os repositórios de livros, empréstimos, usuários, unidades e transferências
+// em memória, com a mesma semântica dos repositórios GORM: primeiro usuário administrador, remoção lógica,
+// contadores de exemplares disponíveis por livro e por unidade e as mesmas validações e erros.
+// Unidades e transferências fazem parte do pacote porque dividem com livros e empréstimos os exemplares
+// alocados em cada unidade. Serve aos testes de ponta a ponta e ao roteiro de contrato dos repositórios.
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+)
+
+// Store guarda os registros em memória. Um único bloqueio protege todos os registros, de modo que cada
+// operação dos repositórios é atômica e isolada das demais, como as transações dos repositórios GORM.
+type Store struct {
+	mutex sync.RWMutex
+
+	users     map[uint]*entities.User
+	books     map[uint]*entities.Book
+	authors   map[string]entities.Author
+	subjects  map[string]entities.Subject
+	loans     map[uint]*entities.Loan
+	branches  map[uint]*entities.Branch
+	holdings  map[uint]*entities.BookHolding
+	transfers map[uint]*entities.TransferRequest
+
+	lastID map[string]uint
+}
+
+// NewStore cria um armazenamento vazio
+func NewStore() *Store {
+	return &Store{
+		users:     make(map[uint]*entities.User),
+		books:     make(map[uint]*entities.Book),
+		authors:   make(map[string]entities.Author),
+		subjects:  make(map[string]entities.Subject),
+		loans:     make(map[uint]*entities.Loan),
+		branches:  make(map[uint]*entities.Branch),
+		holdings:  make(map[uint]*entities.BookHolding),
+		transfers: make(map[uint]*entities.TransferRequest),
+		lastID:    make(map[string]uint),
+	}
+}
+
+// read executa a consulta com o bloqueio de leitura, respeitando o cancelamento do contexto
+func (store *Store) read(ctx context.Context, query func() error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	store.mutex.RLock()
+	defer store.mutex.RUnlock()
+	return query()
+}
+
+// write executa a alteração com o bloqueio de escrita, respeitando o cancelamento do contexto.
+// As alterações validam tudo antes de gravar, para que um erro não deixe registros pela metade.
+func (store *Store) write(ctx context.Context, change func(now time.Time) error) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	return change(time.Now())
+}
+
+// create atribui o próximo ID da tabela e as datas de criação e atualização
+func (store *Store) create(table string, model *gorm.Model, now time.Time) {
+	store.lastID[table]++
+	model.ID = store.lastID[table]
+	if model.CreatedAt.IsZero() {
+		model.CreatedAt = now
+	}
+	model.UpdatedAt = now
+}
+
+// active informa se o registro não foi removido
+func active(model gorm.Model) bool {
+	return !model.DeletedAt.Valid
+}
+
+// sortedIDs retorna as chaves do mapa em ordem crescente
+func sortedIDs[T any](records map[uint]T) []uint {
+	ids := make([]uint, 0, len(records))
+	for id := range records {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// book copia o livro com autores e assuntos, sem os registros relacionados
+func (store *Store) book(book *entities.Book) *entities.Book {
+	copied := *book
+	copied.Authors = append([]entities.Author(nil), book.Authors...)
+	copied.Subjects = append([]entities.Subject(nil), book.Subjects...)
+	copied.Loans = nil
+	copied.Holdings = nil
+	return &copied
+}
+
+// preloadedBook copia o livro com autores, assuntos e exemplares por unidade, como o Preload dos repositórios GORM
+func (store *Store) preloadedBook(book *entities.Book) *entities.Book {
+	copied := store.book(book)
+	for _, id := range sortedIDs(store.holdings) {
+		if holding := store.holdings[id]; holding.BookID == book.ID {
+			copied.Holdings = append(copied.Holdings, *store.holding(holding))
+		}
+	}
+	return copied
+}
+
+// plainBook copia apenas as colunas do livro, como ele aparece carregado em empréstimos e transferências
+func (store *Store) plainBook(book *entities.Book) entities.Book {
+	copied := *book
+	copied.Authors = nil
+	copied.Subjects = nil
+	copied.Loans = nil
+	copied.Holdings = nil
+	return copied
+}
+
+// holding copia os exemplares da unidade com a unidade carregada, desde que ela não tenha sido removida
+func (store *Store) holding(holding *entities.BookHolding) *entities.BookHolding {
+	copied := *holding
+	copied.Book = entities.Book{}
+	copied.Branch = entities.Branch{}
+	if branch, ok := store.branches[holding.BranchID]; ok && active(branch.Model) {
+		copied.Branch = store.plainBranch(branch)
+	}
+	return &copied
+}
+
+// plainBranch copia apenas as colunas da unidade
+func (store *Store) plainBranch(branch *entities.Branch) entities.Branch {
+	copied := *branch
+	copied.Holdings = nil
+	return copied
+}
+
+// user copia o usuário, sem os registros relacionados
+func (store *Store) user(user *entities.User) *entities.User {
+	copied := *user
+	copied.Branch = nil
+	copied.Loans = nil
+	return &copied
+}
+
+// findHolding busca os exemplares de um livro em uma unidade
+func (store *Store) findHolding(bookID, branchID uint) *entities.BookHolding {
+	for _, holding := range store.holdings {
+		if holding.BookID == bookID && holding.BranchID == branchID {
+			return holding
+		}
+	}
+	return nil
+}
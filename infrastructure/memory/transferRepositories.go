@@ -0,0 +1,183 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/repositories"
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+)
+
+// transferRepository implementa a interface TransferRepository em memória
+type transferRepository struct {
+	store *Store
+}
+
+// NewTransferRepository cria uma nova instância do repositório de transferências em memória
+func NewTransferRepository(store *Store) repositories.TransferRepository {
+	return &transferRepository{
+		store: store,
+	}
+}
+
+// Create cria um novo pedido de transferência
+func (transferRepository *transferRepository) Create(ctx context.Context, transfer *entities.TransferRequest) error {
+	transfer.Status = entities.TransferStatusPending
+
+	return transferRepository.store.write(ctx, func(now time.Time) error {
+		// Mesmas chaves estrangeiras do banco: livro e unidades precisam existir, mesmo que removidos
+		_, bookExists := transferRepository.store.books[transfer.BookID]
+		_, fromExists := transferRepository.store.branches[transfer.FromBranchID]
+		_, toExists := transferRepository.store.branches[transfer.ToBranchID]
+		if !bookExists || !fromExists || !toExists {
+			return errors.New("livro ou unidade da transferência não encontrados")
+		}
+
+		transferRepository.store.create("transfer_requests", &transfer.Model, now)
+		transferRepository.store.transfers[transfer.ID] = transferRepository.plain(transfer)
+		return nil
+	})
+}
+
+// FindByID busca um pedido de transferência pelo seu ID
+func (transferRepository *transferRepository) FindByID(ctx context.Context, id uint) (*entities.TransferRequest, error) {
+	var found *entities.TransferRequest
+	err := transferRepository.store.read(ctx, func() error {
+		if transfer, ok := transferRepository.store.transfers[id]; ok && active(transfer.Model) {
+			found = transferRepository.preloaded(transfer)
+		}
+		return nil
+	})
+	return found, err
+}
+
+// List retorna todos os pedidos de transferência, do mais recente ao mais antigo
+func (transferRepository *transferRepository) List(ctx context.Context) ([]*entities.TransferRequest, error) {
+	return transferRepository.list(ctx, func(transfer *entities.TransferRequest) bool {
+		return true
+	})
+}
+
+// ListByBranch retorna os pedidos de transferência de origem ou destino na unidade
+func (transferRepository *transferRepository) ListByBranch(ctx context.Context, branchID uint) ([]*entities.TransferRequest, error) {
+	return transferRepository.list(ctx, func(transfer *entities.TransferRequest) bool {
+		return transfer.FromBranchID == branchID || transfer.ToBranchID == branchID
+	})
+}
+
+// Complete move os exemplares entre as unidades e conclui o pedido de transferência
+func (transferRepository *transferRepository) Complete(ctx context.Context, id uint, resolvedByID uint) error {
+	return transferRepository.store.write(ctx, func(now time.Time) error {
+		transfer, err := transferRepository.pending(id)
+		if err != nil {
+			return err
+		}
+
+		// Retirar os exemplares da unidade de origem
+		from := transferRepository.store.findHolding(transfer.BookID, transfer.FromBranchID)
+		if from == nil {
+			return errors.New("unidade de origem não possui exemplares do livro")
+		}
+		if from.Available < transfer.Quantity {
+			return errors.New("unidade de origem não possui exemplares disponíveis suficientes")
+		}
+		from.Quantity -= transfer.Quantity
+		from.Available -= transfer.Quantity
+		from.UpdatedAt = now
+
+		// Adicionar os exemplares na unidade de destino
+		to := transferRepository.store.findHolding(transfer.BookID, transfer.ToBranchID)
+		if to == nil {
+			to = &entities.BookHolding{BookID: transfer.BookID, BranchID: transfer.ToBranchID}
+			transferRepository.store.create("book_holdings", &to.Model, now)
+			transferRepository.store.holdings[to.ID] = to
+		}
+		to.Quantity += transfer.Quantity
+		to.Available += transfer.Quantity
+		to.UpdatedAt = now
+
+		transferRepository.resolve(transfer, entities.TransferStatusCompleted, resolvedByID, now)
+		return nil
+	})
+}
+
+// Reject recusa um pedido de transferência pendente
+func (transferRepository *transferRepository) Reject(ctx context.Context, id uint, resolvedByID uint) error {
+	return transferRepository.store.write(ctx, func(now time.Time) error {
+		transfer, err := transferRepository.pending(id)
+		if err != nil {
+			return err
+		}
+
+		transferRepository.resolve(transfer, entities.TransferStatusRejected, resolvedByID, now)
+		return nil
+	})
+}
+
+// pending busca o pedido de transferência que ainda aguarda uma decisão
+func (transferRepository *transferRepository) pending(id uint) (*entities.TransferRequest, error) {
+	transfer, ok := transferRepository.store.transfers[id]
+	if !ok || !active(transfer.Model) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	if transfer.Status != entities.TransferStatusPending {
+		return nil, errors.New("transferência já foi resolvida")
+	}
+	return transfer, nil
+}
+
+// resolve registra o desfecho de um pedido de transferência
+func (transferRepository *transferRepository) resolve(transfer *entities.TransferRequest, status string, resolvedByID uint, now time.Time) {
+	transfer.Status = status
+	transfer.ResolvedByID = &resolvedByID
+	transfer.ResolvedAt = &now
+	transfer.UpdatedAt = now
+}
+
+// list retorna os pedidos aceitos pelo critério, do mais recente ao mais antigo
+func (transferRepository *transferRepository) list(ctx context.Context, accept func(transfer *entities.TransferRequest) bool) ([]*entities.TransferRequest, error) {
+	var transfers []*entities.TransferRequest
+	err := transferRepository.store.read(ctx, func() error {
+		for _, id := range sortedIDs(transferRepository.store.transfers) {
+			if transfer := transferRepository.store.transfers[id]; active(transfer.Model) && accept(transfer) {
+				transfers = append(transfers, transferRepository.preloaded(transfer))
+			}
+		}
+		return nil
+	})
+	sort.SliceStable(transfers, func(i, j int) bool {
+		if !transfers[i].CreatedAt.Equal(transfers[j].CreatedAt) {
+			return transfers[i].CreatedAt.After(transfers[j].CreatedAt)
+		}
+		return transfers[i].ID > transfers[j].ID
+	})
+	return transfers, err
+}
+
+// plain copia apenas as colunas do pedido de transferência
+func (transferRepository *transferRepository) plain(transfer *entities.TransferRequest) *entities.TransferRequest {
+	copied := *transfer
+	copied.Book = entities.Book{}
+	copied.FromBranch = entities.Branch{}
+	copied.ToBranch = entities.Branch{}
+	return &copied
+}
+
+// preloaded copia o pedido de transferência com o livro e as unidades carregados, desde que não removidos
+func (transferRepository *transferRepository) preloaded(transfer *entities.TransferRequest) *entities.TransferRequest {
+	copied := transferRepository.plain(transfer)
+	if book, ok := transferRepository.store.books[transfer.BookID]; ok && active(book.Model) {
+		copied.Book = transferRepository.store.plainBook(book)
+	}
+	if branch, ok := transferRepository.store.branches[transfer.FromBranchID]; ok && active(branch.Model) {
+		copied.FromBranch = transferRepository.store.plainBranch(branch)
+	}
+	if branch, ok := transferRepository.store.branches[transfer.ToBranchID]; ok && active(branch.Model) {
+		copied.ToBranch = transferRepository.store.plainBranch(branch)
+	}
+	return copied
+}
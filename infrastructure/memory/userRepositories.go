@@ -0,0 +1,292 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/repositories"
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+	domainerrors "github.com/henrygoeszanin/api_golang_estudos/domain/errors"
+)
+
+// userRepository implementa a interface UserRepository em memória
+type userRepository struct {
+	store *Store
+}
+
+// NewUserRepository cria uma nova instância do repositório de usuários em memória
+func NewUserRepository(store *Store) repositories.UserRepository {
+	return &userRepository{
+		store: store,
+	}
+}
+
+// Create cria um novo usuário, com o papel informado na entidade
+func (userRepository *userRepository) Create(ctx context.Context, user *entities.User) error {
+	return userRepository.store.write(ctx, func(now time.Time) error {
+		if err := userRepository.checkEmail(user); err != nil {
+			return err
+		}
+
+		userRepository.store.create("users", &user.Model, now)
+		userRepository.store.users[user.ID] = userRepository.store.user(user)
+		return nil
+	})
+}
+
+// FindByID busca um usuário pelo seu ID
+func (userRepository *userRepository) FindByID(ctx context.Context, id uint) (*entities.User, error) {
+	return userRepository.find(ctx, false, func(user *entities.User) bool {
+		return user.ID == id
+	})
+}
+
+// FindByIDWithDeleted busca um usuário pelo seu ID, incluindo os removidos
+func (userRepository *userRepository) FindByIDWithDeleted(ctx context.Context, id uint) (*entities.User, error) {
+	return userRepository.find(ctx, true, func(user *entities.User) bool {
+		return user.ID == id
+	})
+}
+
+// FindByEmail busca um usuário pelo seu email
+func (userRepository *userRepository) FindByEmail(ctx context.Context, email string) (*entities.User, error) {
+	return userRepository.find(ctx, false, func(user *entities.User) bool {
+		return user.Email == email
+	})
+}
+
+// Update atualiza os dados de um usuário
+func (userRepository *userRepository) Update(ctx context.Context, user *entities.User) error {
+	return userRepository.store.write(ctx, func(now time.Time) error {
+		if err := userRepository.checkEmail(user); err != nil {
+			return err
+		}
+		if user.ID == 0 {
+			userRepository.store.create("users", &user.Model, now)
+		}
+		user.UpdatedAt = now
+		userRepository.store.users[user.ID] = userRepository.store.user(user)
+		return nil
+	})
+}
+
+// Delete remove um usuário pelo seu ID
+func (userRepository *userRepository) Delete(ctx context.Context, id uint) error {
+	return userRepository.store.write(ctx, func(now time.Time) error {
+		user, ok := userRepository.store.users[id]
+		if !ok || !active(user.Model) {
+			return errors.New("usuário não encontrado")
+		}
+		user.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+		return nil
+	})
+}
+
+// List retorna todos os usuários
+func (userRepository *userRepository) List(ctx context.Context) ([]*entities.User, error) {
+	var users []*entities.User
+	err := userRepository.store.read(ctx, func() error {
+		for _, id := range sortedIDs(userRepository.store.users) {
+			if user := userRepository.store.users[id]; active(user.Model) {
+				users = append(users, userRepository.store.user(user))
+			}
+		}
+		return nil
+	})
+	return users, err
+}
+
+// PromoteToAdmin promove um usuário para administrador
+func (userRepository *userRepository) PromoteToAdmin(ctx context.Context, id uint) error {
+	return userRepository.store.write(ctx, func(now time.Time) error {
+		user, ok := userRepository.store.users[id]
+		if !ok || !active(user.Model) {
+			return errors.New("usuário não encontrado")
+		}
+		user.IsAdmin = true
+		user.UpdatedAt = now
+		return nil
+	})
+}
+
+// IsFirstUser verifica se este será o primeiro usuário no sistema.
+// Usuários removidos também contam, para que o próximo cadastro não seja considerado o primeiro.
+func (userRepository *userRepository) IsFirstUser(ctx context.Context) (bool, error) {
+	var first bool
+	err := userRepository.store.read(ctx, func() error {
+		first = len(userRepository.store.users) == 0
+		return nil
+	})
+	return first, err
+}
+
+// CountAdmins conta os administradores ativos
+func (userRepository *userRepository) CountAdmins(ctx context.Context) (int64, error) {
+	var count int64
+	err := userRepository.store.read(ctx, func() error {
+		for _, user := range userRepository.store.users {
+			if user.IsAdmin && active(user.Model) {
+				count++
+			}
+		}
+		return nil
+	})
+	return count, err
+}
+
+// ListDeleted retorna os usuários removidos, do mais recente ao mais antigo
+func (userRepository *userRepository) ListDeleted(ctx context.Context) ([]*entities.User, error) {
+	var users []*entities.User
+	err := userRepository.store.read(ctx, func() error {
+		for _, id := range sortedIDs(userRepository.store.users) {
+			if user := userRepository.store.users[id]; !active(user.Model) {
+				users = append(users, userRepository.store.user(user))
+			}
+		}
+		return nil
+	})
+	sort.SliceStable(users, func(i, j int) bool {
+		return users[i].DeletedAt.Time.After(users[j].DeletedAt.Time)
+	})
+	return users, err
+}
+
+// Restore desfaz a remoção de um usuário e cancela a exclusão agendada.
+// Falha se os dados já foram apagados ou se o email passou a ser usado por outro usuário.
+func (userRepository *userRepository) Restore(ctx context.Context, id uint) error {
+	return userRepository.store.write(ctx, func(now time.Time) error {
+		user, ok := userRepository.store.users[id]
+		if !ok || active(user.Model) {
+			return fmt.Errorf("%w: usuário removido não encontrado", domainerrors.ErrNotFound)
+		}
+		if user.ErasedAt != nil {
+			return fmt.Errorf("%w: os dados do usuário já foram apagados", domainerrors.ErrConflict)
+		}
+
+		if existing := userRepository.activeByEmail(user.Email, user.ID); existing != nil {
+			return &domainerrors.DuplicateError{ExistingID: existing.ID, Reason: "email já está em uso por outro usuário"}
+		}
+
+		user.DeletedAt = gorm.DeletedAt{}
+		user.ErasureScheduledAt = nil
+		user.UpdatedAt = now
+		return nil
+	})
+}
+
+// ScheduleErasure remove o usuário e agenda o apagamento dos dados pessoais para a data informada.
+// Usuários com empréstimos em aberto não podem ser removidos.
+func (userRepository *userRepository) ScheduleErasure(ctx context.Context, id uint, eraseAt time.Time) error {
+	return userRepository.store.write(ctx, func(now time.Time) error {
+		user, ok := userRepository.store.users[id]
+		if !ok || !active(user.Model) {
+			return errors.New("usuário não encontrado")
+		}
+
+		if err := userRepository.refuseOpenLoans(id); err != nil {
+			return err
+		}
+
+		user.ErasureScheduledAt = &eraseAt
+		user.UpdatedAt = now
+		user.DeletedAt = gorm.DeletedAt{Time: now, Valid: true}
+		return nil
+	})
+}
+
+// Erase apaga os dados pessoais do usuário, removido ou não, mantendo o registro anonimizado
+// para que o histórico de empréstimos continue nas estatísticas.
+// Usuários com empréstimos em aberto não podem ser apagados.
+func (userRepository *userRepository) Erase(ctx context.Context, id uint, erasedAt time.Time) error {
+	return userRepository.store.write(ctx, func(now time.Time) error {
+		user, ok := userRepository.store.users[id]
+		if !ok {
+			return fmt.Errorf("%w: usuário não encontrado", domainerrors.ErrNotFound)
+		}
+		if user.ErasedAt != nil {
+			return fmt.Errorf("%w: os dados do usuário já foram apagados", domainerrors.ErrConflict)
+		}
+
+		if err := userRepository.refuseOpenLoans(id); err != nil {
+			return err
+		}
+
+		user.Anonymize(erasedAt)
+		if !user.DeletedAt.Valid {
+			user.DeletedAt = gorm.DeletedAt{Time: erasedAt, Valid: true}
+		}
+		user.UpdatedAt = now
+		return nil
+	})
+}
+
+// ListErasureDue retorna os IDs dos usuários cujo prazo para desistir da exclusão já terminou
+func (userRepository *userRepository) ListErasureDue(ctx context.Context, now time.Time) ([]uint, error) {
+	var ids []uint
+	err := userRepository.store.read(ctx, func() error {
+		for _, id := range sortedIDs(userRepository.store.users) {
+			user := userRepository.store.users[id]
+			if user.ErasureScheduledAt != nil && !user.ErasureScheduledAt.After(now) && user.ErasedAt == nil {
+				ids = append(ids, id)
+			}
+		}
+		return nil
+	})
+	return ids, err
+}
+
+// find busca o primeiro usuário aceito pelo critério, incluindo os removidos se withDeleted for verdadeiro
+func (userRepository *userRepository) find(ctx context.Context, withDeleted bool, accept func(user *entities.User) bool) (*entities.User, error) {
+	var found *entities.User
+	err := userRepository.store.read(ctx, func() error {
+		for _, id := range sortedIDs(userRepository.store.users) {
+			user := userRepository.store.users[id]
+			if (withDeleted || active(user.Model)) && accept(user) {
+				found = userRepository.store.user(user)
+				return nil
+			}
+		}
+		return nil
+	})
+	return found, err
+}
+
+// checkEmail garante que nenhum outro usuário ativo usa o email, a mesma regra do índice único do banco
+func (userRepository *userRepository) checkEmail(user *entities.User) error {
+	if !active(user.Model) {
+		return nil
+	}
+	if existing := userRepository.activeByEmail(user.Email, user.ID); existing != nil {
+		return fmt.Errorf("%w: email já está em uso por outro usuário", domainerrors.ErrAlreadyExists)
+	}
+	return nil
+}
+
+// activeByEmail busca o usuário ativo com o email informado, ignorando o usuário de ID exceptID
+func (userRepository *userRepository) activeByEmail(email string, exceptID uint) *entities.User {
+	for _, user := range userRepository.store.users {
+		if user.ID != exceptID && active(user.Model) && user.Email == email {
+			return user
+		}
+	}
+	return nil
+}
+
+// refuseOpenLoans retorna erro de conflito se o usuário tiver empréstimos em aberto
+func (userRepository *userRepository) refuseOpenLoans(userID uint) error {
+	openLoans := 0
+	for _, loan := range userRepository.store.loans {
+		if active(loan.Model) && loan.UserID == userID && !loan.IsReturned {
+			openLoans++
+		}
+	}
+	if openLoans > 0 {
+		return fmt.Errorf("%w: usuário possui %d empréstimo(s) em aberto", domainerrors.ErrConflict, openLoans)
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/services"
+)
+
+// collectTimeout limita o tempo das consultas feitas a cada coleta do Prometheus
+const collectTimeout = 5 * time.Second
+
+// libraryCollector expõe os indicadores da biblioteca, calculados no banco a cada coleta
+type libraryCollector struct {
+	statsService   services.LibraryStatsService
+	activeLoans    *prometheus.Desc
+	overdueLoans   *prometheus.Desc
+	booksAvailable *prometheus.Desc
+}
+
+// NewLibraryCollector cria o coletor dos indicadores de empréstimos e acervo
+func NewLibraryCollector(statsService services.LibraryStatsService) prometheus.Collector {
+	return &libraryCollector{
+		statsService: statsService,
+		activeLoans: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "loans_active"),
+			"Empréstimos ainda não devolvidos.", nil, nil),
+		overdueLoans: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "loans_overdue"),
+			"Empréstimos não devolvidos com a data prevista de devolução vencida.", nil, nil),
+		booksAvailable: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "books_available"),
+			"Exemplares disponíveis para empréstimo nos livros em circulação.", nil, nil),
+	}
+}
+
+// Describe envia a descrição das métricas do coletor
+func (collector *libraryCollector) Describe(descs chan<- *prometheus.Desc) {
+	descs <- collector.activeLoans
+	descs <- collector.overdueLoans
+	descs <- collector.booksAvailable
+}
+
+// Collect consulta os indicadores atuais; em caso de falha, as métricas ficam de fora desta coleta
+func (collector *libraryCollector) Collect(metrics chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	stats, err := collector.statsService.Collect(ctx)
+	if err != nil {
+		slog.Error("falha ao calcular os indicadores da biblioteca", "error", err)
+		metrics <- prometheus.NewInvalidMetric(collector.activeLoans, err)
+		return
+	}
+
+	metrics <- prometheus.MustNewConstMetric(collector.activeLoans, prometheus.GaugeValue, float64(stats.ActiveLoans))
+	metrics <- prometheus.MustNewConstMetric(collector.overdueLoans, prometheus.GaugeValue, float64(stats.OverdueLoans))
+	metrics <- prometheus.MustNewConstMetric(collector.booksAvailable, prometheus.GaugeValue, float64(stats.BooksAvailable))
+}
@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MemoryRecorder guarda as métricas em memória, permitindo que testes confiram os incrementos
+type MemoryRecorder struct {
+	mu            sync.Mutex
+	httpRequests  map[string]int
+	dbQueries     map[string]int
+	dbErrors      int
+	loginSuccess  int
+	loginFailures int
+}
+
+// NewMemoryRecorder cria um coletor de métricas em memória
+func NewMemoryRecorder() *MemoryRecorder {
+	return &MemoryRecorder{
+		httpRequests: map[string]int{},
+		dbQueries:    map[string]int{},
+	}
+}
+
+// ObserveHTTPRequest conta a requisição pela chave "MÉTODO rota status"
+func (recorder *MemoryRecorder) ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	recorder.httpRequests[httpKey(method, route, status)]++
+}
+
+// ObserveDBQuery conta a consulta pela chave "operação tabela"
+func (recorder *MemoryRecorder) ObserveDBQuery(operation, table string, duration time.Duration, err error) {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	recorder.dbQueries[operation+" "+table]++
+	if err != nil {
+		recorder.dbErrors++
+	}
+}
+
+// LoginSucceeded conta um login bem-sucedido
+func (recorder *MemoryRecorder) LoginSucceeded() {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	recorder.loginSuccess++
+}
+
+// LoginFailed conta uma tentativa de login recusada
+func (recorder *MemoryRecorder) LoginFailed() {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	recorder.loginFailures++
+}
+
+// HTTPRequests retorna quantas requisições foram registradas para o método, a rota e o status
+func (recorder *MemoryRecorder) HTTPRequests(method, route string, status int) int {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	return recorder.httpRequests[httpKey(method, route, status)]
+}
+
+// DBQueries retorna quantas consultas foram registradas para a operação e a tabela
+func (recorder *MemoryRecorder) DBQueries(operation, table string) int {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	return recorder.dbQueries[operation+" "+table]
+}
+
+// DBErrors retorna quantas consultas terminaram em erro
+func (recorder *MemoryRecorder) DBErrors() int {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	return recorder.dbErrors
+}
+
+// Logins retorna a quantidade de logins bem-sucedidos e recusados
+func (recorder *MemoryRecorder) Logins() (succeeded, failed int) {
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	return recorder.loginSuccess, recorder.loginFailures
+}
+
+// httpKey monta a chave de contagem das requisições
+func httpKey(method, route string, status int) string {
+	return method + " " + route + " " + strconv.Itoa(status)
+}
@@ -0,0 +1,90 @@
+// Package metrics implementa a coleta de métricas da aplicação no formato do Prometheus
+package metrics
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/metrics"
+)
+
+// namespace prefixa o nome de todas as métricas da aplicação
+const namespace = "library"
+
+// prometheusRecorder implementa a interface Recorder com métricas do Prometheus
+type prometheusRecorder struct {
+	httpRequests  *prometheus.CounterVec
+	httpDuration  *prometheus.HistogramVec
+	dbQueries     *prometheus.HistogramVec
+	dbQueryErrors *prometheus.CounterVec
+	loginAttempts *prometheus.CounterVec
+}
+
+// NewPrometheusRecorder cria o coletor de métricas e registra as métricas no registry informado
+func NewPrometheusRecorder(registry prometheus.Registerer) metrics.Recorder {
+	recorder := &prometheusRecorder{
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Requisições HTTP atendidas, por método, rota e status.",
+		}, []string{"method", "route", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Duração das requisições HTTP, por método, rota e status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		dbQueries: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "Duração das consultas ao banco, por operação e tabela.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+		}, []string{"operation", "table"}),
+		dbQueryErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "db_query_errors_total",
+			Help:      "Consultas ao banco que terminaram em erro, por operação e tabela.",
+		}, []string{"operation", "table"}),
+		loginAttempts: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "auth_logins_total",
+			Help:      "Tentativas de login, por resultado (success ou failure).",
+		}, []string{"result"}),
+	}
+
+	registry.MustRegister(
+		recorder.httpRequests,
+		recorder.httpDuration,
+		recorder.dbQueries,
+		recorder.dbQueryErrors,
+		recorder.loginAttempts,
+	)
+	return recorder
+}
+
+// ObserveHTTPRequest registra uma requisição HTTP concluída
+func (recorder *prometheusRecorder) ObserveHTTPRequest(method, route string, status int, duration time.Duration) {
+	statusLabel := strconv.Itoa(status)
+	recorder.httpRequests.WithLabelValues(method, route, statusLabel).Inc()
+	recorder.httpDuration.WithLabelValues(method, route, statusLabel).Observe(duration.Seconds())
+}
+
+// ObserveDBQuery registra uma consulta ao banco concluída
+func (recorder *prometheusRecorder) ObserveDBQuery(operation, table string, duration time.Duration, err error) {
+	recorder.dbQueries.WithLabelValues(operation, table).Observe(duration.Seconds())
+	if err != nil {
+		recorder.dbQueryErrors.WithLabelValues(operation, table).Inc()
+	}
+}
+
+// LoginSucceeded registra um login bem-sucedido
+func (recorder *prometheusRecorder) LoginSucceeded() {
+	recorder.loginAttempts.WithLabelValues("success").Inc()
+}
+
+// LoginFailed registra uma tentativa de login recusada
+func (recorder *prometheusRecorder) LoginFailed() {
+	recorder.loginAttempts.WithLabelValues("failure").Inc()
+}
@@ -0,0 +1,62 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/notifications"
+)
+
+// fileSender acrescenta cada mensagem, em JSON, a um arquivo local; usado em testes para conferir
+// o que teria sido enviado
+type fileSender struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// fileMessage é a linha gravada no arquivo para cada mensagem
+type fileMessage struct {
+	SentAt  time.Time `json:"sent_at"`
+	Channel string    `json:"channel"`
+	UserID  uint      `json:"user_id"`
+	To      string    `json:"to"`
+	Subject string    `json:"subject"`
+	Body    string    `json:"body"`
+}
+
+// NewFileSender cria o envio para o arquivo informado, no formato JSON Lines
+func NewFileSender(path string) notifications.Sender {
+	return &fileSender{path: path}
+}
+
+// Send acrescenta a mensagem ao arquivo
+func (sender *fileSender) Send(ctx context.Context, message notifications.Message) error {
+	line, err := json.Marshal(fileMessage{
+		SentAt:  time.Now().UTC(),
+		Channel: message.Channel,
+		UserID:  message.UserID,
+		To:      message.To,
+		Subject: message.Subject,
+		Body:    message.Body,
+	})
+	if err != nil {
+		return err
+	}
+
+	sender.mutex.Lock()
+	defer sender.mutex.Unlock()
+
+	file, err := os.OpenFile(sender.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("falha ao abrir o arquivo de notificações: %w", err)
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return fmt.Errorf("falha ao gravar a notificação: %w", err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,23 @@
+// Package notifications reúne as formas de entrega das notificações por canais externos
+package notifications
+
+import (
+	"context"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/notifications"
+	"github.com/henrygoeszanin/api_golang_estudos/logging"
+)
+
+// logSender registra as mensagens no log em vez de entregá-las, para desenvolvimento
+type logSender struct{}
+
+// NewLogSender cria o envio pelo log. O destinatário não é registrado, apenas o ID do usuário.
+func NewLogSender() notifications.Sender {
+	return &logSender{}
+}
+
+// Send registra a mensagem no log
+func (sender *logSender) Send(ctx context.Context, message notifications.Message) error {
+	logging.FromContext(ctx).Info("notificação enviada", "channel", message.Channel, "user_id", message.UserID, "subject", message.Subject)
+	return nil
+}
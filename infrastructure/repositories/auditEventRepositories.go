@@ -0,0 +1,127 @@
+package repositories
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/repositories"
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+	"gorm.io/gorm"
+)
+
+// auditEventRepository implementa a interface AuditEventRepository
+type auditEventRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditEventRepository cria uma nova instância do repositório da trilha de auditoria
+func NewAuditEventRepository(db *gorm.DB) repositories.AuditEventRepository {
+	return &auditEventRepository{
+		db: db,
+	}
+}
+
+// Append acrescenta um evento ao fim da cadeia de auditoria.
+// O último elo é bloqueado durante a gravação, de modo que eventos simultâneos
+// sejam encadeados um após o outro.
+func (auditEventRepository *auditEventRepository) Append(ctx context.Context, event *entities.AuditEvent) error {
+	tx := auditEventRepository.db.WithContext(ctx).Begin()
+	if tx.Error != nil {
+		return tx.Error
+	}
+
+	var head entities.AuditChainHead
+	if err := forUpdate(tx).
+		FirstOrCreate(&head, entities.AuditChainHead{ID: 1}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// O banco guarda microssegundos; o hash precisa ser calculado sobre o valor que será lido depois
+	event.CreatedAt = time.Now().UTC().Truncate(time.Microsecond)
+	event.PrevHash = head.LastHash
+	event.Hash = event.ComputeHash()
+
+	if err := tx.Create(event).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	head.LastEventID = event.ID
+	head.LastHash = event.Hash
+	if err := tx.Save(&head).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// List retorna uma página de eventos, do mais recente ao mais antigo, e o total de eventos do filtro
+func (auditEventRepository *auditEventRepository) List(ctx context.Context, filter repositories.AuditEventFilter, offset, limit int) ([]*entities.AuditEvent, int64, error) {
+	query := auditEventRepository.db.WithContext(ctx).Model(&entities.AuditEvent{})
+
+	if filter.ActorID != 0 {
+		query = query.Where("actor_id = ?", filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at <= ?", *filter.To)
+	}
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var events []*entities.AuditEvent
+	result := query.Order("id DESC").Offset(offset).Limit(limit).Find(&events)
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+	return events, total, nil
+}
+
+// ListByUser retorna os eventos realizados pelo usuário ou que tiveram o usuário como alvo
+func (auditEventRepository *auditEventRepository) ListByUser(ctx context.Context, userID uint) ([]*entities.AuditEvent, error) {
+	var events []*entities.AuditEvent
+	result := auditEventRepository.db.WithContext(ctx).
+		Where("actor_id = ? OR (target_type = ? AND target_id = ?)", userID, "user", strconv.FormatUint(uint64(userID), 10)).
+		Order("id").
+		Find(&events)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return events, nil
+}
+
+// Walk percorre toda a cadeia, na ordem de gravação, em lotes do tamanho informado
+func (auditEventRepository *auditEventRepository) Walk(ctx context.Context, batchSize int, process func(events []*entities.AuditEvent) error) error {
+	var batch []*entities.AuditEvent
+	result := auditEventRepository.db.WithContext(ctx).Order("id").FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+		return process(batch)
+	})
+	return result.Error
+}
+
+// Head retorna o último elo registrado da cadeia
+func (auditEventRepository *auditEventRepository) Head(ctx context.Context) (*entities.AuditChainHead, error) {
+	var head entities.AuditChainHead
+	result := auditEventRepository.db.WithContext(ctx).Limit(1).Find(&head, 1)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return &head, nil
+}
@@ -1,11 +1,17 @@
 package repositories
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/repositories"
 	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+	domainerrors "github.com/henrygoeszanin/api_golang_estudos/domain/errors"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/plugin/dbresolver"
 )
 
 // bookRepository implementa a interface BookRepository
@@ -21,18 +27,38 @@ func NewBookRepository(db *gorm.DB) repositories.BookRepository {
 }
 
 // Create cria um novo livro no banco de dados
-func (bookRepository *bookRepository) Create(book *entities.Book) error {
+func (bookRepository *bookRepository) Create(ctx context.Context, book *entities.Book) error {
 	// Garantir que disponível = quantidade inicialmente
 	book.Available = book.Quantity
 
-	result := bookRepository.db.Create(book)
-	return result.Error
+	// Iniciar transação
+	tx := bookRepository.db.WithContext(ctx).Begin()
+
+	if err := bookRepository.create(tx, book, newAssociationCache()); err != nil {
+		tx.Rollback()
+		return bookRepository.duplicateISBN(ctx, []*entities.Book{book}, err)
+	}
+
+	return tx.Commit().Error
 }
 
 // FindByID busca um livro pelo seu ID
-func (bookRepository *bookRepository) FindByID(id uint) (*entities.Book, error) {
+func (bookRepository *bookRepository) FindByID(ctx context.Context, id uint) (*entities.Book, error) {
+	var book entities.Book
+	result := bookRepository.preloaded(ctx).First(&book, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil // Livro não encontrado
+		}
+		return nil, result.Error
+	}
+	return &book, nil
+}
+
+// FindByISBN busca um livro pelo ISBN-13
+func (bookRepository *bookRepository) FindByISBN(ctx context.Context, isbn string) (*entities.Book, error) {
 	var book entities.Book
-	result := bookRepository.db.First(&book, id)
+	result := bookRepository.preloaded(ctx).Where("isbn = ?", isbn).First(&book)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, nil // Livro não encontrado
@@ -42,30 +68,449 @@ func (bookRepository *bookRepository) FindByID(id uint) (*entities.Book, error)
 	return &book, nil
 }
 
-// List retorna todos os livros
-func (bookRepository *bookRepository) List() ([]*entities.Book, error) {
+// FindByDedupKey busca os livros com o mesmo título e autores normalizados
+func (bookRepository *bookRepository) FindByDedupKey(ctx context.Context, key string) ([]*entities.Book, error) {
 	var books []*entities.Book
-	result := bookRepository.db.Find(&books)
+	result := bookRepository.preloaded(ctx).Where("dedup_key = ?", key).Order("id").Find(&books)
 	if result.Error != nil {
 		return nil, result.Error
 	}
 	return books, nil
 }
 
-// Update atualiza os dados de um livro
-func (bookRepository *bookRepository) Update(book *entities.Book) error {
-	result := bookRepository.db.Save(book)
+// FindByISBNs busca os livros com qualquer um dos ISBN-13 informados
+func (bookRepository *bookRepository) FindByISBNs(ctx context.Context, isbns []string) ([]*entities.Book, error) {
+	var books []*entities.Book
+	if len(isbns) == 0 {
+		return books, nil
+	}
+	result := bookRepository.preloaded(ctx).Where("isbn IN ?", isbns).Find(&books)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return books, nil
+}
+
+// FindByDedupKeys busca os livros com qualquer uma das chaves de detecção de repetidos
+func (bookRepository *bookRepository) FindByDedupKeys(ctx context.Context, keys []string) ([]*entities.Book, error) {
+	var books []*entities.Book
+	if len(keys) == 0 {
+		return books, nil
+	}
+	result := bookRepository.db.WithContext(ctx).Where("dedup_key IN ?", keys).Order("id").Find(&books)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return books, nil
+}
+
+// List retorna os livros que atendem ao filtro
+func (bookRepository *bookRepository) List(ctx context.Context, filter repositories.BookFilter) ([]*entities.Book, error) {
+	var books []*entities.Book
+	result := bookRepository.filtered(bookRepository.preloaded(ctx), filter).Order("books.id").Find(&books)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return books, nil
+}
+
+// ListInBatches percorre os livros que atendem ao filtro em lotes ordenados pelo ID,
+// sem carregar todos os registros em memória
+func (bookRepository *bookRepository) ListInBatches(ctx context.Context, filter repositories.BookFilter, batchSize int, process func(books []*entities.Book) error) error {
+	var books []*entities.Book
+	result := bookRepository.filtered(bookRepository.preloaded(ctx), filter).FindInBatches(&books, batchSize, func(tx *gorm.DB, batch int) error {
+		return process(books)
+	})
 	return result.Error
 }
 
-// Delete remove um livro pelo seu ID
-func (bookRepository *bookRepository) Delete(id uint) error {
-	result := bookRepository.db.Delete(&entities.Book{}, id)
+// Update atualiza os dados de um livro
+func (bookRepository *bookRepository) Update(ctx context.Context, book *entities.Book) error {
+	// Iniciar transação
+	tx := bookRepository.db.WithContext(ctx).Begin()
+
+	if err := bookRepository.update(tx, book, newAssociationCache()); err != nil {
+		tx.Rollback()
+		return bookRepository.duplicateISBN(ctx, []*entities.Book{book}, err)
+	}
+
+	return tx.Commit().Error
+}
+
+// SaveBatch cria e atualiza um lote de livros em uma única transação
+func (bookRepository *bookRepository) SaveBatch(ctx context.Context, creates []*entities.Book, updates []*entities.Book) error {
+	// Iniciar transação
+	tx := bookRepository.db.WithContext(ctx).Begin()
+
+	cache := newAssociationCache()
+	for _, book := range creates {
+		book.Available = book.Quantity
+		if err := bookRepository.create(tx, book, cache); err != nil {
+			tx.Rollback()
+			return bookRepository.duplicateISBN(ctx, []*entities.Book{book}, err)
+		}
+	}
+	for _, book := range updates {
+		if err := bookRepository.update(tx, book, cache); err != nil {
+			tx.Rollback()
+			return bookRepository.duplicateISBN(ctx, []*entities.Book{book}, err)
+		}
+	}
+
+	return tx.Commit().Error
+}
+
+// AddCopies adiciona exemplares ao livro, todos disponíveis e sem unidade definida
+func (bookRepository *bookRepository) AddCopies(ctx context.Context, id uint, quantity int) error {
+	result := bookRepository.db.WithContext(ctx).Model(&entities.Book{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"quantity":  gorm.Expr("quantity + ?", quantity),
+		"available": gorm.Expr("available + ?", quantity),
+	})
 	if result.Error != nil {
 		return result.Error
 	}
 	if result.RowsAffected == 0 {
-		return errors.New("livro não encontrado")
+		return fmt.Errorf("%w: livro não encontrado", domainerrors.ErrNotFound)
 	}
 	return nil
 }
+
+// Delete remove um livro pelo seu ID.
+// Livros com empréstimos em aberto não podem ser removidos.
+func (bookRepository *bookRepository) Delete(ctx context.Context, id uint) error {
+	// Iniciar transação
+	tx := bookRepository.db.WithContext(ctx).Begin()
+
+	// Bloquear o livro para que nenhum empréstimo seja criado durante a remoção
+	var book entities.Book
+	if err := forUpdate(tx).First(&book, id).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: livro não encontrado", domainerrors.ErrNotFound)
+		}
+		return err
+	}
+
+	var openLoans int64
+	if err := tx.Model(&entities.Loan{}).Where("book_id = ? AND is_returned = ?", id, false).Count(&openLoans).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if openLoans > 0 {
+		tx.Rollback()
+		return fmt.Errorf("%w: livro possui %d empréstimo(s) em aberto", domainerrors.ErrConflict, openLoans)
+	}
+
+	if err := tx.Delete(&book).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// ListDeleted retorna os livros removidos, do mais recente ao mais antigo
+func (bookRepository *bookRepository) ListDeleted(ctx context.Context) ([]*entities.Book, error) {
+	var books []*entities.Book
+	result := bookRepository.preloaded(ctx).Unscoped().Where("books.deleted_at IS NOT NULL").Order("books.deleted_at DESC").Find(&books)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return books, nil
+}
+
+// SumAvailable soma os exemplares disponíveis dos livros em circulação
+func (bookRepository *bookRepository) SumAvailable(ctx context.Context) (int64, error) {
+	var total int64
+	result := bookRepository.db.WithContext(ctx).Model(&entities.Book{}).
+		Where("status = ?", entities.BookStatusActive).
+		Select("COALESCE(SUM(available), 0)").
+		Scan(&total)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return total, nil
+}
+
+// Restore desfaz a remoção de um livro.
+// Falha se outro livro passou a usar o mesmo ISBN depois da remoção.
+func (bookRepository *bookRepository) Restore(ctx context.Context, id uint) error {
+	// Iniciar transação
+	tx := bookRepository.db.WithContext(ctx).Begin()
+
+	var book entities.Book
+	if err := tx.Unscoped().Where("deleted_at IS NOT NULL").First(&book, id).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: livro removido não encontrado", domainerrors.ErrNotFound)
+		}
+		return err
+	}
+
+	if book.ISBN != nil {
+		var existing entities.Book
+		err := tx.Where("isbn = ?", *book.ISBN).First(&existing).Error
+		if err == nil {
+			tx.Rollback()
+			return &domainerrors.DuplicateError{ExistingID: existing.ID, Reason: "ISBN já cadastrado em outro livro"}
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	if err := tx.Unscoped().Model(&book).Update("deleted_at", nil).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// ReconcileInventory compara os contadores de exemplares disponíveis dos livros e das unidades com os
+// empréstimos em aberto e retorna os que não conferem. Com repair, grava os valores calculados na mesma
+// transação, com livros e exemplares bloqueados para que nenhum empréstimo altere os contadores no meio.
+func (bookRepository *bookRepository) ReconcileInventory(ctx context.Context, repair bool) ([]repositories.InventoryDiscrepancy, error) {
+	// Iniciar transação
+	tx := bookRepository.db.WithContext(ctx).Begin()
+
+	lock := func(query *gorm.DB) *gorm.DB {
+		if repair {
+			return forUpdate(query)
+		}
+		return query
+	}
+
+	var books []entities.Book
+	if err := lock(tx.Select("id", "title", "quantity", "available")).Order("id").Find(&books).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	var holdings []entities.BookHolding
+	if err := lock(tx.Select("id", "book_id", "branch_id", "quantity", "available")).Order("book_id, branch_id").Find(&holdings).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Empréstimos em aberto por livro e unidade de retirada
+	var openLoans []struct {
+		BookID   uint
+		BranchID *uint
+		Total    int
+	}
+	if err := tx.Model(&entities.Loan{}).Select("book_id, branch_id, COUNT(*) AS total").
+		Where("is_returned = ?", false).Group("book_id, branch_id").Scan(&openLoans).Error; err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	openByBook := make(map[uint]int)
+	openByHolding := make(map[[2]uint]int)
+	for _, open := range openLoans {
+		openByBook[open.BookID] += open.Total
+		if open.BranchID != nil {
+			openByHolding[[2]uint{open.BookID, *open.BranchID}] += open.Total
+		}
+	}
+
+	holdingsByBook := make(map[uint][]entities.BookHolding)
+	for _, holding := range holdings {
+		holdingsByBook[holding.BookID] = append(holdingsByBook[holding.BookID], holding)
+	}
+
+	var discrepancies []repositories.InventoryDiscrepancy
+	for _, book := range books {
+		expected := entities.ExpectedAvailable(book.Quantity, openByBook[book.ID])
+		if book.Available != expected {
+			discrepancies = append(discrepancies, repositories.InventoryDiscrepancy{
+				BookID:    book.ID,
+				Title:     book.Title,
+				Quantity:  book.Quantity,
+				Available: book.Available,
+				OpenLoans: openByBook[book.ID],
+				Expected:  expected,
+			})
+			if repair {
+				if err := tx.Model(&entities.Book{}).Where("id = ?", book.ID).Update("available", expected).Error; err != nil {
+					tx.Rollback()
+					return nil, err
+				}
+			}
+		}
+
+		for _, holding := range holdingsByBook[book.ID] {
+			branchID := holding.BranchID
+			open := openByHolding[[2]uint{book.ID, branchID}]
+			expected := entities.ExpectedAvailable(holding.Quantity, open)
+			if holding.Available == expected {
+				continue
+			}
+			discrepancies = append(discrepancies, repositories.InventoryDiscrepancy{
+				BookID:    book.ID,
+				Title:     book.Title,
+				BranchID:  &branchID,
+				Quantity:  holding.Quantity,
+				Available: holding.Available,
+				OpenLoans: open,
+				Expected:  expected,
+			})
+			if repair {
+				if err := tx.Model(&entities.BookHolding{}).Where("id = ?", holding.ID).Update("available", expected).Error; err != nil {
+					tx.Rollback()
+					return nil, err
+				}
+			}
+		}
+	}
+
+	if !repair {
+		// Apenas leitura: nada a gravar
+		tx.Rollback()
+		return discrepancies, nil
+	}
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+	return discrepancies, nil
+}
+
+// preloaded retorna uma consulta de livros com autores, assuntos e unidades carregados
+func (bookRepository *bookRepository) preloaded(ctx context.Context) *gorm.DB {
+	return bookRepository.db.WithContext(ctx).Preload("Authors").Preload("Subjects").Preload("Holdings.Branch")
+}
+
+// filtered aplica os critérios do filtro à consulta de livros
+func (bookRepository *bookRepository) filtered(query *gorm.DB, filter repositories.BookFilter) *gorm.DB {
+	if filter.Title != "" {
+		query = query.Where("LOWER(books.title) LIKE ?", likePattern(filter.Title))
+	}
+	if filter.Author != "" {
+		query = query.Where(
+			"EXISTS (SELECT 1 FROM book_authors JOIN authors ON authors.id = book_authors.author_id "+
+				"WHERE book_authors.book_id = books.id AND LOWER(authors.name) LIKE ?)",
+			likePattern(filter.Author),
+		)
+	}
+	if filter.Subject != "" {
+		query = query.Where(
+			"EXISTS (SELECT 1 FROM book_subjects JOIN subjects ON subjects.id = book_subjects.subject_id "+
+				"WHERE book_subjects.book_id = books.id AND LOWER(subjects.name) LIKE ?)",
+			likePattern(filter.Subject),
+		)
+	}
+	if filter.ISBN != "" {
+		query = query.Where("books.isbn = ?", filter.ISBN)
+	}
+	if filter.Publisher != "" {
+		query = query.Where("LOWER(books.publisher) LIKE ?", likePattern(filter.Publisher))
+	}
+	if filter.Language != "" {
+		query = query.Where("LOWER(books.language) = ?", strings.ToLower(filter.Language))
+	}
+	if filter.YearFrom > 0 {
+		query = query.Where("books.publication_year >= ?", filter.YearFrom)
+	}
+	if filter.YearTo > 0 {
+		query = query.Where("books.publication_year <= ?", filter.YearTo)
+	}
+	if filter.AvailableOnly {
+		query = query.Where("books.available > 0")
+	}
+	if filter.Status != "" {
+		query = query.Where("books.status = ?", filter.Status)
+	}
+	return query
+}
+
+// create insere o livro dentro da transação informada
+func (bookRepository *bookRepository) create(tx *gorm.DB, book *entities.Book, cache *associationCache) error {
+	// Reaproveitar autores e assuntos já cadastrados
+	if err := cache.resolve(tx, book); err != nil {
+		return err
+	}
+	return tx.Create(book).Error
+}
+
+// duplicateISBN converte a recusa do índice único de ISBN, causada por um cadastro concorrente que passou
+// pela verificação do serviço, no erro de livro repetido com o ID do livro que ficou com o ISBN.
+// A busca vai ao banco principal, pois a réplica pode ainda não ter o livro recém-gravado.
+func (bookRepository *bookRepository) duplicateISBN(ctx context.Context, books []*entities.Book, err error) error {
+	if !isUniqueViolation(bookRepository.db, err) {
+		return err
+	}
+
+	for _, book := range books {
+		if book.ISBN == nil {
+			continue
+		}
+		var existing entities.Book
+		if bookRepository.db.WithContext(ctx).Clauses(dbresolver.Write).
+			Where("isbn = ? AND id <> ?", *book.ISBN, book.ID).First(&existing).Error == nil {
+			return &domainerrors.DuplicateError{ExistingID: existing.ID, Reason: "ISBN já cadastrado em outro livro"}
+		}
+	}
+	return err
+}
+
+// update grava o livro e substitui autores e assuntos dentro da transação informada
+func (bookRepository *bookRepository) update(tx *gorm.DB, book *entities.Book, cache *associationCache) error {
+	if err := cache.resolve(tx, book); err != nil {
+		return err
+	}
+
+	// Os exemplares por unidade são mantidos pelo repositório de unidades
+	if err := tx.Omit(clause.Associations).Save(book).Error; err != nil {
+		return err
+	}
+
+	if err := tx.Model(book).Association("Authors").Replace(book.Authors); err != nil {
+		return err
+	}
+	return tx.Model(book).Association("Subjects").Replace(book.Subjects)
+}
+
+// associationCache guarda autores e assuntos já resolvidos, evitando consultas repetidas em lotes
+type associationCache struct {
+	authors  map[string]entities.Author
+	subjects map[string]entities.Subject
+}
+
+// newAssociationCache cria um cache vazio de autores e assuntos
+func newAssociationCache() *associationCache {
+	return &associationCache{
+		authors:  make(map[string]entities.Author),
+		subjects: make(map[string]entities.Subject),
+	}
+}
+
+// resolve substitui autores e assuntos pelo registro existente de mesmo nome, criando os que faltam
+func (cache *associationCache) resolve(tx *gorm.DB, book *entities.Book) error {
+	for i := range book.Authors {
+		name := book.Authors[i].Name
+		if author, ok := cache.authors[name]; ok {
+			book.Authors[i] = author
+			continue
+		}
+		if err := tx.Where(entities.Author{Name: name}).FirstOrCreate(&book.Authors[i]).Error; err != nil {
+			return err
+		}
+		cache.authors[name] = book.Authors[i]
+	}
+	for i := range book.Subjects {
+		name := book.Subjects[i].Name
+		if subject, ok := cache.subjects[name]; ok {
+			book.Subjects[i] = subject
+			continue
+		}
+		if err := tx.Where(entities.Subject{Name: name}).FirstOrCreate(&book.Subjects[i]).Error; err != nil {
+			return err
+		}
+		cache.subjects[name] = book.Subjects[i]
+	}
+	return nil
+}
+
+// likePattern monta o padrão de busca parcial sem diferenciar maiúsculas
+func likePattern(value string) string {
+	return "%" + strings.ToLower(strings.TrimSpace(value)) + "%"
+}
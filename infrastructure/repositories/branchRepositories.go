@@ -0,0 +1,167 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/repositories"
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// branchRepository implementa a interface BranchRepository
+type branchRepository struct {
+	db *gorm.DB
+}
+
+// NewBranchRepository cria uma nova instância do repositório de unidades
+func NewBranchRepository(db *gorm.DB) repositories.BranchRepository {
+	return &branchRepository{
+		db: db,
+	}
+}
+
+// Create cria uma nova unidade no banco de dados
+func (branchRepository *branchRepository) Create(ctx context.Context, branch *entities.Branch) error {
+	result := branchRepository.db.WithContext(ctx).Create(branch)
+	return result.Error
+}
+
+// FindByID busca uma unidade pelo seu ID
+func (branchRepository *branchRepository) FindByID(ctx context.Context, id uint) (*entities.Branch, error) {
+	var branch entities.Branch
+	result := branchRepository.db.WithContext(ctx).First(&branch, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil // Unidade não encontrada
+		}
+		return nil, result.Error
+	}
+	return &branch, nil
+}
+
+// List retorna todas as unidades
+func (branchRepository *branchRepository) List(ctx context.Context) ([]*entities.Branch, error) {
+	var branches []*entities.Branch
+	result := branchRepository.db.WithContext(ctx).Order("name").Find(&branches)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return branches, nil
+}
+
+// Update atualiza os dados de uma unidade
+func (branchRepository *branchRepository) Update(ctx context.Context, branch *entities.Branch) error {
+	result := branchRepository.db.WithContext(ctx).Omit(clause.Associations).Save(branch)
+	return result.Error
+}
+
+// Delete remove uma unidade pelo seu ID, desde que não possua exemplares alocados
+func (branchRepository *branchRepository) Delete(ctx context.Context, id uint) error {
+	var allocated int64
+	if err := branchRepository.db.WithContext(ctx).Model(&entities.BookHolding{}).
+		Where("branch_id = ? AND quantity > 0", id).Count(&allocated).Error; err != nil {
+		return err
+	}
+	if allocated > 0 {
+		return errors.New("unidade possui exemplares alocados")
+	}
+
+	result := branchRepository.db.WithContext(ctx).Delete(&entities.Branch{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("unidade não encontrada")
+	}
+	return nil
+}
+
+// FindHolding busca os exemplares de um livro alocados em uma unidade
+func (branchRepository *branchRepository) FindHolding(ctx context.Context, bookID, branchID uint) (*entities.BookHolding, error) {
+	var holding entities.BookHolding
+	result := branchRepository.db.WithContext(ctx).Preload("Branch").
+		Where("book_id = ? AND branch_id = ?", bookID, branchID).First(&holding)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil // Livro sem exemplares na unidade
+		}
+		return nil, result.Error
+	}
+	return &holding, nil
+}
+
+// SetHoldingQuantity define quantos exemplares de um livro ficam alocados em uma unidade.
+// Os exemplares alocados saem do total do livro; o restante continua sem unidade definida.
+func (branchRepository *branchRepository) SetHoldingQuantity(ctx context.Context, bookID, branchID uint, quantity int) error {
+	// Iniciar transação
+	tx := branchRepository.db.WithContext(ctx).Begin()
+
+	// Bloquear o livro para evitar alocações concorrentes
+	var book entities.Book
+	if err := forUpdate(tx).First(&book, bookID).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("livro não encontrado")
+		}
+		return err
+	}
+
+	var holdings []entities.BookHolding
+	if err := tx.Where("book_id = ?", bookID).Find(&holdings).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Somar o que já está alocado nas demais unidades
+	var holding *entities.BookHolding
+	allocated, allocatedAvailable := 0, 0
+	for i := range holdings {
+		if holdings[i].BranchID == branchID {
+			holding = &holdings[i]
+			continue
+		}
+		allocated += holdings[i].Quantity
+		allocatedAvailable += holdings[i].Available
+	}
+
+	if holding == nil {
+		var count int64
+		if err := tx.Model(&entities.Branch{}).Where("id = ?", branchID).Count(&count).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+		if count == 0 {
+			tx.Rollback()
+			return errors.New("unidade não encontrada")
+		}
+		holding = &entities.BookHolding{BookID: bookID, BranchID: branchID}
+	}
+
+	if quantity < holding.Quantity-holding.Available {
+		tx.Rollback()
+		return errors.New("quantidade menor que o número de exemplares emprestados na unidade")
+	}
+	if allocated+quantity > book.Quantity {
+		tx.Rollback()
+		return errors.New("quantidade excede o total de exemplares do livro")
+	}
+
+	// Novos exemplares só podem vir dos disponíveis sem unidade definida
+	delta := quantity - holding.Quantity
+	unallocatedAvailable := book.Available - allocatedAvailable - holding.Available
+	if delta > unallocatedAvailable {
+		tx.Rollback()
+		return errors.New("não há exemplares livres suficientes para alocar na unidade")
+	}
+
+	holding.Quantity = quantity
+	holding.Available += delta
+	if err := tx.Omit(clause.Associations).Save(holding).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
@@ -0,0 +1,17 @@
+package repositories
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// isUniqueViolation informa se o erro é a recusa de um índice único, em qualquer um dos bancos suportados.
+// A tradução é feita aqui, e não com TranslateError na conexão, para não trocar as mensagens dos demais erros.
+func isUniqueViolation(db *gorm.DB, err error) bool {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return true
+	}
+	translator, ok := db.Dialector.(gorm.ErrorTranslator)
+	return ok && errors.Is(translator.Translate(err), gorm.ErrDuplicatedKey)
+}
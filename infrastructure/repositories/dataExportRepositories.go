@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/repositories"
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+	"gorm.io/gorm"
+)
+
+// dataExportRepository implementa a interface DataExportRepository
+type dataExportRepository struct {
+	db *gorm.DB
+}
+
+// NewDataExportRepository cria uma nova instância do repositório de exportações de dados pessoais
+func NewDataExportRepository(db *gorm.DB) repositories.DataExportRepository {
+	return &dataExportRepository{
+		db: db,
+	}
+}
+
+// Create registra uma nova exportação
+func (dataExportRepository *dataExportRepository) Create(ctx context.Context, export *entities.DataExport) error {
+	result := dataExportRepository.db.WithContext(ctx).Create(export)
+	return result.Error
+}
+
+// FindByID busca uma exportação pelo seu ID
+func (dataExportRepository *dataExportRepository) FindByID(ctx context.Context, id uint) (*entities.DataExport, error) {
+	var export entities.DataExport
+	result := dataExportRepository.db.WithContext(ctx).First(&export, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil // Exportação não encontrada
+		}
+		return nil, result.Error
+	}
+	return &export, nil
+}
+
+// ListByUser retorna as exportações de um usuário, da mais recente à mais antiga
+func (dataExportRepository *dataExportRepository) ListByUser(ctx context.Context, userID uint) ([]*entities.DataExport, error) {
+	var exports []*entities.DataExport
+	result := dataExportRepository.db.WithContext(ctx).Where("user_id = ?", userID).Order("created_at DESC").Find(&exports)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return exports, nil
+}
+
+// ListExpired retorna as exportações com arquivo que já passaram da validade
+// ou que pertencem a usuários removidos
+func (dataExportRepository *dataExportRepository) ListExpired(ctx context.Context, now time.Time) ([]*entities.DataExport, error) {
+	var exports []*entities.DataExport
+	result := dataExportRepository.db.WithContext(ctx).
+		Where("status = ?", entities.DataExportStatusCompleted).
+		Where("expires_at <= ? OR user_id IN (?)", now,
+			dataExportRepository.db.WithContext(ctx).Unscoped().Model(&entities.User{}).Select("id").Where("deleted_at IS NOT NULL")).
+		Find(&exports)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return exports, nil
+}
+
+// Update atualiza uma exportação
+func (dataExportRepository *dataExportRepository) Update(ctx context.Context, export *entities.DataExport) error {
+	result := dataExportRepository.db.WithContext(ctx).Save(export)
+	return result.Error
+}
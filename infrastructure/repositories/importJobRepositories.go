@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/repositories"
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+	"gorm.io/gorm"
+)
+
+// importJobRepository implementa a interface ImportJobRepository
+type importJobRepository struct {
+	db *gorm.DB
+}
+
+// NewImportJobRepository cria uma nova instância do repositório de importações
+func NewImportJobRepository(db *gorm.DB) repositories.ImportJobRepository {
+	return &importJobRepository{
+		db: db,
+	}
+}
+
+// Create registra uma nova importação
+func (importJobRepository *importJobRepository) Create(ctx context.Context, job *entities.ImportJob) error {
+	result := importJobRepository.db.WithContext(ctx).Create(job)
+	return result.Error
+}
+
+// FindByID busca uma importação pelo seu ID
+func (importJobRepository *importJobRepository) FindByID(ctx context.Context, id uint) (*entities.ImportJob, error) {
+	var job entities.ImportJob
+	result := importJobRepository.db.WithContext(ctx).First(&job, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil // Importação não encontrada
+		}
+		return nil, result.Error
+	}
+	return &job, nil
+}
+
+// List retorna as importações mais recentes
+func (importJobRepository *importJobRepository) List(ctx context.Context, limit int) ([]*entities.ImportJob, error) {
+	var jobs []*entities.ImportJob
+	result := importJobRepository.db.WithContext(ctx).Order("created_at DESC").Limit(limit).Find(&jobs)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return jobs, nil
+}
+
+// Update atualiza o andamento de uma importação
+func (importJobRepository *importJobRepository) Update(ctx context.Context, job *entities.ImportJob) error {
+	result := importJobRepository.db.WithContext(ctx).Save(job)
+	return result.Error
+}
@@ -0,0 +1,72 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/repositories"
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+)
+
+// jobRunRepository implementa a interface JobRunRepository
+type jobRunRepository struct {
+	db *gorm.DB
+}
+
+// NewJobRunRepository cria uma nova instância do repositório de execuções de tarefas
+func NewJobRunRepository(db *gorm.DB) repositories.JobRunRepository {
+	return &jobRunRepository{
+		db: db,
+	}
+}
+
+// Claim registra a execução e informa se esta instância ficou com ela. Se outra réplica já registrou
+// a mesma tarefa no mesmo horário previsto, o índice único recusa a gravação e Claim retorna false.
+func (jobRunRepository *jobRunRepository) Claim(ctx context.Context, run *entities.JobRun) (bool, error) {
+	result := jobRunRepository.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(run)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Update grava o andamento de uma execução
+func (jobRunRepository *jobRunRepository) Update(ctx context.Context, run *entities.JobRun) error {
+	result := jobRunRepository.db.WithContext(ctx).Save(run)
+	return result.Error
+}
+
+// List retorna as execuções mais recentes que atendem ao filtro
+func (jobRunRepository *jobRunRepository) List(ctx context.Context, filter repositories.JobRunFilter) ([]*entities.JobRun, error) {
+	query := jobRunRepository.db.WithContext(ctx).Order("scheduled_for DESC, id DESC")
+	if filter.JobName != "" {
+		query = query.Where("job_name = ?", filter.JobName)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var runs []*entities.JobRun
+	result := query.Find(&runs)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return runs, nil
+}
+
+// DeleteFinishedBefore apaga de vez as execuções concluídas antes da data informada e retorna quantas foram apagadas
+func (jobRunRepository *jobRunRepository) DeleteFinishedBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := jobRunRepository.db.WithContext(ctx).Unscoped().
+		Where("status <> ? AND finished_at < ?", entities.JobRunStatusRunning, before).
+		Delete(&entities.JobRun{})
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
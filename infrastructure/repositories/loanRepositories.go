@@ -1,6 +1,7 @@
 package repositories
 
 import (
+	"context"
 	"errors"
 	"time"
 
@@ -22,22 +23,73 @@ func NewLoanRepository(db *gorm.DB) repositories.LoanRepository {
 }
 
 // Create cria um novo empréstimo no banco de dados
-func (loanRepository *loanRepository) Create(loan *entities.Loan) error {
-	// Verificar se o livro está disponível
+func (loanRepository *loanRepository) Create(ctx context.Context, loan *entities.Loan) error {
+	// Iniciar transação
+	tx := loanRepository.db.WithContext(ctx).Begin()
+
+	// Bloquear o usuário para que a remoção da conta não corra com o empréstimo
+	var user entities.User
+	if err := forUpdate(tx).First(&user, loan.UserID).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("usuário não encontrado")
+		}
+		return err
+	}
+
+	// Bloquear o livro para evitar empréstimos concorrentes do último exemplar
 	var book entities.Book
-	if err := loanRepository.db.First(&book, loan.BookID).Error; err != nil {
+	if err := forUpdate(tx).First(&book, loan.BookID).Error; err != nil {
+		tx.Rollback()
 		return err
 	}
 
+	if book.Status == entities.BookStatusWithdrawn {
+		tx.Rollback()
+		return errors.New("livro retirado do acervo")
+	}
+
 	if book.Available <= 0 {
+		tx.Rollback()
 		return errors.New("livro não disponível para empréstimo")
 	}
 
-	// Iniciar transação
-	tx := loanRepository.db.Begin()
+	if loan.BranchID != nil {
+		// Retirada em uma unidade: consumir um exemplar da unidade
+		var holding entities.BookHolding
+		if err := forUpdate(tx).
+			Where("book_id = ? AND branch_id = ?", loan.BookID, *loan.BranchID).
+			First(&holding).Error; err != nil {
+			tx.Rollback()
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("livro não possui exemplares nesta unidade")
+			}
+			return err
+		}
+		if holding.Available <= 0 {
+			tx.Rollback()
+			return errors.New("livro não disponível para empréstimo nesta unidade")
+		}
+		if err := tx.Model(&holding).Update("available", gorm.Expr("available - ?", 1)).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	} else {
+		// Sem unidade: apenas exemplares não alocados podem ser emprestados
+		var allocatedAvailable int64
+		if err := tx.Model(&entities.BookHolding{}).Where("book_id = ?", loan.BookID).
+			Select("COALESCE(SUM(available), 0)").Scan(&allocatedAvailable).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+		if int64(book.Available) <= allocatedAvailable {
+			tx.Rollback()
+			return errors.New("livro disponível apenas em unidades, informe a unidade de retirada")
+		}
+	}
 
 	// Diminuir contador de disponíveis
-	if err := tx.Model(&book).Update("available", book.Available-1).Error; err != nil {
+	if err := tx.Model(&book).Update("available", gorm.Expr("available - ?", 1)).Error; err != nil {
 		tx.Rollback()
 		return err
 	}
@@ -52,9 +104,9 @@ func (loanRepository *loanRepository) Create(loan *entities.Loan) error {
 }
 
 // FindByID busca um empréstimo pelo seu ID
-func (loanRepository *loanRepository) FindByID(id uint) (*entities.Loan, error) {
+func (loanRepository *loanRepository) FindByID(ctx context.Context, id uint) (*entities.Loan, error) {
 	var loan entities.Loan
-	result := loanRepository.db.Preload("Book").Preload("User").First(&loan, id)
+	result := loanRepository.db.WithContext(ctx).Preload("Book", withDeleted).Preload("User").Preload("Branch").First(&loan, id)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, nil // Empréstimo não encontrado
@@ -65,9 +117,56 @@ func (loanRepository *loanRepository) FindByID(id uint) (*entities.Loan, error)
 }
 
 // FindByUserID busca todos os empréstimos de um usuário
-func (loanRepository *loanRepository) FindByUserID(userID uint) ([]*entities.Loan, error) {
+func (loanRepository *loanRepository) FindByUserID(ctx context.Context, userID uint) ([]*entities.Loan, error) {
 	var loans []*entities.Loan
-	result := loanRepository.db.Where("user_id = ?", userID).Preload("Book").Preload("User").Find(&loans)
+	result := loanRepository.db.WithContext(ctx).Where("user_id = ?", userID).Preload("Book", withDeleted).Preload("User").Preload("Branch").Find(&loans)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return loans, nil
+}
+
+// CountByUserID conta os empréstimos de um usuário, devolvidos ou não
+func (loanRepository *loanRepository) CountByUserID(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	result := loanRepository.db.WithContext(ctx).Model(&entities.Loan{}).Where("user_id = ?", userID).Count(&count)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return count, nil
+}
+
+// CountActive conta os empréstimos ainda não devolvidos
+func (loanRepository *loanRepository) CountActive(ctx context.Context) (int64, error) {
+	var count int64
+	result := loanRepository.db.WithContext(ctx).Model(&entities.Loan{}).Where("is_returned = ?", false).Count(&count)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return count, nil
+}
+
+// CountOverdue conta os empréstimos não devolvidos cuja data prevista de devolução já passou
+func (loanRepository *loanRepository) CountOverdue(ctx context.Context, now time.Time) (int64, error) {
+	var count int64
+	result := loanRepository.db.WithContext(ctx).Model(&entities.Loan{}).
+		Where("is_returned = ? AND return_date < ?", false, now).
+		Count(&count)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return count, nil
+}
+
+// ListOverdue retorna os empréstimos não devolvidos cuja data prevista de devolução já passou,
+// do mais atrasado ao mais recente
+func (loanRepository *loanRepository) ListOverdue(ctx context.Context, now time.Time) ([]*entities.Loan, error) {
+	var loans []*entities.Loan
+	result := loanRepository.db.WithContext(ctx).
+		Where("is_returned = ? AND return_date < ?", false, now).
+		Order("return_date ASC, id ASC").
+		Preload("Book", withDeleted).Preload("User").Preload("Branch").
+		Find(&loans)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -75,16 +174,16 @@ func (loanRepository *loanRepository) FindByUserID(userID uint) ([]*entities.Loa
 }
 
 // Update atualiza os dados de um empréstimo
-func (loanRepository *loanRepository) Update(loan *entities.Loan) error {
-	result := loanRepository.db.Save(loan)
+func (loanRepository *loanRepository) Update(ctx context.Context, loan *entities.Loan) error {
+	result := loanRepository.db.WithContext(ctx).Save(loan)
 	return result.Error
 }
 
 // ReturnLoan marca um empréstimo como devolvido e atualiza o estoque do livro
-func (loanRepository *loanRepository) ReturnLoan(id uint, returnDate time.Time) error {
+func (loanRepository *loanRepository) ReturnLoan(ctx context.Context, id uint, returnDate time.Time) error {
 	// Obter o empréstimo
 	var loan entities.Loan
-	if err := loanRepository.db.First(&loan, id).Error; err != nil {
+	if err := loanRepository.db.WithContext(ctx).First(&loan, id).Error; err != nil {
 		return err
 	}
 
@@ -93,7 +192,7 @@ func (loanRepository *loanRepository) ReturnLoan(id uint, returnDate time.Time)
 	}
 
 	// Iniciar transação
-	tx := loanRepository.db.Begin()
+	tx := loanRepository.db.WithContext(ctx).Begin()
 
 	// Atualizar empréstimo
 	if err := tx.Model(&loan).Updates(map[string]interface{}{
@@ -104,12 +203,28 @@ func (loanRepository *loanRepository) ReturnLoan(id uint, returnDate time.Time)
 		return err
 	}
 
-	// Aumentar disponibilidade do livro
-	if err := tx.Model(&entities.Book{}).Where("id = ?", loan.BookID).
+	// Aumentar disponibilidade do livro, mesmo que ele tenha sido removido depois do empréstimo
+	if err := tx.Unscoped().Model(&entities.Book{}).Where("id = ?", loan.BookID).
 		Update("available", gorm.Expr("available + ?", 1)).Error; err != nil {
 		tx.Rollback()
 		return err
 	}
 
+	// Devolver o exemplar à unidade de retirada
+	if loan.BranchID != nil {
+		if err := tx.Model(&entities.BookHolding{}).
+			Where("book_id = ? AND branch_id = ?", loan.BookID, *loan.BranchID).
+			Update("available", gorm.Expr("available + ?", 1)).Error; err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
 	return tx.Commit().Error
 }
+
+// withDeleted carrega o registro relacionado mesmo que ele tenha sido removido,
+// preservando o histórico dos empréstimos
+func withDeleted(db *gorm.DB) *gorm.DB {
+	return db.Unscoped()
+}
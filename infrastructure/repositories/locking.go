@@ -0,0 +1,16 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// forUpdate bloqueia as linhas lidas até o fim da transação (SELECT ... FOR UPDATE).
+// O SQLite não tem bloqueio por linha: lá a transação já começa com o banco reservado para escrita
+// (BEGIN IMMEDIATE, ver database.SQLiteDSN), o que serializa as escritas concorrentes da mesma forma.
+func forUpdate(tx *gorm.DB) *gorm.DB {
+	if tx.Dialector.Name() != "postgres" {
+		return tx
+	}
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"})
+}
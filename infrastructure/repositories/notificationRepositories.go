@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/repositories"
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+)
+
+// notificationRepository implementa a interface NotificationRepository
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+// NewNotificationRepository cria uma nova instância do repositório de notificações
+func NewNotificationRepository(db *gorm.DB) repositories.NotificationRepository {
+	return &notificationRepository{
+		db: db,
+	}
+}
+
+// Create grava a notificação e informa se ela é nova. Se já existir uma notificação com a mesma
+// chave de deduplicação, o índice único recusa a gravação e Create retorna false.
+func (notificationRepository *notificationRepository) Create(ctx context.Context, notification *entities.Notification) (bool, error) {
+	result := notificationRepository.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(notification)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Update grava o andamento da entrega de uma notificação
+func (notificationRepository *notificationRepository) Update(ctx context.Context, notification *entities.Notification) error {
+	result := notificationRepository.db.WithContext(ctx).Save(notification)
+	return result.Error
+}
+
+// ListPending retorna as notificações do canal ainda não entregues que não esgotaram as tentativas,
+// das mais antigas para as mais recentes
+func (notificationRepository *notificationRepository) ListPending(ctx context.Context, channel string, maxAttempts int, limit int) ([]*entities.Notification, error) {
+	var notifications []*entities.Notification
+	result := notificationRepository.db.WithContext(ctx).
+		Where("channel = ? AND sent_at IS NULL AND attempts < ?", channel, maxAttempts).
+		Order("id ASC").
+		Limit(limit).
+		Find(&notifications)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return notifications, nil
+}
+
+// ListInbox retorna as notificações internas do usuário, da mais recente à mais antiga
+func (notificationRepository *notificationRepository) ListInbox(ctx context.Context, userID uint, filter repositories.NotificationFilter) ([]*entities.Notification, error) {
+	query := notificationRepository.inbox(ctx, userID).Order("created_at DESC, id DESC")
+	if filter.UnreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var notifications []*entities.Notification
+	result := query.Find(&notifications)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return notifications, nil
+}
+
+// CountUnread conta as notificações internas do usuário ainda não lidas
+func (notificationRepository *notificationRepository) CountUnread(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	result := notificationRepository.inbox(ctx, userID).Where("read_at IS NULL").Count(&count)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return count, nil
+}
+
+// MarkRead marca uma notificação interna do usuário como lida. Retorna false se ela não existir
+// ou pertencer a outro usuário; marcar de novo uma notificação já lida mantém a data da primeira leitura.
+func (notificationRepository *notificationRepository) MarkRead(ctx context.Context, userID uint, id uint, readAt time.Time) (bool, error) {
+	var count int64
+	if err := notificationRepository.inbox(ctx, userID).Where("id = ?", id).Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count == 0 {
+		return false, nil
+	}
+
+	result := notificationRepository.inbox(ctx, userID).Where("id = ? AND read_at IS NULL", id).Update("read_at", readAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return true, nil
+}
+
+// MarkAllRead marca todas as notificações internas do usuário como lidas e retorna quantas foram marcadas
+func (notificationRepository *notificationRepository) MarkAllRead(ctx context.Context, userID uint, readAt time.Time) (int64, error) {
+	result := notificationRepository.inbox(ctx, userID).Where("read_at IS NULL").Update("read_at", readAt)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
+
+// inbox restringe a consulta às notificações internas do usuário
+func (notificationRepository *notificationRepository) inbox(ctx context.Context, userID uint) *gorm.DB {
+	return notificationRepository.db.WithContext(ctx).Model(&entities.Notification{}).
+		Where("user_id = ? AND channel = ?", userID, entities.NotificationChannelInApp)
+}
@@ -0,0 +1,165 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/repositories"
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+	"gorm.io/gorm"
+)
+
+// transferRepository implementa a interface TransferRepository
+type transferRepository struct {
+	db *gorm.DB
+}
+
+// NewTransferRepository cria uma nova instância do repositório de transferências
+func NewTransferRepository(db *gorm.DB) repositories.TransferRepository {
+	return &transferRepository{
+		db: db,
+	}
+}
+
+// Create cria um novo pedido de transferência no banco de dados
+func (transferRepository *transferRepository) Create(ctx context.Context, transfer *entities.TransferRequest) error {
+	transfer.Status = entities.TransferStatusPending
+
+	result := transferRepository.db.WithContext(ctx).Create(transfer)
+	return result.Error
+}
+
+// FindByID busca um pedido de transferência pelo seu ID
+func (transferRepository *transferRepository) FindByID(ctx context.Context, id uint) (*entities.TransferRequest, error) {
+	var transfer entities.TransferRequest
+	result := transferRepository.preloaded(ctx).First(&transfer, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil // Transferência não encontrada
+		}
+		return nil, result.Error
+	}
+	return &transfer, nil
+}
+
+// List retorna todos os pedidos de transferência
+func (transferRepository *transferRepository) List(ctx context.Context) ([]*entities.TransferRequest, error) {
+	var transfers []*entities.TransferRequest
+	result := transferRepository.preloaded(ctx).Order("created_at DESC").Find(&transfers)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return transfers, nil
+}
+
+// ListByBranch retorna os pedidos de transferência de origem ou destino na unidade
+func (transferRepository *transferRepository) ListByBranch(ctx context.Context, branchID uint) ([]*entities.TransferRequest, error) {
+	var transfers []*entities.TransferRequest
+	result := transferRepository.preloaded(ctx).
+		Where("from_branch_id = ? OR to_branch_id = ?", branchID, branchID).
+		Order("created_at DESC").Find(&transfers)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return transfers, nil
+}
+
+// Complete move os exemplares entre as unidades e conclui o pedido de transferência
+func (transferRepository *transferRepository) Complete(ctx context.Context, id uint, resolvedByID uint) error {
+	// Iniciar transação
+	tx := transferRepository.db.WithContext(ctx).Begin()
+
+	var transfer entities.TransferRequest
+	if err := forUpdate(tx).First(&transfer, id).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if transfer.Status != entities.TransferStatusPending {
+		tx.Rollback()
+		return errors.New("transferência já foi resolvida")
+	}
+
+	// Retirar os exemplares da unidade de origem
+	var from entities.BookHolding
+	if err := forUpdate(tx).
+		Where("book_id = ? AND branch_id = ?", transfer.BookID, transfer.FromBranchID).
+		First(&from).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("unidade de origem não possui exemplares do livro")
+		}
+		return err
+	}
+	if from.Available < transfer.Quantity {
+		tx.Rollback()
+		return errors.New("unidade de origem não possui exemplares disponíveis suficientes")
+	}
+	if err := tx.Model(&from).Updates(map[string]interface{}{
+		"quantity":  gorm.Expr("quantity - ?", transfer.Quantity),
+		"available": gorm.Expr("available - ?", transfer.Quantity),
+	}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// Adicionar os exemplares na unidade de destino
+	to := entities.BookHolding{BookID: transfer.BookID, BranchID: transfer.ToBranchID}
+	if err := tx.Where("book_id = ? AND branch_id = ?", transfer.BookID, transfer.ToBranchID).
+		FirstOrCreate(&to).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Model(&to).Updates(map[string]interface{}{
+		"quantity":  gorm.Expr("quantity + ?", transfer.Quantity),
+		"available": gorm.Expr("available + ?", transfer.Quantity),
+	}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := transferRepository.resolve(tx, &transfer, entities.TransferStatusCompleted, resolvedByID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// Reject recusa um pedido de transferência pendente
+func (transferRepository *transferRepository) Reject(ctx context.Context, id uint, resolvedByID uint) error {
+	// Iniciar transação
+	tx := transferRepository.db.WithContext(ctx).Begin()
+
+	var transfer entities.TransferRequest
+	if err := forUpdate(tx).First(&transfer, id).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if transfer.Status != entities.TransferStatusPending {
+		tx.Rollback()
+		return errors.New("transferência já foi resolvida")
+	}
+
+	if err := transferRepository.resolve(tx, &transfer, entities.TransferStatusRejected, resolvedByID); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// resolve registra o desfecho de um pedido de transferência
+func (transferRepository *transferRepository) resolve(tx *gorm.DB, transfer *entities.TransferRequest, status string, resolvedByID uint) error {
+	now := time.Now()
+	return tx.Model(transfer).Updates(map[string]interface{}{
+		"status":         status,
+		"resolved_by_id": resolvedByID,
+		"resolved_at":    now,
+	}).Error
+}
+
+// preloaded retorna uma consulta com livro e unidades carregados
+func (transferRepository *transferRepository) preloaded(ctx context.Context) *gorm.DB {
+	return transferRepository.db.WithContext(ctx).Preload("Book").Preload("FromBranch").Preload("ToBranch")
+}
@@ -1,11 +1,16 @@
 package repositories
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"time"
 
 	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/repositories"
 	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+	domainerrors "github.com/henrygoeszanin/api_golang_estudos/domain/errors"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // userRepository implementa a interface UserRepository
@@ -20,20 +25,9 @@ func NewUserRepository(db *gorm.DB) repositories.UserRepository {
 	}
 }
 
-// Create cria um novo usuário no banco de dados
-func (userRepository *userRepository) Create(user *entities.User) error {
-	// Verificar se é o primeiro usuário
-	isFirst, err := userRepository.IsFirstUser()
-	if err != nil {
-		return err
-	}
-
-	// Se for o primeiro usuário, definir como administrador
-	if isFirst {
-		user.IsAdmin = true
-	}
-
-	result := userRepository.db.Create(user)
+// Create cria um novo usuário no banco de dados, com o papel informado na entidade
+func (userRepository *userRepository) Create(ctx context.Context, user *entities.User) error {
+	result := userRepository.db.WithContext(ctx).Create(user)
 	if result.Error != nil {
 		return result.Error
 	}
@@ -41,9 +35,22 @@ func (userRepository *userRepository) Create(user *entities.User) error {
 }
 
 // FindByID busca um usuário pelo seu ID
-func (userRepository *userRepository) FindByID(id uint) (*entities.User, error) {
+func (userRepository *userRepository) FindByID(ctx context.Context, id uint) (*entities.User, error) {
+	var user entities.User
+	result := userRepository.db.WithContext(ctx).First(&user, id)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil // Usuário não encontrado
+		}
+		return nil, result.Error
+	}
+	return &user, nil
+}
+
+// FindByIDWithDeleted busca um usuário pelo seu ID, incluindo os removidos
+func (userRepository *userRepository) FindByIDWithDeleted(ctx context.Context, id uint) (*entities.User, error) {
 	var user entities.User
-	result := userRepository.db.First(&user, id)
+	result := userRepository.db.WithContext(ctx).Unscoped().First(&user, id)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, nil // Usuário não encontrado
@@ -54,9 +61,9 @@ func (userRepository *userRepository) FindByID(id uint) (*entities.User, error)
 }
 
 // FindByEmail busca um usuário pelo seu email
-func (userRepository *userRepository) FindByEmail(email string) (*entities.User, error) {
+func (userRepository *userRepository) FindByEmail(ctx context.Context, email string) (*entities.User, error) {
 	var user entities.User
-	result := userRepository.db.Where("email = ?", email).First(&user)
+	result := userRepository.db.WithContext(ctx).Where("email = ?", email).First(&user)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, nil // Usuário não encontrado
@@ -67,14 +74,14 @@ func (userRepository *userRepository) FindByEmail(email string) (*entities.User,
 }
 
 // Update atualiza os dados de um usuário
-func (userRepository *userRepository) Update(user *entities.User) error {
-	result := userRepository.db.Save(user)
+func (userRepository *userRepository) Update(ctx context.Context, user *entities.User) error {
+	result := userRepository.db.WithContext(ctx).Omit(clause.Associations).Save(user)
 	return result.Error
 }
 
 // Delete remove um usuário pelo seu ID
-func (userRepository *userRepository) Delete(id uint) error {
-	result := userRepository.db.Delete(&entities.User{}, id)
+func (userRepository *userRepository) Delete(ctx context.Context, id uint) error {
+	result := userRepository.db.WithContext(ctx).Delete(&entities.User{}, id)
 	if result.Error != nil {
 		return result.Error
 	}
@@ -85,9 +92,9 @@ func (userRepository *userRepository) Delete(id uint) error {
 }
 
 // List retorna todos os usuários
-func (userRepository *userRepository) List() ([]*entities.User, error) {
+func (userRepository *userRepository) List(ctx context.Context) ([]*entities.User, error) {
 	var users []*entities.User
-	result := userRepository.db.Find(&users)
+	result := userRepository.db.WithContext(ctx).Find(&users)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -95,8 +102,8 @@ func (userRepository *userRepository) List() ([]*entities.User, error) {
 }
 
 // PromoteToAdmin promove um usuário para administrador
-func (userRepository *userRepository) PromoteToAdmin(id uint) error {
-	result := userRepository.db.Model(&entities.User{}).Where("id = ?", id).Update("is_admin", true)
+func (userRepository *userRepository) PromoteToAdmin(ctx context.Context, id uint) error {
+	result := userRepository.db.WithContext(ctx).Model(&entities.User{}).Where("id = ?", id).Update("is_admin", true)
 	if result.Error != nil {
 		return result.Error
 	}
@@ -106,11 +113,171 @@ func (userRepository *userRepository) PromoteToAdmin(id uint) error {
 	return nil
 }
 
-// IsFirstUser verifica se este será o primeiro usuário no sistema
-func (userRepository *userRepository) IsFirstUser() (bool, error) {
+// IsFirstUser verifica se este será o primeiro usuário no sistema.
+// Usuários removidos também contam, para que o próximo cadastro não seja considerado o primeiro.
+func (userRepository *userRepository) IsFirstUser(ctx context.Context) (bool, error) {
 	var count int64
-	if err := userRepository.db.Model(&entities.User{}).Count(&count).Error; err != nil {
+	if err := userRepository.db.WithContext(ctx).Unscoped().Model(&entities.User{}).Count(&count).Error; err != nil {
 		return false, err
 	}
 	return count == 0, nil
 }
+
+// CountAdmins conta os administradores ativos
+func (userRepository *userRepository) CountAdmins(ctx context.Context) (int64, error) {
+	var count int64
+	if err := userRepository.db.WithContext(ctx).Model(&entities.User{}).Where("is_admin = ?", true).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// ListDeleted retorna os usuários removidos, do mais recente ao mais antigo
+func (userRepository *userRepository) ListDeleted(ctx context.Context) ([]*entities.User, error) {
+	var users []*entities.User
+	result := userRepository.db.WithContext(ctx).Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at DESC").Find(&users)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return users, nil
+}
+
+// Restore desfaz a remoção de um usuário e cancela a exclusão agendada.
+// Falha se os dados já foram apagados ou se o email passou a ser usado por outro usuário.
+func (userRepository *userRepository) Restore(ctx context.Context, id uint) error {
+	// Iniciar transação
+	tx := userRepository.db.WithContext(ctx).Begin()
+
+	var user entities.User
+	if err := forUpdate(tx.Unscoped()).Where("deleted_at IS NOT NULL").First(&user, id).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: usuário removido não encontrado", domainerrors.ErrNotFound)
+		}
+		return err
+	}
+	if user.ErasedAt != nil {
+		tx.Rollback()
+		return fmt.Errorf("%w: os dados do usuário já foram apagados", domainerrors.ErrConflict)
+	}
+
+	var existing entities.User
+	err := tx.Where("email = ?", user.Email).First(&existing).Error
+	if err == nil {
+		tx.Rollback()
+		return &domainerrors.DuplicateError{ExistingID: existing.ID, Reason: "email já está em uso por outro usuário"}
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Unscoped().Model(&user).Updates(map[string]interface{}{
+		"deleted_at":           nil,
+		"erasure_scheduled_at": nil,
+	}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// ScheduleErasure remove o usuário e agenda o apagamento dos dados pessoais para a data informada.
+// Usuários com empréstimos em aberto não podem ser removidos.
+func (userRepository *userRepository) ScheduleErasure(ctx context.Context, id uint, eraseAt time.Time) error {
+	// Iniciar transação
+	tx := userRepository.db.WithContext(ctx).Begin()
+
+	var user entities.User
+	if err := forUpdate(tx).First(&user, id).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("usuário não encontrado")
+		}
+		return err
+	}
+
+	if err := refuseOpenLoans(tx, id); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Model(&user).Update("erasure_scheduled_at", eraseAt).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Delete(&user).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// Erase apaga os dados pessoais do usuário, removido ou não, mantendo o registro anonimizado
+// para que o histórico de empréstimos continue nas estatísticas.
+// Usuários com empréstimos em aberto não podem ser apagados.
+func (userRepository *userRepository) Erase(ctx context.Context, id uint, erasedAt time.Time) error {
+	// Iniciar transação
+	tx := userRepository.db.WithContext(ctx).Begin()
+
+	var user entities.User
+	if err := forUpdate(tx.Unscoped()).First(&user, id).Error; err != nil {
+		tx.Rollback()
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: usuário não encontrado", domainerrors.ErrNotFound)
+		}
+		return err
+	}
+	if user.ErasedAt != nil {
+		tx.Rollback()
+		return fmt.Errorf("%w: os dados do usuário já foram apagados", domainerrors.ErrConflict)
+	}
+
+	if err := refuseOpenLoans(tx, id); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	user.Anonymize(erasedAt)
+	if !user.DeletedAt.Valid {
+		user.DeletedAt = gorm.DeletedAt{Time: erasedAt, Valid: true}
+	}
+	if err := tx.Unscoped().Omit(clause.Associations).Save(&user).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	// As notificações trazem o nome do usuário e os livros emprestados
+	if err := tx.Unscoped().Where("user_id = ?", id).Delete(&entities.Notification{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit().Error
+}
+
+// ListErasureDue retorna os IDs dos usuários cujo prazo para desistir da exclusão já terminou
+func (userRepository *userRepository) ListErasureDue(ctx context.Context, now time.Time) ([]uint, error) {
+	var ids []uint
+	result := userRepository.db.WithContext(ctx).Unscoped().Model(&entities.User{}).
+		Where("erasure_scheduled_at <= ? AND erased_at IS NULL", now).
+		Pluck("id", &ids)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return ids, nil
+}
+
+// refuseOpenLoans retorna erro de conflito se o usuário tiver empréstimos em aberto
+func refuseOpenLoans(tx *gorm.DB, userID uint) error {
+	var openLoans int64
+	if err := tx.Model(&entities.Loan{}).Where("user_id = ? AND is_returned = ?", userID, false).Count(&openLoans).Error; err != nil {
+		return err
+	}
+	if openLoans > 0 {
+		return fmt.Errorf("%w: usuário possui %d empréstimo(s) em aberto", domainerrors.ErrConflict, openLoans)
+	}
+	return nil
+}
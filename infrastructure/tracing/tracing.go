@@ -0,0 +1,78 @@
+// Package tracing configura o provedor do OpenTelemetry e o exportador dos spans
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+
+	"github.com/henrygoeszanin/api_golang_estudos/config"
+)
+
+// Exportadores aceitos em TRACING_EXPORTER
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Setup configura o rastreamento conforme cfg e registra o provedor e o propagador globais.
+// O contexto de rastreamento recebido nos cabeçalhos traceparent/baggage é sempre propagado,
+// mesmo com o exportador desligado. Retorna a função que envia os spans pendentes e encerra o provedor.
+func Setup(ctx context.Context, cfg *config.Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var processor sdktrace.SpanProcessor
+	switch strings.ToLower(cfg.TracingExporter) {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		exporter, err := stdouttrace.New(stdouttrace.WithPrettyPrint())
+		if err != nil {
+			return nil, fmt.Errorf("falha ao criar o exportador de spans: %w", err)
+		}
+		processor = sdktrace.NewSimpleSpanProcessor(exporter)
+	case ExporterOTLP:
+		options := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.OTLPEndpoint)}
+		if cfg.OTLPInsecure {
+			options = append(options, otlptracehttp.WithInsecure())
+		}
+		exporter, err := otlptracehttp.New(ctx, options...)
+		if err != nil {
+			return nil, fmt.Errorf("falha ao criar o exportador OTLP: %w", err)
+		}
+		processor = sdktrace.NewBatchSpanProcessor(exporter)
+	default:
+		return nil, fmt.Errorf("exportador de spans desconhecido: %q (use none, stdout ou otlp)", cfg.TracingExporter)
+	}
+
+	provider := NewProvider(cfg.ServiceName, cfg.TracingSampleRatio, processor)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// NewProvider cria o provedor de spans com o nome do serviço, a fração de requisições amostradas
+// e o processador informados. Requisições que chegam com um rastreamento já amostrado são sempre registradas.
+func NewProvider(serviceName string, sampleRatio float64, processor sdktrace.SpanProcessor) *sdktrace.TracerProvider {
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithResource(resource.NewSchemaless(semconv.ServiceName(serviceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+		sdktrace.WithSpanProcessor(processor),
+	)
+}
+
+// NewInMemoryProvider cria um provedor que guarda os spans em memória, para testes.
+// Registre-o com otel.SetTracerProvider e consulte os spans com exporter.GetSpans().
+func NewInMemoryProvider(serviceName string) (*sdktrace.TracerProvider, *tracetest.InMemoryExporter) {
+	exporter := tracetest.NewInMemoryExporter()
+	return NewProvider(serviceName, 1, sdktrace.NewSimpleSpanProcessor(exporter)), exporter
+}
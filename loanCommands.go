@@ -0,0 +1,95 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	"github.com/henrygoeszanin/api_golang_estudos/application/services"
+)
+
+// runLoanListOverdue executa o subcomando loan list-overdue: lista os empréstimos em atraso,
+// do mais atrasado ao mais recente
+func runLoanListOverdue(args []string) int {
+	tool, code := loadTool("library-api loan list-overdue", args, nil)
+	if tool == nil {
+		return code
+	}
+
+	if err := tool.open(); err != nil {
+		return tool.fail("falha ao abrir o banco de dados", err)
+	}
+	defer tool.close()
+
+	loans, err := tool.loanService().ListOverdue(context.Background())
+	if err != nil {
+		return tool.fail("falha ao listar os empréstimos em atraso", err)
+	}
+	return printLoans(tool, loans)
+}
+
+// runLoanForceReturn executa o subcomando loan force-return: registra a devolução de um empréstimo
+// sem conferir o usuário nem a unidade, para corrigir devoluções que não passaram pelo balcão
+func runLoanForceReturn(args []string) int {
+	var id uint
+	tool, code := loadTool("library-api loan force-return", args, func(flagSet *flag.FlagSet) {
+		flagSet.UintVar(&id, "id", 0, "ID do empréstimo")
+	})
+	if tool == nil {
+		return code
+	}
+
+	if id == 0 {
+		fmt.Fprintln(os.Stderr, "informe o empréstimo em --id")
+		return 2
+	}
+
+	if err := tool.open(); err != nil {
+		return tool.fail("falha ao abrir o banco de dados", err)
+	}
+	defer tool.close()
+
+	ctx := context.Background()
+	var loan *dtos.LoanResponseDTO
+	err := tool.audited(ctx, "loan force-return", services.AuditTargetLoan, id, func() (uint, error) {
+		var err error
+		loan, err = tool.loanService().ForceReturn(ctx, id)
+		return id, err
+	})
+	if err != nil {
+		return tool.fail("falha ao registrar a devolução", err, "id", id)
+	}
+	tool.logger.Info("devolução registrada", "id", loan.ID, "book_id", loan.BookID, "user_id", loan.UserID)
+	return printLoans(tool, []dtos.LoanResponseDTO{*loan})
+}
+
+// printLoans escreve os empréstimos em tabela ou JSON
+func printLoans(tool *tool, loans []dtos.LoanResponseDTO) int {
+	rows := make([][]string, 0, len(loans))
+	for _, loan := range loans {
+		branch := "-"
+		if loan.BranchName != "" {
+			branch = loan.BranchName
+		}
+		rows = append(rows, []string{
+			strconv.FormatUint(uint64(loan.ID), 10),
+			loan.BookTitle,
+			loan.UserName,
+			branch,
+			formatTime(&loan.LoanDate),
+			formatTime(&loan.ReturnDate),
+			formatTime(loan.ReturnedAt),
+		})
+	}
+
+	if loans == nil {
+		loans = []dtos.LoanResponseDTO{}
+	}
+	if err := tool.print(loans, []string{"ID", "LIVRO", "USUÁRIO", "UNIDADE", "RETIRADO EM", "DEVOLVER ATÉ", "DEVOLVIDO EM"}, rows); err != nil {
+		return tool.fail("falha ao escrever o resultado", err)
+	}
+	return 0
+}
@@ -0,0 +1,87 @@
+// Package logging configura os logs estruturados da aplicação e leva o logger de cada requisição
+// pelo context.Context até os serviços e repositórios.
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// contextKey identifica os valores de logging guardados no contexto
+type contextKey int
+
+const (
+	loggerKey contextKey = iota
+	requestIDKey
+)
+
+// New cria o logger da aplicação no nível ("debug", "info", "warn" ou "error") e
+// formato ("json" ou "text") informados. Valores desconhecidos usam info e json.
+func New(output io.Writer, level, format string) *slog.Logger {
+	options := &slog.HandlerOptions{
+		Level:       ParseLevel(level),
+		ReplaceAttr: redactAttr,
+	}
+
+	var handler slog.Handler
+	if strings.EqualFold(format, "text") {
+		handler = slog.NewTextHandler(output, options)
+	} else {
+		handler = slog.NewJSONHandler(output, options)
+	}
+	return slog.New(handler)
+}
+
+// ParseLevel converte o nome do nível de log; valores desconhecidos resultam em info
+func ParseLevel(level string) slog.Level {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+// WithLogger guarda o logger no contexto
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey, logger)
+}
+
+// FromContext retorna o logger guardado no contexto ou, se não houver, o logger padrão
+func FromContext(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(loggerKey).(*slog.Logger); ok {
+			return logger
+		}
+	}
+	return slog.Default()
+}
+
+// WithRequestID guarda o ID da requisição no contexto
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID retorna o ID da requisição guardado no contexto, ou texto vazio
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDKey).(string)
+	return requestID
+}
+
+// Detach cria um contexto sem prazo nem cancelamento da requisição, mas com o mesmo logger e ID.
+// Usado nas tarefas em segundo plano iniciadas por uma requisição, que continuam depois da resposta.
+func Detach(parent context.Context, ctx context.Context) context.Context {
+	ctx = WithLogger(ctx, FromContext(parent))
+	if requestID := RequestID(parent); requestID != "" {
+		ctx = WithRequestID(ctx, requestID)
+	}
+	return ctx
+}
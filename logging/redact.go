@@ -0,0 +1,56 @@
+package logging
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// redacted substitui os valores sensíveis nos logs
+const redacted = "[REDACTED]"
+
+// sensitiveKeys lista trechos de nomes de atributos cujo valor nunca deve ser registrado
+var sensitiveKeys = []string{"password", "senha", "token", "authorization", "secret", "cookie", "signature", "api_key", "apikey"}
+
+// Padrões de credenciais em textos livres: cabeçalhos Bearer, JWTs e parâmetros de URL
+var (
+	bearerPattern     = regexp.MustCompile(`(?i)bearer\s+[A-Za-z0-9._~+/=-]+`)
+	jwtPattern        = regexp.MustCompile(`eyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)
+	queryParamPattern = regexp.MustCompile(`(?i)((?:token|signature|password)=)[^&\s"]+`)
+)
+
+// redactAttr oculta os atributos sensíveis antes de o registro ser escrito
+func redactAttr(groups []string, attr slog.Attr) slog.Attr {
+	if isSensitiveKey(attr.Key) {
+		return slog.String(attr.Key, redacted)
+	}
+
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		return slog.String(attr.Key, RedactString(attr.Value.String()))
+	case slog.KindAny:
+		// Erros e valores formatáveis também podem carregar credenciais no texto
+		if err, ok := attr.Value.Any().(error); ok {
+			return slog.String(attr.Key, RedactString(err.Error()))
+		}
+	}
+	return attr
+}
+
+// isSensitiveKey indica se o nome do atributo sugere uma credencial
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(key, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactString oculta tokens, assinaturas e senhas presentes em um texto
+func RedactString(value string) string {
+	value = bearerPattern.ReplaceAllString(value, "Bearer "+redacted)
+	value = jwtPattern.ReplaceAllString(value, redacted)
+	return queryParamPattern.ReplaceAllString(value, "${1}"+redacted)
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+
+	"github.com/henrygoeszanin/api_golang_estudos/infrastructure/database"
+)
+
+// runMigrate executa o subcomando migrate: conecta ao banco, o que cria as tabelas e aplica as migrações
+// pendentes, e lista as migrações versionadas com a data de aplicação. Permite migrar o banco antes de
+// subir uma nova versão da API.
+func runMigrate(args []string) int {
+	tool, code := loadTool("library-api migrate", args, nil)
+	if tool == nil {
+		return code
+	}
+
+	if err := tool.open(); err != nil {
+		return tool.fail("falha ao migrar o banco de dados", err)
+	}
+	defer tool.close()
+
+	migrations, err := database.Migrations(context.Background(), tool.db)
+	if err != nil {
+		return tool.fail("falha ao consultar as migrações", err)
+	}
+
+	rows := make([][]string, 0, len(migrations))
+	for _, migration := range migrations {
+		rows = append(rows, []string{migration.ID, formatTime(migration.AppliedAt)})
+	}
+	if err := tool.print(migrations, []string{"MIGRAÇÃO", "APLICADA EM"}, rows); err != nil {
+		return tool.fail("falha ao escrever o resultado", err)
+	}
+	tool.logger.Info("banco de dados atualizado", "driver", tool.cfg.DBDriver)
+	return 0
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/services"
+)
+
+// AuditHandler manipula as requisições de consulta à trilha de auditoria
+type AuditHandler struct {
+	auditService services.AuditService
+}
+
+// NewAuditHandler cria uma nova instância de AuditHandler
+func NewAuditHandler(auditService services.AuditService) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+	}
+}
+
+// List lista os eventos da trilha de auditoria, com filtros e paginação na query string
+func (auditHandler *AuditHandler) List(c *gin.Context) {
+	var filterDTO dtos.AuditListFilterDTO
+	if err := c.ShouldBindQuery(&filterDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := auditHandler.auditService.List(c.Request.Context(), filterDTO)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// Verify confere a integridade da cadeia de auditoria
+func (auditHandler *AuditHandler) Verify(c *gin.Context) {
+	result, err := auditHandler.auditService.Verify(c.Request.Context())
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
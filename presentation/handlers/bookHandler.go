@@ -1,12 +1,17 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	"github.com/henrygoeszanin/api_golang_estudos/application/export"
 	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/services"
+	domainerrors "github.com/henrygoeszanin/api_golang_estudos/domain/errors"
 )
 
 // BookHandler manipula as requisições relacionadas a livros
@@ -21,11 +26,17 @@ func NewBookHandler(bookService services.BookService) *BookHandler {
 	}
 }
 
-// List lista todos os livros
+// List lista os livros, aplicando os filtros informados na query string
 func (bookHandler *BookHandler) List(c *gin.Context) {
-	books, err := bookHandler.bookService.List()
+	var filterDTO dtos.BookListFilterDTO
+	if err := c.ShouldBindQuery(&filterDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	books, err := bookHandler.bookService.List(c.Request.Context(), filterDTO)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		respondError(c, http.StatusInternalServerError, err)
 		return
 	}
 
@@ -41,32 +52,85 @@ func (bookHandler *BookHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	book, err := bookHandler.bookService.GetByID(uint(id))
+	book, err := bookHandler.bookService.GetByID(c.Request.Context(), uint(id))
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		respondError(c, http.StatusNotFound, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, book)
 }
 
-// Create adiciona um novo livro
+// GetByISBN busca um livro pelo ISBN
+func (bookHandler *BookHandler) GetByISBN(c *gin.Context) {
+	book, err := bookHandler.bookService.GetByISBN(c.Request.Context(), c.Param("isbn"))
+	if err != nil {
+		respondError(c, http.StatusNotFound, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, book)
+}
+
+// Create adiciona um novo livro.
+// Se o livro já existir, responde 409 com o registro existente ou, com ?merge=increment_quantity,
+// soma os exemplares ao registro existente.
 func (bookHandler *BookHandler) Create(c *gin.Context) {
+	var options dtos.BookCreateOptionsDTO
+	if err := c.ShouldBindQuery(&options); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	var bookDTO dtos.BookCreateDTO
 	if err := c.ShouldBindJSON(&bookDTO); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	createdBook, err := bookHandler.bookService.Create(bookDTO)
+	createdBook, err := bookHandler.bookService.Create(c.Request.Context(), bookDTO)
+	var duplicate *domainerrors.DuplicateError
+	if errors.As(err, &duplicate) {
+		bookHandler.handleDuplicate(c, duplicate, bookDTO, options)
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
 	c.JSON(http.StatusCreated, createdBook)
 }
 
+// handleDuplicate responde à tentativa de criar um livro já cadastrado
+func (bookHandler *BookHandler) handleDuplicate(c *gin.Context, duplicate *domainerrors.DuplicateError, bookDTO dtos.BookCreateDTO, options dtos.BookCreateOptionsDTO) {
+	if options.Merge == dtos.MergeIncrementQuantity {
+		book, err := bookHandler.bookService.AddCopies(c.Request.Context(), duplicate.ExistingID, bookDTO.Quantity)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Exemplares adicionados ao livro existente",
+			"book":    book,
+		})
+		return
+	}
+
+	response := gin.H{
+		"error":       duplicate.Error(),
+		"existing_id": duplicate.ExistingID,
+	}
+	// O livro existente pode estar retirado do acervo ou removido, e aí não aparece na busca pública;
+	// o conflito continua sendo a resposta, apenas sem os dados dele
+	if existing, err := bookHandler.bookService.GetByID(c.Request.Context(), duplicate.ExistingID); err == nil {
+		response["existing"] = existing
+	}
+
+	c.JSON(http.StatusConflict, response)
+}
+
 // Update atualiza os dados de um livro
 func (bookHandler *BookHandler) Update(c *gin.Context) {
 	idStr := c.Param("id")
@@ -82,9 +146,9 @@ func (bookHandler *BookHandler) Update(c *gin.Context) {
 		return
 	}
 
-	book, err := bookHandler.bookService.Update(uint(id), bookDTO)
+	book, err := bookHandler.bookService.Update(c.Request.Context(), uint(id), bookDTO)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
@@ -100,10 +164,153 @@ func (bookHandler *BookHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := bookHandler.bookService.Delete(uint(id)); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if err := bookHandler.bookService.Delete(c.Request.Context(), uint(id)); err != nil {
+		respondError(c, http.StatusBadRequest, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Livro removido com sucesso"})
 }
+
+// AdminList lista os livros para a administração, incluindo os retirados do acervo
+func (bookHandler *BookHandler) AdminList(c *gin.Context) {
+	var filterDTO dtos.AdminBookListFilterDTO
+	if err := c.ShouldBindQuery(&filterDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	books, err := bookHandler.bookService.AdminList(c.Request.Context(), filterDTO)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, books)
+}
+
+// Withdraw retira um livro do acervo
+func (bookHandler *BookHandler) Withdraw(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	book, err := bookHandler.bookService.Withdraw(c.Request.Context(), uint(id))
+	if err != nil {
+		respondError(c, http.StatusNotFound, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, book)
+}
+
+// Reinstate devolve ao catálogo um livro retirado do acervo
+func (bookHandler *BookHandler) Reinstate(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	book, err := bookHandler.bookService.Reinstate(c.Request.Context(), uint(id))
+	if err != nil {
+		respondError(c, http.StatusNotFound, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, book)
+}
+
+// ListDeleted lista os livros removidos
+func (bookHandler *BookHandler) ListDeleted(c *gin.Context) {
+	books, err := bookHandler.bookService.ListDeleted(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, books)
+}
+
+// Restore desfaz a remoção de um livro
+func (bookHandler *BookHandler) Restore(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	book, err := bookHandler.bookService.Restore(c.Request.Context(), uint(id))
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, book)
+}
+
+// Export exporta os livros que atendem aos filtros da listagem em CSV, JSON, MARC21 ou MARCXML.
+// O arquivo é enviado aos poucos, conforme os livros são lidos do banco.
+func (bookHandler *BookHandler) Export(c *gin.Context) {
+	var options dtos.BookExportOptionsDTO
+	if err := c.ShouldBindQuery(&options); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var filterDTO dtos.BookListFilterDTO
+	if err := c.ShouldBindQuery(&filterDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	format := options.Format
+	if format == "" {
+		format = dtos.ExportFormatCSV
+	}
+
+	filename := fmt.Sprintf("acervo-%s.%s", time.Now().Format("20060102"), export.FileExtension(format))
+	c.Header("Content-Type", export.ContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	extendConnectionDeadlines(c)
+
+	if err := bookHandler.bookService.Export(c.Request.Context(), filterDTO, format, c.Writer); err != nil {
+		if c.Writer.Written() {
+			// O envio já começou: não há como trocar o status, apenas interromper o arquivo
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Disposition")
+		respondError(c, http.StatusInternalServerError, err)
+	}
+}
+
+// Inventory confere os exemplares disponíveis de cada livro e unidade com os empréstimos em aberto,
+// sem alterar nada, e lista os contadores divergentes
+func (bookHandler *BookHandler) Inventory(c *gin.Context) {
+	report, err := bookHandler.bookService.ReconcileInventory(c.Request.Context(), false)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// Reconcile corrige os contadores de exemplares disponíveis que divergem dos empréstimos em aberto
+// e lista os valores anteriores
+func (bookHandler *BookHandler) Reconcile(c *gin.Context) {
+	report, err := bookHandler.bookService.ReconcileInventory(c.Request.Context(), true)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/services"
+)
+
+const (
+	// maxImportSize é o tamanho máximo aceito para um arquivo de importação
+	maxImportSize = 200 << 20
+	// syncImportLimit é o tamanho a partir do qual a importação é processada em segundo plano
+	syncImportLimit = 2 << 20
+)
+
+// BookImportHandler manipula as requisições de importação de livros em lote
+type BookImportHandler struct {
+	bookImportService services.BookImportService
+}
+
+// NewBookImportHandler cria uma nova instância de BookImportHandler
+func NewBookImportHandler(bookImportService services.BookImportService) *BookImportHandler {
+	return &BookImportHandler{
+		bookImportService: bookImportService,
+	}
+}
+
+// Import importa livros de um arquivo CSV ou JSON Lines enviado no corpo da requisição.
+// Arquivos pequenos são processados na hora e respondidos com o relatório linha a linha;
+// arquivos grandes, de tamanho desconhecido ou com ?async=true viram uma importação em segundo plano.
+func (bookImportHandler *BookImportHandler) Import(c *gin.Context) {
+	var options dtos.BookImportOptionsDTO
+	if err := c.ShouldBindQuery(&options); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	format := options.Format
+	if format == "" {
+		format = importFormatFromContentType(c.ContentType())
+	}
+	if format == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "informe o formato com ?format=csv|jsonl ou pelo Content-Type"})
+		return
+	}
+
+	extendConnectionDeadlines(c)
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	defer body.Close()
+
+	if !options.Async && c.Request.ContentLength >= 0 && c.Request.ContentLength <= syncImportLimit {
+		report, err := bookImportHandler.bookImportService.Import(c.Request.Context(), format, options.DryRun, body)
+		if err != nil && report == nil {
+			respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+		if err != nil {
+			// Os lotes anteriores à falha já foram gravados: o relatório acompanha o erro
+			c.JSON(errorStatus(http.StatusInternalServerError, err), report)
+			return
+		}
+
+		c.JSON(http.StatusOK, report)
+		return
+	}
+
+	// Guardar o arquivo em disco para processá-lo depois que a requisição terminar
+	path, err := spoolImportFile(body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	claims := jwt.ExtractClaims(c)
+	adminID := uint(claims["id"].(float64))
+
+	job, err := bookImportHandler.bookImportService.StartImport(c.Request.Context(), adminID, format, options.DryRun, path)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	statusURL := fmt.Sprintf("/api/admin/books/import/%d", job.ID)
+	c.Header("Location", statusURL)
+	c.JSON(http.StatusAccepted, gin.H{
+		"job":        job,
+		"status_url": statusURL,
+	})
+}
+
+// ListJobs lista as importações mais recentes
+func (bookImportHandler *BookImportHandler) ListJobs(c *gin.Context) {
+	jobs, err := bookImportHandler.bookImportService.ListJobs(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, jobs)
+}
+
+// GetJob busca o andamento de uma importação
+func (bookImportHandler *BookImportHandler) GetJob(c *gin.Context) {
+	idStr := c.Param("jobId")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	job, err := bookImportHandler.bookImportService.GetJob(c.Request.Context(), uint(id))
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// importFormatFromContentType identifica o formato de importação pelo Content-Type
+func importFormatFromContentType(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = strings.ToLower(contentType)
+	}
+
+	switch mediaType {
+	case "text/csv", "application/csv":
+		return dtos.ImportFormatCSV
+	case "application/x-ndjson", "application/jsonl", "application/x-jsonlines":
+		return dtos.ImportFormatJSONL
+	}
+	return ""
+}
+
+// spoolImportFile grava o corpo da requisição em um arquivo temporário e retorna o caminho
+func spoolImportFile(body io.Reader) (string, error) {
+	file, err := os.CreateTemp("", "book-import-*")
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, body); err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("falha ao receber o arquivo: %w", err)
+	}
+
+	return file.Name(), nil
+}
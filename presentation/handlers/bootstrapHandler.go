@@ -0,0 +1,38 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/services"
+)
+
+// BootstrapHandler manipula a criação do primeiro administrador
+type BootstrapHandler struct {
+	bootstrapService services.BootstrapService
+}
+
+// NewBootstrapHandler cria uma nova instância de BootstrapHandler
+func NewBootstrapHandler(bootstrapService services.BootstrapService) *BootstrapHandler {
+	return &BootstrapHandler{
+		bootstrapService: bootstrapService,
+	}
+}
+
+// Setup cria o primeiro administrador com o token de instalação exibido na inicialização do servidor
+func (bootstrapHandler *BootstrapHandler) Setup(c *gin.Context) {
+	var setupDTO dtos.SetupDTO
+	if err := c.ShouldBindJSON(&setupDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	admin, err := bootstrapHandler.bootstrapService.CompleteSetup(c.Request.Context(), setupDTO)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, admin)
+}
@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/services"
+)
+
+// BranchHandler manipula as requisições relacionadas a unidades e transferências
+type BranchHandler struct {
+	branchService services.BranchService
+}
+
+// NewBranchHandler cria uma nova instância de BranchHandler
+func NewBranchHandler(branchService services.BranchService) *BranchHandler {
+	return &BranchHandler{
+		branchService: branchService,
+	}
+}
+
+// List lista todas as unidades
+func (branchHandler *BranchHandler) List(c *gin.Context) {
+	branches, err := branchHandler.branchService.List(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, branches)
+}
+
+// GetByID busca uma unidade pelo ID
+func (branchHandler *BranchHandler) GetByID(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	branch, err := branchHandler.branchService.GetByID(c.Request.Context(), uint(id))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, branch)
+}
+
+// Create adiciona uma nova unidade
+func (branchHandler *BranchHandler) Create(c *gin.Context) {
+	var branchDTO dtos.BranchCreateDTO
+	if err := c.ShouldBindJSON(&branchDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	branch, err := branchHandler.branchService.Create(c.Request.Context(), branchDTO)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, branch)
+}
+
+// Update atualiza os dados de uma unidade
+func (branchHandler *BranchHandler) Update(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var branchDTO dtos.BranchUpdateDTO
+	if err := c.ShouldBindJSON(&branchDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	branch, err := branchHandler.branchService.Update(c.Request.Context(), uint(id), branchDTO)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, branch)
+}
+
+// Delete remove uma unidade
+func (branchHandler *BranchHandler) Delete(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	if err := branchHandler.branchService.Delete(c.Request.Context(), uint(id)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Unidade removida com sucesso"})
+}
+
+// SetHolding define quantos exemplares de um livro ficam em uma unidade
+func (branchHandler *BranchHandler) SetHolding(c *gin.Context) {
+	bookID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+	branchID, err := strconv.ParseUint(c.Param("branchId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID da unidade inválido"})
+		return
+	}
+
+	var holdingDTO dtos.HoldingUpdateDTO
+	if err := c.ShouldBindJSON(&holdingDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	book, err := branchHandler.branchService.SetHolding(c.Request.Context(), uint(bookID), uint(branchID), holdingDTO)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, book)
+}
+
+// ListTransfers lista as transferências das unidades do usuário da equipe
+func (branchHandler *BranchHandler) ListTransfers(c *gin.Context) {
+	claims := jwt.ExtractClaims(c)
+	staffID := uint(claims["id"].(float64))
+
+	transfers, err := branchHandler.branchService.ListTransfers(c.Request.Context(), staffID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, transfers)
+}
+
+// RequestTransfer solicita uma transferência de exemplares entre unidades
+func (branchHandler *BranchHandler) RequestTransfer(c *gin.Context) {
+	claims := jwt.ExtractClaims(c)
+	staffID := uint(claims["id"].(float64))
+
+	var transferDTO dtos.TransferCreateDTO
+	if err := c.ShouldBindJSON(&transferDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	transfer, err := branchHandler.branchService.RequestTransfer(c.Request.Context(), staffID, transferDTO)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, transfer)
+}
+
+// ApproveTransfer aprova uma transferência e move os exemplares
+func (branchHandler *BranchHandler) ApproveTransfer(c *gin.Context) {
+	claims := jwt.ExtractClaims(c)
+	staffID := uint(claims["id"].(float64))
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	transfer, err := branchHandler.branchService.ApproveTransfer(c.Request.Context(), uint(id), staffID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
+
+// RejectTransfer recusa uma transferência pendente
+func (branchHandler *BranchHandler) RejectTransfer(c *gin.Context) {
+	claims := jwt.ExtractClaims(c)
+	staffID := uint(claims["id"].(float64))
+
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	transfer, err := branchHandler.branchService.RejectTransfer(c.Request.Context(), uint(id), staffID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, transfer)
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/services"
+)
+
+// DataExportHandler manipula as requisições de exportação de dados pessoais
+type DataExportHandler struct {
+	dataExportService services.DataExportService
+}
+
+// NewDataExportHandler cria uma nova instância de DataExportHandler
+func NewDataExportHandler(dataExportService services.DataExportService) *DataExportHandler {
+	return &DataExportHandler{
+		dataExportService: dataExportService,
+	}
+}
+
+// Export exporta os dados pessoais do usuário logado em ZIP (padrão) ou JSON.
+// Históricos grandes, ou com ?async=true, são gerados em segundo plano e baixados depois por um link temporário.
+func (dataExportHandler *DataExportHandler) Export(c *gin.Context) {
+	claims := jwt.ExtractClaims(c)
+	userID := uint(claims["id"].(float64))
+
+	var options dtos.DataExportOptionsDTO
+	if err := c.ShouldBindQuery(&options); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	format := options.Format
+	if format == "" {
+		format = dtos.DataExportFormatZIP
+	}
+
+	async := options.Async
+	if !async {
+		requiresAsync, err := dataExportHandler.dataExportService.RequiresAsync(c.Request.Context(), userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		async = requiresAsync
+	}
+
+	if async {
+		export, err := dataExportHandler.dataExportService.StartExport(c.Request.Context(), userID, format)
+		if err != nil {
+			respondError(c, http.StatusInternalServerError, err)
+			return
+		}
+
+		statusURL := fmt.Sprintf("/api/users/me/exports/%d", export.ID)
+		c.Header("Location", statusURL)
+		c.JSON(http.StatusAccepted, gin.H{
+			"export":     export,
+			"status_url": statusURL,
+		})
+		return
+	}
+
+	contentType := "application/zip"
+	if format == dtos.DataExportFormatJSON {
+		contentType = "application/json; charset=utf-8"
+	}
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", dataExportFileName(time.Now(), format)))
+	extendConnectionDeadlines(c)
+
+	if err := dataExportHandler.dataExportService.WriteBundle(c.Request.Context(), userID, format, c.Writer); err != nil {
+		if c.Writer.Written() {
+			_ = c.Error(err)
+			c.Abort()
+			return
+		}
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Disposition")
+		respondError(c, http.StatusInternalServerError, err)
+	}
+}
+
+// ListExports lista as exportações do usuário logado
+func (dataExportHandler *DataExportHandler) ListExports(c *gin.Context) {
+	claims := jwt.ExtractClaims(c)
+	userID := uint(claims["id"].(float64))
+
+	exports, err := dataExportHandler.dataExportService.ListExports(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, exports)
+}
+
+// GetExport busca o andamento de uma exportação do usuário logado
+func (dataExportHandler *DataExportHandler) GetExport(c *gin.Context) {
+	claims := jwt.ExtractClaims(c)
+	userID := uint(claims["id"].(float64))
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	export, err := dataExportHandler.dataExportService.GetExport(c.Request.Context(), userID, uint(id))
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, export)
+}
+
+// Download envia o arquivo de uma exportação a partir do link assinado
+func (dataExportHandler *DataExportHandler) Download(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "link de download inválido"})
+		return
+	}
+
+	export, err := dataExportHandler.dataExportService.OpenDownload(c.Request.Context(), uint(id), expires, c.Query("signature"))
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	extendConnectionDeadlines(c)
+	c.FileAttachment(export.FilePath, dataExportFileName(*export.CompletedAt, export.Format))
+}
+
+// dataExportFileName monta o nome do arquivo entregue ao usuário
+func dataExportFileName(generatedAt time.Time, format string) string {
+	return fmt.Sprintf("meus-dados-%s.%s", generatedAt.Format("20060102"), format)
+}
@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	domainerrors "github.com/henrygoeszanin/api_golang_estudos/domain/errors"
+)
+
+// respondError responde com o status correspondente ao erro de domínio ou, se não houver, com o status padrão
+func respondError(c *gin.Context, defaultStatus int, err error) {
+	status := errorStatus(defaultStatus, err)
+	if status == http.StatusGatewayTimeout {
+		c.JSON(status, gin.H{"error": "tempo limite da requisição excedido"})
+		return
+	}
+
+	c.JSON(status, gin.H{"error": err.Error()})
+}
+
+// errorStatus retorna o status correspondente ao erro de domínio ou, se não houver, o status padrão
+func errorStatus(defaultStatus int, err error) int {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.Is(err, domainerrors.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domainerrors.ErrAlreadyExists), errors.Is(err, domainerrors.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, domainerrors.ErrInvalidData):
+		return http.StatusBadRequest
+	case errors.Is(err, domainerrors.ErrUnauthorized):
+		return http.StatusUnauthorized
+	case errors.Is(err, domainerrors.ErrForbidden):
+		return http.StatusForbidden
+	}
+	return defaultStatus
+}
@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/services"
+)
+
+// HealthHandler manipula as verificações de saúde consultadas pelo orquestrador e pelo balanceador
+type HealthHandler struct {
+	healthService services.HealthService
+}
+
+// NewHealthHandler cria uma nova instância de HealthHandler
+func NewHealthHandler(healthService services.HealthService) *HealthHandler {
+	return &HealthHandler{
+		healthService: healthService,
+	}
+}
+
+// Live responde se o processo está em execução
+func (healthHandler *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, healthHandler.healthService.Live(c.Request.Context()))
+}
+
+// Ready responde se a aplicação pode receber tráfego, com a situação e a latência de cada dependência.
+// Retorna 503 quando alguma dependência falha ou quando o servidor está em encerramento.
+func (healthHandler *HealthHandler) Ready(c *gin.Context) {
+	report, ready := healthHandler.healthService.Ready(c.Request.Context())
+	if !ready {
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
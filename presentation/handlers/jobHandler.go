@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/gin-gonic/gin"
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/services"
+)
+
+// JobHandler manipula as requisições de consulta e execução das tarefas agendadas
+type JobHandler struct {
+	jobService services.JobService
+}
+
+// NewJobHandler cria uma nova instância de JobHandler
+func NewJobHandler(jobService services.JobService) *JobHandler {
+	return &JobHandler{
+		jobService: jobService,
+	}
+}
+
+// List lista as tarefas agendadas com o próximo horário previsto e a última execução
+func (jobHandler *JobHandler) List(c *gin.Context) {
+	jobs, err := jobHandler.jobService.List(c.Request.Context())
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, jobs)
+}
+
+// ListRuns lista o histórico de execuções, com filtros por tarefa e situação na query string
+func (jobHandler *JobHandler) ListRuns(c *gin.Context) {
+	var filterDTO dtos.JobRunFilterDTO
+	if err := c.ShouldBindQuery(&filterDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	runs, err := jobHandler.jobService.ListRuns(c.Request.Context(), filterDTO)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, runs)
+}
+
+// Run executa a tarefa imediatamente. A resposta traz a execução iniciada; o resultado aparece no histórico.
+func (jobHandler *JobHandler) Run(c *gin.Context) {
+	name := c.Param("name")
+
+	run, err := jobHandler.jobService.Run(c.Request.Context(), name)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.Header("Location", "/api/admin/jobs/runs?job="+url.QueryEscape(name))
+	c.JSON(http.StatusAccepted, run)
+}
@@ -28,7 +28,7 @@ func (loalHandler *LoanHandler) List(c *gin.Context) {
 	claims := jwt.ExtractClaims(c)
 	userID := uint(claims["id"].(float64))
 
-	loans, err := loalHandler.loanService.ListByUser(userID)
+	loans, err := loalHandler.loanService.ListByUser(c.Request.Context(), userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -50,7 +50,7 @@ func (loalHandler *LoanHandler) Create(c *gin.Context) {
 		return
 	}
 
-	loan, err := loalHandler.loanService.Create(userID, loanDTO)
+	loan, err := loalHandler.loanService.Create(c.Request.Context(), userID, loanDTO)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -73,7 +73,7 @@ func (loalHandler *LoanHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	loan, err := loalHandler.loanService.GetByID(uint(id), userID)
+	loan, err := loalHandler.loanService.GetByID(c.Request.Context(), uint(id), userID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -96,7 +96,7 @@ func (loalHandler *LoanHandler) ReturnLoan(c *gin.Context) {
 		return
 	}
 
-	loan, err := loalHandler.loanService.ReturnLoan(uint(id), userID)
+	loan, err := loalHandler.loanService.ReturnLoan(c.Request.Context(), uint(id), userID)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -107,3 +107,49 @@ func (loalHandler *LoanHandler) ReturnLoan(c *gin.Context) {
 		"loan":    loan,
 	})
 }
+
+// CheckOut registra um empréstimo no balcão da unidade
+func (loalHandler *LoanHandler) CheckOut(c *gin.Context) {
+	// Obter ID do bibliotecário das claims do JWT
+	claims := jwt.ExtractClaims(c)
+	staffID := uint(claims["id"].(float64))
+
+	var loanDTO dtos.StaffLoanCreateDTO
+	if err := c.ShouldBindJSON(&loanDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	loan, err := loalHandler.loanService.CheckOut(c.Request.Context(), staffID, loanDTO)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, loan)
+}
+
+// CheckIn registra no balcão a devolução de um empréstimo
+func (loalHandler *LoanHandler) CheckIn(c *gin.Context) {
+	// Obter ID do bibliotecário das claims do JWT
+	claims := jwt.ExtractClaims(c)
+	staffID := uint(claims["id"].(float64))
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	loan, err := loalHandler.loanService.CheckIn(c.Request.Context(), uint(id), staffID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Livro devolvido com sucesso",
+		"loan":    loan,
+	})
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/services"
+)
+
+// NotificationHandler manipula as requisições da caixa de entrada e das preferências de notificação
+type NotificationHandler struct {
+	notificationService services.NotificationService
+}
+
+// NewNotificationHandler cria uma nova instância de NotificationHandler
+func NewNotificationHandler(notificationService services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+	}
+}
+
+// Inbox lista as notificações do usuário logado, com a contagem de não lidas
+func (notificationHandler *NotificationHandler) Inbox(c *gin.Context) {
+	claims := jwt.ExtractClaims(c)
+	userID := uint(claims["id"].(float64))
+
+	var filterDTO dtos.NotificationFilterDTO
+	if err := c.ShouldBindQuery(&filterDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	inbox, err := notificationHandler.notificationService.Inbox(c.Request.Context(), userID, filterDTO)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, inbox)
+}
+
+// MarkRead marca uma notificação do usuário logado como lida
+func (notificationHandler *NotificationHandler) MarkRead(c *gin.Context) {
+	claims := jwt.ExtractClaims(c)
+	userID := uint(claims["id"].(float64))
+
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	if err := notificationHandler.notificationService.MarkRead(c.Request.Context(), userID, uint(id)); err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notificação marcada como lida"})
+}
+
+// MarkAllRead marca todas as notificações do usuário logado como lidas
+func (notificationHandler *NotificationHandler) MarkAllRead(c *gin.Context) {
+	claims := jwt.ExtractClaims(c)
+	userID := uint(claims["id"].(float64))
+
+	marked, err := notificationHandler.notificationService.MarkAllRead(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"marked": marked})
+}
+
+// GetPreferences retorna os canais de notificação do usuário logado
+func (notificationHandler *NotificationHandler) GetPreferences(c *gin.Context) {
+	claims := jwt.ExtractClaims(c)
+	userID := uint(claims["id"].(float64))
+
+	preferences, err := notificationHandler.notificationService.GetPreferences(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
+
+// UpdatePreferences liga ou desliga os canais de notificação do usuário logado
+func (notificationHandler *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	claims := jwt.ExtractClaims(c)
+	userID := uint(claims["id"].(float64))
+
+	var preferencesDTO dtos.NotificationPreferencesUpdateDTO
+	if err := c.ShouldBindJSON(&preferencesDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	preferences, err := notificationHandler.notificationService.UpdatePreferences(c.Request.Context(), userID, preferencesDTO)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, preferences)
+}
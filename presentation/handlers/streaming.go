@@ -0,0 +1,18 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// extendConnectionDeadlines alinha os limites de leitura e escrita da conexão ao prazo da requisição.
+// Os limites do servidor valem para a resposta inteira e cortariam a transmissão de arquivos grandes;
+// sem prazo na requisição, os limites são removidos.
+func extendConnectionDeadlines(c *gin.Context) {
+	deadline, _ := c.Request.Context().Deadline()
+	controller := http.NewResponseController(c.Writer)
+	// Nem toda conexão permite ajustar os limites (ex.: gravadores de teste); nesse caso valem os do servidor
+	_ = controller.SetReadDeadline(deadline)
+	_ = controller.SetWriteDeadline(deadline)
+}
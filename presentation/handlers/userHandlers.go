@@ -32,7 +32,7 @@ func (userHandler *UserHandler) Register(c *gin.Context) {
 		return
 	}
 
-	createdUser, err := userHandler.userService.Create(userDTO)
+	createdUser, err := userHandler.userService.Create(c.Request.Context(), userDTO)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -50,7 +50,7 @@ func (userHandler *UserHandler) GetByID(c *gin.Context) {
 		return
 	}
 
-	user, err := userHandler.userService.GetByID(uint(id))
+	user, err := userHandler.userService.GetByID(c.Request.Context(), uint(id))
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -74,7 +74,7 @@ func (userHandler *UserHandler) Update(c *gin.Context) {
 		return
 	}
 
-	user, err := userHandler.userService.Update(uint(id), userDTO)
+	user, err := userHandler.userService.Update(c.Request.Context(), uint(id), userDTO)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -92,7 +92,7 @@ func (userHandler *UserHandler) Delete(c *gin.Context) {
 		return
 	}
 
-	if err := userHandler.userService.Delete(uint(id)); err != nil {
+	if err := userHandler.userService.Delete(c.Request.Context(), uint(id)); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
@@ -102,7 +102,7 @@ func (userHandler *UserHandler) Delete(c *gin.Context) {
 
 // List lista todos os usuários
 func (userHandler *UserHandler) List(c *gin.Context) {
-	users, err := userHandler.userService.List()
+	users, err := userHandler.userService.List(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -120,7 +120,7 @@ func (userHandler *UserHandler) PromoteToAdmin(c *gin.Context) {
 		return
 	}
 
-	user, err := userHandler.userService.PromoteToAdmin(uint(id))
+	user, err := userHandler.userService.PromoteToAdmin(c.Request.Context(), uint(id))
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -159,7 +159,7 @@ func (userHandler *UserHandler) GetMe(c *gin.Context) {
 	}
 
 	// Buscar o usuário no serviço
-	user, err := userHandler.userService.GetByID(userID)
+	user, err := userHandler.userService.GetByID(c.Request.Context(), userID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -182,7 +182,7 @@ func (userHandler *UserHandler) UpdateMe(c *gin.Context) {
 	}
 
 	// Atualizar o usuário
-	updatedUser, err := userHandler.userService.Update(userID, userDTO)
+	updatedUser, err := userHandler.userService.Update(c.Request.Context(), userID, userDTO)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -190,3 +190,92 @@ func (userHandler *UserHandler) UpdateMe(c *gin.Context) {
 
 	c.JSON(http.StatusOK, updatedUser)
 }
+
+// DeleteMe atende ao pedido de exclusão da conta do usuário logado.
+// O acesso é encerrado na hora e os dados pessoais são apagados ao fim do prazo de carência.
+func (userHandler *UserHandler) DeleteMe(c *gin.Context) {
+	claims := jwt.ExtractClaims(c)
+	userID := uint(claims["id"].(float64))
+
+	user, err := userHandler.userService.RequestDeletion(c.Request.Context(), userID)
+	if err != nil {
+		respondError(c, http.StatusBadRequest, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":              "Conta removida. Os dados pessoais serão apagados ao fim do prazo de carência",
+		"erasure_scheduled_at": user.ErasureScheduledAt,
+	})
+}
+
+// ListDeleted lista os usuários removidos
+func (userHandler *UserHandler) ListDeleted(c *gin.Context) {
+	users, err := userHandler.userService.ListDeleted(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+// Restore desfaz a remoção de um usuário
+func (userHandler *UserHandler) Restore(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	user, err := userHandler.userService.Restore(c.Request.Context(), uint(id))
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// Erase apaga os dados pessoais de um usuário, mantendo o histórico de empréstimos
+func (userHandler *UserHandler) Erase(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	user, err := userHandler.userService.Erase(c.Request.Context(), uint(id))
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
+
+// AssignBranch vincula um usuário a uma unidade como bibliotecário
+func (userHandler *UserHandler) AssignBranch(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "ID inválido"})
+		return
+	}
+
+	var branchDTO dtos.UserBranchDTO
+	if err := c.ShouldBindJSON(&branchDTO); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := userHandler.userService.AssignBranch(c.Request.Context(), uint(id), branchDTO)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, user)
+}
@@ -0,0 +1,167 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/services"
+	"github.com/henrygoeszanin/api_golang_estudos/logging"
+)
+
+// auditMaxCapturedBody limita quanto da resposta é guardado para descobrir o alvo de uma criação
+const auditMaxCapturedBody = 1 << 20
+
+// Audit registra na trilha de auditoria toda requisição que altera estado (POST, PUT, PATCH, DELETE).
+// O alvo é identificado pelo parâmetro :id da rota ou, nas criações, pelo campo "id" da resposta.
+// Deve ser usado depois do middleware de autenticação, para que o autor da ação seja conhecido.
+func Audit(auditService services.AuditService, targetType string) gin.HandlerFunc {
+	return audit(auditService, targetType, func(c *gin.Context, actorID *uint) string {
+		return c.Param("id")
+	})
+}
+
+// AuditSelf funciona como Audit, mas considera como alvo o próprio usuário autenticado.
+// Usado nas rotas /me, que não trazem o ID na URL.
+func AuditSelf(auditService services.AuditService) gin.HandlerFunc {
+	return audit(auditService, "user", func(c *gin.Context, actorID *uint) string {
+		if actorID == nil {
+			return ""
+		}
+		return strconv.FormatUint(uint64(*actorID), 10)
+	})
+}
+
+// audit monta o middleware de auditoria com a regra de identificação do alvo informada
+func audit(auditService services.AuditService, targetType string, targetFromRequest func(c *gin.Context, actorID *uint) string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodGet, http.MethodHead, http.MethodOptions:
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		actorID := auditActor(c)
+		targetID := targetFromRequest(c, actorID)
+
+		var before interface{}
+		if targetID != "" {
+			snapshot, err := auditService.Snapshot(ctx, targetType, targetID)
+			if err != nil {
+				logging.FromContext(ctx).Error("auditoria: falha ao capturar o estado anterior", "target_type", targetType, "target_id", targetID, "error", err)
+			}
+			before = snapshot
+		}
+
+		writer := &auditResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		// A ação já aconteceu: o registro deve ser gravado mesmo que o cliente tenha desistido da resposta
+		ctx = context.WithoutCancel(ctx)
+		if targetID == "" {
+			targetID = createdTargetID(writer.body.Bytes(), targetType)
+		}
+
+		var after interface{}
+		if targetID != "" {
+			snapshot, err := auditService.Snapshot(ctx, targetType, targetID)
+			if err != nil {
+				logging.FromContext(ctx).Error("auditoria: falha ao capturar o estado posterior", "target_type", targetType, "target_id", targetID, "error", err)
+			}
+			after = snapshot
+		}
+
+		action := c.Request.Method + " " + c.FullPath()
+		err := auditService.Record(ctx, dtos.AuditRecordDTO{
+			ActorID:    actorID,
+			Action:     action,
+			TargetType: targetType,
+			TargetID:   targetID,
+			StatusCode: writer.Status(),
+			Before:     before,
+			After:      after,
+			IP:         c.ClientIP(),
+			UserAgent:  c.Request.UserAgent(),
+			RequestID:  logging.RequestID(ctx),
+		})
+		if err != nil {
+			// A ação já foi concluída; a falha no registro não deve mudar a resposta
+			logging.FromContext(ctx).Error("auditoria: falha ao registrar a ação", "action", action, "error", err)
+		}
+	}
+}
+
+// auditActor extrai o ID do autor da ação das claims do token. O e-mail não é gravado: a trilha não pode
+// ser alterada, e ele precisa sumir quando os dados pessoais do usuário são apagados.
+func auditActor(c *gin.Context) *uint {
+	claims := jwt.ExtractClaims(c)
+	idFloat, ok := claims["id"].(float64)
+	if !ok {
+		return nil
+	}
+	id := uint(idFloat)
+	return &id
+}
+
+// createdTargetID procura o ID do registro criado no corpo da resposta.
+// Aceita o ID no nível principal ou dentro de um objeto com o nome do tipo do alvo.
+func createdTargetID(body []byte, targetType string) string {
+	var response map[string]json.RawMessage
+	if err := json.Unmarshal(body, &response); err != nil {
+		return ""
+	}
+
+	if id := jsonID(response["id"]); id != "" {
+		return id
+	}
+
+	var nested map[string]json.RawMessage
+	if err := json.Unmarshal(response[targetType], &nested); err != nil {
+		return ""
+	}
+	return jsonID(nested["id"])
+}
+
+// jsonID converte um ID numérico em JSON para texto
+func jsonID(raw json.RawMessage) string {
+	var id uint64
+	if err := json.Unmarshal(raw, &id); err != nil || id == 0 {
+		return ""
+	}
+	return strconv.FormatUint(id, 10)
+}
+
+// auditResponseWriter guarda uma cópia do início da resposta enquanto ela é enviada
+type auditResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+// Write envia os dados ao cliente e guarda uma cópia, até o limite definido
+func (writer *auditResponseWriter) Write(data []byte) (int, error) {
+	if remaining := auditMaxCapturedBody - writer.body.Len(); remaining > 0 {
+		if len(data) < remaining {
+			remaining = len(data)
+		}
+		writer.body.Write(data[:remaining])
+	}
+	return writer.ResponseWriter.Write(data)
+}
+
+// Unwrap devolve o writer original, para que os ajustes de conexão (ex.: http.ResponseController) o alcancem
+func (writer *auditResponseWriter) Unwrap() http.ResponseWriter {
+	return writer.ResponseWriter
+}
+
+// WriteString envia o texto ao cliente e guarda uma cópia, até o limite definido
+func (writer *auditResponseWriter) WriteString(data string) (int, error) {
+	return writer.Write([]byte(data))
+}
@@ -1,7 +1,9 @@
 package middlewares
 
 import (
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"strings"
 	"time"
@@ -11,8 +13,11 @@ import (
 	jwttoken "github.com/golang-jwt/jwt/v4" // Adicione esta importação
 
 	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/metrics"
 	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/services"
 	"github.com/henrygoeszanin/api_golang_estudos/config"
+	domainerrors "github.com/henrygoeszanin/api_golang_estudos/domain/errors"
+	"github.com/henrygoeszanin/api_golang_estudos/logging"
 )
 
 // TokenExtractor é um middleware que extrai o token de diferentes fontes
@@ -41,21 +46,20 @@ func TokenExtractor() gin.HandlerFunc {
 
 		if token != "" {
 			c.Request.Header.Set("Authorization", "Bearer "+token)
-			fmt.Printf("TokenExtractor: Token encontrado\n")
-		} else {
-			fmt.Printf("TokenExtractor: Nenhum token encontrado\n")
 		}
 
 		c.Next()
 	}
 }
 
+// identityKey é a chave do contexto com o usuário autenticado, recarregado do banco a cada requisição
+const identityKey = "id"
+
 // AdminRequired verifica se o usuário é administrador
 func AdminRequired() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		claims := jwt.ExtractClaims(c)
-		isAdmin, exists := claims["is_admin"]
-		if !exists || isAdmin != true {
+		user, ok := currentUser(c)
+		if !ok || !user.IsAdmin {
 			c.JSON(http.StatusForbidden, gin.H{
 				"code":    http.StatusForbidden,
 				"message": "Acesso restrito a administradores",
@@ -67,6 +71,94 @@ func AdminRequired() gin.HandlerFunc {
 	}
 }
 
+// StaffRequired verifica se o usuário é administrador ou bibliotecário.
+// A restrição por unidade é aplicada pelos serviços.
+func StaffRequired() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := currentUser(c)
+		if !ok || (!user.IsAdmin && !user.IsLibrarian) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"code":    http.StatusForbidden,
+				"message": "Acesso restrito à equipe da biblioteca",
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// currentUser retorna o usuário autenticado. Os papéis vêm do banco, não das claims do token,
+// para que um administrador rebaixado perca o acesso sem esperar o token expirar.
+func currentUser(c *gin.Context) (*dtos.UserResponseDTO, bool) {
+	value, exists := c.Get(identityKey)
+	if !exists {
+		return nil, false
+	}
+	user, ok := value.(*dtos.UserResponseDTO)
+	return user, ok && user != nil
+}
+
+// authFailureKey guarda no contexto o motivo pelo qual um token válido foi recusado
+const authFailureKey = "auth_failure"
+
+// errInvalidIdentity indica um token assinado sem as claims esperadas
+var errInvalidIdentity = fmt.Errorf("%w: token sem a identificação do usuário", domainerrors.ErrUnauthorized)
+
+// RefreshHandler renova o token apenas enquanto o usuário continuar ativo, com os papéis atuais.
+// O RefreshHandler do gin-jwt copia as claims do token antigo sem consultar o usuário.
+func RefreshHandler(authMiddleware *jwt.GinJWTMiddleware, userService services.UserService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims, err := authMiddleware.CheckIfTokenExpire(c)
+		if err != nil {
+			refuse(c, authMiddleware, http.StatusUnauthorized, authMiddleware.HTTPStatusMessageFunc(err, c))
+			return
+		}
+
+		user, err := activeUser(c, userService, claims)
+		if err != nil {
+			c.Set(authFailureKey, err)
+			refuse(c, authMiddleware, http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		// O novo token leva os papéis atuais do usuário, não os do token antigo
+		token, expire, err := authMiddleware.TokenGenerator(user)
+		if err != nil {
+			refuse(c, authMiddleware, http.StatusUnauthorized, authMiddleware.HTTPStatusMessageFunc(jwt.ErrFailedTokenCreation, c))
+			return
+		}
+		authMiddleware.SetCookie(c, token)
+		authMiddleware.RefreshResponse(c, http.StatusOK, token, expire)
+	}
+}
+
+// activeUser busca o dono do token, recusando usuários removidos ou apagados
+func activeUser(c *gin.Context, userService services.UserService, claims jwttoken.MapClaims) (*dtos.UserResponseDTO, error) {
+	id, ok := claims["id"].(float64)
+	if !ok {
+		return nil, errInvalidIdentity
+	}
+
+	return userService.GetByID(c.Request.Context(), uint(id))
+}
+
+// refuse encerra a requisição com a resposta de autenticação recusada do middleware
+func refuse(c *gin.Context, authMiddleware *jwt.GinJWTMiddleware, code int, message string) {
+	c.Header("WWW-Authenticate", "JWT realm="+authMiddleware.Realm)
+	c.Abort()
+	authMiddleware.Unauthorized(c, code, message)
+}
+
+// authFailure converte o motivo da recusa guardado no contexto em status e mensagem.
+// Usuários removidos depois do login deixam de ser autenticados; falhas na consulta não são culpa do token.
+func authFailure(err error) (int, string) {
+	if errors.Is(err, domainerrors.ErrNotFound) || errors.Is(err, domainerrors.ErrUnauthorized) {
+		return http.StatusUnauthorized, err.Error()
+	}
+	return http.StatusInternalServerError, "erro ao verificar o usuário do token"
+}
+
 // Estrutura para o login
 type login struct {
 	Email    string `json:"email" binding:"required,email"`
@@ -74,22 +166,22 @@ type login struct {
 }
 
 // SetupJWTMiddleware configura o middleware JWT
-func SetupJWTMiddleware(userService services.UserService, cfg *config.Config) (*jwt.GinJWTMiddleware, error) {
+func SetupJWTMiddleware(userService services.UserService, cfg *config.Config, recorder metrics.Recorder) (*jwt.GinJWTMiddleware, error) {
 	return jwt.New(&jwt.GinJWTMiddleware{
 		Realm:       "library-api",
 		Key:         []byte(cfg.JWTSecret),
-		Timeout:     time.Hour * 24,
-		MaxRefresh:  time.Hour * 24 * 7,
-		IdentityKey: "id",
+		Timeout:     time.Duration(cfg.JWTTimeoutHours) * time.Hour,
+		MaxRefresh:  time.Duration(cfg.JWTMaxRefreshHours) * time.Hour,
+		IdentityKey: identityKey,
 
 		// Configurações de cookies
 		SendCookie:     true,
 		CookieName:     "jwt",
-		CookieMaxAge:   24 * time.Hour,
-		CookieDomain:   "",
-		SecureCookie:   false,
-		CookieHTTPOnly: true,
-		CookieSameSite: http.SameSiteDefaultMode,
+		CookieMaxAge:   time.Duration(cfg.JWTTimeoutHours) * time.Hour,
+		CookieDomain:   cfg.CookieDomain,
+		SecureCookie:   cfg.CookieSecure,
+		CookieHTTPOnly: cfg.CookieHTTPOnly,
+		CookieSameSite: cookieSameSite(cfg.CookieSameSite),
 
 		// Configuração do token
 		TokenLookup:   "cookie:jwt,header:Authorization",
@@ -103,68 +195,56 @@ func SetupJWTMiddleware(userService services.UserService, cfg *config.Config) (*
 				return nil, jwt.ErrMissingLoginValues
 			}
 
-			fmt.Printf("Login - Tentativa para email: %s\n", loginVals.Email)
-
-			user, err := userService.AuthenticateUser(loginVals.Email, loginVals.Password)
+			ctx := c.Request.Context()
+			user, err := userService.AuthenticateUser(ctx, loginVals.Email, loginVals.Password)
 			if err != nil {
-				fmt.Printf("Login - Falha: %v\n", err)
+				logging.FromContext(ctx).Warn("falha no login", "error", err)
+				recorder.LoginFailed()
 				return nil, jwt.ErrFailedAuthentication
 			}
+			recorder.LoginSucceeded()
 
-			fmt.Printf("Login - Sucesso para usuário: %s (ID: %d)\n", user.Email, user.ID)
-			fmt.Printf("Tipo do usuário retornado: %T\n", user)
+			logging.FromContext(ctx).Info("login realizado", "user_id", user.ID)
 
 			// Garantindo que retornamos explicitamente um *dtos.UserResponseDTO
 			userDTO := &dtos.UserResponseDTO{
-				ID:      user.ID,
-				Email:   user.Email,
-				IsAdmin: user.IsAdmin,
+				ID:          user.ID,
+				Email:       user.Email,
+				IsAdmin:     user.IsAdmin,
+				IsLibrarian: user.IsLibrarian,
+				BranchID:    user.BranchID,
 			}
 
-			fmt.Printf("Criando token com user info: ID=%d, Email=%s, IsAdmin=%v\n",
-				userDTO.ID, userDTO.Email, userDTO.IsAdmin)
-
 			return userDTO, nil
 		},
 
 		// Função para gerar o payload do token
 		PayloadFunc: func(data interface{}) jwttoken.MapClaims {
-			fmt.Printf("PayloadFunc recebeu dados do tipo: %T\n", data)
-
-			// Tente converter para UserResponseDTO
 			if user, ok := data.(*dtos.UserResponseDTO); ok {
-				fmt.Printf("Convertido com sucesso para UserResponseDTO: ID=%d, Email=%s\n",
-					user.ID, user.Email)
-
 				return jwttoken.MapClaims{
-					"id":       user.ID,
-					"email":    user.Email,
-					"is_admin": user.IsAdmin,
+					"id":           user.ID,
+					"email":        user.Email,
+					"is_admin":     user.IsAdmin,
+					"is_librarian": user.IsLibrarian,
+					"branch_id":    user.BranchID,
 				}
 			}
 
-			// Se falhar, tente converter direto do objeto retornado pelo serviço
-			// Assumindo que o objeto tem campos ID, Email e IsAdmin
-			fmt.Printf("AVISO: Falha na conversão para UserResponseDTO. Tentando alternativas...\n")
-
-			// Adicione detalhes sobre o objeto recebido para debug
-			fmt.Printf("Conteúdo do objeto recebido: %+v\n", data)
-
+			slog.Error("dados inesperados ao gerar o token", "type", fmt.Sprintf("%T", data))
 			return jwttoken.MapClaims{}
 		},
 
 		// Função para extrair a identidade do token
 		IdentityHandler: func(c *gin.Context) interface{} {
 			claims := jwt.ExtractClaims(c)
-			fmt.Printf("IdentityHandler - Claims recebidas: %+v\n", claims)
 
 			// Verificar se os campos necessários existem
-			idVal, idExists := claims["ID"]
-			emailVal, emailExists := claims["Email"]
-			isAdminVal, isAdminExists := claims["IsAdmin"]
+			idVal, idExists := claims["id"]
+			emailVal, emailExists := claims["email"]
+			isAdminVal, isAdminExists := claims["is_admin"]
 
 			if !idExists || !emailExists || !isAdminExists {
-				fmt.Printf("ALERTA: JWT incompleto ou inválido. Claims: %+v\n", claims)
+				logging.FromContext(c.Request.Context()).Warn("token sem as claims esperadas")
 				return nil
 			}
 
@@ -173,7 +253,7 @@ func SetupJWTMiddleware(userService services.UserService, cfg *config.Config) (*
 			if idFloat, ok := idVal.(float64); ok {
 				id = uint(idFloat)
 			} else {
-				fmt.Printf("ERRO: Campo 'id' não é um número: %T = %v\n", idVal, idVal)
+				logging.FromContext(c.Request.Context()).Warn("claim id do token não é um número", "type", fmt.Sprintf("%T", idVal))
 				return nil
 			}
 
@@ -184,18 +264,27 @@ func SetupJWTMiddleware(userService services.UserService, cfg *config.Config) (*
 			}
 		},
 
-		// Função para autorizar o acesso
+		// Função para autorizar o acesso: o token só vale enquanto o usuário existir.
+		// Quem removeu a conta perde o acesso na hora, mesmo com um token ainda válido.
 		Authorizator: func(data interface{}, c *gin.Context) bool {
-			// Qualquer usuário autenticado está autorizado por padrão
+			if data == nil {
+				c.Set(authFailureKey, errInvalidIdentity)
+				return false
+			}
+
+			user, err := activeUser(c, userService, jwt.ExtractClaims(c))
+			if err != nil {
+				c.Set(authFailureKey, err)
+				return false
+			}
+
+			// A identidade montada das claims dá lugar ao usuário atual, com os papéis do banco
+			c.Set(identityKey, user)
 			return true
 		},
 
 		// Função para resposta do login
 		LoginResponse: func(c *gin.Context, code int, token string, expire time.Time) {
-			fmt.Println("==== Login Bem-Sucedido ====")
-			fmt.Printf("Token gerado: %s\n", token)
-			fmt.Printf("Expira em: %v\n", expire)
-
 			c.JSON(code, gin.H{
 				"token":  token,
 				"expire": expire.Format(time.RFC3339),
@@ -204,9 +293,10 @@ func SetupJWTMiddleware(userService services.UserService, cfg *config.Config) (*
 
 		// Função para erro de autenticação
 		Unauthorized: func(c *gin.Context, code int, message string) {
-			fmt.Printf("==== Falha na Autenticação ====\n")
-			fmt.Printf("Rota: %s %s\n", c.Request.Method, c.Request.URL.Path)
-			fmt.Printf("Código: %d, Mensagem: %s\n", code, message)
+			if failure, ok := c.Get(authFailureKey); ok {
+				code, message = authFailure(failure.(error))
+			}
+			logging.FromContext(c.Request.Context()).Warn("autenticação recusada", "status", code, "reason", message)
 
 			c.JSON(code, gin.H{
 				"code":    code,
@@ -215,3 +305,17 @@ func SetupJWTMiddleware(userService services.UserService, cfg *config.Config) (*
 		},
 	})
 }
+
+// cookieSameSite converte a política SameSite da configuração
+func cookieSameSite(value string) http.SameSite {
+	switch value {
+	case "lax":
+		return http.SameSiteLaxMode
+	case "strict":
+		return http.SameSiteStrictMode
+	case "none":
+		return http.SameSiteNoneMode
+	default:
+		return http.SameSiteDefaultMode
+	}
+}
@@ -0,0 +1,217 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/services"
+	"github.com/henrygoeszanin/api_golang_estudos/config"
+	"github.com/henrygoeszanin/api_golang_estudos/domain/entities"
+	"github.com/henrygoeszanin/api_golang_estudos/infrastructure/memory"
+	"github.com/henrygoeszanin/api_golang_estudos/infrastructure/metrics"
+)
+
+func TestLoginCounters(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := memory.NewStore()
+	userRepository := memory.NewUserRepository(store)
+	hash, err := bcrypt.GenerateFromPassword([]byte("senha123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := userRepository.Create(context.Background(), &entities.User{Name: "Leitora", Email: "leitora@biblioteca.test", Password: string(hash)}); err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := metrics.NewMemoryRecorder()
+	userService := services.NewUserService(userRepository, memory.NewBranchRepository(store), time.Hour, false)
+	authMiddleware, err := SetupJWTMiddleware(userService, config.Defaults(config.ProfileTest), recorder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := gin.New()
+	router.POST("/api/auth/login", authMiddleware.LoginHandler)
+
+	tests := []struct {
+		name      string
+		body      map[string]string
+		status    int
+		succeeded int
+		failed    int
+	}{
+		{"senha correta", map[string]string{"email": "leitora@biblioteca.test", "password": "senha123"}, http.StatusOK, 1, 0},
+		{"senha errada", map[string]string{"email": "leitora@biblioteca.test", "password": "errada"}, http.StatusUnauthorized, 1, 1},
+		{"usuário inexistente", map[string]string{"email": "ninguem@biblioteca.test", "password": "senha123"}, http.StatusUnauthorized, 1, 2},
+		// Sem os campos obrigatórios não há tentativa de autenticação a contar
+		{"sem senha", map[string]string{"email": "leitora@biblioteca.test"}, http.StatusUnauthorized, 1, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := json.Marshal(tt.body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			request := httptest.NewRequest("POST", "/api/auth/login", bytes.NewReader(body))
+			request.Header.Set("Content-Type", "application/json")
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, request)
+
+			if response.Code != tt.status {
+				t.Errorf("status %d, esperado %d: %s", response.Code, tt.status, response.Body)
+			}
+			succeeded, failed := recorder.Logins()
+			if succeeded != tt.succeeded || failed != tt.failed {
+				t.Errorf("logins %d bem-sucedidos e %d recusados, esperados %d e %d", succeeded, failed, tt.succeeded, tt.failed)
+			}
+		})
+	}
+}
+
+func TestDeletedUserToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := memory.NewStore()
+	userRepository := memory.NewUserRepository(store)
+	hash, err := bcrypt.GenerateFromPassword([]byte("senha123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &entities.User{Name: "Leitora", Email: "leitora@biblioteca.test", Password: string(hash)}
+	if err := userRepository.Create(context.Background(), user); err != nil {
+		t.Fatal(err)
+	}
+
+	userService := services.NewUserService(userRepository, memory.NewBranchRepository(store), time.Hour, false)
+	authMiddleware, err := SetupJWTMiddleware(userService, config.Defaults(config.ProfileTest), metrics.NewMemoryRecorder())
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := gin.New()
+	router.POST("/api/auth/login", authMiddleware.LoginHandler)
+	router.GET("/api/auth/refresh", RefreshHandler(authMiddleware, userService))
+	router.GET("/api/loans/", authMiddleware.MiddlewareFunc(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{})
+	})
+
+	token := signIn(t, router, "leitora@biblioteca.test", "senha123")
+	if status := authenticated(router, "/api/loans/", token); status != http.StatusOK {
+		t.Fatalf("status %d antes da remoção, esperado %d", status, http.StatusOK)
+	}
+
+	if _, err := userService.RequestDeletion(context.Background(), user.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{"/api/loans/", "/api/auth/refresh"} {
+		if status := authenticated(router, path, token); status != http.StatusUnauthorized {
+			t.Errorf("%s: status %d com o token de um usuário removido, esperado %d", path, status, http.StatusUnauthorized)
+		}
+	}
+}
+
+// signIn autentica o usuário e retorna o token emitido
+func signIn(t *testing.T, router *gin.Engine, email, password string) string {
+	t.Helper()
+	body, err := json.Marshal(map[string]string{"email": email, "password": password})
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := httptest.NewRequest("POST", "/api/auth/login", bytes.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, request)
+	if response.Code != http.StatusOK {
+		t.Fatalf("login: status %d: %s", response.Code, response.Body)
+	}
+
+	var payload struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(response.Body.Bytes(), &payload); err != nil {
+		t.Fatal(err)
+	}
+	return payload.Token
+}
+
+// authenticated faz um GET com o token e retorna o status da resposta
+func authenticated(router *gin.Engine, path, token string) int {
+	request := httptest.NewRequest("GET", path, nil)
+	request.Header.Set("Authorization", "Bearer "+token)
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, request)
+	return response.Code
+}
+
+func TestDemotedAdminToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := memory.NewStore()
+	userRepository := memory.NewUserRepository(store)
+	hash, err := bcrypt.GenerateFromPassword([]byte("senha123"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Um segundo administrador permite rebaixar o primeiro
+	admin := &entities.User{Name: "Admin", Email: "admin@biblioteca.test", Password: string(hash), IsAdmin: true}
+	for _, user := range []*entities.User{admin, {Name: "Outra", Email: "outra@biblioteca.test", Password: string(hash), IsAdmin: true}} {
+		if err := userRepository.Create(context.Background(), user); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	userService := services.NewUserService(userRepository, memory.NewBranchRepository(store), time.Hour, false)
+	authMiddleware, err := SetupJWTMiddleware(userService, config.Defaults(config.ProfileTest), metrics.NewMemoryRecorder())
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := gin.New()
+	router.POST("/api/auth/login", authMiddleware.LoginHandler)
+	router.GET("/api/auth/refresh", RefreshHandler(authMiddleware, userService))
+	ok := func(c *gin.Context) { c.JSON(http.StatusOK, gin.H{}) }
+	router.GET("/api/admin/users", authMiddleware.MiddlewareFunc(), AdminRequired(), ok)
+	router.GET("/api/staff/loans", authMiddleware.MiddlewareFunc(), StaffRequired(), ok)
+
+	token := signIn(t, router, "admin@biblioteca.test", "senha123")
+	if status := authenticated(router, "/api/admin/users", token); status != http.StatusOK {
+		t.Fatalf("status %d antes do rebaixamento, esperado %d", status, http.StatusOK)
+	}
+
+	if _, err := userService.DemoteFromAdmin(context.Background(), admin.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{"/api/admin/users", "/api/staff/loans"} {
+		if status := authenticated(router, path, token); status != http.StatusForbidden {
+			t.Errorf("%s: status %d com o token antigo, esperado %d", path, status, http.StatusForbidden)
+		}
+	}
+
+	// O token renovado também não devolve o papel retirado
+	request := httptest.NewRequest("GET", "/api/auth/refresh", nil)
+	request.Header.Set("Authorization", "Bearer "+token)
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, request)
+	if response.Code != http.StatusOK {
+		t.Fatalf("refresh: status %d: %s", response.Code, response.Body)
+	}
+	var payload struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(response.Body.Bytes(), &payload); err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := authMiddleware.ParseTokenString(payload.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims := jwt.ExtractClaimsFromToken(parsed); claims["is_admin"] != false {
+		t.Errorf("token renovado com is_admin %v, esperado false", claims["is_admin"])
+	}
+}
@@ -0,0 +1,64 @@
+package middlewares
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Métodos e cabeçalhos aceitos nas requisições entre origens
+const (
+	corsAllowedMethods = "GET, POST, PUT, PATCH, DELETE, OPTIONS"
+	corsAllowedHeaders = "Authorization, Content-Type, " + RequestIDHeader
+)
+
+// CORS libera o acesso à API pelos navegadores nas origens informadas e responde às verificações prévias.
+// Sem origens configuradas, nenhum cabeçalho de CORS é enviado e os navegadores bloqueiam o acesso de outras origens.
+func CORS(allowedOrigins []string, allowCredentials bool, maxAge time.Duration) gin.HandlerFunc {
+	allowAny := false
+	origins := make(map[string]bool, len(allowedOrigins))
+	for _, origin := range allowedOrigins {
+		if origin == "*" {
+			allowAny = true
+		}
+		origins[strings.TrimSuffix(origin, "/")] = true
+	}
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" || len(origins) == 0 {
+			c.Next()
+			return
+		}
+
+		c.Writer.Header().Add("Vary", "Origin")
+		if !allowAny && !origins[origin] {
+			c.Next()
+			return
+		}
+
+		if allowAny {
+			c.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			c.Header("Access-Control-Allow-Origin", origin)
+		}
+		if allowCredentials {
+			c.Header("Access-Control-Allow-Credentials", "true")
+		}
+		c.Header("Access-Control-Expose-Headers", RequestIDHeader)
+
+		// Verificação prévia do navegador: responde sem passar pelas rotas
+		if c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != "" {
+			c.Header("Access-Control-Allow-Methods", corsAllowedMethods)
+			c.Header("Access-Control-Allow-Headers", corsAllowedHeaders)
+			c.Header("Access-Control-Max-Age", strconv.Itoa(int(maxAge.Seconds())))
+			c.AbortWithStatus(http.StatusNoContent)
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,24 @@
+package middlewares
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/interfaces/metrics"
+)
+
+// Metrics registra a contagem e a duração de cada requisição, identificada pelo modelo da rota.
+// Requisições que não correspondem a nenhuma rota são agrupadas, para não criar uma série por URL.
+func Metrics(recorder metrics.Recorder) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		recorder.ObserveHTTPRequest(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
@@ -0,0 +1,53 @@
+package middlewares
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/henrygoeszanin/api_golang_estudos/infrastructure/metrics"
+)
+
+func TestMetricsRecordsRouteTemplate(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := metrics.NewMemoryRecorder()
+	router := gin.New()
+	router.Use(Metrics(recorder))
+	router.GET("/api/books/:id", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.POST("/api/books/:id", func(c *gin.Context) { c.Status(http.StatusCreated) })
+
+	requests := []struct {
+		method string
+		path   string
+	}{
+		{"GET", "/api/books/1"},
+		{"GET", "/api/books/2"},
+		{"POST", "/api/books/3"},
+		{"GET", "/api/nao-existe/4"},
+		{"GET", "/api/nao-existe/5"},
+	}
+	for _, request := range requests {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(request.method, request.path, nil))
+	}
+
+	// Cada série é identificada pelo modelo da rota, nunca pela URL recebida
+	expected := []struct {
+		method string
+		route  string
+		status int
+		count  int
+	}{
+		{"GET", "/api/books/:id", http.StatusOK, 2},
+		{"POST", "/api/books/:id", http.StatusCreated, 1},
+		{"GET", "unmatched", http.StatusNotFound, 2},
+		{"GET", "/api/books/1", http.StatusOK, 0},
+		{"GET", "/api/nao-existe/4", http.StatusNotFound, 0},
+	}
+	for _, want := range expected {
+		if got := recorder.HTTPRequests(want.method, want.route, want.status); got != want.count {
+			t.Errorf("%s %s %d: %d requisições, esperadas %d", want.method, want.route, want.status, got, want.count)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package middlewares
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"runtime/debug"
+	"time"
+
+	jwt "github.com/appleboy/gin-jwt/v2"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/henrygoeszanin/api_golang_estudos/logging"
+)
+
+// RequestIDHeader é o cabeçalho que identifica a requisição entre serviços e nos logs
+const RequestIDHeader = "X-Request-ID"
+
+// validRequestID limita os IDs aceitos do cliente, para que não poluam os logs
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,64}$`)
+
+// RequestID aproveita o X-Request-ID recebido ou gera um novo, devolve-o na resposta e
+// guarda no contexto da requisição um logger que inclui o ID em todos os registros
+func RequestID(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID.MatchString(requestID) {
+			requestID = newRequestID()
+		}
+
+		ctx := logging.WithRequestID(c.Request.Context(), requestID)
+		requestLogger := logger.With("request_id", requestID)
+		// Com o rastreamento ativo, os registros também apontam para o trace da requisição
+		if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+			requestLogger = requestLogger.With("trace_id", spanContext.TraceID().String(), "span_id", spanContext.SpanID().String())
+		}
+		ctx = logging.WithLogger(ctx, requestLogger)
+		c.Request = c.Request.WithContext(ctx)
+		c.Header(RequestIDHeader, requestID)
+
+		c.Next()
+	}
+}
+
+// newRequestID gera um ID aleatório de 128 bits em hexadecimal
+func newRequestID() string {
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
+
+// RequestLogger registra cada requisição concluída com status, duração e usuário autenticado.
+// A query string não é registrada, pois pode conter tokens e assinaturas.
+func RequestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		status := c.Writer.Status()
+		attrs := []any{
+			"method", c.Request.Method,
+			"path", c.Request.URL.Path,
+			"route", c.FullPath(),
+			"status", status,
+			"duration_ms", time.Since(start).Milliseconds(),
+			"bytes", c.Writer.Size(),
+			"client_ip", c.ClientIP(),
+		}
+		if userID, ok := jwt.ExtractClaims(c)["id"].(float64); ok {
+			attrs = append(attrs, "user_id", uint(userID))
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, "error", c.Errors.String())
+		}
+
+		level := slog.LevelInfo
+		switch {
+		case status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+		logging.FromContext(c.Request.Context()).Log(c.Request.Context(), level, "requisição concluída", attrs...)
+	}
+}
+
+// Recovery responde 500 quando um handler entra em pânico, registrando o erro e a pilha no log
+func Recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				logging.FromContext(c.Request.Context()).Error("pânico ao atender a requisição",
+					"panic", recovered,
+					"stack", string(debug.Stack()),
+				)
+				if !c.Writer.Written() {
+					c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "erro interno do servidor"})
+					return
+				}
+				c.Abort()
+			}
+		}()
+		c.Next()
+	}
+}
@@ -0,0 +1,31 @@
+package middlewares
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Timeout define um prazo para a requisição. As consultas ao banco e demais operações que recebem
+// o contexto da requisição são canceladas quando o prazo acaba; um tempo zero ou negativo desliga o prazo.
+// routeTimeouts dá um prazo próprio a rotas específicas, identificadas pelo método e pelo caminho
+// registrado (ex.: "GET /api/admin/books/export").
+func Timeout(timeout time.Duration, routeTimeouts map[string]time.Duration) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		timeout := timeout
+		if routeTimeout, ok := routeTimeouts[c.Request.Method+" "+c.FullPath()]; ok {
+			timeout = routeTimeout
+		}
+		if timeout <= 0 {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
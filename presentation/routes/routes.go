@@ -1,95 +1,325 @@
 package routes
 
 import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
 	jwt "github.com/appleboy/gin-jwt/v2"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"gorm.io/gorm"
 
+	metricsinterfaces "github.com/henrygoeszanin/api_golang_estudos/application/interfaces/metrics"
+	notificationinterfaces "github.com/henrygoeszanin/api_golang_estudos/application/interfaces/notifications"
+	repositoryinterfaces "github.com/henrygoeszanin/api_golang_estudos/application/interfaces/repositories"
+	serviceinterfaces "github.com/henrygoeszanin/api_golang_estudos/application/interfaces/services"
+	"github.com/henrygoeszanin/api_golang_estudos/application/scheduler"
 	"github.com/henrygoeszanin/api_golang_estudos/application/services"
+	"github.com/henrygoeszanin/api_golang_estudos/application/tracing"
+	"github.com/henrygoeszanin/api_golang_estudos/application/workers"
 	"github.com/henrygoeszanin/api_golang_estudos/config"
+	"github.com/henrygoeszanin/api_golang_estudos/domain/cron"
+	"github.com/henrygoeszanin/api_golang_estudos/infrastructure/database"
+	"github.com/henrygoeszanin/api_golang_estudos/infrastructure/metrics"
+	"github.com/henrygoeszanin/api_golang_estudos/infrastructure/notifications"
 	"github.com/henrygoeszanin/api_golang_estudos/infrastructure/repositories"
+	"github.com/henrygoeszanin/api_golang_estudos/logging"
 	"github.com/henrygoeszanin/api_golang_estudos/presentation/handlers"
 	"github.com/henrygoeszanin/api_golang_estudos/presentation/middlewares"
 )
 
-// SetupRoutes configura todas as rotas da API
-func SetupRoutes(router *gin.Engine, db *gorm.DB, cfg *config.Config) {
-	// Inicializar repositórios
-	userRepository := repositories.NewUserRepository(db)
-	bookRepository := repositories.NewBookRepository(db)
-	loanRepository := repositories.NewLoanRepository(db)
+// Runtime reúne os componentes criados com as rotas que o main controla durante o encerramento
+type Runtime struct {
+	Health         serviceinterfaces.HealthService
+	BackgroundPool *workers.Pool
+	// Agendador das tarefas recorrentes; o main o inicia quando habilitado na configuração,
+	// e o encerramento do pool em segundo plano o interrompe
+	Scheduler *scheduler.Scheduler
+
+	// Endpoint /metrics no formato do Prometheus, publicado pelo main em um servidor interno
+	Metrics http.Handler
+
+	// Token de uso único para criar o primeiro administrador em POST /api/auth/setup;
+	// vazio quando o sistema já tem um administrador
+	SetupToken string
+}
+
+// Repositories reúne os repositórios usados pelos serviços da API
+type Repositories struct {
+	User         repositoryinterfaces.UserRepository
+	Book         repositoryinterfaces.BookRepository
+	Loan         repositoryinterfaces.LoanRepository
+	Branch       repositoryinterfaces.BranchRepository
+	Transfer     repositoryinterfaces.TransferRepository
+	ImportJob    repositoryinterfaces.ImportJobRepository
+	DataExport   repositoryinterfaces.DataExportRepository
+	AuditEvent   repositoryinterfaces.AuditEventRepository
+	JobRun       repositoryinterfaces.JobRunRepository
+	Notification repositoryinterfaces.NotificationRepository
+}
+
+// GormRepositories cria os repositórios GORM sobre a conexão com o banco
+func GormRepositories(db *gorm.DB) Repositories {
+	return Repositories{
+		User:         repositories.NewUserRepository(db),
+		Book:         repositories.NewBookRepository(db),
+		Loan:         repositories.NewLoanRepository(db),
+		Branch:       repositories.NewBranchRepository(db),
+		Transfer:     repositories.NewTransferRepository(db),
+		ImportJob:    repositories.NewImportJobRepository(db),
+		DataExport:   repositories.NewDataExportRepository(db),
+		AuditEvent:   repositories.NewAuditEventRepository(db),
+		JobRun:       repositories.NewJobRunRepository(db),
+		Notification: repositories.NewNotificationRepository(db),
+	}
+}
+
+// SetupRoutes configura todas as rotas da API com os repositórios GORM
+func SetupRoutes(router *gin.Engine, db *gorm.DB, cfg *config.Config) *Runtime {
+	return SetupRoutesWithRepositories(router, db, GormRepositories(db), cfg)
+}
+
+// SetupRoutesWithRepositories configura todas as rotas da API com os repositórios informados.
+// A conexão com o banco continua sendo usada pelas verificações de saúde e pelas métricas do pool.
+func SetupRoutesWithRepositories(router *gin.Engine, db *gorm.DB, repos Repositories, cfg *config.Config) *Runtime {
+	// Liberar o acesso pelos navegadores nas origens configuradas
+	router.Use(middlewares.CORS(cfg.CORSAllowedOrigins, cfg.CORSAllowCredentials, time.Duration(cfg.CORSMaxAgeSeconds)*time.Second))
+
+	// Com réplicas de leitura, as consultas feitas depois de uma escrita na mesma requisição vão ao banco principal
+	router.Use(func(c *gin.Context) {
+		c.Request = c.Request.WithContext(database.WithSession(c.Request.Context()))
+		c.Next()
+	})
+
+	// Repositórios
+	userRepository := repos.User
+	bookRepository := repos.Book
+	loanRepository := repos.Loan
+	branchRepository := repos.Branch
+	transferRepository := repos.Transfer
+	importJobRepository := repos.ImportJob
+	dataExportRepository := repos.DataExport
+	auditEventRepository := repos.AuditEvent
+	jobRunRepository := repos.JobRun
+	notificationRepository := repos.Notification
+
+	// Inicializar pool de tarefas em segundo plano
+	backgroundPool := workers.NewPool()
+
+	// Inicializar serviços, com um span de rastreamento para cada método
+	userService := tracing.NewUserService(services.NewUserService(
+		userRepository,
+		branchRepository,
+		time.Duration(cfg.AccountErasureGraceDays)*24*time.Hour,
+		cfg.AuthFirstUserAdmin,
+	))
+	bootstrapService := tracing.NewBootstrapService(services.NewBootstrapService(userService, userRepository))
+	bookService := tracing.NewBookService(services.NewBookService(bookRepository))
+	loanService := tracing.NewLoanService(services.NewLoanService(loanRepository, bookRepository, userRepository))
+	branchService := tracing.NewBranchService(services.NewBranchService(branchRepository, transferRepository, bookRepository, userRepository))
+	bookImportService := tracing.NewBookImportService(services.NewBookImportService(bookRepository, importJobRepository, backgroundPool))
+	auditService := tracing.NewAuditService(services.NewAuditService(
+		auditEventRepository,
+		bookRepository,
+		userRepository,
+		loanRepository,
+		branchRepository,
+		transferRepository,
+	))
+	dataExportService := tracing.NewDataExportService(services.NewDataExportService(
+		userRepository,
+		loanRepository,
+		auditService,
+		dataExportRepository,
+		backgroundPool,
+		cfg.DataExportDir,
+		time.Duration(cfg.DataExportLinkTTLHours)*time.Hour,
+		derivedKey(cfg.JWTSecret, "data-export-download"),
+	))
+
+	// Tarefas recorrentes, executadas pelo agendador nos horários configurados
+	jobScheduler := setupScheduler(cfg, jobRunRepository, backgroundPool)
+	notificationService := tracing.NewNotificationService(services.NewNotificationService(
+		notificationRepository,
+		loanRepository,
+		userRepository,
+		notificationSender(cfg),
+		cfg.NotificationReminderDays,
+		cfg.NotificationOverdueDays,
+		jobScheduler.Location(),
+	))
+	jobService := tracing.NewJobService(services.NewJobService(jobScheduler, jobRunRepository))
+	registerJobs(cfg, jobScheduler, userService, dataExportService, notificationService, bookService, jobService)
+
+	// Sem nenhum administrador, gerar o token de instalação que permite criar o primeiro
+	setupToken, err := bootstrapService.IssueSetupToken(context.Background())
+	if err != nil {
+		slog.Error("falha ao gerar o token de instalação", "error", err)
+	}
+
+	// Verificações de saúde: conectividade com o banco e migrações aplicadas
+	healthService := services.NewHealthService(
+		time.Duration(cfg.HealthCheckTimeoutSeconds)*time.Second,
+		database.NewPingChecker(db),
+		database.NewMigrationsChecker(db),
+	)
 
-	// Inicializar serviços
-	userService := services.NewUserService(userRepository)
-	bookService := services.NewBookService(bookRepository)
-	loanService := services.NewLoanService(loanRepository, bookRepository)
+	// Configurar métricas: duração das requisições e consultas, pool de conexões e indicadores da biblioteca
+	libraryStatsService := tracing.NewLibraryStatsService(services.NewLibraryStatsService(bookRepository, loanRepository))
+	metricsRecorder, metricsHandler := setupMetrics(router, db, libraryStatsService)
 
 	// Configurar middleware JWT
-	authMiddleware, err := middlewares.SetupJWTMiddleware(userService, cfg)
+	authMiddleware, err := middlewares.SetupJWTMiddleware(userService, cfg, metricsRecorder)
 	if err != nil {
 		panic("JWT middleware setup failed: " + err.Error())
 	}
 
 	// Inicializar handlers
 	userHandler := handlers.NewUserHandler(userService)
+	bootstrapHandler := handlers.NewBootstrapHandler(bootstrapService)
 	bookHandler := handlers.NewBookHandler(bookService)
 	loanHandler := handlers.NewLoanHandler(loanService)
+	branchHandler := handlers.NewBranchHandler(branchService)
+	bookImportHandler := handlers.NewBookImportHandler(bookImportService)
+	dataExportHandler := handlers.NewDataExportHandler(dataExportService)
+	auditHandler := handlers.NewAuditHandler(auditService)
+	healthHandler := handlers.NewHealthHandler(healthService)
+	jobHandler := handlers.NewJobHandler(jobService)
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
 
-	// Definir grupo base da API
-	api := router.Group("/api")
+	// Definir grupo base da API, com prazo para cada requisição. As rotas que transmitem arquivos
+	// têm um prazo próprio, mais longo
+	streamTimeout := time.Duration(cfg.StreamTimeoutSeconds) * time.Second
+	streamingRoutes := map[string]time.Duration{
+		"GET /api/admin/books/export":   streamTimeout,
+		"POST /api/admin/books/import":  streamTimeout,
+		"GET /api/users/me/export":      streamTimeout,
+		"GET /api/exports/:id/download": streamTimeout,
+	}
+	api := router.Group("/api", middlewares.Timeout(time.Duration(cfg.RequestTimeoutSeconds)*time.Second, streamingRoutes))
 
 	// Configurar grupos de rotas por domínio
-	setupHealthRoutes(api)
-	setupAuthRoutes(api, userHandler, authMiddleware)
-	setupBookRoutes(api, bookHandler, authMiddleware)
-	setupLoanRoutes(api, loanHandler, authMiddleware)
-	setupUserRoutes(api, userHandler, authMiddleware)
+	setupHealthRoutes(api, healthHandler)
+	setupAuthRoutes(api, userHandler, bootstrapHandler, userService, authMiddleware)
+	setupBookRoutes(api, bookHandler, bookImportHandler, auditService, authMiddleware)
+	setupLoanRoutes(api, loanHandler, auditService, authMiddleware)
+	setupUserRoutes(api, userHandler, dataExportHandler, notificationHandler, auditService, authMiddleware)
+	setupBranchRoutes(api, branchHandler, auditService, authMiddleware)
+	setupStaffRoutes(api, loanHandler, branchHandler, auditService, authMiddleware)
+	setupAuditRoutes(api, auditHandler, authMiddleware)
+	setupJobRoutes(api, jobHandler, auditService, authMiddleware)
+
+	return &Runtime{Health: healthService, BackgroundPool: backgroundPool, Scheduler: jobScheduler, Metrics: metricsHandler, SetupToken: setupToken}
 }
 
 // setupHealthRoutes configura rotas de health check
-func setupHealthRoutes(router *gin.RouterGroup) {
-	router.GET("/health", func(c *gin.Context) {
-		c.JSON(200, gin.H{
-			"status": "API funcionando corretamente",
-		})
-	})
+func setupHealthRoutes(router *gin.RouterGroup, healthHandler *handlers.HealthHandler) {
+	health := router.Group("/health")
+	{
+		// Mantida para clientes antigos; equivale à verificação de vida
+		health.GET("", healthHandler.Live)
+		health.GET("/live", healthHandler.Live)
+		health.GET("/ready", healthHandler.Ready)
+	}
 }
 
 // setupAuthRoutes configura rotas de autenticação
-func setupAuthRoutes(router *gin.RouterGroup, userHandler *handlers.UserHandler, authMiddleware *jwt.GinJWTMiddleware) {
+func setupAuthRoutes(router *gin.RouterGroup, userHandler *handlers.UserHandler, bootstrapHandler *handlers.BootstrapHandler, userService serviceinterfaces.UserService, authMiddleware *jwt.GinJWTMiddleware) {
 	auth := router.Group("/auth")
 	{
 		// Rotas públicas de autenticação
 		auth.POST("/login", authMiddleware.LoginHandler)
-		auth.GET("/refresh", authMiddleware.RefreshHandler)
+		auth.GET("/refresh", middlewares.RefreshHandler(authMiddleware, userService))
 		auth.POST("/register", userHandler.Register)
+		auth.POST("/setup", bootstrapHandler.Setup)
 	}
 }
 
 // setupBookRoutes configura rotas relacionadas a livros
-func setupBookRoutes(router *gin.RouterGroup, bookHandler *handlers.BookHandler, authMiddleware *jwt.GinJWTMiddleware) {
+func setupBookRoutes(router *gin.RouterGroup, bookHandler *handlers.BookHandler, bookImportHandler *handlers.BookImportHandler, auditService serviceinterfaces.AuditService, authMiddleware *jwt.GinJWTMiddleware) {
 	// Rotas públicas (consulta)
 	books := router.Group("/books")
 	{
 		books.GET("/", bookHandler.List)
 		books.GET("/:id", bookHandler.GetByID)
+		books.GET("/isbn/:isbn", bookHandler.GetByISBN)
 	}
 
 	// Rotas administrativas (gerenciamento)
 	adminBooks := router.Group("/admin/books")
-	adminBooks.Use(middlewares.TokenExtractor(), authMiddleware.MiddlewareFunc(), middlewares.AdminRequired())
+	adminBooks.Use(middlewares.TokenExtractor(), authMiddleware.MiddlewareFunc(), middlewares.Audit(auditService, services.AuditTargetBook), middlewares.AdminRequired())
 	{
+		adminBooks.GET("/", bookHandler.AdminList)
 		adminBooks.POST("/", bookHandler.Create)
 		adminBooks.PUT("/:id", bookHandler.Update)
 		adminBooks.DELETE("/:id", bookHandler.Delete)
+		adminBooks.PUT("/:id/withdraw", bookHandler.Withdraw)
+		adminBooks.PUT("/:id/reinstate", bookHandler.Reinstate)
+		adminBooks.GET("/deleted", bookHandler.ListDeleted)
+		adminBooks.PUT("/:id/restore", bookHandler.Restore)
+		adminBooks.GET("/export", bookHandler.Export)
+		adminBooks.GET("/inventory", bookHandler.Inventory)
+		adminBooks.POST("/inventory/reconcile", bookHandler.Reconcile)
+		adminBooks.POST("/import", bookImportHandler.Import)
+		adminBooks.GET("/import", bookImportHandler.ListJobs)
+		adminBooks.GET("/import/:jobId", bookImportHandler.GetJob)
+	}
+}
+
+// setupBranchRoutes configura rotas relacionadas a unidades
+func setupBranchRoutes(router *gin.RouterGroup, branchHandler *handlers.BranchHandler, auditService serviceinterfaces.AuditService, authMiddleware *jwt.GinJWTMiddleware) {
+	// Rotas públicas (consulta)
+	branches := router.Group("/branches")
+	{
+		branches.GET("/", branchHandler.List)
+		branches.GET("/:id", branchHandler.GetByID)
+	}
+
+	// Rotas administrativas (gerenciamento de unidades e do acervo por unidade)
+	adminBranches := router.Group("/admin/branches")
+	adminBranches.Use(middlewares.TokenExtractor(), authMiddleware.MiddlewareFunc(), middlewares.Audit(auditService, services.AuditTargetBranch), middlewares.AdminRequired())
+	{
+		adminBranches.POST("/", branchHandler.Create)
+		adminBranches.PUT("/:id", branchHandler.Update)
+		adminBranches.DELETE("/:id", branchHandler.Delete)
+	}
+
+	adminHoldings := router.Group("/admin/books")
+	adminHoldings.Use(middlewares.TokenExtractor(), authMiddleware.MiddlewareFunc(), middlewares.Audit(auditService, services.AuditTargetBook), middlewares.AdminRequired())
+	{
+		adminHoldings.PUT("/:id/holdings/:branchId", branchHandler.SetHolding)
+	}
+}
+
+// setupStaffRoutes configura rotas de balcão para bibliotecários e administradores
+func setupStaffRoutes(router *gin.RouterGroup, loanHandler *handlers.LoanHandler, branchHandler *handlers.BranchHandler, auditService serviceinterfaces.AuditService, authMiddleware *jwt.GinJWTMiddleware) {
+	staff := router.Group("/staff")
+	staff.Use(middlewares.TokenExtractor(), authMiddleware.MiddlewareFunc())
+	{
+		staffLoans := staff.Group("/loans", middlewares.Audit(auditService, services.AuditTargetLoan), middlewares.StaffRequired())
+		staffLoans.POST("", loanHandler.CheckOut)
+		staffLoans.PUT("/:id/return", loanHandler.CheckIn)
+
+		staffTransfers := staff.Group("/transfers", middlewares.Audit(auditService, services.AuditTargetTransfer), middlewares.StaffRequired())
+		staffTransfers.GET("", branchHandler.ListTransfers)
+		staffTransfers.POST("", branchHandler.RequestTransfer)
+		staffTransfers.PUT("/:id/approve", branchHandler.ApproveTransfer)
+		staffTransfers.PUT("/:id/reject", branchHandler.RejectTransfer)
 	}
 }
 
 // setupLoanRoutes configura rotas relacionadas a empréstimos
-func setupLoanRoutes(router *gin.RouterGroup, loanHandler *handlers.LoanHandler, authMiddleware *jwt.GinJWTMiddleware) {
+func setupLoanRoutes(router *gin.RouterGroup, loanHandler *handlers.LoanHandler, auditService serviceinterfaces.AuditService, authMiddleware *jwt.GinJWTMiddleware) {
 	// Todas as rotas de empréstimos requerem autenticação
 	loans := router.Group("/loans")
-	loans.Use(middlewares.TokenExtractor(), authMiddleware.MiddlewareFunc())
+	loans.Use(middlewares.TokenExtractor(), authMiddleware.MiddlewareFunc(), middlewares.Audit(auditService, services.AuditTargetLoan))
 	{
 		loans.GET("/", loanHandler.List)
 		loans.POST("/", loanHandler.Create)
@@ -99,23 +329,210 @@ func setupLoanRoutes(router *gin.RouterGroup, loanHandler *handlers.LoanHandler,
 }
 
 // setupUserRoutes configura rotas relacionadas a usuários
-func setupUserRoutes(router *gin.RouterGroup, userHandler *handlers.UserHandler, authMiddleware *jwt.GinJWTMiddleware) {
+func setupUserRoutes(router *gin.RouterGroup, userHandler *handlers.UserHandler, dataExportHandler *handlers.DataExportHandler, notificationHandler *handlers.NotificationHandler, auditService serviceinterfaces.AuditService, authMiddleware *jwt.GinJWTMiddleware) {
 	// Rotas de usuário que precisam de autenticação
 	users := router.Group("/users")
-	users.Use(middlewares.TokenExtractor(), authMiddleware.MiddlewareFunc())
+	users.Use(middlewares.TokenExtractor(), authMiddleware.MiddlewareFunc(), middlewares.AuditSelf(auditService))
 	{
 		users.GET("/me", userHandler.GetMe)
 		users.PUT("/me", userHandler.UpdateMe)
+		users.DELETE("/me", userHandler.DeleteMe)
+		users.GET("/me/export", dataExportHandler.Export)
+		users.GET("/me/exports", dataExportHandler.ListExports)
+		users.GET("/me/exports/:id", dataExportHandler.GetExport)
+		users.GET("/me/notifications", notificationHandler.Inbox)
+		users.PUT("/me/notifications/read", notificationHandler.MarkAllRead)
+		users.PUT("/me/notifications/:id/read", notificationHandler.MarkRead)
+		users.GET("/me/notification-preferences", notificationHandler.GetPreferences)
+		users.PUT("/me/notification-preferences", notificationHandler.UpdatePreferences)
 	}
 
+	// Download das exportações de dados pessoais, autorizado pela assinatura do link
+	router.GET("/exports/:id/download", dataExportHandler.Download)
+
 	// Rotas administrativas para gerenciamento de usuários
 	adminUsers := router.Group("/admin/users")
-	adminUsers.Use(middlewares.TokenExtractor(), authMiddleware.MiddlewareFunc(), middlewares.AdminRequired())
+	adminUsers.Use(middlewares.TokenExtractor(), authMiddleware.MiddlewareFunc(), middlewares.Audit(auditService, services.AuditTargetUser), middlewares.AdminRequired())
 	{
 		adminUsers.GET("/", userHandler.List)
+		adminUsers.GET("/deleted", userHandler.ListDeleted)
 		adminUsers.GET("/:id", userHandler.GetByID)
 		adminUsers.PUT("/:id", userHandler.Update)
 		adminUsers.DELETE("/:id", userHandler.Delete)
 		adminUsers.PUT("/:id/promote", userHandler.PromoteToAdmin)
+		adminUsers.PUT("/:id/branch", userHandler.AssignBranch)
+		adminUsers.PUT("/:id/restore", userHandler.Restore)
+		adminUsers.POST("/:id/erase", userHandler.Erase)
 	}
 }
+
+// setupAuditRoutes configura a consulta à trilha de auditoria, restrita a administradores
+func setupAuditRoutes(router *gin.RouterGroup, auditHandler *handlers.AuditHandler, authMiddleware *jwt.GinJWTMiddleware) {
+	audit := router.Group("/admin/audit")
+	audit.Use(middlewares.TokenExtractor(), authMiddleware.MiddlewareFunc(), middlewares.AdminRequired())
+	{
+		audit.GET("", auditHandler.List)
+		audit.GET("/verify", auditHandler.Verify)
+	}
+}
+
+// setupJobRoutes configura a consulta e a execução das tarefas agendadas, restritas a administradores
+func setupJobRoutes(router *gin.RouterGroup, jobHandler *handlers.JobHandler, auditService serviceinterfaces.AuditService, authMiddleware *jwt.GinJWTMiddleware) {
+	jobs := router.Group("/admin/jobs")
+	jobs.Use(middlewares.TokenExtractor(), authMiddleware.MiddlewareFunc(), middlewares.Audit(auditService, services.AuditTargetJob), middlewares.AdminRequired())
+	{
+		jobs.GET("", jobHandler.List)
+		jobs.GET("/runs", jobHandler.ListRuns)
+		jobs.POST("/:name/run", jobHandler.Run)
+	}
+}
+
+// setupScheduler cria o agendador com o fuso e as novas tentativas da configuração
+func setupScheduler(cfg *config.Config, jobRunRepository repositoryinterfaces.JobRunRepository, pool *workers.Pool) *scheduler.Scheduler {
+	location, err := time.LoadLocation(cfg.SchedulerTimezone)
+	if err != nil {
+		panic("scheduler setup failed: " + err.Error())
+	}
+
+	return scheduler.New(jobRunRepository, pool, scheduler.Options{
+		Location:     location,
+		MaxAttempts:  cfg.SchedulerMaxAttempts,
+		RetryBackoff: time.Duration(cfg.SchedulerRetryBackoffSeconds) * time.Second,
+	})
+}
+
+// registerJobs registra as tarefas recorrentes com as expressões cron da configuração
+func registerJobs(
+	cfg *config.Config,
+	jobScheduler *scheduler.Scheduler,
+	userService serviceinterfaces.UserService,
+	dataExportService serviceinterfaces.DataExportService,
+	notificationService serviceinterfaces.NotificationService,
+	bookService serviceinterfaces.BookService,
+	jobService serviceinterfaces.JobService,
+) {
+	// Apagar os dados das contas cujo prazo de carência para exclusão terminou
+	jobScheduler.Register(scheduler.Job{
+		Name:        "account-erasure",
+		Description: "Apaga os dados pessoais das contas cujo prazo de carência para exclusão terminou",
+		Schedule:    cronSchedule(cfg.SchedulerAccountErasure),
+		Run: func(ctx context.Context) error {
+			erased, err := userService.EraseDue(ctx)
+			if erased > 0 {
+				logging.FromContext(ctx).Info("dados pessoais apagados", "users", erased)
+			}
+			return err
+		},
+	})
+
+	// Remover os arquivos de exportação de dados pessoais vencidos
+	jobScheduler.Register(scheduler.Job{
+		Name:        "data-export-cleanup",
+		Description: "Remove os arquivos de exportação de dados pessoais com o link vencido",
+		Schedule:    cronSchedule(cfg.SchedulerDataExportCleanup),
+		Run: func(ctx context.Context) error {
+			_, err := dataExportService.DeleteExpired(ctx)
+			return err
+		},
+	})
+
+	// Enviar os lembretes de devolução e os avisos de atraso
+	jobScheduler.Register(scheduler.Job{
+		Name:        "overdue-scan",
+		Description: "Envia os lembretes de devolução e os avisos de atraso dos empréstimos em aberto",
+		Schedule:    cronSchedule(cfg.SchedulerOverdueScan),
+		Run: func(ctx context.Context) error {
+			result, err := notificationService.SendLoanReminders(ctx)
+			if err != nil {
+				return err
+			}
+			logging.FromContext(ctx).Info("notificações de empréstimos processadas",
+				"loans", result.Loans, "created", result.Created, "sent", result.Sent, "failed", result.Failed)
+			if result.Failed > 0 {
+				return fmt.Errorf("%d notificações não foram entregues e serão tentadas na próxima varredura", result.Failed)
+			}
+			return nil
+		},
+	})
+
+	// Conferir a disponibilidade do acervo contra os empréstimos em aberto, sem corrigir
+	jobScheduler.Register(scheduler.Job{
+		Name:        "inventory-report",
+		Description: "Confere a disponibilidade do acervo contra os empréstimos em aberto e registra as divergências",
+		Schedule:    cronSchedule(cfg.SchedulerInventoryReport),
+		Run: func(ctx context.Context) error {
+			_, err := bookService.ReconcileInventory(ctx, false)
+			return err
+		},
+	})
+
+	// Manter o histórico de execuções apenas pelos dias configurados
+	jobScheduler.Register(scheduler.Job{
+		Name:        "job-history-cleanup",
+		Description: "Remove do histórico as execuções de tarefas mais antigas que o período de retenção",
+		Schedule:    cronSchedule(cfg.SchedulerHistoryCleanup),
+		Run: func(ctx context.Context) error {
+			removed, err := jobService.PruneHistory(ctx, time.Duration(cfg.SchedulerHistoryDays)*24*time.Hour)
+			if removed > 0 {
+				logging.FromContext(ctx).Info("histórico de tarefas reduzido", "runs", removed)
+			}
+			return err
+		},
+	})
+}
+
+// derivedKey deriva do segredo uma chave própria para cada finalidade, de modo que
+// a chave que assina os links de download não é a mesma que assina os tokens JWT
+func derivedKey(secret, purpose string) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
+// notificationSender escolhe a entrega dos e-mails configurada
+func notificationSender(cfg *config.Config) notificationinterfaces.Sender {
+	if cfg.NotificationEmailSender == "file" {
+		return notifications.NewFileSender(cfg.NotificationFilePath)
+	}
+	return notifications.NewLogSender()
+}
+
+// cronSchedule interpreta a expressão cron já validada na configuração; vazia, retorna nil
+func cronSchedule(expression string) *cron.Schedule {
+	if expression == "" {
+		return nil
+	}
+	schedule, err := cron.Parse(expression)
+	if err != nil {
+		panic("scheduler setup failed: " + err.Error())
+	}
+	return schedule
+}
+
+// setupMetrics instala a coleta de métricas e retorna o handler do endpoint /metrics no formato do Prometheus.
+// O endpoint não passa pelo router da API: cada coleta consulta o banco e não deve ficar exposta às rotas públicas.
+func setupMetrics(router *gin.Engine, db *gorm.DB, libraryStatsService serviceinterfaces.LibraryStatsService) (metricsinterfaces.Recorder, http.Handler) {
+	registry := prometheus.NewRegistry()
+	recorder := metrics.NewPrometheusRecorder(registry)
+
+	if err := db.Use(database.NewMetricsPlugin(recorder)); err != nil {
+		panic("metrics plugin setup failed: " + err.Error())
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		panic("metrics setup failed: " + err.Error())
+	}
+
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		collectors.NewDBStatsCollector(sqlDB, "library"),
+		metrics.NewLibraryCollector(libraryStatsService),
+	)
+
+	router.Use(middlewares.Metrics(recorder))
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{Registry: registry}))
+	return recorder, mux
+}
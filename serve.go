@@ -0,0 +1,191 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"gorm.io/gorm"
+
+	"github.com/henrygoeszanin/api_golang_estudos/config"
+	"github.com/henrygoeszanin/api_golang_estudos/infrastructure/database"
+	"github.com/henrygoeszanin/api_golang_estudos/infrastructure/tracing"
+	"github.com/henrygoeszanin/api_golang_estudos/logging"
+	"github.com/henrygoeszanin/api_golang_estudos/presentation/middlewares"
+	"github.com/henrygoeszanin/api_golang_estudos/presentation/routes"
+)
+
+// runServe executa o subcomando serve: inicia o servidor da API e o mantém até receber SIGINT ou SIGTERM.
+// Retorna o código de saída do processo.
+func runServe(args []string) int {
+	// Carregar configurações: valores padrão do perfil, arquivo, variáveis de ambiente e argumentos
+	cfg, err := config.Load(args)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	// Configurar os logs estruturados; o pacote log padrão também passa a usar este logger
+	logger := logging.New(os.Stdout, cfg.LogLevel, cfg.LogFormat)
+	slog.SetDefault(logger)
+	if dotenvErr != nil {
+		logger.Info("arquivo .env não encontrado, usando variáveis de ambiente e valores padrão")
+	}
+	logger.Info("configuração carregada", "profile", cfg.Profile)
+
+	// Configurar o rastreamento distribuído
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg)
+	if err != nil {
+		logger.Error("falha ao configurar o rastreamento", "error", err)
+		return 1
+	}
+	defer func() {
+		if err := shutdownTracing(context.Background()); err != nil {
+			logger.Error("falha ao enviar os spans pendentes", "error", err)
+		}
+	}()
+
+	// Inicializar o banco de dados
+	db, err := database.SetupDatabase(cfg)
+	if err != nil {
+		logger.Error("falha ao conectar ao banco de dados", "error", err)
+		return 1
+	}
+
+	// Mensagens de depuração do Gin (rotas registradas, avisos de modo) também vão para o logger
+	gin.DebugPrintFunc = func(format string, values ...interface{}) {
+		logger.Debug(strings.TrimSpace(fmt.Sprintf(format, values...)))
+	}
+	gin.DebugPrintRouteFunc = func(method, path, handler string, handlers int) {
+		logger.Debug("rota registrada", "method", method, "path", path, "handler", handler)
+	}
+
+	// Configurar o router Gin com rastreamento, ID de requisição, log de acesso e recuperação de pânicos
+	router := gin.New()
+	router.Use(
+		otelgin.Middleware(cfg.ServiceName),
+		middlewares.RequestID(logger),
+		middlewares.RequestLogger(),
+		middlewares.Recovery(),
+	)
+
+	// Configurar rotas
+	runtime := routes.SetupRoutes(router, db, cfg)
+
+	// O token de instalação vai direto para a saída de erro: o logger oculta tokens, e ele não deve
+	// ficar guardado junto dos logs
+	if runtime.SetupToken != "" {
+		logger.Warn("nenhum administrador cadastrado: crie o primeiro em POST /api/auth/setup com o token de instalação exibido na saída de erro ou use o subcomando create-admin")
+		fmt.Fprintf(os.Stderr, "\nToken de instalação (uso único): %s\n\n", runtime.SetupToken)
+	}
+
+	// Iniciar as tarefas recorrentes; com várias réplicas, cada horário é executado por apenas uma delas
+	if cfg.SchedulerEnabled {
+		runtime.Scheduler.Start()
+		logger.Info("agendador de tarefas iniciado", "timezone", cfg.SchedulerTimezone)
+	} else {
+		logger.Info("agendador de tarefas desabilitado: as tarefas só rodam a pedido em POST /api/admin/jobs/:name/run")
+	}
+
+	// Iniciar o servidor; SIGINT e SIGTERM iniciam o encerramento ordenado
+	server := newHTTPServer(cfg, cfg.ServerPort, router)
+	signalCtx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
+
+	serverErr := make(chan error, 2)
+	go func() {
+		logger.Info("servidor iniciado", "port", cfg.ServerPort)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	// As métricas ficam em um servidor à parte, para que a porta possa ficar restrita à rede interna
+	var metricsServer *http.Server
+	if cfg.MetricsPort != "" {
+		metricsServer = newHTTPServer(cfg, cfg.MetricsPort, runtime.Metrics)
+		go func() {
+			logger.Info("servidor de métricas iniciado", "port", cfg.MetricsPort)
+			if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				serverErr <- fmt.Errorf("servidor de métricas: %w", err)
+			}
+		}()
+	}
+
+	// Se um dos servidores não subir, o outro, as tarefas e o banco são encerrados do mesmo jeito,
+	// mas sem a espera do balanceador, que nunca chegou a enviar requisições
+	exitCode, drain := 0, true
+	select {
+	case err := <-serverErr:
+		logger.Error("erro ao iniciar o servidor", "error", err)
+		exitCode, drain = 1, false
+	case <-signalCtx.Done():
+	}
+	stopSignals()
+
+	shutdown(logger, cfg, server, metricsServer, runtime, db, drain)
+	return exitCode
+}
+
+// newHTTPServer cria um servidor HTTP na porta informada com os limites de tempo e de cabeçalhos da configuração
+func newHTTPServer(cfg *config.Config, port string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              fmt.Sprintf(":%s", port),
+		Handler:           handler,
+		ReadHeaderTimeout: time.Duration(cfg.ServerReadHeaderTimeoutSeconds) * time.Second,
+		ReadTimeout:       time.Duration(cfg.ServerReadTimeoutSeconds) * time.Second,
+		WriteTimeout:      time.Duration(cfg.ServerWriteTimeoutSeconds) * time.Second,
+		IdleTimeout:       time.Duration(cfg.ServerIdleTimeoutSeconds) * time.Second,
+		MaxHeaderBytes:    cfg.ServerMaxHeaderBytes,
+	}
+}
+
+// shutdown encerra a aplicação em ordem: deixa de estar pronta, aguarda o balanceador parar de enviar
+// requisições, conclui as requisições em andamento, para as tarefas em segundo plano e fecha o banco.
+// Um segundo sinal durante o encerramento interrompe a espera. Sem drain, a espera pelo balanceador é pulada.
+func shutdown(logger *slog.Logger, cfg *config.Config, server, metricsServer *http.Server, runtime *routes.Runtime, db *gorm.DB, drain bool) {
+	logger.Info("encerrando o servidor", "drain_seconds", cfg.ShutdownDrainSeconds, "timeout_seconds", cfg.ShutdownTimeoutSeconds)
+	runtime.Health.StartDraining()
+
+	forceCtx, stopForce := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopForce()
+
+	if drain {
+		select {
+		case <-time.After(time.Duration(cfg.ShutdownDrainSeconds) * time.Second):
+		case <-forceCtx.Done():
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(forceCtx, time.Duration(cfg.ShutdownTimeoutSeconds)*time.Second)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		logger.Error("requisições interrompidas no encerramento", "error", err)
+	}
+	if metricsServer != nil {
+		if err := metricsServer.Shutdown(ctx); err != nil {
+			logger.Error("servidor de métricas interrompido no encerramento", "error", err)
+		}
+	}
+	if err := runtime.BackgroundPool.Shutdown(ctx); err != nil {
+		logger.Error("tarefas em segundo plano interrompidas no encerramento", "error", err)
+	}
+	if err := database.Close(db); err != nil {
+		logger.Error("falha ao fechar as conexões com o banco", "error", err)
+	}
+	logger.Info("servidor encerrado")
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/henrygoeszanin/api_golang_estudos/application/dtos"
+	serviceinterfaces "github.com/henrygoeszanin/api_golang_estudos/application/interfaces/services"
+	"github.com/henrygoeszanin/api_golang_estudos/application/services"
+)
+
+// runUserList executa o subcomando user list: lista os usuários ativos ou, com --deleted, os removidos
+func runUserList(args []string) int {
+	var deleted bool
+	tool, code := loadTool("library-api user list", args, func(flagSet *flag.FlagSet) {
+		flagSet.BoolVar(&deleted, "deleted", false, "lista os usuários removidos, incluindo os com exclusão agendada")
+	})
+	if tool == nil {
+		return code
+	}
+
+	if err := tool.open(); err != nil {
+		return tool.fail("falha ao abrir o banco de dados", err)
+	}
+	defer tool.close()
+
+	userService := tool.userService()
+	var users []dtos.UserResponseDTO
+	var err error
+	if deleted {
+		users, err = userService.ListDeleted(context.Background())
+	} else {
+		users, err = userService.List(context.Background())
+	}
+	if err != nil {
+		return tool.fail("falha ao listar os usuários", err)
+	}
+	return printUsers(tool, users)
+}
+
+// runUserPromote executa o subcomando user promote: promove um usuário a administrador
+func runUserPromote(args []string) int {
+	return changeUserRole("promote", args, serviceinterfaces.UserService.PromoteToAdmin)
+}
+
+// runUserDemote executa o subcomando user demote: remove o papel de administrador de um usuário.
+// O último administrador não pode ser rebaixado.
+func runUserDemote(args []string) int {
+	return changeUserRole("demote", args, serviceinterfaces.UserService.DemoteFromAdmin)
+}
+
+// changeUserRole executa user promote e user demote, que diferem apenas na operação aplicada ao usuário
+func changeUserRole(name string, args []string, change func(userService serviceinterfaces.UserService, ctx context.Context, id uint) (*dtos.UserResponseDTO, error)) int {
+	var id uint
+	var email string
+	tool, code := loadTool("library-api user "+name, args, func(flagSet *flag.FlagSet) {
+		flagSet.UintVar(&id, "id", 0, "ID do usuário")
+		flagSet.StringVar(&email, "email", "", "email do usuário")
+	})
+	if tool == nil {
+		return code
+	}
+
+	if err := tool.open(); err != nil {
+		return tool.fail("falha ao abrir o banco de dados", err)
+	}
+	defer tool.close()
+
+	ctx := context.Background()
+	user, err := tool.findUser(ctx, id, email)
+	if err != nil {
+		return tool.fail("falha ao buscar o usuário", err)
+	}
+
+	var updated *dtos.UserResponseDTO
+	err = tool.audited(ctx, "user "+name, services.AuditTargetUser, user.ID, func() (uint, error) {
+		var err error
+		updated, err = change(tool.userService(), ctx, user.ID)
+		return user.ID, err
+	})
+	if err != nil {
+		return tool.fail("falha ao alterar o papel do usuário", err, "id", user.ID)
+	}
+	return printUsers(tool, []dtos.UserResponseDTO{*updated})
+}
+
+// runUserResetPassword executa o subcomando user reset-password: troca a senha de um usuário.
+// Sem --password, a nova senha é lida da primeira linha da entrada padrão.
+func runUserResetPassword(args []string) int {
+	var id uint
+	var email, password string
+	tool, code := loadTool("library-api user reset-password", args, func(flagSet *flag.FlagSet) {
+		flagSet.UintVar(&id, "id", 0, "ID do usuário")
+		flagSet.StringVar(&email, "email", "", "email do usuário")
+		flagSet.StringVar(&password, "password", "", "nova senha (sem ela, é lida da entrada padrão)")
+	})
+	if tool == nil {
+		return code
+	}
+
+	if password == "" {
+		var err error
+		if password, err = readPassword(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+	}
+	updateDTO := dtos.UserUpdateDTO{Password: password}
+	problems := dtos.Validate(updateDTO)
+	if password == "" {
+		problems = append(problems, "campo Password obrigatório")
+	}
+	if len(problems) > 0 {
+		fmt.Fprintf(os.Stderr, "senha inválida:\n  - %s\n", strings.Join(problems, "\n  - "))
+		return 2
+	}
+
+	if err := tool.open(); err != nil {
+		return tool.fail("falha ao abrir o banco de dados", err)
+	}
+	defer tool.close()
+
+	ctx := context.Background()
+	user, err := tool.findUser(ctx, id, email)
+	if err != nil {
+		return tool.fail("falha ao buscar o usuário", err)
+	}
+
+	var updated *dtos.UserResponseDTO
+	err = tool.audited(ctx, "user reset-password", services.AuditTargetUser, user.ID, func() (uint, error) {
+		var err error
+		updated, err = tool.userService().Update(ctx, user.ID, updateDTO)
+		return user.ID, err
+	})
+	if err != nil {
+		return tool.fail("falha ao trocar a senha", err, "id", user.ID)
+	}
+	tool.logger.Info("senha alterada", "id", updated.ID, "email", updated.Email)
+	return printUsers(tool, []dtos.UserResponseDTO{*updated})
+}
+
+// printUsers escreve os usuários em tabela ou JSON
+func printUsers(tool *tool, users []dtos.UserResponseDTO) int {
+	rows := make([][]string, 0, len(users))
+	for _, user := range users {
+		branch := "-"
+		if user.BranchID != nil {
+			branch = strconv.FormatUint(uint64(*user.BranchID), 10)
+		}
+		rows = append(rows, []string{
+			strconv.FormatUint(uint64(user.ID), 10),
+			user.Name,
+			user.Email,
+			formatBool(user.IsAdmin),
+			formatBool(user.IsLibrarian),
+			branch,
+			formatTime(&user.CreatedAt),
+			formatTime(user.DeletedAt),
+		})
+	}
+
+	if users == nil {
+		users = []dtos.UserResponseDTO{}
+	}
+	if err := tool.print(users, []string{"ID", "NOME", "EMAIL", "ADMIN", "BIBLIOTECÁRIO", "UNIDADE", "CRIADO EM", "REMOVIDO EM"}, rows); err != nil {
+		return tool.fail("falha ao escrever o resultado", err)
+	}
+	return 0
+}